@@ -15,6 +15,7 @@ import (
 	logger "github.com/devWaylander/coins_store/internal/middleware/logger"
 	"github.com/devWaylander/coins_store/internal/repo"
 	"github.com/devWaylander/coins_store/internal/service"
+	"github.com/devWaylander/coins_store/internal/worker"
 	errorgroup "github.com/devWaylander/coins_store/pkg/error_group"
 	"github.com/devWaylander/coins_store/pkg/log"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -61,13 +62,14 @@ func main() {
 
 	// Repositories
 	usecaseRepo := repo.New(dbPool)
+	txManager := repo.NewTxManager(dbPool)
 	authMiddlewareRepo := auth.NewAuthRepo(dbPool)
 
-	// Auth Middleware
-	authMiddleware := auth.NewMiddleware(authMiddlewareRepo, cfg.Common.JWTSecret)
-
 	// Service
-	service := service.New(usecaseRepo)
+	service := service.New(usecaseRepo, txManager, cfg)
+
+	// Auth Middleware
+	authMiddleware := auth.NewMiddleware(authMiddlewareRepo, cfg.Common.JWTSecret, service)
 
 	// Handler
 	mux := http.NewServeMux()
@@ -87,6 +89,24 @@ func main() {
 		log.Logger.Info().Msgf("Server is up on port: %s", cfg.Common.Port)
 		return httpServer.ListenAndServe()
 	})
+	g.Go(func() error {
+		return worker.Run(gCtx, "schedules", cfg.Scheduler.Interval, service.ExecuteDueSchedules)
+	})
+	g.Go(func() error {
+		return worker.Run(gCtx, "allowance", cfg.Allowance.CheckInterval, service.PayAllowance)
+	})
+	g.Go(func() error {
+		return worker.Run(gCtx, "expiration", cfg.Expiration.CheckInterval, service.ExpireCoins)
+	})
+	g.Go(func() error {
+		return worker.Run(gCtx, "holds", cfg.Hold.SweepInterval, service.ReleaseExpiredHolds)
+	})
+	g.Go(func() error {
+		return worker.Run(gCtx, "fraud", cfg.Fraud.CheckInterval, service.DetectFraud)
+	})
+	g.Go(func() error {
+		return worker.Run(gCtx, "snapshots", cfg.Snapshot.CheckInterval, service.SnapshotBalances)
+	})
 	g.Go(func() error {
 		<-gCtx.Done()
 		log.Logger.Info().Msgf("Server on port %s is shutting down", cfg.Common.Port)
@@ -5,19 +5,42 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+	// часовой пояс компании должен загружаться и в образе без системной базы часовых поясов
+	_ "time/tzdata"
 
 	"github.com/caarlos0/env/v10"
 	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
 	"github.com/joho/godotenv"
 )
 
+const (
+	AllowancePeriodDaily   = "daily"
+	AllowancePeriodWeekly  = "weekly"
+	AllowancePeriodMonthly = "monthly"
+
+	ExpirationTransferKeep  = "keep"
+	ExpirationTransferReset = "reset"
+)
+
 var (
 	C Config
 )
 
 type Config struct {
-	Common Common `envPrefix:"COMMON_"`
-	DB     DB     `envPrefix:"DB_"`
+	Common         Common         `envPrefix:"COMMON_"`
+	DB             DB             `envPrefix:"DB_"`
+	Scheduler      Scheduler      `envPrefix:"SCHEDULER_"`
+	Allowance      Allowance      `envPrefix:"ALLOWANCE_"`
+	Expiration     Expiration     `envPrefix:"EXPIRATION_"`
+	PaymentRequest PaymentRequest `envPrefix:"PAYMENT_REQUEST_"`
+	Hold           Hold           `envPrefix:"HOLD_"`
+	TransferLimit  TransferLimit  `envPrefix:"TRANSFER_LIMIT_"`
+	Fraud          Fraud          `envPrefix:"FRAUD_"`
+	Snapshot       Snapshot       `envPrefix:"SNAPSHOT_"`
+	Referral       Referral       `envPrefix:"REFERRAL_"`
+	CheckIn        CheckIn        `envPrefix:"CHECK_IN_"`
+	Notification   Notification   `envPrefix:"NOTIFICATION_"`
 }
 
 type Common struct {
@@ -40,6 +63,152 @@ type DB struct {
 	DBUrl                string        `json:"dbURL"`
 }
 
+type Scheduler struct {
+	Interval time.Duration `env:"INTERVAL" envDefault:"10s"`
+	// MaxFailures подряд неудачных запусков по бизнес-причинам ставят расписание на паузу
+	MaxFailures int `env:"MAX_FAILURES" envDefault:"3"`
+	BatchSize   int `env:"BATCH_SIZE" envDefault:"100"`
+	// RetryDelay через сколько повторяется разовый перевод после сбоя инфраструктуры
+	RetryDelay time.Duration `env:"RETRY_DELAY" envDefault:"1m"`
+}
+
+type Allowance struct {
+	// Amount 0 отключает периодическое начисление
+	Amount int64 `env:"AMOUNT" envDefault:"0"`
+	// Period daily, weekly или monthly
+	Period        string        `env:"PERIOD" envDefault:"monthly"`
+	CheckInterval time.Duration `env:"CHECK_INTERVAL" envDefault:"1h"`
+}
+
+type Expiration struct {
+	// Months 0 отключает сгорание монет
+	Months int `env:"MONTHS" envDefault:"0"`
+	// SoonWindow за сколько до сгорания монеты показываются в /api/info
+	SoonWindow time.Duration `env:"SOON_WINDOW" envDefault:"720h"`
+	// TransferPolicy keep или reset срока действия переведённых монет
+	TransferPolicy string        `env:"TRANSFER_POLICY" envDefault:"keep"`
+	CheckInterval  time.Duration `env:"CHECK_INTERVAL" envDefault:"1h"`
+	BatchSize      int           `env:"BATCH_SIZE" envDefault:"100"`
+}
+
+type PaymentRequest struct {
+	// TTL время, через которое неоплаченный запрос монет истекает
+	TTL time.Duration `env:"TTL" envDefault:"72h"`
+}
+
+type Hold struct {
+	DefaultTTL    time.Duration `env:"DEFAULT_TTL" envDefault:"24h"`
+	MaxTTL        time.Duration `env:"MAX_TTL" envDefault:"720h"`
+	SweepInterval time.Duration `env:"SWEEP_INTERVAL" envDefault:"1m"`
+}
+
+type TransferLimit struct {
+	Default TransferLimits `envPrefix:"DEFAULT_"`
+	// Admin переопределения для роли admin
+	Admin TransferLimits `envPrefix:"ADMIN_"`
+	// ReceivedPeriod период лимита на получение от одного отправителя: daily, weekly или monthly
+	ReceivedPeriod string `env:"RECEIVED_PERIOD" envDefault:"daily"`
+}
+
+// TransferLimits лимиты переводов, 0 - без ограничения.
+// В переопределениях для роли 0 - значение по умолчанию, -1 - без ограничения.
+type TransferLimits struct {
+	// MaxAmount максимальная сумма одного перевода
+	MaxAmount int64 `env:"MAX_AMOUNT"`
+	// DailyTotal и WeeklyTotal максимальная сумма отправленного за календарные сутки и неделю
+	DailyTotal  int64 `env:"DAILY_TOTAL"`
+	WeeklyTotal int64 `env:"WEEKLY_TOTAL"`
+	// HourlyCount максимальное количество переводов за последние 60 минут
+	HourlyCount int64 `env:"HOURLY_COUNT"`
+	// ReceivedTotal максимальная сумма, получаемая от одного отправителя за ReceivedPeriod
+	ReceivedTotal int64 `env:"RECEIVED_TOTAL"`
+}
+
+// ForRole возвращает лимиты роли с учётом переопределений, 0 в результате - без ограничения
+func (tl TransferLimit) ForRole(role string) TransferLimits {
+	limits := tl.Default
+	if role != models.RoleAdmin {
+		return limits
+	}
+
+	override := func(limit *int64, value int64) {
+		switch {
+		case value < 0:
+			*limit = 0
+		case value > 0:
+			*limit = value
+		}
+	}
+	override(&limits.MaxAmount, tl.Admin.MaxAmount)
+	override(&limits.DailyTotal, tl.Admin.DailyTotal)
+	override(&limits.WeeklyTotal, tl.Admin.WeeklyTotal)
+	override(&limits.HourlyCount, tl.Admin.HourlyCount)
+	override(&limits.ReceivedTotal, tl.Admin.ReceivedTotal)
+
+	return limits
+}
+
+// Enabled false, если ни для одной роли лимиты не заданы
+func (tl TransferLimit) Enabled() bool {
+	return tl.Default != TransferLimits{} || tl.Admin != TransferLimits{}
+}
+
+type Fraud struct {
+	CheckInterval time.Duration `env:"CHECK_INTERVAL" envDefault:"10m"`
+	// Window за какой период истории проверяются правила
+	Window time.Duration `env:"WINDOW" envDefault:"24h"`
+	// NewAccountAge и FunnelMinSenders сколько аккаунтов, переводящих монеты в течение NewAccountAge
+	// после регистрации, должны перевести монеты одному получателю
+	NewAccountAge    time.Duration `env:"NEW_ACCOUNT_AGE" envDefault:"72h"`
+	FunnelMinSenders int           `env:"FUNNEL_MIN_SENDERS" envDefault:"5"`
+	// BurstWindow и BurstAmount сколько монет аккаунт должен отправить в течение BurstWindow после регистрации
+	BurstWindow time.Duration `env:"BURST_WINDOW" envDefault:"1h"`
+	BurstAmount int64         `env:"BURST_AMOUNT" envDefault:"800"`
+	// AutoHold блокирует отправку и покупки аккаунтов из новых кейсов до разбора администратором
+	AutoHold bool `env:"AUTO_HOLD" envDefault:"false"`
+}
+
+type Snapshot struct {
+	CheckInterval time.Duration `env:"CHECK_INTERVAL" envDefault:"1h"`
+	// BackfillDays за сколько прошедших суток создаются снимки, если снимков ещё нет
+	BackfillDays int `env:"BACKFILL_DAYS" envDefault:"7"`
+}
+
+type Referral struct {
+	// Bonus монеты, которые получают приглашённый и пригласивший после первой активности приглашённого,
+	// 0 отключает бонусы, приглашения при этом учитываются
+	Bonus int64 `env:"BONUS" envDefault:"0"`
+	// MinAmount сколько заработанных монет (не из стартового баланса) должна потратить первая активность,
+	// чтобы приглашение засчиталось. Значения меньше 1 считаются как 1.
+	MinAmount int64 `env:"MIN_AMOUNT" envDefault:"1"`
+}
+
+type CheckIn struct {
+	// Reward монеты за ежедневную отметку, 0 отключает отметки
+	Reward int64 `env:"REWARD" envDefault:"5"`
+	// StreakBonus прибавка к награде за каждый день серии после первого, StreakBonusMax - предел прибавки
+	StreakBonus    int64 `env:"STREAK_BONUS" envDefault:"1"`
+	StreakBonusMax int64 `env:"STREAK_BONUS_MAX" envDefault:"10"`
+	// Timezone часовой пояс компании, в котором считаются сутки отметок
+	Timezone string `env:"TIMEZONE" envDefault:"UTC"`
+}
+
+// Location часовой пояс отметок, UTC если он не задан или не загружается
+func (c CheckIn) Location() *time.Location {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+type Notification struct {
+	// LowBalanceThreshold порог уведомления о низком балансе монет по умолчанию, 0 отключает уведомление
+	// для пользователей, не задавших свой порог
+	LowBalanceThreshold int64 `env:"LOW_BALANCE_THRESHOLD" envDefault:"100"`
+}
+
 func Parse() (Config, error) {
 	isContainer := isRunningInContainer()
 
@@ -67,6 +236,28 @@ func Parse() (Config, error) {
 		return C, err
 	}
 
+	switch C.Allowance.Period {
+	case AllowancePeriodDaily, AllowancePeriodWeekly, AllowancePeriodMonthly:
+	default:
+		return C, fmt.Errorf("unknown allowance period: %s", C.Allowance.Period)
+	}
+
+	switch C.Expiration.TransferPolicy {
+	case ExpirationTransferKeep, ExpirationTransferReset:
+	default:
+		return C, fmt.Errorf("unknown expiration transfer policy: %s", C.Expiration.TransferPolicy)
+	}
+
+	switch C.TransferLimit.ReceivedPeriod {
+	case AllowancePeriodDaily, AllowancePeriodWeekly, AllowancePeriodMonthly:
+	default:
+		return C, fmt.Errorf("unknown transfer limit received period: %s", C.TransferLimit.ReceivedPeriod)
+	}
+
+	if _, err := time.LoadLocation(C.CheckIn.Timezone); err != nil {
+		return C, fmt.Errorf("unknown check-in timezone: %s", C.CheckIn.Timezone)
+	}
+
 	C.DB.DBUrl = C.DB.DBLocalUrl
 	if isContainer {
 		C.DB.DBUrl = C.DB.DBContainerUrl
@@ -0,0 +1,6 @@
+-- migrate:up
+-- имя предмета уникально только в рамках инвентаря, это уже обеспечивает первичный ключ
+ALTER TABLE shop."inventory_merch" DROP CONSTRAINT IF EXISTS "inventory_merch_name_key";
+
+-- migrate:down
+ALTER TABLE shop."inventory_merch" ADD CONSTRAINT "inventory_merch_name_key" UNIQUE (name);
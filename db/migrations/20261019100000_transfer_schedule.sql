@@ -0,0 +1,37 @@
+-- migrate:up
+-- transfer schedule
+CREATE TABLE shop."transfer_schedule" (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    recipient VARCHAR(64) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    -- NULL для разового перевода
+    interval_seconds BIGINT DEFAULT NULL CHECK (interval_seconds > 0),
+    next_run_at TIMESTAMPTZ NOT NULL,
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    paused_at TIMESTAMPTZ DEFAULT NULL,
+    completed_at TIMESTAMPTZ DEFAULT NULL,
+    deleted_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "transfer_schedule@user_id_idx" ON shop."transfer_schedule" (user_id);
+CREATE INDEX "transfer_schedule@next_run_at_idx" ON shop."transfer_schedule" (next_run_at)
+    WHERE paused_at IS NULL AND completed_at IS NULL AND deleted_at IS NULL;
+
+-- transfer schedule run
+CREATE TABLE shop."transfer_schedule_run" (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL REFERENCES shop."transfer_schedule" (id),
+    scheduled_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    error VARCHAR(255) DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- один запуск на каждое срабатывание расписания
+CREATE UNIQUE INDEX "transfer_schedule_run@schedule_id_scheduled_at_idx" ON shop."transfer_schedule_run" (schedule_id, scheduled_at);
+
+-- migrate:down
+DROP TABLE IF EXISTS shop."transfer_schedule_run";
+DROP TABLE IF EXISTS shop."transfer_schedule";
@@ -0,0 +1,41 @@
+-- migrate:up
+-- роли пользователей
+ALTER TABLE shop."user" ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'user';
+
+-- системные балансы (казначейство) могут уходить в минус: их баланс равен выпущенным монетам со знаком минус
+ALTER TABLE shop."balance" ADD COLUMN is_system BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE shop."balance" DROP CONSTRAINT IF EXISTS "balance_amount_check";
+ALTER TABLE shop."balance" ADD CONSTRAINT "balance_amount_check" CHECK (amount >= 0 OR is_system);
+
+-- system account
+CREATE TABLE shop."system_account" (
+    name VARCHAR(64) PRIMARY KEY,
+    balance_id BIGINT NOT NULL REFERENCES shop."balance" (id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- тип и причина операции в истории
+ALTER TABLE shop."balance_history" ADD COLUMN type VARCHAR(32) NOT NULL DEFAULT 'transfer';
+ALTER TABLE shop."balance_history" ADD COLUMN reason VARCHAR(255) DEFAULT NULL;
+
+UPDATE shop."balance_history" SET type = 'purchase' WHERE recipient = 'AvitoShop';
+
+-- allowance run, одна выплата за период
+CREATE TABLE shop."allowance_run" (
+    period_start TIMESTAMPTZ PRIMARY KEY,
+    amount BIGINT NOT NULL,
+    users_count BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- migrate:down
+DROP TABLE IF EXISTS shop."allowance_run";
+ALTER TABLE shop."balance_history" DROP COLUMN IF EXISTS reason;
+ALTER TABLE shop."balance_history" DROP COLUMN IF EXISTS type;
+DROP TABLE IF EXISTS shop."system_account";
+ALTER TABLE shop."balance" DROP CONSTRAINT IF EXISTS "balance_amount_check";
+DELETE FROM shop."balance_history" WHERE balance_id IN (SELECT id FROM shop."balance" WHERE is_system);
+DELETE FROM shop."balance" WHERE is_system;
+ALTER TABLE shop."balance" ADD CONSTRAINT "balance_amount_check" CHECK (amount >= 0);
+ALTER TABLE shop."balance" DROP COLUMN IF EXISTS is_system;
+ALTER TABLE shop."user" DROP COLUMN IF EXISTS role;
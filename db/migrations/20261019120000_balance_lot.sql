@@ -0,0 +1,25 @@
+-- migrate:up
+-- лоты монет: сумма остатков лотов пользователя всегда равна его балансу
+CREATE TABLE shop."balance_lot" (
+    id BIGSERIAL PRIMARY KEY,
+    balance_id BIGINT NOT NULL REFERENCES shop."balance" (id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    granted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "balance_lot@balance_id_granted_at_idx" ON shop."balance_lot" (balance_id, granted_at, id);
+CREATE INDEX "balance_lot@granted_at_idx" ON shop."balance_lot" (granted_at);
+
+-- срок жизни существующих монет отсчитывается с момента введения политики
+INSERT INTO
+    shop."balance_lot" (balance_id, amount)
+SELECT
+    b.id, b.amount
+FROM
+    shop."balance" b
+WHERE
+    NOT b.is_system AND b.amount > 0;
+
+-- migrate:down
+DROP TABLE IF EXISTS shop."balance_lot";
@@ -0,0 +1,23 @@
+-- migrate:up
+-- payment request, запрос монет у другого пользователя
+CREATE TABLE shop."payment_request" (
+    id BIGSERIAL PRIMARY KEY,
+    requester_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    payer VARCHAR(64) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    memo VARCHAR(255) DEFAULT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMPTZ NOT NULL,
+    resolved_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "payment_request@requester_id_idx" ON shop."payment_request" (requester_id);
+CREATE INDEX "payment_request@payer_idx" ON shop."payment_request" (payer);
+
+-- не больше одного ожидающего запроса одной суммы от пользователя к плательщику
+CREATE UNIQUE INDEX "payment_request@pending_idx" ON shop."payment_request" (requester_id, payer, amount)
+    WHERE status = 'pending';
+
+-- migrate:down
+DROP TABLE IF EXISTS shop."payment_request";
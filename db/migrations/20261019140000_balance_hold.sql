@@ -0,0 +1,22 @@
+-- migrate:up
+-- balance hold, зарезервированные, но ещё не потраченные монеты
+CREATE TABLE shop."balance_hold" (
+    id BIGSERIAL PRIMARY KEY,
+    balance_id BIGINT NOT NULL REFERENCES shop."balance" (id),
+    owner_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    recipient VARCHAR(64) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    reference VARCHAR(128) DEFAULT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMPTZ NOT NULL,
+    resolved_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "balance_hold@balance_id_idx" ON shop."balance_hold" (balance_id) WHERE status = 'active';
+CREATE INDEX "balance_hold@owner_id_idx" ON shop."balance_hold" (owner_id);
+CREATE INDEX "balance_hold@recipient_idx" ON shop."balance_hold" (recipient);
+CREATE INDEX "balance_hold@expires_at_idx" ON shop."balance_hold" (expires_at) WHERE status = 'active';
+
+-- migrate:down
+DROP TABLE IF EXISTS shop."balance_hold";
@@ -0,0 +1,6 @@
+-- migrate:up
+-- использование лимитов переводов считается по истории баланса за период
+CREATE INDEX "balance_history@transfer_balance_id_created_at_idx" ON shop."balance_history" (balance_id, created_at) WHERE type = 'transfer';
+
+-- migrate:down
+DROP INDEX IF EXISTS shop."balance_history@transfer_balance_id_created_at_idx";
@@ -0,0 +1,28 @@
+-- migrate:up
+-- fraud case, одно срабатывание правила на набор аккаунтов
+CREATE TABLE shop."fraud_case" (
+    id BIGSERIAL PRIMARY KEY,
+    rule VARCHAR(32) NOT NULL,
+    -- subject_key однозначно описывает набор аккаунтов, повторное срабатывание на тот же набор не создаёт новый кейс
+    subject_key VARCHAR(1024) NOT NULL,
+    usernames VARCHAR(64)[] NOT NULL,
+    details TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    resolved_by VARCHAR(64) DEFAULT NULL,
+    resolved_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX "fraud_case@rule_subject_key_idx" ON shop."fraud_case" (rule, subject_key);
+CREATE INDEX "fraud_case@status_idx" ON shop."fraud_case" (status);
+
+-- автоматическая блокировка отправки и покупок по срабатыванию правил
+ALTER TABLE shop."user" ADD COLUMN fraud_hold BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- правила ищут переводы по времени во всей истории
+CREATE INDEX "balance_history@transfer_created_at_idx" ON shop."balance_history" (created_at) WHERE type = 'transfer';
+
+-- migrate:down
+DROP INDEX IF EXISTS shop."balance_history@transfer_created_at_idx";
+ALTER TABLE shop."user" DROP COLUMN IF EXISTS fraud_hold;
+DROP TABLE IF EXISTS shop."fraud_case";
@@ -0,0 +1,21 @@
+-- migrate:up
+-- active, frozen (вход и просмотр без отправки и покупок) или suspended (без входа).
+-- Удалённые (offboarded) аккаунты отмечаются deleted_at.
+ALTER TABLE shop."user" ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active';
+
+-- account audit, действия администраторов над аккаунтами
+CREATE TABLE shop."account_audit" (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    action VARCHAR(16) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    actor VARCHAR(64) NOT NULL,
+    swept_amount BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "account_audit@user_id_idx" ON shop."account_audit" (user_id);
+
+-- migrate:down
+DROP TABLE IF EXISTS shop."account_audit";
+ALTER TABLE shop."user" DROP COLUMN IF EXISTS status;
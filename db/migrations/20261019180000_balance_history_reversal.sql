@@ -0,0 +1,12 @@
+-- migrate:up
+-- Отмена перевода не удаляет историю: у обеих сторон создаются компенсирующие записи типа reversal,
+-- reversal_of ссылается на отменённую запись того же баланса.
+ALTER TABLE shop."balance_history" ADD COLUMN reversal_of BIGINT DEFAULT NULL REFERENCES shop."balance_history" (id);
+ALTER TABLE shop."balance_history" ADD COLUMN actor VARCHAR(64) DEFAULT NULL;
+
+CREATE UNIQUE INDEX "balance_history@reversal_of_idx" ON shop."balance_history" (reversal_of) WHERE reversal_of IS NOT NULL;
+
+-- migrate:down
+DROP INDEX IF EXISTS shop."balance_history@reversal_of_idx";
+ALTER TABLE shop."balance_history" DROP COLUMN IF EXISTS actor;
+ALTER TABLE shop."balance_history" DROP COLUMN IF EXISTS reversal_of;
@@ -0,0 +1,9 @@
+-- migrate:up
+-- название купленного предмета для выписок, у покупок до миграции не заполнено
+ALTER TABLE shop."balance_history" ADD COLUMN merch_name VARCHAR(255) DEFAULT NULL;
+
+CREATE INDEX "balance_history@balance_id_created_at_idx" ON shop."balance_history" (balance_id, created_at);
+
+-- migrate:down
+DROP INDEX IF EXISTS shop."balance_history@balance_id_created_at_idx";
+ALTER TABLE shop."balance_history" DROP COLUMN IF EXISTS merch_name;
@@ -0,0 +1,14 @@
+-- migrate:up
+-- остаток баланса на конец суток (UTC), отправная точка для расчёта баланса на момент времени
+CREATE TABLE shop."balance_snapshot" (
+    PRIMARY KEY (balance_id, day),
+    balance_id BIGINT NOT NULL REFERENCES shop."balance" (id),
+    day DATE NOT NULL,
+    amount BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "balance_snapshot@day_idx" ON shop."balance_snapshot" (day);
+
+-- migrate:down
+DROP TABLE IF EXISTS shop."balance_snapshot";
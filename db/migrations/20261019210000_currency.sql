@@ -0,0 +1,47 @@
+-- migrate:up
+-- справочник валют, монеты остаются валютой по умолчанию
+CREATE TABLE shop."currency" (
+    code VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO shop."currency" (code, name) VALUES
+    ('coins', 'Монеты'),
+    ('kudos', 'Благодарности');
+
+-- кошелёк: один баланс на пользователя в каждой валюте
+ALTER TABLE shop."balance" ADD COLUMN currency VARCHAR(32) NOT NULL DEFAULT 'coins' REFERENCES shop."currency" (code);
+ALTER TABLE shop."balance" ADD COLUMN user_id BIGINT DEFAULT NULL REFERENCES shop."user" (id);
+
+UPDATE shop."balance" b SET user_id = u.id FROM shop."user" u WHERE u.balance_id = b.id;
+
+CREATE UNIQUE INDEX "balance@user_id_currency_idx" ON shop."balance" (user_id, currency);
+
+-- валюта записи истории совпадает с валютой баланса
+ALTER TABLE shop."balance_history" ADD COLUMN currency VARCHAR(32) NOT NULL DEFAULT 'coins' REFERENCES shop."currency" (code);
+
+-- цена мерча может быть в любой валюте
+ALTER TABLE shop."merch" ADD COLUMN currency VARCHAR(32) NOT NULL DEFAULT 'coins' REFERENCES shop."currency" (code);
+
+-- системный счёт заводится на каждую валюту
+ALTER TABLE shop."system_account" ADD COLUMN currency VARCHAR(32) NOT NULL DEFAULT 'coins' REFERENCES shop."currency" (code);
+ALTER TABLE shop."system_account" DROP CONSTRAINT "system_account_pkey";
+ALTER TABLE shop."system_account" ADD PRIMARY KEY (name, currency);
+
+-- migrate:down
+DELETE FROM shop."system_account" WHERE currency <> 'coins';
+DELETE FROM shop."balance_history" WHERE balance_id IN (SELECT id FROM shop."balance" WHERE currency <> 'coins');
+DELETE FROM shop."balance_lot" WHERE balance_id IN (SELECT id FROM shop."balance" WHERE currency <> 'coins');
+DELETE FROM shop."balance_hold" WHERE balance_id IN (SELECT id FROM shop."balance" WHERE currency <> 'coins');
+DELETE FROM shop."balance_snapshot" WHERE balance_id IN (SELECT id FROM shop."balance" WHERE currency <> 'coins');
+DELETE FROM shop."balance" WHERE currency <> 'coins';
+ALTER TABLE shop."system_account" DROP CONSTRAINT "system_account_pkey";
+ALTER TABLE shop."system_account" ADD PRIMARY KEY (name);
+ALTER TABLE shop."system_account" DROP COLUMN IF EXISTS currency;
+ALTER TABLE shop."merch" DROP COLUMN IF EXISTS currency;
+ALTER TABLE shop."balance_history" DROP COLUMN IF EXISTS currency;
+DROP INDEX IF EXISTS shop."balance@user_id_currency_idx";
+ALTER TABLE shop."balance" DROP COLUMN IF EXISTS user_id;
+ALTER TABLE shop."balance" DROP COLUMN IF EXISTS currency;
+DROP TABLE IF EXISTS shop."currency";
@@ -0,0 +1,39 @@
+-- migrate:up
+-- команда с общим кошельком монет
+CREATE TABLE shop."team" (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(58) UNIQUE NOT NULL,
+    balance_id BIGINT NOT NULL REFERENCES shop."balance" (id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- участники команды: владельцы управляют составом, тратить общий кошелёк могут владельцы и участники с can_spend
+CREATE TABLE shop."team_member" (
+    PRIMARY KEY (team_id, user_id),
+    team_id BIGINT NOT NULL REFERENCES shop."team" (id),
+    user_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    role VARCHAR(16) NOT NULL DEFAULT 'member',
+    can_spend BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "team_member@user_id_idx" ON shop."team_member" (user_id);
+
+-- инвентарь принадлежит пользователю или команде
+ALTER TABLE shop."inventory" ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE shop."inventory" ADD COLUMN team_id BIGINT UNIQUE DEFAULT NULL REFERENCES shop."team" (id);
+ALTER TABLE shop."inventory" ADD CONSTRAINT "inventory_owner_check" CHECK ((user_id IS NULL) <> (team_id IS NULL));
+
+-- migrate:down
+DELETE FROM shop."inventory_merch" WHERE inventory_id IN (SELECT id FROM shop."inventory" WHERE team_id IS NOT NULL);
+DELETE FROM shop."inventory" WHERE team_id IS NOT NULL;
+ALTER TABLE shop."inventory" DROP CONSTRAINT IF EXISTS "inventory_owner_check";
+ALTER TABLE shop."inventory" DROP COLUMN IF EXISTS team_id;
+ALTER TABLE shop."inventory" ALTER COLUMN user_id SET NOT NULL;
+DROP TABLE IF EXISTS shop."team_member";
+DELETE FROM shop."balance_history" WHERE balance_id IN (SELECT balance_id FROM shop."team");
+DELETE FROM shop."balance_lot" WHERE balance_id IN (SELECT balance_id FROM shop."team");
+DELETE FROM shop."balance_snapshot" WHERE balance_id IN (SELECT balance_id FROM shop."team");
+ALTER TABLE shop."team" DROP CONSTRAINT IF EXISTS "team_balance_id_fkey";
+DELETE FROM shop."balance" WHERE id IN (SELECT balance_id FROM shop."team");
+DROP TABLE IF EXISTS shop."team";
@@ -0,0 +1,92 @@
+-- migrate:up
+-- организация (тенант): у каждой свой каталог, стартовый баланс и пользователи
+CREATE TABLE shop."organization" (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) UNIQUE NOT NULL,
+    starting_balance BIGINT NOT NULL DEFAULT 1000 CHECK (starting_balance >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- существующие данные переходят в организацию по умолчанию
+INSERT INTO shop."organization" (name, starting_balance) VALUES ('default', 1000);
+
+ALTER TABLE shop."user" ADD COLUMN org_id BIGINT REFERENCES shop."organization" (id);
+ALTER TABLE shop."merch" ADD COLUMN org_id BIGINT REFERENCES shop."organization" (id);
+ALTER TABLE shop."balance" ADD COLUMN org_id BIGINT REFERENCES shop."organization" (id);
+ALTER TABLE shop."balance_history" ADD COLUMN org_id BIGINT REFERENCES shop."organization" (id);
+ALTER TABLE shop."system_account" ADD COLUMN org_id BIGINT REFERENCES shop."organization" (id);
+ALTER TABLE shop."allowance_run" ADD COLUMN org_id BIGINT REFERENCES shop."organization" (id);
+ALTER TABLE shop."fraud_case" ADD COLUMN org_id BIGINT REFERENCES shop."organization" (id);
+ALTER TABLE shop."team" ADD COLUMN org_id BIGINT REFERENCES shop."organization" (id);
+
+UPDATE shop."user" SET org_id = (SELECT id FROM shop."organization" WHERE name = 'default');
+UPDATE shop."merch" SET org_id = (SELECT id FROM shop."organization" WHERE name = 'default');
+UPDATE shop."balance" SET org_id = (SELECT id FROM shop."organization" WHERE name = 'default');
+UPDATE shop."balance_history" SET org_id = (SELECT id FROM shop."organization" WHERE name = 'default');
+UPDATE shop."system_account" SET org_id = (SELECT id FROM shop."organization" WHERE name = 'default');
+UPDATE shop."allowance_run" SET org_id = (SELECT id FROM shop."organization" WHERE name = 'default');
+UPDATE shop."fraud_case" SET org_id = (SELECT id FROM shop."organization" WHERE name = 'default');
+UPDATE shop."team" SET org_id = (SELECT id FROM shop."organization" WHERE name = 'default');
+
+ALTER TABLE shop."user" ALTER COLUMN org_id SET NOT NULL;
+ALTER TABLE shop."merch" ALTER COLUMN org_id SET NOT NULL;
+ALTER TABLE shop."balance" ALTER COLUMN org_id SET NOT NULL;
+ALTER TABLE shop."balance_history" ALTER COLUMN org_id SET NOT NULL;
+ALTER TABLE shop."system_account" ALTER COLUMN org_id SET NOT NULL;
+ALTER TABLE shop."allowance_run" ALTER COLUMN org_id SET NOT NULL;
+ALTER TABLE shop."fraud_case" ALTER COLUMN org_id SET NOT NULL;
+ALTER TABLE shop."team" ALTER COLUMN org_id SET NOT NULL;
+
+-- имена уникальны только внутри организации
+DROP INDEX IF EXISTS shop."user@username_idx";
+CREATE UNIQUE INDEX "user@org_id_username_idx" ON shop."user" (org_id, username);
+
+ALTER TABLE shop."merch" DROP CONSTRAINT IF EXISTS "merch_name_key";
+DROP INDEX IF EXISTS shop."merch@name_id_idx";
+CREATE UNIQUE INDEX "merch@org_id_name_idx" ON shop."merch" (org_id, name);
+
+ALTER TABLE shop."team" DROP CONSTRAINT IF EXISTS "team_name_key";
+CREATE UNIQUE INDEX "team@org_id_name_idx" ON shop."team" (org_id, name);
+
+-- системные счета, начисления и кейсы фрода ведутся отдельно по организациям
+ALTER TABLE shop."system_account" DROP CONSTRAINT "system_account_pkey";
+ALTER TABLE shop."system_account" ADD PRIMARY KEY (org_id, name, currency);
+
+ALTER TABLE shop."allowance_run" DROP CONSTRAINT "allowance_run_pkey";
+ALTER TABLE shop."allowance_run" ADD PRIMARY KEY (org_id, period_start);
+
+DROP INDEX IF EXISTS shop."fraud_case@rule_subject_key_idx";
+CREATE UNIQUE INDEX "fraud_case@org_id_rule_subject_key_idx" ON shop."fraud_case" (org_id, rule, subject_key);
+
+CREATE INDEX "balance@org_id_idx" ON shop."balance" (org_id);
+CREATE INDEX "balance_history@org_id_created_at_idx" ON shop."balance_history" (org_id, created_at);
+
+-- migrate:down
+DELETE FROM shop."inventory_merch" WHERE merch_id IN (
+    SELECT m.id FROM shop."merch" m INNER JOIN shop."organization" o ON o.id = m.org_id WHERE o.name <> 'default'
+);
+DELETE FROM shop."merch" WHERE org_id <> (SELECT id FROM shop."organization" WHERE name = 'default');
+DROP INDEX IF EXISTS shop."balance_history@org_id_created_at_idx";
+DROP INDEX IF EXISTS shop."balance@org_id_idx";
+DROP INDEX IF EXISTS shop."fraud_case@org_id_rule_subject_key_idx";
+CREATE UNIQUE INDEX "fraud_case@rule_subject_key_idx" ON shop."fraud_case" (rule, subject_key);
+ALTER TABLE shop."allowance_run" DROP CONSTRAINT "allowance_run_pkey";
+ALTER TABLE shop."allowance_run" ADD PRIMARY KEY (period_start);
+ALTER TABLE shop."system_account" DROP CONSTRAINT "system_account_pkey";
+ALTER TABLE shop."system_account" ADD PRIMARY KEY (name, currency);
+DROP INDEX IF EXISTS shop."team@org_id_name_idx";
+ALTER TABLE shop."team" ADD CONSTRAINT "team_name_key" UNIQUE (name);
+DROP INDEX IF EXISTS shop."merch@org_id_name_idx";
+ALTER TABLE shop."merch" ADD CONSTRAINT "merch_name_key" UNIQUE (name);
+CREATE INDEX "merch@name_id_idx" ON shop."merch" (name);
+DROP INDEX IF EXISTS shop."user@org_id_username_idx";
+CREATE UNIQUE INDEX "user@username_idx" ON shop."user" (username);
+ALTER TABLE shop."team" DROP COLUMN IF EXISTS org_id;
+ALTER TABLE shop."fraud_case" DROP COLUMN IF EXISTS org_id;
+ALTER TABLE shop."allowance_run" DROP COLUMN IF EXISTS org_id;
+ALTER TABLE shop."system_account" DROP COLUMN IF EXISTS org_id;
+ALTER TABLE shop."balance_history" DROP COLUMN IF EXISTS org_id;
+ALTER TABLE shop."balance" DROP COLUMN IF EXISTS org_id;
+ALTER TABLE shop."merch" DROP COLUMN IF EXISTS org_id;
+ALTER TABLE shop."user" DROP COLUMN IF EXISTS org_id;
+DROP TABLE IF EXISTS shop."organization";
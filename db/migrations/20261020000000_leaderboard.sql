@@ -0,0 +1,93 @@
+-- migrate:up
+-- агрегаты рейтинга по календарным периодам, обновляются при каждом переводе монет между пользователями
+CREATE TABLE shop."leaderboard_stat" (
+    PRIMARY KEY (user_id, period, period_start),
+    user_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    period VARCHAR(8) NOT NULL,
+    period_start TIMESTAMPTZ NOT NULL,
+    received BIGINT NOT NULL DEFAULT 0,
+    given BIGINT NOT NULL DEFAULT 0,
+    thanked BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX "leaderboard_stat@period_period_start_idx" ON shop."leaderboard_stat" (period, period_start);
+
+-- пары отправитель-получатель за период: первая пара увеличивает thanked отправителя
+CREATE TABLE shop."leaderboard_pair" (
+    PRIMARY KEY (sender_id, period, period_start, recipient_id),
+    sender_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    recipient_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    period VARCHAR(8) NOT NULL,
+    period_start TIMESTAMPTZ NOT NULL
+);
+
+-- пользователь может скрыть себя из рейтинга
+ALTER TABLE shop."user" ADD COLUMN leaderboard_opt_out BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- агрегаты по уже совершённым и не отменённым переводам монет
+WITH transfers AS (
+    SELECT
+        s.id AS sender_id,
+        r.id AS recipient_id,
+        bh.transaction_amount AS amount,
+        bh.created_at AT TIME ZONE 'UTC' AS created_at
+    FROM
+        shop."balance_history" bh
+    INNER JOIN
+        shop."user" r
+    ON
+        r.balance_id = bh.balance_id AND r.username = bh.recipient
+    INNER JOIN
+        shop."user" s
+    ON
+        s.org_id = r.org_id AND s.username = bh.sender
+    WHERE
+        bh.type = 'transfer' AND bh.currency = 'coins' AND NOT EXISTS (
+            SELECT 1 FROM shop."balance_history" rev WHERE rev.reversal_of = bh.id
+        )
+), periods AS (
+    SELECT
+        t.sender_id,
+        t.recipient_id,
+        t.amount,
+        p.period,
+        p.period_start
+    FROM
+        transfers t
+    CROSS JOIN LATERAL (VALUES
+        ('week', date_trunc('week', t.created_at) AT TIME ZONE 'UTC'),
+        ('month', date_trunc('month', t.created_at) AT TIME ZONE 'UTC'),
+        ('all', TIMESTAMPTZ '1970-01-01 00:00:00+00')
+    ) AS p (period, period_start)
+), pairs AS (
+    INSERT INTO shop."leaderboard_pair" (sender_id, recipient_id, period, period_start)
+    SELECT DISTINCT
+        sender_id, recipient_id, period, period_start
+    FROM
+        periods
+), stats AS (
+    SELECT sender_id AS user_id, period, period_start, 0 AS received, amount AS given FROM periods
+    UNION ALL
+    SELECT recipient_id, period, period_start, amount, 0 FROM periods
+)
+INSERT INTO shop."leaderboard_stat" (user_id, period, period_start, received, given, thanked)
+SELECT
+    st.user_id,
+    st.period,
+    st.period_start,
+    SUM(st.received),
+    SUM(st.given),
+    (
+        SELECT COUNT(DISTINCT p.recipient_id)
+        FROM periods p
+        WHERE p.sender_id = st.user_id AND p.period = st.period AND p.period_start = st.period_start
+    )
+FROM
+    stats st
+GROUP BY
+    st.user_id, st.period, st.period_start;
+
+-- migrate:down
+ALTER TABLE shop."user" DROP COLUMN IF EXISTS leaderboard_opt_out;
+DROP TABLE IF EXISTS shop."leaderboard_pair";
+DROP TABLE IF EXISTS shop."leaderboard_stat";
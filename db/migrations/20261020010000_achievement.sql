@@ -0,0 +1,73 @@
+-- migrate:up
+-- определения достижений: событие, на которое реагирует достижение, метрика, порог и награда в монетах
+CREATE TABLE shop."achievement" (
+    id BIGSERIAL PRIMARY KEY,
+    org_id BIGINT NOT NULL REFERENCES shop."organization" (id),
+    code VARCHAR(64) NOT NULL,
+    title VARCHAR(128) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    event VARCHAR(16) NOT NULL CHECK (event IN ('transfer', 'purchase', 'sign_in')),
+    metric VARCHAR(32) NOT NULL CHECK (metric IN ('sign_ins', 'transfers_sent', 'purchases', 'coins_given', 'coins_received', 'people_thanked', 'catalog_percent')),
+    threshold BIGINT NOT NULL CHECK (threshold > 0),
+    reward BIGINT NOT NULL DEFAULT 0 CHECK (reward >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX "achievement@org_id_code_idx" ON shop."achievement" (org_id, code);
+CREATE INDEX "achievement@org_id_event_idx" ON shop."achievement" (org_id, event);
+
+-- выданные достижения, первичный ключ не даёт выдать достижение дважды
+CREATE TABLE shop."user_achievement" (
+    user_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    achievement_id BIGINT NOT NULL REFERENCES shop."achievement" (id),
+    awarded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, achievement_id)
+);
+
+-- счётчики активности пользователя, которых нет в других агрегатах
+CREATE TABLE shop."user_activity" (
+    user_id BIGINT PRIMARY KEY REFERENCES shop."user" (id),
+    sign_ins BIGINT NOT NULL DEFAULT 0,
+    transfers_sent BIGINT NOT NULL DEFAULT 0,
+    purchases BIGINT NOT NULL DEFAULT 0
+);
+
+-- переводы и покупки до появления достижений, число входов неизвестно
+INSERT INTO shop."user_activity" (user_id, transfers_sent, purchases)
+SELECT
+    u.id,
+    COUNT(*) FILTER (WHERE bh.type = 'transfer' AND NOT EXISTS (SELECT 1 FROM shop."balance_history" r WHERE r.reversal_of = bh.id)),
+    COUNT(*) FILTER (WHERE bh.type = 'purchase')
+FROM
+    shop."user" u
+INNER JOIN
+    shop."balance" b
+ON
+    b.user_id = u.id AND b.currency = 'coins'
+INNER JOIN
+    shop."balance_history" bh
+ON
+    bh.balance_id = b.id AND bh.sender = u.username
+GROUP BY
+    u.id;
+
+INSERT INTO shop."achievement" (org_id, code, title, description, event, metric, threshold, reward)
+SELECT
+    o.id, a.code, a.title, a.description, a.event, a.metric, a.threshold, a.reward
+FROM
+    shop."organization" o
+CROSS JOIN (VALUES
+    ('first-sign-in', 'Добро пожаловать', 'Первый вход в магазин', 'sign_in', 'sign_ins', 1, 0),
+    ('regular', 'Завсегдатай', '30 входов в магазин', 'sign_in', 'sign_ins', 30, 20),
+    ('first-transfer', 'Первый перевод', 'Отправить монеты коллеге', 'transfer', 'transfers_sent', 1, 0),
+    ('thanked-10', 'Щедрая душа', 'Отправить монеты 10 разным коллегам', 'transfer', 'people_thanked', 10, 50),
+    ('received-1000', 'Любимец коллег', 'Получить 1000 монет от коллег', 'transfer', 'coins_received', 1000, 0),
+    ('first-purchase', 'Первая покупка', 'Купить первый предмет', 'purchase', 'purchases', 1, 0),
+    ('whole-catalog', 'Коллекционер', 'Купить каждый предмет каталога', 'purchase', 'catalog_percent', 100, 100)
+) AS a (code, title, description, event, metric, threshold, reward);
+
+-- migrate:down
+DROP TABLE IF EXISTS shop."user_activity";
+DROP TABLE IF EXISTS shop."user_achievement";
+DROP TABLE IF EXISTS shop."achievement";
@@ -0,0 +1,36 @@
+-- migrate:up
+-- публичные благодарности: создаются только для переводов, отмеченных отправителем как публичные
+CREATE TABLE shop."kudos" (
+    id BIGSERIAL PRIMARY KEY,
+    org_id BIGINT NOT NULL REFERENCES shop."organization" (id),
+    sender_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    recipient_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    message VARCHAR(255) NOT NULL,
+    currency VARCHAR(32) NOT NULL REFERENCES shop."currency" (code),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    -- монеты, добавленные другими пользователями сверху
+    boosted BIGINT NOT NULL DEFAULT 0 CHECK (boosted >= 0),
+    boosts BIGINT NOT NULL DEFAULT 0 CHECK (boosts >= 0),
+    -- отменённый администратором перевод скрывает благодарность из ленты
+    hidden_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- лента читается страницами по убыванию id
+CREATE INDEX "kudos@org_id_id_idx" ON shop."kudos" (org_id, id DESC) WHERE hidden_at IS NULL;
+
+CREATE TABLE shop."kudos_reaction" (
+    kudos_id BIGINT NOT NULL REFERENCES shop."kudos" (id),
+    user_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    emoji VARCHAR(16) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (kudos_id, user_id, emoji)
+);
+
+-- записи исходного перевода и добавленных сверху монет ссылаются на благодарность
+ALTER TABLE shop."balance_history" ADD COLUMN kudos_id BIGINT DEFAULT NULL REFERENCES shop."kudos" (id);
+
+-- migrate:down
+ALTER TABLE shop."balance_history" DROP COLUMN IF EXISTS kudos_id;
+DROP TABLE IF EXISTS shop."kudos_reaction";
+DROP TABLE IF EXISTS shop."kudos";
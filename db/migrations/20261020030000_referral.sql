@@ -0,0 +1,25 @@
+-- migrate:up
+-- у каждого пользователя свой код приглашения, существующие пользователи получают его при миграции
+ALTER TABLE shop."user" ADD COLUMN referral_code VARCHAR(16) NOT NULL
+    DEFAULT upper(substr(md5(random()::TEXT || clock_timestamp()::TEXT), 1, 10));
+CREATE UNIQUE INDEX "user@org_id_referral_code_idx" ON shop."user" (org_id, referral_code);
+
+-- приглашение фиксируется при регистрации, бонус выплачивается после первой активности приглашённого
+CREATE TABLE shop."referral" (
+    id BIGSERIAL PRIMARY KEY,
+    org_id BIGINT NOT NULL REFERENCES shop."organization" (id),
+    referrer_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    referee_id BIGINT NOT NULL UNIQUE REFERENCES shop."user" (id),
+    -- бонус каждой из сторон на момент выплаты
+    bonus BIGINT NOT NULL DEFAULT 0 CHECK (bonus >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    qualified_at TIMESTAMPTZ DEFAULT NULL,
+    CHECK (referrer_id <> referee_id)
+);
+
+CREATE INDEX "referral@referrer_id_idx" ON shop."referral" (referrer_id);
+
+-- migrate:down
+DROP TABLE IF EXISTS shop."referral";
+DROP INDEX IF EXISTS shop."user@org_id_referral_code_idx";
+ALTER TABLE shop."user" DROP COLUMN IF EXISTS referral_code;
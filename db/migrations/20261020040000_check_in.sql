@@ -0,0 +1,14 @@
+-- migrate:up
+-- ежедневные отметки: сутки считаются в часовом поясе компании, ключ не даёт отметиться дважды за сутки
+CREATE TABLE shop."check_in" (
+    user_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    day DATE NOT NULL,
+    -- номер дня в серии отметок подряд, пропущенный день начинает серию заново
+    streak INT NOT NULL CHECK (streak > 0),
+    reward BIGINT NOT NULL CHECK (reward >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, day)
+);
+
+-- migrate:down
+DROP TABLE IF EXISTS shop."check_in";
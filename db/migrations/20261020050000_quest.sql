@@ -0,0 +1,44 @@
+-- migrate:up
+-- ограниченные по времени задания: метрика считается по событиям перевода или покупки внутри периода задания
+CREATE TABLE shop."quest" (
+    id BIGSERIAL PRIMARY KEY,
+    org_id BIGINT NOT NULL REFERENCES shop."organization" (id),
+    title VARCHAR(128) NOT NULL,
+    description VARCHAR(512) NOT NULL DEFAULT '',
+    event VARCHAR(16) NOT NULL CHECK (event IN ('transfer', 'purchase')),
+    metric VARCHAR(32) NOT NULL CHECK (metric IN ('transfers', 'coins_sent', 'distinct_recipients', 'purchases', 'coins_spent')),
+    -- учитываются только переводы пользователям, не состоящим ни в одной команде с отправителем
+    other_team BOOLEAN NOT NULL DEFAULT FALSE,
+    target BIGINT NOT NULL CHECK (target > 0),
+    reward BIGINT NOT NULL DEFAULT 0 CHECK (reward >= 0),
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ DEFAULT NULL,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX "quest@org_id_event_ends_at_idx" ON shop."quest" (org_id, event, ends_at) WHERE deleted_at IS NULL;
+
+-- прогресс пользователя, completed_at выставляется один раз вместе с выплатой награды
+CREATE TABLE shop."quest_progress" (
+    quest_id BIGINT NOT NULL REFERENCES shop."quest" (id),
+    user_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    progress BIGINT NOT NULL DEFAULT 0,
+    completed_at TIMESTAMPTZ DEFAULT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (quest_id, user_id)
+);
+
+-- получатели для метрики distinct_recipients
+CREATE TABLE shop."quest_recipient" (
+    quest_id BIGINT NOT NULL REFERENCES shop."quest" (id),
+    user_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    recipient_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    PRIMARY KEY (quest_id, user_id, recipient_id)
+);
+
+-- migrate:down
+DROP TABLE IF EXISTS shop."quest_recipient";
+DROP TABLE IF EXISTS shop."quest_progress";
+DROP TABLE IF EXISTS shop."quest";
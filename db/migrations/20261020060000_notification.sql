@@ -0,0 +1,37 @@
+-- migrate:up
+-- входящие уведомления пользователя, пишутся в транзакции вызвавшего их события
+CREATE TABLE shop."notification" (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES shop."user" (id),
+    type VARCHAR(32) NOT NULL CHECK (type IN ('coins_received', 'purchase', 'low_balance', 'grant')),
+    -- отправитель монет: пользователь, команда или казначейство
+    from_user VARCHAR(255) DEFAULT NULL,
+    item VARCHAR(255) DEFAULT NULL,
+    status VARCHAR(16) DEFAULT NULL,
+    -- сумма события, для low_balance - баланс после списания
+    amount BIGINT NOT NULL DEFAULT 0,
+    currency VARCHAR(32) NOT NULL REFERENCES shop."currency" (code),
+    message VARCHAR(255) DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMPTZ DEFAULT NULL
+);
+
+-- входящие читаются страницами по убыванию id
+CREATE INDEX "notification@user_id_id_idx" ON shop."notification" (user_id, id DESC);
+CREATE INDEX "notification@user_id_unread_idx" ON shop."notification" (user_id) WHERE read_at IS NULL;
+
+-- настройки уведомлений, отсутствие строки означает настройки по умолчанию
+CREATE TABLE shop."notification_preference" (
+    user_id BIGINT PRIMARY KEY REFERENCES shop."user" (id),
+    coins_received BOOLEAN NOT NULL DEFAULT TRUE,
+    purchase BOOLEAN NOT NULL DEFAULT TRUE,
+    low_balance BOOLEAN NOT NULL DEFAULT TRUE,
+    admin_grant BOOLEAN NOT NULL DEFAULT TRUE,
+    -- NULL - порог из конфигурации
+    low_balance_threshold BIGINT DEFAULT NULL CHECK (low_balance_threshold >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- migrate:down
+DROP TABLE IF EXISTS shop."notification_preference";
+DROP TABLE IF EXISTS shop."notification";
@@ -0,0 +1,11 @@
+-- migrate:up
+-- обе записи перевода получают общий transfer_id, по нему находится вторая сторона перевода при отмене.
+-- У записей, созданных до миграции, transfer_id пустой
+CREATE SEQUENCE shop."balance_history_transfer_id_seq";
+ALTER TABLE shop."balance_history" ADD COLUMN transfer_id BIGINT DEFAULT NULL;
+CREATE INDEX "balance_history@transfer_id_idx" ON shop."balance_history" (transfer_id);
+
+-- migrate:down
+DROP INDEX IF EXISTS shop."balance_history@transfer_id_idx";
+ALTER TABLE shop."balance_history" DROP COLUMN IF EXISTS transfer_id;
+DROP SEQUENCE IF EXISTS shop."balance_history_transfer_id_seq";
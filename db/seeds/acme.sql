@@ -0,0 +1,20 @@
+-- демонстрационная организация acme со своим каталогом и стартовым балансом.
+-- Скрипт идемпотентный, используется для локального окружения и e2e тестов.
+INSERT INTO shop."organization" (name, starting_balance) VALUES ('acme', 500)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO shop."merch" (org_id, name, price)
+SELECT
+    o.id, m.name, m.price
+FROM
+    shop."organization" o
+CROSS JOIN (VALUES
+    ('t-shirt', 60),
+    ('cup', 15),
+    ('sticker', 5),
+    ('notebook', 40),
+    ('backpack', 250)
+) AS m (name, price)
+WHERE
+    o.name = 'acme'
+ON CONFLICT (org_id, name) DO NOTHING;
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+)
+
+func newAchievementHandles(mux *http.ServeMux, service Service) {
+	// Получить профиль пользователя с выданными достижениями.
+	mux.HandleFunc("GET /api/users/{username}", func(w http.ResponseWriter, r *http.Request) {
+		profileDTO, err := service.GetProfile(r.Context(), r.PathValue("username"))
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrUserNotFound:
+				http.Error(w, internalErrors.ErrUserNotFound, http.StatusNotFound)
+			default:
+				http.Error(w, internalErrors.ErrGetProfile, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, profileDTO)
+	})
+}
@@ -0,0 +1,376 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/metrics"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func newAdminHandles(mux *http.ServeMux, service Service) {
+	// Метрики конкуренции транзакций и блокировок балансов.
+	mux.Handle("GET /api/admin/debug/vars", metrics.Handler())
+
+	// Начислить монеты из казначейства списку пользователей или всем.
+	mux.HandleFunc("POST /api/admin/grants", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.GrantReqBody{}
+
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+		if body.All == (len(body.Usernames) > 0) || body.Amount < 1 {
+			http.Error(w, internalErrors.ErrInvalidGrantReqParams, http.StatusBadRequest)
+			return
+		}
+
+		recipients := make([]models.GrantRecipient, 0, len(body.Usernames))
+		for _, username := range body.Usernames {
+			recipients = append(recipients, models.GrantRecipient{Username: username, Amount: body.Amount})
+		}
+
+		grantCoins(ctx, w, service, models.GrantQuery{
+			Recipients: recipients,
+			All:        body.All,
+			Amount:     body.Amount,
+			Reason:     body.Reason,
+			Currency:   body.Currency,
+			DryRun:     body.DryRun,
+		})
+	})
+	// Начислить монеты по CSV (username,amount). Причина, валюта и dry-run передаются в query параметрах.
+	mux.HandleFunc("POST /api/admin/grants/csv", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		dryRun, err := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+		if err != nil && r.URL.Query().Has("dryRun") {
+			http.Error(w, internalErrors.ErrInvalidGrantReqParams, http.StatusBadRequest)
+			return
+		}
+
+		recipients, invalid, err := parseGrantCSV(r.Body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrInvalidGrantCSV, http.StatusBadRequest)
+			return
+		}
+
+		grantCoins(ctx, w, service, models.GrantQuery{
+			Recipients: recipients,
+			Reason:     r.URL.Query().Get("reason"),
+			Currency:   r.URL.Query().Get("currency"),
+			DryRun:     dryRun,
+			Invalid:    invalid,
+		})
+	})
+	// Получить кейсы подозрительных операций, по умолчанию открытые. status=all возвращает все кейсы.
+	mux.HandleFunc("GET /api/admin/fraud/cases", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		status := r.URL.Query().Get("status")
+		switch status {
+		case "":
+			status = models.FraudCaseStatusOpen
+		case "all":
+			status = ""
+		}
+
+		casesDTO, err := service.GetFraudCases(ctx, status)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidFraudCaseReqParams:
+				http.Error(w, internalErrors.ErrInvalidFraudCaseReqParams, http.StatusBadRequest)
+			default:
+				http.Error(w, internalErrors.ErrGetFraudCases, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, casesDTO)
+	})
+	// Подтвердить кейс: аккаунты кейса блокируются.
+	mux.HandleFunc("POST /api/admin/fraud/cases/{id}/confirm", func(w http.ResponseWriter, r *http.Request) {
+		resolveFraudCase(w, r, service, models.FraudCaseStatusConfirmed)
+	})
+	// Отклонить кейс: блокировка аккаунтов снимается.
+	mux.HandleFunc("POST /api/admin/fraud/cases/{id}/dismiss", func(w http.ResponseWriter, r *http.Request) {
+		resolveFraudCase(w, r, service, models.FraudCaseStatusDismissed)
+	})
+	// Заморозить аккаунт: вход и просмотр разрешены, отправка монет и покупки запрещены.
+	mux.HandleFunc("POST /api/admin/users/{username}/freeze", func(w http.ResponseWriter, r *http.Request) {
+		changeAccount(w, r, models.AccountActionFreeze, service.ChangeAccountStatus)
+	})
+	// Заблокировать аккаунт: вход запрещён, выданные токены перестают действовать.
+	mux.HandleFunc("POST /api/admin/users/{username}/suspend", func(w http.ResponseWriter, r *http.Request) {
+		changeAccount(w, r, models.AccountActionSuspend, service.ChangeAccountStatus)
+	})
+	// Снять заморозку или блокировку.
+	mux.HandleFunc("POST /api/admin/users/{username}/activate", func(w http.ResponseWriter, r *http.Request) {
+		changeAccount(w, r, models.AccountActionActivate, service.ChangeAccountStatus)
+	})
+	// Удалить аккаунт, остаток монет по запросу переводится в казначейство.
+	mux.HandleFunc("POST /api/admin/users/{username}/offboard", func(w http.ResponseWriter, r *http.Request) {
+		changeAccount(w, r, models.AccountActionOffboard, service.OffboardAccount)
+	})
+	// Получить аудит действий администраторов над аккаунтом.
+	mux.HandleFunc("GET /api/admin/users/{username}/audit", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		auditDTO, err := service.GetAccountAudit(ctx, r.PathValue("username"))
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrGetAccountAudit, http.StatusInternalServerError)
+			return
+		}
+
+		sendResponse(w, auditDTO)
+	})
+	// Получить историю баланса пользователя с идентификаторами записей для отмены.
+	mux.HandleFunc("GET /api/admin/users/{username}/transactions", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		transactionsDTO, err := service.GetTransactions(ctx, r.PathValue("username"))
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrGetTransactions, http.StatusInternalServerError)
+			return
+		}
+
+		sendResponse(w, transactionsDTO)
+	})
+	// Скачать выписку пользователя за период в CSV или JSON Lines.
+	mux.HandleFunc("GET /api/admin/users/{username}/statement", func(w http.ResponseWriter, r *http.Request) {
+		sendStatement(w, r, service, r.PathValue("username"))
+	})
+	// Получить баланс пользователя на момент времени.
+	mux.HandleFunc("GET /api/admin/users/{username}/balance", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		at, err := parseTimeParam(r.URL.Query().Get("at"))
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidBalanceAtReqParams, http.StatusBadRequest)
+			return
+		}
+
+		balanceAtDTO, err := service.GetBalanceAt(ctx, models.BalanceAtQuery{
+			Username: r.PathValue("username"),
+			At:       at,
+		})
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidBalanceAtReqParams:
+				http.Error(w, internalErrors.ErrInvalidBalanceAtReqParams, http.StatusBadRequest)
+			case internalErrors.ErrUserNotFound:
+				http.Error(w, internalErrors.ErrUserNotFound, http.StatusNotFound)
+			default:
+				http.Error(w, internalErrors.ErrGetBalanceAt, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, balanceAtDTO)
+	})
+	// Отменить перевод: монеты возвращаются отправителю компенсирующими записями истории.
+	mux.HandleFunc("POST /api/admin/transactions/{id}/reverse", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.ReversalReqBody{}
+
+		entryID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidReversalReqParams, http.StatusBadRequest)
+			return
+		}
+
+		err = json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrReverseTransaction, http.StatusInternalServerError)
+			return
+		}
+
+		reversalDTO, err := service.ReverseTransaction(ctx, models.ReversalQuery{
+			Admin:   claims.Username,
+			EntryID: entryID,
+			Reason:  body.Reason,
+		})
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrTransactionNotFound:
+				http.Error(w, internalErrors.ErrTransactionNotFound, http.StatusNotFound)
+			case internalErrors.ErrInvalidReversalReqParams,
+				internalErrors.ErrTransactionNotReversible,
+				internalErrors.ErrTransactionAlreadyReversed,
+				internalErrors.ErrReversalNotEnoughCoins:
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
+				http.Error(w, internalErrors.ErrReverseTransaction, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, reversalDTO)
+	})
+}
+
+func changeAccount(
+	w http.ResponseWriter,
+	r *http.Request,
+	action string,
+	change func(ctx context.Context, qp models.AccountQuery) (models.AccountDTO, error),
+) {
+	ctx := r.Context()
+	body := models.AccountActionReqBody{}
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		log.Logger.Err(err).Msg(err.Error())
+		http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+		return
+	}
+
+	claims, err := decodeCtxClaims(ctx)
+	if err != nil {
+		http.Error(w, internalErrors.ErrChangeAccountStatus, http.StatusInternalServerError)
+		return
+	}
+
+	accountDTO, err := change(ctx, models.AccountQuery{
+		Admin:      claims.Username,
+		Username:   r.PathValue("username"),
+		Action:     action,
+		Reason:     body.Reason,
+		SweepCoins: body.SweepCoins,
+	})
+	if err != nil {
+		switch err.Error() {
+		case internalErrors.ErrUserNotFound:
+			http.Error(w, internalErrors.ErrUserNotFound, http.StatusNotFound)
+		case internalErrors.ErrInvalidAccountReqParams,
+			internalErrors.ErrAccountStatusUnchanged:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, internalErrors.ErrChangeAccountStatus, http.StatusInternalServerError)
+			log.Logger.Err(err).Msg(err.Error())
+		}
+		return
+	}
+
+	sendResponse(w, accountDTO)
+}
+
+func resolveFraudCase(w http.ResponseWriter, r *http.Request, service Service, status string) {
+	ctx := r.Context()
+
+	caseID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, internalErrors.ErrInvalidFraudCaseReqParams, http.StatusBadRequest)
+		return
+	}
+
+	claims, err := decodeCtxClaims(ctx)
+	if err != nil {
+		http.Error(w, internalErrors.ErrResolveFraudCase, http.StatusInternalServerError)
+		return
+	}
+
+	caseDTO, err := service.ResolveFraudCase(ctx, models.FraudCaseQuery{
+		ID:     caseID,
+		Admin:  claims.Username,
+		Status: status,
+	})
+	if err != nil {
+		switch err.Error() {
+		case internalErrors.ErrFraudCaseNotFound:
+			http.Error(w, internalErrors.ErrFraudCaseNotFound, http.StatusNotFound)
+		case internalErrors.ErrFraudCaseResolved:
+			http.Error(w, internalErrors.ErrFraudCaseResolved, http.StatusBadRequest)
+		default:
+			http.Error(w, internalErrors.ErrResolveFraudCase, http.StatusInternalServerError)
+			log.Logger.Err(err).Msg(err.Error())
+		}
+		return
+	}
+
+	sendResponse(w, caseDTO)
+}
+
+func grantCoins(ctx context.Context, w http.ResponseWriter, service Service, qp models.GrantQuery) {
+	claims, err := decodeCtxClaims(ctx)
+	if err != nil {
+		http.Error(w, internalErrors.ErrGrantCoins, http.StatusInternalServerError)
+		return
+	}
+	qp.Admin = claims.Username
+
+	resultDTO, err := service.GrantCoins(ctx, qp)
+	if err != nil {
+		switch err.Error() {
+		case internalErrors.ErrInvalidGrantReqParams:
+			http.Error(w, internalErrors.ErrInvalidGrantReqParams, http.StatusBadRequest)
+		case internalErrors.ErrUnknownCurrency:
+			http.Error(w, internalErrors.ErrUnknownCurrency, http.StatusBadRequest)
+		default:
+			http.Error(w, internalErrors.ErrGrantCoins, http.StatusInternalServerError)
+			log.Logger.Err(err).Msg(err.Error())
+		}
+		return
+	}
+
+	sendResponse(w, resultDTO)
+}
+
+// parseGrantCSV разбирает строки username,amount. Строка заголовка необязательна.
+// Строки с неверной суммой не прерывают разбор и возвращаются как invalid.
+func parseGrantCSV(body io.Reader) ([]models.GrantRecipient, []models.GrantInvalidDTO, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	recipients := []models.GrantRecipient{}
+	invalid := []models.GrantInvalidDTO{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		username := strings.TrimSpace(record[0])
+		if line == 1 && strings.EqualFold(username, "username") {
+			continue
+		}
+
+		amount, err := strconv.ParseInt(strings.TrimSpace(record[1]), 10, 64)
+		if err != nil {
+			invalid = append(invalid, models.GrantInvalidDTO{Line: line, ToUser: username, Error: internalErrors.ErrInvalidGrantAmount})
+			continue
+		}
+
+		recipients = append(recipients, models.GrantRecipient{Line: line, Username: username, Amount: amount})
+	}
+
+	return recipients, invalid, nil
+}
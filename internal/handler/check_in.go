@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+)
+
+func newCheckInHandles(mux *http.ServeMux, service Service) {
+	// Ежедневная отметка с наградой, не чаще раза в сутки компании.
+	mux.HandleFunc("POST /api/checkIn", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrCheckIn, http.StatusInternalServerError)
+			return
+		}
+
+		checkInDTO, err := service.CheckIn(ctx, claims.Username)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrAlreadyCheckedIn:
+				http.Error(w, internalErrors.ErrAlreadyCheckedIn, http.StatusConflict)
+			case internalErrors.ErrCheckInDisabled:
+				http.Error(w, internalErrors.ErrCheckInDisabled, http.StatusNotFound)
+			default:
+				http.Error(w, internalErrors.ErrCheckIn, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, checkInDTO)
+	})
+}
@@ -4,7 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
 	"github.com/devWaylander/coins_store/pkg/log"
@@ -19,6 +24,71 @@ type Service interface {
 	GetUserInfo(ctx context.Context, qp models.InfoQuery) (models.InfoDTO, error)
 	BuyItem(ctx context.Context, qp models.ItemQuery) error
 	SendCoins(ctx context.Context, qp models.CoinsQuery) error
+	BulkSendCoins(ctx context.Context, qp models.BulkCoinsQuery) (models.BulkSendCoinsResultDTO, error)
+	// Schedule
+	CreateSchedule(ctx context.Context, qp models.ScheduleQuery) (models.ScheduleDTO, error)
+	GetSchedules(ctx context.Context, userID int64) ([]models.ScheduleDTO, error)
+	UpdateSchedule(ctx context.Context, qp models.ScheduleQuery) (models.ScheduleDTO, error)
+	DeleteSchedule(ctx context.Context, userID, scheduleID int64) error
+	GetScheduleRuns(ctx context.Context, userID, scheduleID int64) ([]models.ScheduleRunDTO, error)
+	// Hold
+	CreateHold(ctx context.Context, qp models.HoldQuery) (models.HoldDTO, error)
+	GetHolds(ctx context.Context, userID int64, username string) ([]models.HoldDTO, error)
+	CaptureHold(ctx context.Context, qp models.HoldQuery) (models.HoldDTO, error)
+	ReleaseHold(ctx context.Context, qp models.HoldQuery) (models.HoldDTO, error)
+	// Payment request
+	CreatePaymentRequest(ctx context.Context, qp models.PaymentRequestQuery) (models.PaymentRequestDTO, error)
+	GetIncomingPaymentRequests(ctx context.Context, username string) ([]models.PaymentRequestDTO, error)
+	GetOutgoingPaymentRequests(ctx context.Context, userID int64) ([]models.PaymentRequestDTO, error)
+	AcceptPaymentRequest(ctx context.Context, qp models.PaymentRequestQuery) (models.PaymentRequestDTO, error)
+	DeclinePaymentRequest(ctx context.Context, qp models.PaymentRequestQuery) (models.PaymentRequestDTO, error)
+	// Admin
+	GrantCoins(ctx context.Context, qp models.GrantQuery) (models.GrantResultDTO, error)
+	GetFraudCases(ctx context.Context, status string) ([]models.FraudCaseDTO, error)
+	ResolveFraudCase(ctx context.Context, qp models.FraudCaseQuery) (models.FraudCaseDTO, error)
+	ChangeAccountStatus(ctx context.Context, qp models.AccountQuery) (models.AccountDTO, error)
+	OffboardAccount(ctx context.Context, qp models.AccountQuery) (models.AccountDTO, error)
+	GetAccountAudit(ctx context.Context, username string) ([]models.AccountAuditDTO, error)
+	GetTransactions(ctx context.Context, username string) ([]models.TransactionDTO, error)
+	ReverseTransaction(ctx context.Context, qp models.ReversalQuery) (models.ReversalDTO, error)
+	// Statement
+	WriteStatement(ctx context.Context, qp models.StatementQuery, emit func(line models.StatementLineDTO) error) error
+	GetBalanceAt(ctx context.Context, qp models.BalanceAtQuery) (models.BalanceAtDTO, error)
+	// Team
+	CreateTeam(ctx context.Context, qp models.TeamQuery) (models.TeamDTO, error)
+	GetTeams(ctx context.Context, qp models.TeamQuery) ([]models.TeamDTO, error)
+	GetTeam(ctx context.Context, qp models.TeamQuery) (models.TeamDTO, error)
+	SetTeamMember(ctx context.Context, qp models.TeamMemberQuery) (models.TeamMemberDTO, error)
+	RemoveTeamMember(ctx context.Context, qp models.TeamMemberQuery) error
+	DepositToTeam(ctx context.Context, qp models.TeamTransferQuery) error
+	SendFromTeam(ctx context.Context, qp models.TeamTransferQuery) error
+	BuyTeamItem(ctx context.Context, qp models.TeamItemQuery) error
+	// Leaderboard
+	GetLeaderboard(ctx context.Context, qp models.LeaderboardQuery) (models.LeaderboardDTO, error)
+	SetLeaderboardOptOut(ctx context.Context, userID int64, optOut bool) error
+	// Achievement
+	GetProfile(ctx context.Context, username string) (models.ProfileDTO, error)
+	// Kudos
+	GetKudosFeed(ctx context.Context, qp models.KudosFeedQuery) (models.KudosFeedDTO, error)
+	BoostKudos(ctx context.Context, qp models.KudosBoostQuery) error
+	ReactToKudos(ctx context.Context, qp models.KudosReactionQuery) error
+	RemoveKudosReaction(ctx context.Context, qp models.KudosReactionQuery) error
+	// Referral
+	GetReferrals(ctx context.Context, username string) (models.ReferralsDTO, error)
+	// Check in
+	CheckIn(ctx context.Context, username string) (models.CheckInDTO, error)
+	// Quest
+	CreateQuest(ctx context.Context, body models.QuestReqBody) (models.QuestDTO, error)
+	GetQuests(ctx context.Context) ([]models.QuestDTO, error)
+	UpdateQuest(ctx context.Context, questID int64, body models.QuestReqBody) (models.QuestDTO, error)
+	DeleteQuest(ctx context.Context, questID int64) error
+	GetUserQuests(ctx context.Context, username string) ([]models.UserQuestDTO, error)
+	// Notification
+	GetNotifications(ctx context.Context, qp models.NotificationsQuery) (models.NotificationsDTO, error)
+	CountUnreadNotifications(ctx context.Context, userID int64) (models.UnreadNotificationsDTO, error)
+	MarkNotificationsRead(ctx context.Context, qp models.NotificationsReadQuery) (models.UnreadNotificationsDTO, error)
+	GetNotificationPreferences(ctx context.Context, username string) (models.NotificationPreferencesDTO, error)
+	UpdateNotificationPreferences(ctx context.Context, userID int64, username string, body models.NotificationPreferencesReqBody) (models.NotificationPreferencesDTO, error)
 }
 
 func New(ctx context.Context, mux *http.ServeMux, authMiddleware AuthMiddleware, service Service) {
@@ -50,6 +120,12 @@ func New(ctx context.Context, mux *http.ServeMux, authMiddleware AuthMiddleware,
 				http.Error(w, internalErrors.ErrWrongPassword, http.StatusUnauthorized)
 			case internalErrors.ErrWrongPasswordFormat:
 				http.Error(w, internalErrors.ErrWrongPasswordFormat, http.StatusUnauthorized)
+			case internalErrors.ErrOrganizationNotFound:
+				http.Error(w, internalErrors.ErrOrganizationNotFound, http.StatusUnauthorized)
+			case internalErrors.ErrInvalidReferralCode:
+				http.Error(w, internalErrors.ErrInvalidReferralCode, http.StatusBadRequest)
+			case internalErrors.ErrAccountSuspended, internalErrors.ErrAccountOffboarded:
+				http.Error(w, err.Error(), http.StatusForbidden)
 			default:
 				http.Error(w, internalErrors.ErrLogin, http.StatusInternalServerError)
 				log.Logger.Err(err).Msg(err.Error())
@@ -108,6 +184,11 @@ func New(ctx context.Context, mux *http.ServeMux, authMiddleware AuthMiddleware,
 				http.Error(w, internalErrors.ErrItemDoesntExist, http.StatusBadRequest)
 			case internalErrors.ErrNotEnoughCoins:
 				http.Error(w, internalErrors.ErrNotEnoughCoins, http.StatusBadRequest)
+			case internalErrors.ErrAccountOnFraudHold,
+				internalErrors.ErrAccountFrozen,
+				internalErrors.ErrAccountSuspended,
+				internalErrors.ErrAccountOffboarded:
+				http.Error(w, err.Error(), http.StatusForbidden)
 			default:
 				http.Error(w, internalErrors.ErrGetBuyItem, http.StatusInternalServerError)
 				log.Logger.Err(err).Msg(err.Error())
@@ -128,7 +209,8 @@ func New(ctx context.Context, mux *http.ServeMux, authMiddleware AuthMiddleware,
 			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
 			return
 		}
-		if body.Amount < 1 {
+		body.Message = strings.TrimSpace(body.Message)
+		if body.Amount < 1 || (body.Public && body.Message == "") || utf8.RuneCountInString(body.Message) > models.KudosMessageMaxLength {
 			http.Error(w, internalErrors.ErrInvalidSendCoinsReqParams, http.StatusBadRequest)
 			return
 		}
@@ -147,13 +229,26 @@ func New(ctx context.Context, mux *http.ServeMux, authMiddleware AuthMiddleware,
 			Amount:    body.Amount,
 			Sender:    claims.Username,
 			Recipient: body.Recipient,
+			Currency:  body.Currency,
+			Memo:      body.Message,
+			Public:    body.Public,
 		})
 		if err != nil {
+			if sendTransferLimitError(w, err) {
+				return
+			}
 			switch err.Error() {
 			case internalErrors.ErrInvalidRecipient:
 				http.Error(w, internalErrors.ErrInvalidRecipient, http.StatusBadRequest)
+			case internalErrors.ErrUnknownCurrency:
+				http.Error(w, internalErrors.ErrUnknownCurrency, http.StatusBadRequest)
 			case internalErrors.ErrNotEnoughCoins:
 				http.Error(w, internalErrors.ErrNotEnoughCoins, http.StatusBadRequest)
+			case internalErrors.ErrAccountOnFraudHold,
+				internalErrors.ErrAccountFrozen,
+				internalErrors.ErrAccountSuspended,
+				internalErrors.ErrAccountOffboarded:
+				http.Error(w, err.Error(), http.StatusForbidden)
 			default:
 				http.Error(w, internalErrors.ErrGetBuyItem, http.StatusInternalServerError)
 				log.Logger.Err(err).Msg(err.Error())
@@ -163,9 +258,75 @@ func New(ctx context.Context, mux *http.ServeMux, authMiddleware AuthMiddleware,
 
 		w.WriteHeader(http.StatusOK)
 	})
+	// Отправить монеты нескольким пользователям: все переводы выполняются вместе или ни один.
+	mux.HandleFunc("POST /api/sendCoin/bulk", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.BulkSendCoinsReqBody{}
+
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrSendCoins, http.StatusInternalServerError)
+			return
+		}
+
+		resultDTO, err := service.BulkSendCoins(ctx, models.BulkCoinsQuery{
+			UserID:    claims.UserID,
+			Sender:    claims.Username,
+			Transfers: body.Transfers,
+		})
+		if err != nil {
+			if sendTransferLimitError(w, err) {
+				return
+			}
+			switch err.Error() {
+			case internalErrors.ErrInvalidBulkTransfers:
+				sendResponseWithStatus(w, http.StatusBadRequest, resultDTO)
+			case internalErrors.ErrInvalidSendCoinsReqParams,
+				internalErrors.ErrTooManyBulkTransfers,
+				internalErrors.ErrNotEnoughCoins:
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case internalErrors.ErrAccountOnFraudHold,
+				internalErrors.ErrAccountFrozen,
+				internalErrors.ErrAccountSuspended,
+				internalErrors.ErrAccountOffboarded:
+				http.Error(w, err.Error(), http.StatusForbidden)
+			default:
+				http.Error(w, internalErrors.ErrSendCoins, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, resultDTO)
+	})
+
+	newScheduleHandles(mux, service)
+	newPaymentRequestHandles(mux, service)
+	newHoldHandles(mux, service)
+	newStatementHandles(mux, service)
+	newTeamHandles(mux, service)
+	newLeaderboardHandles(mux, service)
+	newAchievementHandles(mux, service)
+	newKudosHandles(mux, service)
+	newReferralHandles(mux, service)
+	newCheckInHandles(mux, service)
+	newQuestHandles(mux, service)
+	newNotificationHandles(mux, service)
+	newAdminHandles(mux, service)
 }
 
 func sendResponse(w http.ResponseWriter, data any) {
+	sendResponseWithStatus(w, http.StatusOK, data)
+}
+
+func sendResponseWithStatus(w http.ResponseWriter, status int, data any) {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		log.Logger.Err(err).Msg(err.Error())
@@ -174,7 +335,7 @@ func sendResponse(w http.ResponseWriter, data any) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(status)
 	if _, err := w.Write(jsonData); err != nil {
 		log.Logger.Err(err).Msg(err.Error())
 		http.Error(w, internalErrors.ErrMarshalResponse, http.StatusInternalServerError)
@@ -182,6 +343,28 @@ func sendResponse(w http.ResponseWriter, data any) {
 	}
 }
 
+// sendTransferLimitError отвечает ошибкой превышения лимита переводов с моментом его сброса.
+// Возвращает false, если err не является ошибкой лимита.
+func sendTransferLimitError(w http.ResponseWriter, err error) bool {
+	limitErr := &internalErrors.LimitError{}
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+
+	// лимит на сумму одного перевода не сбрасывается со временем
+	status := http.StatusBadRequest
+	limitDTO := models.TransferLimitErrorDTO{Error: limitErr.Code}
+	if !limitErr.ResetAt.IsZero() {
+		status = http.StatusTooManyRequests
+		limitDTO.ResetAt = &limitErr.ResetAt
+		retryAfter := int64(math.Ceil(time.Until(limitErr.ResetAt).Seconds()))
+		w.Header().Set("Retry-After", strconv.FormatInt(max(retryAfter, 1), 10))
+	}
+
+	sendResponseWithStatus(w, status, limitDTO)
+	return true
+}
+
 func decodeCtxClaims(ctx context.Context) (models.Claims, error) {
 	err := errors.New(internalErrors.ErrDecodeCtx)
 
@@ -196,5 +379,8 @@ func decodeCtxClaims(ctx context.Context) (models.Claims, error) {
 		return models.Claims{}, err
 	}
 
-	return models.Claims{UserID: userID, Username: username}, nil
+	// роль есть только в токенах, выпущенных после появления ролей
+	role, _ := ctx.Value(models.RoleKey).(string)
+
+	return models.Claims{UserID: userID, Username: username, Role: role}, nil
 }
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func newHoldHandles(mux *http.ServeMux, service Service) {
+	// Зарезервировать монеты в пользу получателя.
+	mux.HandleFunc("POST /api/holds", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.HoldReqBody{}
+
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrCreateHold, http.StatusInternalServerError)
+			return
+		}
+
+		qp := models.HoldQuery{
+			UserID:    claims.UserID,
+			Username:  claims.Username,
+			Recipient: body.Recipient,
+			Amount:    body.Amount,
+			Reference: body.Reference,
+		}
+		if body.TTL != "" {
+			qp.TTL, err = time.ParseDuration(body.TTL)
+			if err != nil || qp.TTL <= 0 {
+				http.Error(w, internalErrors.ErrInvalidHoldReqParams, http.StatusBadRequest)
+				return
+			}
+		}
+
+		holdDTO, err := service.CreateHold(ctx, qp)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidHoldReqParams,
+				internalErrors.ErrInvalidRecipientYourself,
+				internalErrors.ErrInvalidRecipient,
+				internalErrors.ErrNotEnoughCoins:
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case internalErrors.ErrAccountOnFraudHold,
+				internalErrors.ErrAccountFrozen,
+				internalErrors.ErrAccountSuspended,
+				internalErrors.ErrAccountOffboarded:
+				http.Error(w, err.Error(), http.StatusForbidden)
+			default:
+				http.Error(w, internalErrors.ErrCreateHold, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, holdDTO)
+	})
+	// Получить резервы, где пользователь владелец или получатель.
+	mux.HandleFunc("GET /api/holds", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetHolds, http.StatusInternalServerError)
+			return
+		}
+
+		holdsDTO, err := service.GetHolds(ctx, claims.UserID, claims.Username)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrGetHolds, http.StatusInternalServerError)
+			return
+		}
+
+		sendResponse(w, holdsDTO)
+	})
+	// Захватить резерв: перевести зарезервированные монеты получателю.
+	mux.HandleFunc("POST /api/holds/{id}/capture", func(w http.ResponseWriter, r *http.Request) {
+		resolveHold(w, r, service.CaptureHold)
+	})
+	// Освободить резерв: вернуть монеты владельцу.
+	mux.HandleFunc("POST /api/holds/{id}/release", func(w http.ResponseWriter, r *http.Request) {
+		resolveHold(w, r, service.ReleaseHold)
+	})
+}
+
+func resolveHold(
+	w http.ResponseWriter,
+	r *http.Request,
+	resolve func(ctx context.Context, qp models.HoldQuery) (models.HoldDTO, error),
+) {
+	ctx := r.Context()
+
+	holdID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, internalErrors.ErrInvalidHoldReqParams, http.StatusBadRequest)
+		return
+	}
+
+	claims, err := decodeCtxClaims(ctx)
+	if err != nil {
+		http.Error(w, internalErrors.ErrResolveHold, http.StatusInternalServerError)
+		return
+	}
+
+	holdDTO, err := resolve(ctx, models.HoldQuery{
+		ID:       holdID,
+		UserID:   claims.UserID,
+		Username: claims.Username,
+	})
+	if err != nil {
+		if sendTransferLimitError(w, err) {
+			return
+		}
+		switch err.Error() {
+		case internalErrors.ErrHoldNotFound:
+			http.Error(w, internalErrors.ErrHoldNotFound, http.StatusNotFound)
+		case internalErrors.ErrHoldNotActive,
+			internalErrors.ErrNotEnoughCoins,
+			internalErrors.ErrInvalidRecipient:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case internalErrors.ErrAccountOnFraudHold,
+			internalErrors.ErrAccountFrozen,
+			internalErrors.ErrAccountSuspended,
+			internalErrors.ErrAccountOffboarded:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, internalErrors.ErrResolveHold, http.StatusInternalServerError)
+			log.Logger.Err(err).Msg(err.Error())
+		}
+		return
+	}
+
+	sendResponse(w, holdDTO)
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func newKudosHandles(mux *http.ServeMux, service Service) {
+	// Получить страницу ленты публичных благодарностей, начиная с последних.
+	mux.HandleFunc("GET /api/kudos", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetKudosFeed, http.StatusInternalServerError)
+			return
+		}
+
+		qp := models.KudosFeedQuery{
+			UserID: claims.UserID,
+			Cursor: r.URL.Query().Get("cursor"),
+		}
+		if r.URL.Query().Has("limit") {
+			qp.Limit, err = strconv.Atoi(r.URL.Query().Get("limit"))
+			if err != nil {
+				http.Error(w, internalErrors.ErrInvalidKudosReqParams, http.StatusBadRequest)
+				return
+			}
+		}
+
+		feedDTO, err := service.GetKudosFeed(ctx, qp)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidKudosReqParams:
+				http.Error(w, internalErrors.ErrInvalidKudosReqParams, http.StatusBadRequest)
+			default:
+				http.Error(w, internalErrors.ErrGetKudosFeed, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, feedDTO)
+	})
+	// Добавить монеты сверху к чужой благодарности.
+	mux.HandleFunc("POST /api/kudos/{id}/boost", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.KudosBoostReqBody{}
+
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		kudosID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidKudosReqParams, http.StatusBadRequest)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrBoostKudos, http.StatusInternalServerError)
+			return
+		}
+
+		err = service.BoostKudos(ctx, models.KudosBoostQuery{
+			UserID:   claims.UserID,
+			Username: claims.Username,
+			KudosID:  kudosID,
+			Amount:   body.Amount,
+		})
+		if err != nil {
+			if sendTransferLimitError(w, err) {
+				return
+			}
+			switch err.Error() {
+			case internalErrors.ErrInvalidKudosReqParams,
+				internalErrors.ErrKudosBoostOwn,
+				internalErrors.ErrInvalidRecipientYourself,
+				internalErrors.ErrInvalidRecipient,
+				internalErrors.ErrNotEnoughCoins:
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case internalErrors.ErrKudosNotFound:
+				http.Error(w, internalErrors.ErrKudosNotFound, http.StatusNotFound)
+			case internalErrors.ErrAccountOnFraudHold,
+				internalErrors.ErrAccountFrozen,
+				internalErrors.ErrAccountSuspended,
+				internalErrors.ErrAccountOffboarded:
+				http.Error(w, err.Error(), http.StatusForbidden)
+			default:
+				http.Error(w, internalErrors.ErrBoostKudos, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+	// Поставить реакцию на благодарность.
+	mux.HandleFunc("PUT /api/kudos/{id}/reactions/{emoji}", func(w http.ResponseWriter, r *http.Request) {
+		sendKudosReaction(w, r, service.ReactToKudos)
+	})
+	// Снять свою реакцию с благодарности.
+	mux.HandleFunc("DELETE /api/kudos/{id}/reactions/{emoji}", func(w http.ResponseWriter, r *http.Request) {
+		sendKudosReaction(w, r, service.RemoveKudosReaction)
+	})
+}
+
+// sendKudosReaction разбирает путь реакции и отвечает результатом действия
+func sendKudosReaction(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, qp models.KudosReactionQuery) error) {
+	ctx := r.Context()
+
+	kudosID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, internalErrors.ErrInvalidKudosReqParams, http.StatusBadRequest)
+		return
+	}
+
+	claims, err := decodeCtxClaims(ctx)
+	if err != nil {
+		http.Error(w, internalErrors.ErrReactKudos, http.StatusInternalServerError)
+		return
+	}
+
+	err = action(ctx, models.KudosReactionQuery{
+		UserID:  claims.UserID,
+		KudosID: kudosID,
+		Emoji:   r.PathValue("emoji"),
+	})
+	if err != nil {
+		switch err.Error() {
+		case internalErrors.ErrInvalidKudosReaction:
+			http.Error(w, internalErrors.ErrInvalidKudosReaction, http.StatusBadRequest)
+		case internalErrors.ErrKudosNotFound:
+			http.Error(w, internalErrors.ErrKudosNotFound, http.StatusNotFound)
+		default:
+			http.Error(w, internalErrors.ErrReactKudos, http.StatusInternalServerError)
+			log.Logger.Err(err).Msg(err.Error())
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
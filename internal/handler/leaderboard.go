@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func newLeaderboardHandles(mux *http.ServeMux, service Service) {
+	// Получить рейтинг по полученным, отправленным монетам или числу поблагодарённых за неделю, месяц или всё время.
+	mux.HandleFunc("GET /api/leaderboard", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetLeaderboard, http.StatusInternalServerError)
+			return
+		}
+
+		qp, err := parseLeaderboardParams(r.URL.Query(), claims.Username)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidLeaderboardReqParams, http.StatusBadRequest)
+			return
+		}
+
+		leaderboardDTO, err := service.GetLeaderboard(ctx, qp)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidLeaderboardReqParams:
+				http.Error(w, internalErrors.ErrInvalidLeaderboardReqParams, http.StatusBadRequest)
+			case internalErrors.ErrTeamNotFound:
+				http.Error(w, internalErrors.ErrTeamNotFound, http.StatusNotFound)
+			default:
+				http.Error(w, internalErrors.ErrGetLeaderboard, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, leaderboardDTO)
+	})
+	// Скрыть себя из рейтинга или вернуться в него.
+	mux.HandleFunc("PUT /api/leaderboard/optOut", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.LeaderboardOptOutReqBody{}
+
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrSetLeaderboardOptOut, http.StatusInternalServerError)
+			return
+		}
+
+		err = service.SetLeaderboardOptOut(ctx, claims.UserID, body.OptOut)
+		if err != nil {
+			http.Error(w, internalErrors.ErrSetLeaderboardOptOut, http.StatusInternalServerError)
+			log.Logger.Err(err).Msg(err.Error())
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// parseLeaderboardParams читает metric, period, team и limit. Пустые значения заполняет сервис.
+func parseLeaderboardParams(values url.Values, username string) (models.LeaderboardQuery, error) {
+	qp := models.LeaderboardQuery{
+		Username: username,
+		Metric:   values.Get("metric"),
+		Period:   values.Get("period"),
+	}
+
+	if values.Has("team") {
+		teamID, err := strconv.ParseInt(values.Get("team"), 10, 64)
+		if err != nil {
+			return qp, err
+		}
+		if teamID < 1 {
+			return qp, errors.New(internalErrors.ErrInvalidLeaderboardReqParams)
+		}
+		qp.TeamID = teamID
+	}
+	if values.Has("limit") {
+		limit, err := strconv.Atoi(values.Get("limit"))
+		if err != nil {
+			return qp, err
+		}
+		qp.Limit = limit
+	}
+
+	return qp, nil
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func newNotificationHandles(mux *http.ServeMux, service Service) {
+	// Получить страницу входящих уведомлений, начиная с последних.
+	mux.HandleFunc("GET /api/notifications", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetNotifications, http.StatusInternalServerError)
+			return
+		}
+
+		qp := models.NotificationsQuery{
+			UserID: claims.UserID,
+			Cursor: r.URL.Query().Get("cursor"),
+		}
+		if r.URL.Query().Has("unread") {
+			qp.UnreadOnly, err = strconv.ParseBool(r.URL.Query().Get("unread"))
+			if err != nil {
+				http.Error(w, internalErrors.ErrInvalidNotificationReqParams, http.StatusBadRequest)
+				return
+			}
+		}
+		if r.URL.Query().Has("limit") {
+			qp.Limit, err = strconv.Atoi(r.URL.Query().Get("limit"))
+			if err != nil {
+				http.Error(w, internalErrors.ErrInvalidNotificationReqParams, http.StatusBadRequest)
+				return
+			}
+		}
+
+		notificationsDTO, err := service.GetNotifications(ctx, qp)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidNotificationReqParams:
+				http.Error(w, internalErrors.ErrInvalidNotificationReqParams, http.StatusBadRequest)
+			default:
+				http.Error(w, internalErrors.ErrGetNotifications, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, notificationsDTO)
+	})
+	// Получить количество непрочитанных уведомлений.
+	mux.HandleFunc("GET /api/notifications/unread", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetNotifications, http.StatusInternalServerError)
+			return
+		}
+
+		unreadDTO, err := service.CountUnreadNotifications(ctx, claims.UserID)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetNotifications, http.StatusInternalServerError)
+			log.Logger.Err(err).Msg(err.Error())
+			return
+		}
+
+		sendResponse(w, unreadDTO)
+	})
+	// Отметить прочитанными перечисленные или все уведомления.
+	mux.HandleFunc("POST /api/notifications/read", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.NotificationsReadReqBody{}
+
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrReadNotifications, http.StatusInternalServerError)
+			return
+		}
+
+		unreadDTO, err := service.MarkNotificationsRead(ctx, models.NotificationsReadQuery{
+			UserID: claims.UserID,
+			IDs:    body.IDs,
+			All:    body.All,
+		})
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidNotificationReqParams:
+				http.Error(w, internalErrors.ErrInvalidNotificationReqParams, http.StatusBadRequest)
+			default:
+				http.Error(w, internalErrors.ErrReadNotifications, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, unreadDTO)
+	})
+	// Получить настройки уведомлений.
+	mux.HandleFunc("GET /api/notifications/preferences", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetNotificationPreferences, http.StatusInternalServerError)
+			return
+		}
+
+		preferencesDTO, err := service.GetNotificationPreferences(ctx, claims.Username)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetNotificationPreferences, http.StatusInternalServerError)
+			log.Logger.Err(err).Msg(err.Error())
+			return
+		}
+
+		sendResponse(w, preferencesDTO)
+	})
+	// Изменить настройки уведомлений. Непереданные поля не меняются.
+	mux.HandleFunc("PUT /api/notifications/preferences", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.NotificationPreferencesReqBody{}
+
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrUpdateNotificationPreferences, http.StatusInternalServerError)
+			return
+		}
+
+		preferencesDTO, err := service.UpdateNotificationPreferences(ctx, claims.UserID, claims.Username, body)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidNotificationReqParams:
+				http.Error(w, internalErrors.ErrInvalidNotificationReqParams, http.StatusBadRequest)
+			default:
+				http.Error(w, internalErrors.ErrUpdateNotificationPreferences, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, preferencesDTO)
+	})
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func newPaymentRequestHandles(mux *http.ServeMux, service Service) {
+	// Запросить монеты у другого пользователя.
+	mux.HandleFunc("POST /api/requests", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.PaymentRequestReqBody{}
+
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrCreatePaymentRequest, http.StatusInternalServerError)
+			return
+		}
+
+		requestDTO, err := service.CreatePaymentRequest(ctx, models.PaymentRequestQuery{
+			UserID:   claims.UserID,
+			Username: claims.Username,
+			Payer:    body.Payer,
+			Amount:   body.Amount,
+			Memo:     body.Memo,
+		})
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidPaymentRequestReqParams,
+				internalErrors.ErrInvalidRecipientYourself,
+				internalErrors.ErrInvalidRecipient:
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case internalErrors.ErrDuplicatePaymentRequest:
+				http.Error(w, internalErrors.ErrDuplicatePaymentRequest, http.StatusConflict)
+			default:
+				http.Error(w, internalErrors.ErrCreatePaymentRequest, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, requestDTO)
+	})
+	// Получить ожидающие оплаты запросы к себе.
+	mux.HandleFunc("GET /api/requests/incoming", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetPaymentRequests, http.StatusInternalServerError)
+			return
+		}
+
+		requestsDTO, err := service.GetIncomingPaymentRequests(ctx, claims.Username)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrGetPaymentRequests, http.StatusInternalServerError)
+			return
+		}
+
+		sendResponse(w, requestsDTO)
+	})
+	// Получить свои запросы и их статусы.
+	mux.HandleFunc("GET /api/requests/outgoing", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetPaymentRequests, http.StatusInternalServerError)
+			return
+		}
+
+		requestsDTO, err := service.GetOutgoingPaymentRequests(ctx, claims.UserID)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrGetPaymentRequests, http.StatusInternalServerError)
+			return
+		}
+
+		sendResponse(w, requestsDTO)
+	})
+	// Оплатить запрос.
+	mux.HandleFunc("POST /api/requests/{id}/accept", func(w http.ResponseWriter, r *http.Request) {
+		resolvePaymentRequest(w, r, service.AcceptPaymentRequest)
+	})
+	// Отклонить запрос.
+	mux.HandleFunc("POST /api/requests/{id}/decline", func(w http.ResponseWriter, r *http.Request) {
+		resolvePaymentRequest(w, r, service.DeclinePaymentRequest)
+	})
+}
+
+func resolvePaymentRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+	resolve func(ctx context.Context, qp models.PaymentRequestQuery) (models.PaymentRequestDTO, error),
+) {
+	ctx := r.Context()
+
+	requestID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, internalErrors.ErrInvalidPaymentRequestReqParams, http.StatusBadRequest)
+		return
+	}
+
+	claims, err := decodeCtxClaims(ctx)
+	if err != nil {
+		http.Error(w, internalErrors.ErrResolvePaymentRequest, http.StatusInternalServerError)
+		return
+	}
+
+	requestDTO, err := resolve(ctx, models.PaymentRequestQuery{
+		ID:       requestID,
+		UserID:   claims.UserID,
+		Username: claims.Username,
+	})
+	if err != nil {
+		if sendTransferLimitError(w, err) {
+			return
+		}
+		switch err.Error() {
+		case internalErrors.ErrPaymentRequestNotFound:
+			http.Error(w, internalErrors.ErrPaymentRequestNotFound, http.StatusNotFound)
+		case internalErrors.ErrPaymentRequestExpired,
+			internalErrors.ErrPaymentRequestResolved,
+			internalErrors.ErrNotEnoughCoins,
+			internalErrors.ErrInvalidRecipient:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case internalErrors.ErrAccountOnFraudHold,
+			internalErrors.ErrAccountFrozen,
+			internalErrors.ErrAccountSuspended,
+			internalErrors.ErrAccountOffboarded:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, internalErrors.ErrResolvePaymentRequest, http.StatusInternalServerError)
+			log.Logger.Err(err).Msg(err.Error())
+		}
+		return
+	}
+
+	sendResponse(w, requestDTO)
+}
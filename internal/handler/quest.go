@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func newQuestHandles(mux *http.ServeMux, service Service) {
+	// Создать задание с наградой за выполнение в заданный период.
+	mux.HandleFunc("POST /api/admin/quests", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.QuestReqBody{}
+
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		questDTO, err := service.CreateQuest(ctx, body)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidQuestReqParams:
+				http.Error(w, internalErrors.ErrInvalidQuestReqParams, http.StatusBadRequest)
+			default:
+				http.Error(w, internalErrors.ErrCreateQuest, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, questDTO)
+	})
+	// Получить все задания организации, включая завершённые и будущие.
+	mux.HandleFunc("GET /api/admin/quests", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		questsDTO, err := service.GetQuests(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetQuests, http.StatusInternalServerError)
+			log.Logger.Err(err).Msg(err.Error())
+			return
+		}
+
+		sendResponse(w, questsDTO)
+	})
+	// Изменить задание. Накопленный прогресс участников сохраняется.
+	mux.HandleFunc("PUT /api/admin/quests/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.QuestReqBody{}
+
+		questID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidQuestReqParams, http.StatusBadRequest)
+			return
+		}
+
+		err = json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		questDTO, err := service.UpdateQuest(ctx, questID, body)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidQuestReqParams:
+				http.Error(w, internalErrors.ErrInvalidQuestReqParams, http.StatusBadRequest)
+			case internalErrors.ErrQuestNotFound:
+				http.Error(w, internalErrors.ErrQuestNotFound, http.StatusNotFound)
+			default:
+				http.Error(w, internalErrors.ErrUpdateQuest, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, questDTO)
+	})
+	// Удалить задание. Выплаченные награды остаются у пользователей.
+	mux.HandleFunc("DELETE /api/admin/quests/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		questID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidQuestReqParams, http.StatusBadRequest)
+			return
+		}
+
+		err = service.DeleteQuest(ctx, questID)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidQuestReqParams:
+				http.Error(w, internalErrors.ErrInvalidQuestReqParams, http.StatusBadRequest)
+			case internalErrors.ErrQuestNotFound:
+				http.Error(w, internalErrors.ErrQuestNotFound, http.StatusNotFound)
+			default:
+				http.Error(w, internalErrors.ErrDeleteQuest, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+	// Получить активные задания и свой прогресс по ним.
+	mux.HandleFunc("GET /api/quests", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetQuests, http.StatusInternalServerError)
+			return
+		}
+
+		questsDTO, err := service.GetUserQuests(ctx, claims.Username)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetQuests, http.StatusInternalServerError)
+			log.Logger.Err(err).Msg(err.Error())
+			return
+		}
+
+		sendResponse(w, questsDTO)
+	})
+}
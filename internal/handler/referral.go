@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+)
+
+func newReferralHandles(mux *http.ServeMux, service Service) {
+	// Получить свой код приглашения, приглашённых пользователей и полученные бонусы.
+	mux.HandleFunc("GET /api/referrals", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetReferrals, http.StatusInternalServerError)
+			return
+		}
+
+		referralsDTO, err := service.GetReferrals(ctx, claims.Username)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetReferrals, http.StatusInternalServerError)
+			log.Logger.Err(err).Msg(err.Error())
+			return
+		}
+
+		sendResponse(w, referralsDTO)
+	})
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func newScheduleHandles(mux *http.ServeMux, service Service) {
+	// Создать расписание перевода монет: разового (без interval) или повторяющегося.
+	mux.HandleFunc("POST /api/schedules", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.ScheduleReqBody{}
+
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrCreateSchedule, http.StatusInternalServerError)
+			return
+		}
+
+		qp := models.ScheduleQuery{
+			UserID:    claims.UserID,
+			Username:  claims.Username,
+			Recipient: body.Recipient,
+			Amount:    &body.Amount,
+		}
+		if body.RunAt != nil {
+			runAt := time.Time(*body.RunAt)
+			qp.RunAt = &runAt
+		}
+		if body.Interval != "" {
+			interval, err := time.ParseDuration(body.Interval)
+			if err != nil {
+				http.Error(w, internalErrors.ErrInvalidScheduleReqParams, http.StatusBadRequest)
+				return
+			}
+			qp.Interval = &interval
+		}
+
+		scheduleDTO, err := service.CreateSchedule(ctx, qp)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidScheduleReqParams:
+				http.Error(w, internalErrors.ErrInvalidScheduleReqParams, http.StatusBadRequest)
+			case internalErrors.ErrInvalidRecipient:
+				http.Error(w, internalErrors.ErrInvalidRecipient, http.StatusBadRequest)
+			default:
+				http.Error(w, internalErrors.ErrCreateSchedule, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, scheduleDTO)
+	})
+	// Получить список своих расписаний.
+	mux.HandleFunc("GET /api/schedules", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetSchedules, http.StatusInternalServerError)
+			return
+		}
+
+		schedulesDTO, err := service.GetSchedules(ctx, claims.UserID)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrGetSchedules, http.StatusInternalServerError)
+			return
+		}
+
+		sendResponse(w, schedulesDTO)
+	})
+	// Изменить сумму, интервал, время следующего запуска или приостановить/возобновить расписание.
+	mux.HandleFunc("PUT /api/schedules/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.ScheduleUpdateReqBody{}
+
+		scheduleID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidScheduleReqParams, http.StatusBadRequest)
+			return
+		}
+
+		err = json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrUpdateSchedule, http.StatusInternalServerError)
+			return
+		}
+
+		qp := models.ScheduleQuery{
+			ID:       scheduleID,
+			UserID:   claims.UserID,
+			Username: claims.Username,
+			Amount:   body.Amount,
+			Paused:   body.Paused,
+		}
+		if body.RunAt != nil {
+			runAt := time.Time(*body.RunAt)
+			qp.RunAt = &runAt
+		}
+		if body.Interval != nil {
+			var interval time.Duration
+			if *body.Interval != "" {
+				interval, err = time.ParseDuration(*body.Interval)
+				if err != nil {
+					http.Error(w, internalErrors.ErrInvalidScheduleReqParams, http.StatusBadRequest)
+					return
+				}
+			}
+			qp.Interval = &interval
+		}
+
+		scheduleDTO, err := service.UpdateSchedule(ctx, qp)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrInvalidScheduleReqParams:
+				http.Error(w, internalErrors.ErrInvalidScheduleReqParams, http.StatusBadRequest)
+			case internalErrors.ErrScheduleCompleted:
+				http.Error(w, internalErrors.ErrScheduleCompleted, http.StatusBadRequest)
+			case internalErrors.ErrScheduleNotFound:
+				http.Error(w, internalErrors.ErrScheduleNotFound, http.StatusNotFound)
+			default:
+				http.Error(w, internalErrors.ErrUpdateSchedule, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, scheduleDTO)
+	})
+	// Удалить расписание.
+	mux.HandleFunc("DELETE /api/schedules/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		scheduleID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidScheduleReqParams, http.StatusBadRequest)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrDeleteSchedule, http.StatusInternalServerError)
+			return
+		}
+
+		err = service.DeleteSchedule(ctx, claims.UserID, scheduleID)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrScheduleNotFound:
+				http.Error(w, internalErrors.ErrScheduleNotFound, http.StatusNotFound)
+			default:
+				http.Error(w, internalErrors.ErrDeleteSchedule, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+	// Получить результаты запусков расписания.
+	mux.HandleFunc("GET /api/schedules/{id}/runs", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		scheduleID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidScheduleReqParams, http.StatusBadRequest)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetSchedules, http.StatusInternalServerError)
+			return
+		}
+
+		runsDTO, err := service.GetScheduleRuns(ctx, claims.UserID, scheduleID)
+		if err != nil {
+			switch err.Error() {
+			case internalErrors.ErrScheduleNotFound:
+				http.Error(w, internalErrors.ErrScheduleNotFound, http.StatusNotFound)
+			default:
+				http.Error(w, internalErrors.ErrGetSchedules, http.StatusInternalServerError)
+				log.Logger.Err(err).Msg(err.Error())
+			}
+			return
+		}
+
+		sendResponse(w, runsDTO)
+	})
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Строки выписки отправляются клиенту пачками, а не накапливаются в памяти
+const statementFlushLines = 500
+
+func newStatementHandles(mux *http.ServeMux, service Service) {
+	// Скачать выписку по своему балансу за период в CSV или JSON Lines.
+	mux.HandleFunc("GET /api/statement", func(w http.ResponseWriter, r *http.Request) {
+		claims, err := decodeCtxClaims(r.Context())
+		if err != nil {
+			http.Error(w, internalErrors.ErrGetStatement, http.StatusInternalServerError)
+			return
+		}
+
+		sendStatement(w, r, service, claims.Username)
+	})
+}
+
+// sendStatement отдаёт выписку потоком. Статус и заголовки отправляются с первой строкой,
+// поэтому ошибка в середине выписки только обрывает поток: у оборванной выписки нет строки closing.
+func sendStatement(w http.ResponseWriter, r *http.Request, service Service, username string) {
+	ctx := r.Context()
+
+	qp, format, err := parseStatementParams(r.URL.Query(), username, time.Now())
+	if err != nil {
+		http.Error(w, internalErrors.ErrInvalidStatementReqParams, http.StatusBadRequest)
+		return
+	}
+
+	sw := &statementWriter{w: w, format: format, filename: statementFilename(qp, format)}
+	err = service.WriteStatement(ctx, qp, sw.write)
+	if err == nil {
+		err = sw.flush()
+	}
+	if err != nil {
+		if sw.lines > 0 {
+			log.Logger.Err(err).Msg(err.Error())
+			return
+		}
+
+		switch err.Error() {
+		case internalErrors.ErrInvalidStatementReqParams:
+			http.Error(w, internalErrors.ErrInvalidStatementReqParams, http.StatusBadRequest)
+		case internalErrors.ErrUserNotFound:
+			http.Error(w, internalErrors.ErrUserNotFound, http.StatusNotFound)
+		default:
+			http.Error(w, internalErrors.ErrGetStatement, http.StatusInternalServerError)
+			log.Logger.Err(err).Msg(err.Error())
+		}
+	}
+}
+
+// parseStatementParams читает from и to (RFC 3339 или дата YYYY-MM-DD в UTC) и формат выписки.
+// По умолчанию to - текущий момент, формат - csv.
+func parseStatementParams(values url.Values, username string, now time.Time) (models.StatementQuery, string, error) {
+	qp := models.StatementQuery{Username: username, To: now}
+
+	from, err := parseTimeParam(values.Get("from"))
+	if err != nil {
+		return qp, "", err
+	}
+	qp.From = from
+	if values.Has("to") {
+		qp.To, err = parseTimeParam(values.Get("to"))
+		if err != nil {
+			return qp, "", err
+		}
+	}
+
+	format := values.Get("format")
+	switch format {
+	case "":
+		format = models.StatementFormatCSV
+	case models.StatementFormatCSV, models.StatementFormatJSONL:
+	default:
+		return qp, "", fmt.Errorf("unknown statement format %q", format)
+	}
+
+	return qp, format, nil
+}
+
+// parseTimeParam разбирает момент времени в RFC 3339 или дату YYYY-MM-DD в UTC
+func parseTimeParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func statementFilename(qp models.StatementQuery, format string) string {
+	return fmt.Sprintf("statement-%s-%s-%s.%s",
+		qp.Username,
+		qp.From.UTC().Format(time.DateOnly),
+		qp.To.UTC().Format(time.DateOnly),
+		format,
+	)
+}
+
+type statementWriter struct {
+	w        http.ResponseWriter
+	format   string
+	filename string
+	csv      *csv.Writer
+	json     *json.Encoder
+	lines    int
+}
+
+func (sw *statementWriter) write(line models.StatementLineDTO) error {
+	if sw.lines == 0 {
+		if err := sw.start(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if sw.format == models.StatementFormatCSV {
+		err = sw.csv.Write(line.CSVRecord())
+	} else {
+		err = sw.json.Encode(line)
+	}
+	if err != nil {
+		return err
+	}
+
+	sw.lines++
+	if sw.lines%statementFlushLines == 0 {
+		return sw.flush()
+	}
+
+	return nil
+}
+
+func (sw *statementWriter) start() error {
+	contentType := "application/x-ndjson"
+	if sw.format == models.StatementFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	sw.w.Header().Set("Content-Type", contentType)
+	sw.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", sw.filename))
+	sw.w.WriteHeader(http.StatusOK)
+
+	if sw.format == models.StatementFormatCSV {
+		sw.csv = csv.NewWriter(sw.w)
+		return sw.csv.Write(models.StatementCSVHeader)
+	}
+	sw.json = json.NewEncoder(sw.w)
+
+	return nil
+}
+
+func (sw *statementWriter) flush() error {
+	if sw.csv != nil {
+		sw.csv.Flush()
+		if err := sw.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if flusher, ok := sw.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	return nil
+}
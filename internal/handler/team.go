@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func newTeamHandles(mux *http.ServeMux, service Service) {
+	// Создать команду с общим кошельком. Создатель становится владельцем.
+	mux.HandleFunc("POST /api/teams", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.CreateTeamReqBody{}
+
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrTeam, http.StatusInternalServerError)
+			return
+		}
+
+		teamDTO, err := service.CreateTeam(ctx, models.TeamQuery{
+			UserID:   claims.UserID,
+			Username: claims.Username,
+			Name:     body.Name,
+		})
+		if err != nil {
+			sendTeamError(w, err)
+			return
+		}
+
+		sendResponse(w, teamDTO)
+	})
+	// Получить команды пользователя.
+	mux.HandleFunc("GET /api/teams", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrTeam, http.StatusInternalServerError)
+			return
+		}
+
+		teamsDTO, err := service.GetTeams(ctx, models.TeamQuery{
+			UserID:   claims.UserID,
+			Username: claims.Username,
+		})
+		if err != nil {
+			sendTeamError(w, err)
+			return
+		}
+
+		sendResponse(w, teamsDTO)
+	})
+	// Получить команду с участниками, инвентарём и историей кошелька.
+	mux.HandleFunc("GET /api/teams/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		teamID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidTeamReqParams, http.StatusBadRequest)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrTeam, http.StatusInternalServerError)
+			return
+		}
+
+		teamDTO, err := service.GetTeam(ctx, models.TeamQuery{
+			ID:       teamID,
+			UserID:   claims.UserID,
+			Username: claims.Username,
+		})
+		if err != nil {
+			sendTeamError(w, err)
+			return
+		}
+
+		sendResponse(w, teamDTO)
+	})
+	// Добавить участника или изменить его роль и право тратить кошелёк.
+	mux.HandleFunc("PUT /api/teams/{id}/members/{username}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.TeamMemberReqBody{}
+
+		teamID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidTeamReqParams, http.StatusBadRequest)
+			return
+		}
+
+		err = json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrTeam, http.StatusInternalServerError)
+			return
+		}
+
+		memberDTO, err := service.SetTeamMember(ctx, models.TeamMemberQuery{
+			TeamID:   teamID,
+			Actor:    claims.Username,
+			Username: r.PathValue("username"),
+			Role:     body.Role,
+			CanSpend: body.CanSpend,
+		})
+		if err != nil {
+			sendTeamError(w, err)
+			return
+		}
+
+		sendResponse(w, memberDTO)
+	})
+	// Исключить участника или выйти из команды.
+	mux.HandleFunc("DELETE /api/teams/{id}/members/{username}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		teamID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidTeamReqParams, http.StatusBadRequest)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrTeam, http.StatusInternalServerError)
+			return
+		}
+
+		err = service.RemoveTeamMember(ctx, models.TeamMemberQuery{
+			TeamID:   teamID,
+			Actor:    claims.Username,
+			Username: r.PathValue("username"),
+		})
+		if err != nil {
+			sendTeamError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+	// Пополнить кошелёк команды своими монетами.
+	mux.HandleFunc("POST /api/teams/{id}/deposit", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.TeamDepositReqBody{}
+
+		teamID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidTeamReqParams, http.StatusBadRequest)
+			return
+		}
+
+		err = json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrTeam, http.StatusInternalServerError)
+			return
+		}
+
+		err = service.DepositToTeam(ctx, models.TeamTransferQuery{
+			TeamID:   teamID,
+			Username: claims.Username,
+			Amount:   body.Amount,
+		})
+		if err != nil {
+			sendTeamError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+	// Отправить монеты из кошелька команды пользователю.
+	mux.HandleFunc("POST /api/teams/{id}/sendCoin", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body := models.TeamSendCoinsReqBody{}
+
+		teamID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidTeamReqParams, http.StatusBadRequest)
+			return
+		}
+
+		err = json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrUnmarshalResponse, http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrTeam, http.StatusInternalServerError)
+			return
+		}
+
+		err = service.SendFromTeam(ctx, models.TeamTransferQuery{
+			TeamID:    teamID,
+			Username:  claims.Username,
+			Recipient: body.Recipient,
+			Amount:    body.Amount,
+		})
+		if err != nil {
+			sendTeamError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+	// Купить предмет за монеты команды в инвентарь команды или участника.
+	mux.HandleFunc("GET /api/teams/{id}/buy/{item}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		teamID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, internalErrors.ErrInvalidTeamReqParams, http.StatusBadRequest)
+			return
+		}
+
+		claims, err := decodeCtxClaims(ctx)
+		if err != nil {
+			http.Error(w, internalErrors.ErrTeam, http.StatusInternalServerError)
+			return
+		}
+
+		err = service.BuyTeamItem(ctx, models.TeamItemQuery{
+			TeamID:   teamID,
+			Username: claims.Username,
+			Item:     r.PathValue("item"),
+			ForUser:  r.URL.Query().Get("forUser"),
+		})
+		if err != nil {
+			sendTeamError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func sendTeamError(w http.ResponseWriter, err error) {
+	if sendTransferLimitError(w, err) {
+		return
+	}
+	switch err.Error() {
+	case internalErrors.ErrTeamNotFound,
+		internalErrors.ErrTeamMemberNotFound,
+		internalErrors.ErrUserNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case internalErrors.ErrTeamForbidden,
+		internalErrors.ErrAccountOnFraudHold,
+		internalErrors.ErrAccountFrozen,
+		internalErrors.ErrAccountSuspended,
+		internalErrors.ErrAccountOffboarded:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case internalErrors.ErrInvalidTeamReqParams,
+		internalErrors.ErrTeamNameTaken,
+		internalErrors.ErrTeamLastOwner,
+		internalErrors.ErrTeamCurrencyUnsupported,
+		internalErrors.ErrInvalidRecipient,
+		internalErrors.ErrItemDoesntExist,
+		internalErrors.ErrNotEnoughCoins:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, internalErrors.ErrTeam, http.StatusInternalServerError)
+		log.Logger.Err(err).Msg(err.Error())
+	}
+}
@@ -16,25 +16,38 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Обработчики с этим префиксом доступны только администраторам
+const adminHandlesPrefix = "/api/admin/"
+
 var unsecuredHandles = map[string]*struct{}{
 	"/api/auth": {},
 }
 
 type Repository interface {
-	CreateUserTX(ctx context.Context, username, passwordHash string) (int64, error)
-	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
-	GetUserPassHashByUsername(ctx context.Context, username string) (string, error)
+	GetOrganizationByName(ctx context.Context, name string) (models.Organization, error)
+	CreateUserTX(ctx context.Context, org models.Organization, username, passwordHash string, referrerID int64) (int64, error)
+	GetUserByUsername(ctx context.Context, orgID int64, username string) (*models.User, error)
+	GetUserByReferralCode(ctx context.Context, orgID int64, code string) (*models.User, error)
+	GetUserByID(ctx context.Context, orgID, userID int64) (*models.User, error)
+	GetUserPassHashByUsername(ctx context.Context, orgID int64, username string) (string, error)
+}
+
+// SignInRecorder учитывает успешные входы пользователей, например для достижений
+type SignInRecorder interface {
+	RecordSignIn(ctx context.Context, username string) error
 }
 
 type middleware struct {
-	repo   Repository
-	jwtKey string
+	repo    Repository
+	jwtKey  string
+	signIns SignInRecorder
 }
 
-func NewMiddleware(repo Repository, jwtKey string) *middleware {
+func NewMiddleware(repo Repository, jwtKey string, signIns SignInRecorder) *middleware {
 	return &middleware{
-		repo:   repo,
-		jwtKey: jwtKey,
+		repo:    repo,
+		jwtKey:  jwtKey,
+		signIns: signIns,
 	}
 }
 
@@ -71,8 +84,28 @@ func (m *middleware) Middleware(next http.Handler) http.Handler {
 			http.Error(w, internalErrors.ErrInvalidClaims, http.StatusUnauthorized)
 			return
 		}
+		// токен, выданный до блокировки или удаления аккаунта, перестаёт действовать сразу
+		user, err := m.repo.GetUserByID(r.Context(), claims.OrgID, claims.UserID)
+		if err != nil {
+			log.Logger.Err(err).Msg(err.Error())
+			http.Error(w, internalErrors.ErrLogin, http.StatusInternalServerError)
+			return
+		}
+		if err := checkUserCanLogin(user); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		// роль берётся из БД: снятие роли администратора действует сразу, а не после истечения токена
+		if strings.HasPrefix(r.URL.Path, adminHandlesPrefix) && user.Role != models.RoleAdmin {
+			http.Error(w, internalErrors.ErrForbidden, http.StatusForbidden)
+			return
+		}
+
 		ctx := context.WithValue(r.Context(), models.UserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, models.UsernameKey, claims.Username)
+		ctx = context.WithValue(ctx, models.RoleKey, user.Role)
+		// все запросы репозитория ограничены организацией из токена
+		ctx = context.WithValue(ctx, models.OrgIDKey, claims.OrgID)
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
@@ -80,7 +113,19 @@ func (m *middleware) Middleware(next http.Handler) http.Handler {
 }
 
 func (m *middleware) LoginWithPass(ctx context.Context, qp models.AuthQuery) (models.AuthDTO, error) {
-	user, err := m.repo.GetUserByUsername(ctx, qp.Username)
+	orgName := qp.Organization
+	if orgName == "" {
+		orgName = models.OrganizationDefault
+	}
+	org, err := m.repo.GetOrganizationByName(ctx, orgName)
+	if err != nil {
+		return models.AuthDTO{}, err
+	}
+	if org.ID == 0 {
+		return models.AuthDTO{}, errors.New(internalErrors.ErrOrganizationNotFound)
+	}
+
+	user, err := m.repo.GetUserByUsername(ctx, org.ID, qp.Username)
 	if err != nil {
 		return models.AuthDTO{}, err
 	}
@@ -97,24 +142,35 @@ func (m *middleware) LoginWithPass(ctx context.Context, qp models.AuthQuery) (mo
 			return models.AuthDTO{}, errors.New(internalErrors.ErrWrongUsernameFormat)
 		}
 
+		referrerID, err := m.resolveReferrer(ctx, org.ID, qp.ReferralCode)
+		if err != nil {
+			return models.AuthDTO{}, err
+		}
+
 		passHash, err := m.passwordHash(qp.Password)
 		if err != nil {
 			return models.AuthDTO{}, err
 		}
-		userID, err := m.repo.CreateUserTX(ctx, qp.Username, passHash)
+		userID, err := m.repo.CreateUserTX(ctx, org, qp.Username, passHash, referrerID)
 		if err != nil {
 			return models.AuthDTO{}, err
 		}
 
-		token, err := m.generateJWT(userID, qp.Username)
+		token, err := m.generateJWT(org.ID, userID, qp.Username, models.RoleUser)
 		if err != nil {
 			return models.AuthDTO{}, err
 		}
+		m.recordSignIn(ctx, org.ID, qp.Username)
 
 		return models.AuthDTO{Token: token}, err
 	}
 
-	passHash, err := m.repo.GetUserPassHashByUsername(ctx, qp.Username)
+	// имя удалённого пользователя нельзя занять заново
+	if user.Deleted {
+		return models.AuthDTO{}, errors.New(internalErrors.ErrAccountOffboarded)
+	}
+
+	passHash, err := m.repo.GetUserPassHashByUsername(ctx, org.ID, qp.Username)
 	if err != nil {
 		return models.AuthDTO{}, err
 	}
@@ -122,20 +178,70 @@ func (m *middleware) LoginWithPass(ctx context.Context, qp models.AuthQuery) (mo
 	if err != nil {
 		return models.AuthDTO{}, errors.New(internalErrors.ErrWrongPassword)
 	}
-	token, err := m.generateJWT(user.ID, qp.Username)
+	if err := checkUserCanLogin(user); err != nil {
+		return models.AuthDTO{}, err
+	}
+	token, err := m.generateJWT(org.ID, user.ID, qp.Username, user.Role)
 	if err != nil {
 		return models.AuthDTO{}, err
 	}
+	m.recordSignIn(ctx, org.ID, qp.Username)
 
 	return models.AuthDTO{Token: token}, nil
 }
 
-func (m *middleware) generateJWT(userID int64, username string) (string, error) {
+// resolveReferrer возвращает id пригласившего пользователя, 0 - регистрация без приглашения.
+// Пригласить могут только действующие пользователи организации.
+func (m *middleware) resolveReferrer(ctx context.Context, orgID int64, code string) (int64, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return 0, nil
+	}
+
+	referrer, err := m.repo.GetUserByReferralCode(ctx, orgID, code)
+	if err != nil {
+		return 0, err
+	}
+	if checkUserCanLogin(referrer) != nil {
+		return 0, errors.New(internalErrors.ErrInvalidReferralCode)
+	}
+
+	return referrer.ID, nil
+}
+
+// recordSignIn учитывает вход в организации пользователя. Ошибка учёта не мешает получить токен.
+func (m *middleware) recordSignIn(ctx context.Context, orgID int64, username string) {
+	if m.signIns == nil {
+		return
+	}
+
+	ctx = context.WithValue(ctx, models.OrgIDKey, orgID)
+	if err := m.signIns.RecordSignIn(ctx, username); err != nil {
+		log.Logger.Err(err).Msg(err.Error())
+	}
+}
+
+// checkUserCanLogin запрещает вход удалённым и заблокированным (suspended) пользователям.
+// Замороженные (frozen) пользователи входят и просматривают данные, но не тратят монеты.
+func checkUserCanLogin(user *models.User) error {
+	if user.ID == 0 || user.Deleted {
+		return errors.New(internalErrors.ErrAccountOffboarded)
+	}
+	if user.Status == models.UserStatusSuspended {
+		return errors.New(internalErrors.ErrAccountSuspended)
+	}
+
+	return nil
+}
+
+func (m *middleware) generateJWT(orgID, userID int64, username, role string) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour)
 
 	claims := &models.Claims{
 		UserID:   userID,
 		Username: username,
+		Role:     role,
+		OrgID:    orgID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
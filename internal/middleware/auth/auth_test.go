@@ -3,11 +3,24 @@ package auth
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
 	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/golang-jwt/jwt/v5"
 )
 
+var defaultOrganization = models.Organization{ID: 1, Name: models.OrganizationDefault, StartingBalance: 1000}
+
+func getDefaultOrganization(ctx context.Context, name string) (models.Organization, error) {
+	if name != models.OrganizationDefault {
+		return models.Organization{}, nil
+	}
+	return defaultOrganization, nil
+}
+
 func Test_middleware_LoginWithPass(t *testing.T) {
 	type fields struct {
 		repo   Repository
@@ -28,10 +41,11 @@ func Test_middleware_LoginWithPass(t *testing.T) {
 			name: "Successfully create new user and generate token",
 			fields: fields{
 				repo: &MockRepository{
-					GetUserByUsernameFunc: func(ctx context.Context, username string) (*models.User, error) {
+					GetOrganizationByNameFunc: getDefaultOrganization,
+					GetUserByUsernameFunc: func(ctx context.Context, orgID int64, username string) (*models.User, error) {
 						return &models.User{ID: 0}, nil
 					},
-					CreateUserTXFunc: func(ctx context.Context, username, passwordHash string) (int64, error) {
+					CreateUserTXFunc: func(ctx context.Context, org models.Organization, username, passwordHash string, referrerID int64) (int64, error) {
 						return 1, nil
 					},
 				},
@@ -48,10 +62,11 @@ func Test_middleware_LoginWithPass(t *testing.T) {
 		// 	name: "User already exists and password is correct",
 		// 	fields: fields{
 		// 		repo: &MockRepository{
-		// 			GetUserByUsernameFunc: func(ctx context.Context, username string) (*models.User, error) {
+		// 			GetOrganizationByNameFunc: getDefaultOrganization,
+		// 			GetUserByUsernameFunc: func(ctx context.Context, orgID int64, username string) (*models.User, error) {
 		// 				return &models.User{ID: 1}, nil
 		// 			},
-		// 			GetUserPassHashByUsernameFunc: func(ctx context.Context, username string) (string, error) {
+		// 			GetUserPassHashByUsernameFunc: func(ctx context.Context, orgID int64, username string) (string, error) {
 		// 				return "hashedpassword", nil
 		// 			},
 		// 		},
@@ -68,7 +83,8 @@ func Test_middleware_LoginWithPass(t *testing.T) {
 			name: "Invalid password format for new user",
 			fields: fields{
 				repo: &MockRepository{
-					GetUserByUsernameFunc: func(ctx context.Context, username string) (*models.User, error) {
+					GetOrganizationByNameFunc: getDefaultOrganization,
+					GetUserByUsernameFunc: func(ctx context.Context, orgID int64, username string) (*models.User, error) {
 						return &models.User{ID: 0}, nil
 					},
 				},
@@ -85,10 +101,11 @@ func Test_middleware_LoginWithPass(t *testing.T) {
 			name: "Error on creating user",
 			fields: fields{
 				repo: &MockRepository{
-					GetUserByUsernameFunc: func(ctx context.Context, username string) (*models.User, error) {
+					GetOrganizationByNameFunc: getDefaultOrganization,
+					GetUserByUsernameFunc: func(ctx context.Context, orgID int64, username string) (*models.User, error) {
 						return &models.User{ID: 0}, nil
 					},
-					CreateUserTXFunc: func(ctx context.Context, username, passwordHash string) (int64, error) {
+					CreateUserTXFunc: func(ctx context.Context, org models.Organization, username, passwordHash string, referrerID int64) (int64, error) {
 						return 0, errors.New("database error") // Ошибка при создании пользователя
 					},
 				},
@@ -105,10 +122,11 @@ func Test_middleware_LoginWithPass(t *testing.T) {
 		// 	name: "Error on generating JWT token",
 		// 	fields: fields{
 		// 		repo: &MockRepository{
-		// 			GetUserByUsernameFunc: func(ctx context.Context, username string) (*models.User, error) {
+		// 			GetOrganizationByNameFunc: getDefaultOrganization,
+		// 			GetUserByUsernameFunc: func(ctx context.Context, orgID int64, username string) (*models.User, error) {
 		// 				return &models.User{ID: 0}, nil
 		// 			},
-		// 			CreateUserTXFunc: func(ctx context.Context, username, passwordHash string) (int64, error) {
+		// 			CreateUserTXFunc: func(ctx context.Context, org models.Organization, username, passwordHash string, referrerID int64) (int64, error) {
 		// 				return 1, nil
 		// 			},
 		// 		},
@@ -140,3 +158,465 @@ func Test_middleware_LoginWithPass(t *testing.T) {
 		})
 	}
 }
+
+func Test_middleware_LoginWithPass_accountStatus(t *testing.T) {
+	m := &middleware{jwtKey: "someKey"}
+	passHash, err := m.passwordHash("Test123@")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		user    models.User
+		wantErr string
+	}{
+		{
+			name: "Frozen user can log in",
+			user: models.User{ID: 1, Status: models.UserStatusFrozen},
+		},
+		{
+			name:    "Suspended user can't log in",
+			user:    models.User{ID: 1, Status: models.UserStatusSuspended},
+			wantErr: internalErrors.ErrAccountSuspended,
+		},
+		{
+			name:    "Offboarded user can't log in or register again",
+			user:    models.User{ID: 1, Status: models.UserStatusActive, Deleted: true},
+			wantErr: internalErrors.ErrAccountOffboarded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.repo = &MockRepository{
+				GetOrganizationByNameFunc: getDefaultOrganization,
+				GetUserByUsernameFunc: func(ctx context.Context, orgID int64, username string) (*models.User, error) {
+					return &tt.user, nil
+				},
+				GetUserPassHashByUsernameFunc: func(ctx context.Context, orgID int64, username string) (string, error) {
+					return passHash, nil
+				},
+			}
+
+			got, err := m.LoginWithPass(context.Background(), models.AuthQuery{Username: "testuser", Password: "Test123@"})
+			if tt.wantErr == "" {
+				if err != nil || got.Token == "" {
+					t.Errorf("middleware.LoginWithPass() = %v, error = %v", got, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("middleware.LoginWithPass() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_middleware_Middleware_accountStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       models.User
+		wantStatus int
+	}{
+		{
+			name:       "Frozen user token is accepted",
+			user:       models.User{ID: 1, Status: models.UserStatusFrozen},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Suspended user token is rejected",
+			user:       models.User{ID: 1, Status: models.UserStatusSuspended},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Offboarded user token is rejected",
+			user:       models.User{ID: 1, Status: models.UserStatusActive, Deleted: true},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &middleware{
+				repo: &MockRepository{
+					GetUserByIDFunc: func(ctx context.Context, orgID, userID int64) (*models.User, error) {
+						return &tt.user, nil
+					},
+				},
+				jwtKey: "someKey",
+			}
+			token, err := m.generateJWT(1, 1, "testuser", models.RoleUser)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("middleware.Middleware() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_middleware_Middleware_metrics(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		withToken  bool
+		wantStatus int
+	}{
+		{
+			name:       "Metrics without token are rejected",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Metrics for user are forbidden",
+			role:       models.RoleUser,
+			withToken:  true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Metrics for admin are allowed",
+			role:       models.RoleAdmin,
+			withToken:  true,
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &middleware{
+				repo: &MockRepository{
+					GetUserByIDFunc: func(ctx context.Context, orgID, userID int64) (*models.User, error) {
+						return &models.User{ID: userID, Role: tt.role, Status: models.UserStatusActive}, nil
+					},
+				},
+				jwtKey: "someKey",
+			}
+
+			handler := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/debug/vars", nil)
+			if tt.withToken {
+				token, err := m.generateJWT(1, 1, "testuser", tt.role)
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("middleware.Middleware() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_middleware_Middleware_storedRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		tokenRole  string
+		storedRole string
+		wantStatus int
+	}{
+		{
+			name:       "Demoted admin token is forbidden",
+			tokenRole:  models.RoleAdmin,
+			storedRole: models.RoleUser,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Promoted user token is allowed",
+			tokenRole:  models.RoleUser,
+			storedRole: models.RoleAdmin,
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &middleware{
+				repo: &MockRepository{
+					GetUserByIDFunc: func(ctx context.Context, orgID, userID int64) (*models.User, error) {
+						return &models.User{ID: userID, Role: tt.storedRole, Status: models.UserStatusActive}, nil
+					},
+				},
+				jwtKey: "someKey",
+			}
+			token, err := m.generateJWT(1, 1, "testuser", tt.tokenRole)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var gotRole string
+			handler := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotRole, _ = r.Context().Value(models.RoleKey).(string)
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/fraud/cases", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("middleware.Middleware() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+			if rec.Code == http.StatusOK && gotRole != tt.storedRole {
+				t.Errorf("middleware.Middleware() role = %v, want %v", gotRole, tt.storedRole)
+			}
+		})
+	}
+}
+
+func Test_middleware_LoginWithPass_organization(t *testing.T) {
+	acme := models.Organization{ID: 2, Name: "acme", StartingBalance: 500}
+
+	t.Run("Unknown organization", func(t *testing.T) {
+		m := &middleware{
+			repo: &MockRepository{
+				GetOrganizationByNameFunc: func(ctx context.Context, name string) (models.Organization, error) {
+					return models.Organization{}, nil
+				},
+			},
+			jwtKey: "someKey",
+		}
+
+		_, err := m.LoginWithPass(context.Background(), models.AuthQuery{Organization: "unknown", Username: "testuser", Password: "Test123@"})
+		if err == nil || err.Error() != internalErrors.ErrOrganizationNotFound {
+			t.Errorf("middleware.LoginWithPass() error = %v, wantErr %v", err, internalErrors.ErrOrganizationNotFound)
+		}
+	})
+
+	t.Run("New user is created in requested organization", func(t *testing.T) {
+		var gotOrg models.Organization
+		m := &middleware{
+			repo: &MockRepository{
+				GetOrganizationByNameFunc: func(ctx context.Context, name string) (models.Organization, error) {
+					return acme, nil
+				},
+				GetUserByUsernameFunc: func(ctx context.Context, orgID int64, username string) (*models.User, error) {
+					if orgID != acme.ID {
+						t.Errorf("GetUserByUsername() orgID = %v, want %v", orgID, acme.ID)
+					}
+					return &models.User{}, nil
+				},
+				CreateUserTXFunc: func(ctx context.Context, org models.Organization, username, passwordHash string, referrerID int64) (int64, error) {
+					gotOrg = org
+					return 1, nil
+				},
+			},
+			jwtKey: "someKey",
+		}
+
+		got, err := m.LoginWithPass(context.Background(), models.AuthQuery{Organization: "acme", Username: "testuser", Password: "Test123@"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotOrg != acme {
+			t.Errorf("CreateUserTX() org = %v, want %v", gotOrg, acme)
+		}
+
+		claims := &models.Claims{}
+		_, err = jwt.ParseWithClaims(got.Token, claims, func(token *jwt.Token) (any, error) {
+			return []byte("someKey"), nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claims.OrgID != acme.ID {
+			t.Errorf("token org = %v, want %v", claims.OrgID, acme.ID)
+		}
+	})
+}
+
+func Test_middleware_Middleware_organization(t *testing.T) {
+	m := &middleware{
+		repo: &MockRepository{
+			GetUserByIDFunc: func(ctx context.Context, orgID, userID int64) (*models.User, error) {
+				if orgID != 2 {
+					return &models.User{}, nil
+				}
+				return &models.User{ID: userID, Status: models.UserStatusActive}, nil
+			},
+		},
+		jwtKey: "someKey",
+	}
+	token, err := m.generateJWT(2, 1, "testuser", models.RoleUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotOrgID int64
+	handler := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotOrgID, _ = r.Context().Value(models.OrgIDKey).(int64)
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("middleware.Middleware() status = %v, want %v", rec.Code, http.StatusOK)
+	}
+	if gotOrgID != 2 {
+		t.Errorf("middleware.Middleware() orgID = %v, want %v", gotOrgID, 2)
+	}
+}
+
+type signInRecorderFunc func(ctx context.Context, username string) error
+
+func (f signInRecorderFunc) RecordSignIn(ctx context.Context, username string) error {
+	return f(ctx, username)
+}
+
+func Test_middleware_LoginWithPass_recordSignIn(t *testing.T) {
+	m := &middleware{jwtKey: "someKey"}
+	passHash, err := m.passwordHash("Test123@")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		password  string
+		recordErr error
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "Sign in is recorded in user organization",
+			password:  "Test123@",
+			wantCalls: 1,
+		},
+		{
+			name:      "Recording error doesn't fail login",
+			password:  "Test123@",
+			recordErr: errors.New("db down"),
+			wantCalls: 1,
+		},
+		{
+			name:     "Failed login isn't recorded",
+			password: "Wrong123@",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			m := &middleware{
+				repo: &MockRepository{
+					GetOrganizationByNameFunc: getDefaultOrganization,
+					GetUserByUsernameFunc: func(ctx context.Context, orgID int64, username string) (*models.User, error) {
+						return &models.User{ID: 1, Username: username, Role: models.RoleUser, Status: models.UserStatusActive}, nil
+					},
+					GetUserPassHashByUsernameFunc: func(ctx context.Context, orgID int64, username string) (string, error) {
+						return passHash, nil
+					},
+				},
+				jwtKey: "someKey",
+				signIns: signInRecorderFunc(func(ctx context.Context, username string) error {
+					calls++
+					if orgID, _ := ctx.Value(models.OrgIDKey).(int64); orgID != defaultOrganization.ID {
+						t.Errorf("RecordSignIn() orgID = %v, want %v", orgID, defaultOrganization.ID)
+					}
+					if username != "testuser" {
+						t.Errorf("RecordSignIn() username = %v, want %v", username, "testuser")
+					}
+					return tt.recordErr
+				}),
+			}
+
+			_, err := m.LoginWithPass(context.Background(), models.AuthQuery{Username: "testuser", Password: tt.password})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("middleware.LoginWithPass() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("RecordSignIn() calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func Test_middleware_LoginWithPass_referral(t *testing.T) {
+	tests := []struct {
+		name         string
+		code         string
+		referrer     *models.User
+		wantReferrer int64
+		wantErr      string
+	}{
+		{
+			name:         "success_-_referrer_resolved_by_code",
+			code:         " ab12cd34ef ",
+			referrer:     &models.User{ID: 7, Username: "referrer", Status: models.UserStatusActive},
+			wantReferrer: 7,
+		},
+		{
+			name:         "success_-_no_code",
+			wantReferrer: 0,
+		},
+		{
+			name:     "fail_-_unknown_code",
+			code:     "UNKNOWN",
+			referrer: &models.User{},
+			wantErr:  internalErrors.ErrInvalidReferralCode,
+		},
+		{
+			name:     "fail_-_suspended_referrer",
+			code:     "AB12CD34EF",
+			referrer: &models.User{ID: 7, Username: "referrer", Status: models.UserStatusSuspended},
+			wantErr:  internalErrors.ErrInvalidReferralCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotReferrer int64 = -1
+			m := &middleware{
+				repo: &MockRepository{
+					GetOrganizationByNameFunc: func(ctx context.Context, name string) (models.Organization, error) {
+						return models.Organization{ID: 1, Name: models.OrganizationDefault}, nil
+					},
+					GetUserByUsernameFunc: func(ctx context.Context, orgID int64, username string) (*models.User, error) {
+						return &models.User{}, nil
+					},
+					GetUserByReferralCodeFunc: func(ctx context.Context, orgID int64, code string) (*models.User, error) {
+						if code != "AB12CD34EF" && code != "UNKNOWN" {
+							t.Errorf("GetUserByReferralCode() code = %v", code)
+						}
+						return tt.referrer, nil
+					},
+					CreateUserTXFunc: func(ctx context.Context, org models.Organization, username, passwordHash string, referrerID int64) (int64, error) {
+						gotReferrer = referrerID
+						return 1, nil
+					},
+				},
+				jwtKey: "someKey",
+			}
+
+			_, err := m.LoginWithPass(context.Background(), models.AuthQuery{Username: "newbie", Password: "Test123@", ReferralCode: tt.code})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("middleware.LoginWithPass() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if gotReferrer != -1 {
+					t.Errorf("CreateUserTX() called with invalid referral code")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotReferrer != tt.wantReferrer {
+				t.Errorf("CreateUserTX() referrerID = %v, want %v", gotReferrer, tt.wantReferrer)
+			}
+		})
+	}
+}
@@ -26,7 +26,35 @@ func (r *repository) txRollback(ctx context.Context, tx pgx.Tx, err error) {
 	}
 }
 
-func (r *repository) CreateUserTX(ctx context.Context, username, passwordHash string) (int64, error) {
+// GetOrganizationByName возвращает пустую организацию, если она не найдена
+func (r *repository) GetOrganizationByName(ctx context.Context, name string) (models.Organization, error) {
+	org := models.Organization{}
+
+	query := `
+		SELECT
+			o.id,
+			o.name,
+			o.starting_balance
+		FROM
+			shop."organization" o
+		WHERE
+			o.name = $1
+	`
+
+	err := r.db.QueryRow(ctx, query, name).Scan(&org.ID, &org.Name, &org.StartingBalance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Organization{}, nil
+		}
+		return models.Organization{}, fmt.Errorf("GetOrganizationByName failed: %w", err)
+	}
+
+	return org, nil
+}
+
+// CreateUserTX создаёт пользователя в организации со стартовым балансом этой организации.
+// Ненулевой referrerID фиксирует приглашение, бонус по нему выплачивается позже.
+func (r *repository) CreateUserTX(ctx context.Context, org models.Organization, username, passwordHash string, referrerID int64) (int64, error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return 0, err
@@ -34,29 +62,45 @@ func (r *repository) CreateUserTX(ctx context.Context, username, passwordHash st
 
 	// создание баланса
 	var balanceID int64
-	query := `INSERT INTO shop."balance" (amount) VALUES (1000) RETURNING id`
-	err = tx.QueryRow(ctx, query).Scan(&balanceID)
+	query := `INSERT INTO shop."balance" (org_id, amount) VALUES ($1, $2) RETURNING id`
+	err = tx.QueryRow(ctx, query, org.ID, org.StartingBalance).Scan(&balanceID)
 	if err != nil {
 		r.txRollback(ctx, tx, err)
 		return 0, fmt.Errorf("failed to create balance CreateUserTX: %w", err)
 	}
 
+	// стартовые монеты - первый лот баланса
+	query = `INSERT INTO shop."balance_lot" (balance_id, amount) SELECT $1, $2 WHERE $2 > 0`
+	_, err = tx.Exec(ctx, query, balanceID, org.StartingBalance)
+	if err != nil {
+		r.txRollback(ctx, tx, err)
+		return 0, fmt.Errorf("failed to create balance lot CreateUserTX: %w", err)
+	}
+
 	// создание пользователя
 	var userID int64
 	query = `
 		INSERT INTO 
-			shop."user" (balance_id, username, password_hash)
+			shop."user" (org_id, balance_id, username, password_hash)
 		VALUES 
-			($1, $2, $3)
+			($4, $1, $2, $3)
 		RETURNING 
 			id
 	`
-	err = tx.QueryRow(ctx, query, balanceID, username, passwordHash).Scan(&userID)
+	err = tx.QueryRow(ctx, query, balanceID, username, passwordHash, org.ID).Scan(&userID)
 	if err != nil {
 		r.txRollback(ctx, tx, err)
 		return 0, fmt.Errorf("failed to create user CreateUserTX: %w", err)
 	}
 
+	// баланс монет - кошелёк пользователя в валюте по умолчанию
+	query = `UPDATE shop."balance" SET user_id = $1 WHERE id = $2`
+	_, err = tx.Exec(ctx, query, userID, balanceID)
+	if err != nil {
+		r.txRollback(ctx, tx, err)
+		return 0, fmt.Errorf("failed to link balance CreateUserTX: %w", err)
+	}
+
 	// создание инвентаря пользователя
 	var inventoryID int64
 	query = `INSERT INTO shop."inventory" (user_id) VALUES ($1) RETURNING id`
@@ -66,6 +110,16 @@ func (r *repository) CreateUserTX(ctx context.Context, username, passwordHash st
 		return 0, fmt.Errorf("failed to create inventory CreateUserTX: %w", err)
 	}
 
+	// приглашение
+	if referrerID != 0 {
+		query = `INSERT INTO shop."referral" (org_id, referrer_id, referee_id) VALUES ($1, $2, $3)`
+		_, err = tx.Exec(ctx, query, org.ID, referrerID, userID)
+		if err != nil {
+			r.txRollback(ctx, tx, err)
+			return 0, fmt.Errorf("failed to create referral CreateUserTX: %w", err)
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		err = fmt.Errorf("failed to commit transaction CreateUserTX: %w", err)
 		r.txRollback(ctx, tx, err)
@@ -75,29 +129,94 @@ func (r *repository) CreateUserTX(ctx context.Context, username, passwordHash st
 	return userID, nil
 }
 
-func (r *repository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
-	user := models.UserDB{}
+// GetUserByUsername возвращает и удалённых пользователей, чтобы их имя нельзя было зарегистрировать заново
+func (r *repository) GetUserByUsername(ctx context.Context, orgID int64, username string) (*models.User, error) {
+	query := `
+		SELECT
+			u.id,
+			u.balance_id,
+			u.username,
+			u.password_hash,
+			u.role,
+			u.status,
+			u.created_at,
+			u.deleted_at
+		FROM
+			shop."user" u
+		WHERE
+			u.org_id = $1 AND u.username = $2
+	`
+
+	user, err := scanUser(r.db.QueryRow(ctx, query, orgID, username))
+	if err != nil {
+		return &models.User{}, fmt.Errorf("GetUserByUsername failed: %w", err)
+	}
+
+	return user, nil
+}
 
+func (r *repository) GetUserByID(ctx context.Context, orgID, userID int64) (*models.User, error) {
 	query := `
 		SELECT
 			u.id,
 			u.balance_id,
 			u.username,
 			u.password_hash,
+			u.role,
+			u.status,
 			u.created_at,
 			u.deleted_at
 		FROM
 			shop."user" u
 		WHERE
-			u.username = $1 AND u.deleted_at IS NULL
+			u.org_id = $1 AND u.id = $2
 	`
 
-	row := r.db.QueryRow(ctx, query, username)
+	user, err := scanUser(r.db.QueryRow(ctx, query, orgID, userID))
+	if err != nil {
+		return &models.User{}, fmt.Errorf("GetUserByID failed: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetUserByReferralCode возвращает пустого пользователя, если код не найден
+func (r *repository) GetUserByReferralCode(ctx context.Context, orgID int64, code string) (*models.User, error) {
+	query := `
+		SELECT
+			u.id,
+			u.balance_id,
+			u.username,
+			u.password_hash,
+			u.role,
+			u.status,
+			u.created_at,
+			u.deleted_at
+		FROM
+			shop."user" u
+		WHERE
+			u.org_id = $1 AND u.referral_code = $2
+	`
+
+	user, err := scanUser(r.db.QueryRow(ctx, query, orgID, code))
+	if err != nil {
+		return &models.User{}, fmt.Errorf("GetUserByReferralCode failed: %w", err)
+	}
+
+	return user, nil
+}
+
+// scanUser возвращает пустого пользователя, если строка не найдена
+func scanUser(row pgx.Row) (*models.User, error) {
+	user := models.UserDB{}
+
 	err := row.Scan(
 		&user.ID,
 		&user.BalanceID,
 		&user.Username,
 		&user.PasswordHash,
+		&user.Role,
+		&user.Status,
 		&user.CreatedAt,
 		&user.DeletedAt,
 	)
@@ -105,13 +224,13 @@ func (r *repository) GetUserByUsername(ctx context.Context, username string) (*m
 		if errors.Is(err, pgx.ErrNoRows) {
 			return &models.User{}, nil
 		}
-		return &models.User{}, fmt.Errorf("GetUserByUsername failed: %w", err)
+		return &models.User{}, err
 	}
 
 	return user.ToModelUser(), nil
 }
 
-func (r *repository) GetUserPassHashByUsername(ctx context.Context, username string) (string, error) {
+func (r *repository) GetUserPassHashByUsername(ctx context.Context, orgID int64, username string) (string, error) {
 	passHash := ""
 
 	query := `
@@ -120,10 +239,10 @@ func (r *repository) GetUserPassHashByUsername(ctx context.Context, username str
 		FROM
 			shop."user" u
 		WHERE
-			u.username = $1 AND u.deleted_at IS NULL
+			u.org_id = $1 AND u.username = $2 AND u.deleted_at IS NULL
 	`
 
-	row := r.db.QueryRow(ctx, query, username)
+	row := r.db.QueryRow(ctx, query, orgID, username)
 	err := row.Scan(&passHash)
 	if err != nil {
 		return "", fmt.Errorf("GetUserPassHashByUsername failed: %w", err)
@@ -7,19 +7,34 @@ import (
 )
 
 type MockRepository struct {
-	CreateUserTXFunc              func(ctx context.Context, username, passwordHash string) (int64, error)
-	GetUserByUsernameFunc         func(ctx context.Context, username string) (*models.User, error)
-	GetUserPassHashByUsernameFunc func(ctx context.Context, username string) (string, error)
+	GetOrganizationByNameFunc     func(ctx context.Context, name string) (models.Organization, error)
+	CreateUserTXFunc              func(ctx context.Context, org models.Organization, username, passwordHash string, referrerID int64) (int64, error)
+	GetUserByUsernameFunc         func(ctx context.Context, orgID int64, username string) (*models.User, error)
+	GetUserByReferralCodeFunc     func(ctx context.Context, orgID int64, code string) (*models.User, error)
+	GetUserByIDFunc               func(ctx context.Context, orgID, userID int64) (*models.User, error)
+	GetUserPassHashByUsernameFunc func(ctx context.Context, orgID int64, username string) (string, error)
 }
 
-func (m *MockRepository) CreateUserTX(ctx context.Context, username, passwordHash string) (int64, error) {
-	return m.CreateUserTXFunc(ctx, username, passwordHash)
+func (m *MockRepository) GetOrganizationByName(ctx context.Context, name string) (models.Organization, error) {
+	return m.GetOrganizationByNameFunc(ctx, name)
 }
 
-func (m *MockRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
-	return m.GetUserByUsernameFunc(ctx, username)
+func (m *MockRepository) CreateUserTX(ctx context.Context, org models.Organization, username, passwordHash string, referrerID int64) (int64, error) {
+	return m.CreateUserTXFunc(ctx, org, username, passwordHash, referrerID)
 }
 
-func (m *MockRepository) GetUserPassHashByUsername(ctx context.Context, username string) (string, error) {
-	return m.GetUserPassHashByUsernameFunc(ctx, username)
+func (m *MockRepository) GetUserByUsername(ctx context.Context, orgID int64, username string) (*models.User, error) {
+	return m.GetUserByUsernameFunc(ctx, orgID, username)
+}
+
+func (m *MockRepository) GetUserByReferralCode(ctx context.Context, orgID int64, code string) (*models.User, error) {
+	return m.GetUserByReferralCodeFunc(ctx, orgID, code)
+}
+
+func (m *MockRepository) GetUserByID(ctx context.Context, orgID, userID int64) (*models.User, error) {
+	return m.GetUserByIDFunc(ctx, orgID, userID)
+}
+
+func (m *MockRepository) GetUserPassHashByUsername(ctx context.Context, orgID int64, username string) (string, error) {
+	return m.GetUserPassHashByUsernameFunc(ctx, orgID, username)
 }
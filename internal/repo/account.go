@@ -0,0 +1,205 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Account
+// GetAccountState возвращает ограничения пользователя, влияющие на списание монет
+func (r *repository) GetAccountState(ctx context.Context, username string) (models.AccountState, error) {
+	state := models.AccountState{}
+
+	query := `
+		SELECT
+			u.status,
+			u.fraud_hold,
+			u.deleted_at IS NOT NULL
+		FROM
+			shop."user" u
+		WHERE
+			u.org_id = $1 AND u.username = $2
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, orgID(ctx), username).Scan(&state.Status, &state.FraudHold, &state.Deleted)
+	if err != nil {
+		return state, fmt.Errorf("GetAccountState failed: %w", err)
+	}
+
+	return state, nil
+}
+
+// GetUserForUpdate блокирует строку пользователя, чтобы действия администраторов над аккаунтом не пересекались
+func (r *repository) GetUserForUpdate(ctx context.Context, username string) (models.User, error) {
+	udb := models.UserDB{}
+
+	query := `
+		SELECT
+			u.id,
+			u.balance_id,
+			u.username,
+			u.role,
+			u.status,
+			u.deleted_at,
+			u.created_at
+		FROM
+			shop."user" u
+		WHERE
+			u.org_id = $1 AND u.username = $2
+		FOR UPDATE
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, orgID(ctx), username).Scan(
+		&udb.ID,
+		&udb.BalanceID,
+		&udb.Username,
+		&udb.Role,
+		&udb.Status,
+		&udb.DeletedAt,
+		&udb.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.User{}, nil
+		}
+		return models.User{}, fmt.Errorf("GetUserForUpdate failed: %w", err)
+	}
+
+	return *udb.ToModelUser(), nil
+}
+
+func (r *repository) UpdateUserStatus(ctx context.Context, userID int64, status string) error {
+	query := `
+		UPDATE
+			shop."user"
+		SET
+			status = $1
+		WHERE
+			id = $2 AND org_id = $3
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, status, userID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("UpdateUserStatus failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows updated UpdateUserStatus")
+	}
+
+	return nil
+}
+
+// OffboardUser мягко удаляет пользователя вместе со всеми его кошельками
+func (r *repository) OffboardUser(ctx context.Context, userID int64) error {
+	query := `
+		WITH u AS (
+			UPDATE
+				shop."user"
+			SET
+				deleted_at = NOW()
+			WHERE
+				id = $1 AND org_id = $2
+			RETURNING
+				id
+		)
+		UPDATE
+			shop."balance" b
+		SET
+			deleted_at = NOW()
+		FROM
+			u
+		WHERE
+			b.user_id = u.id
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, userID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("OffboardUser failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows updated OffboardUser")
+	}
+
+	return nil
+}
+
+// Account audit
+func (r *repository) CreateAccountAudit(ctx context.Context, audit models.AccountAudit) error {
+	query := `
+		INSERT INTO
+			shop."account_audit" (user_id, action, reason, actor, swept_amount)
+		SELECT
+			u.id, $2, $3, $4, $5
+		FROM
+			shop."user" u
+		WHERE
+			u.id = $1 AND u.org_id = $6
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, audit.UserID, audit.Action, audit.Reason, audit.Actor, audit.SweptAmount, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("CreateAccountAudit failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows inserted CreateAccountAudit")
+	}
+
+	return nil
+}
+
+func (r *repository) GetAccountAudit(ctx context.Context, username string) ([]models.AccountAudit, error) {
+	query := `
+		SELECT
+			aa.id,
+			aa.user_id,
+			aa.action,
+			aa.reason,
+			aa.actor,
+			aa.swept_amount,
+			aa.created_at
+		FROM
+			shop."account_audit" aa
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = aa.user_id
+		WHERE
+			u.org_id = $1 AND u.username = $2
+		ORDER BY
+			aa.id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, orgID(ctx), username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetAccountAudit: %w", err)
+	}
+	defer rows.Close()
+
+	audit := []models.AccountAudit{}
+	for rows.Next() {
+		aadb := models.AccountAuditDB{}
+		err := rows.Scan(
+			&aadb.ID,
+			&aadb.UserID,
+			&aadb.Action,
+			&aadb.Reason,
+			&aadb.Actor,
+			&aadb.SweptAmount,
+			&aadb.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan GetAccountAudit: %w", err)
+		}
+		audit = append(audit, aadb.ToModelAccountAudit())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetAccountAudit: %w", err)
+	}
+
+	return audit, nil
+}
@@ -0,0 +1,214 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Столбцы счётчиков активности. В запрос попадает только столбец из этого списка.
+var activityColumns = map[string]string{
+	models.ActivitySignIns:       "sign_ins",
+	models.ActivityTransfersSent: "transfers_sent",
+	models.ActivityPurchases:     "purchases",
+}
+
+// Achievement
+// IncrementUserActivity увеличивает счётчик активности пользователя организации
+func (r *repository) IncrementUserActivity(ctx context.Context, username, activity string) error {
+	column, ok := activityColumns[activity]
+	if !ok {
+		return fmt.Errorf("IncrementUserActivity failed: unknown activity %q", activity)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO
+			shop."user_activity" (user_id, %[1]s)
+		SELECT
+			u.id, 1
+		FROM
+			shop."user" u
+		WHERE
+			u.org_id = $1 AND u.username = $2
+		ON CONFLICT (user_id)
+		DO UPDATE SET %[1]s = shop."user_activity".%[1]s + 1
+	`, column)
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, orgID(ctx), username)
+	if err != nil {
+		return fmt.Errorf("IncrementUserActivity failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows updated IncrementUserActivity")
+	}
+
+	return nil
+}
+
+// GetDueAchievements возвращает ещё не выданные пользователю достижения события, порог которых уже достигнут.
+// Метрики считаются по счётчикам активности, агрегатам рейтинга за всё время и инвентарю.
+func (r *repository) GetDueAchievements(ctx context.Context, username, event string) ([]models.Achievement, error) {
+	query := `
+		WITH u AS (
+			SELECT
+				id, org_id
+			FROM
+				shop."user"
+			WHERE
+				org_id = $1 AND username = $2
+		), m AS (
+			SELECT
+				COALESCE(ua.sign_ins, 0) AS sign_ins,
+				COALESCE(ua.transfers_sent, 0) AS transfers_sent,
+				COALESCE(ua.purchases, 0) AS purchases,
+				COALESCE(ls.given, 0) AS coins_given,
+				COALESCE(ls.received, 0) AS coins_received,
+				COALESCE(ls.thanked, 0) AS people_thanked,
+				(
+					SELECT
+						COALESCE(COUNT(DISTINCT im.merch_id) * 100 / NULLIF((SELECT COUNT(*) FROM shop."merch" WHERE org_id = u.org_id), 0), 0)
+					FROM
+						shop."inventory" i
+					INNER JOIN
+						shop."inventory_merch" im
+					ON
+						im.inventory_id = i.id
+					WHERE
+						i.user_id = u.id
+				) AS catalog_percent
+			FROM
+				u
+			LEFT JOIN
+				shop."user_activity" ua
+			ON
+				ua.user_id = u.id
+			LEFT JOIN
+				shop."leaderboard_stat" ls
+			ON
+				ls.user_id = u.id AND ls.period = 'all'
+		)
+		SELECT
+			a.id,
+			a.code,
+			a.title,
+			a.description,
+			a.event,
+			a.metric,
+			a.threshold,
+			a.reward
+		FROM
+			u
+		CROSS JOIN
+			m
+		INNER JOIN
+			shop."achievement" a
+		ON
+			a.org_id = u.org_id AND a.event = $3 AND a.active
+		WHERE
+			NOT EXISTS (SELECT 1 FROM shop."user_achievement" ua WHERE ua.user_id = u.id AND ua.achievement_id = a.id)
+			AND CASE a.metric
+				WHEN 'sign_ins' THEN m.sign_ins
+				WHEN 'transfers_sent' THEN m.transfers_sent
+				WHEN 'purchases' THEN m.purchases
+				WHEN 'coins_given' THEN m.coins_given
+				WHEN 'coins_received' THEN m.coins_received
+				WHEN 'people_thanked' THEN m.people_thanked
+				WHEN 'catalog_percent' THEN m.catalog_percent
+			END >= a.threshold
+		ORDER BY
+			a.id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, orgID(ctx), username, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetDueAchievements: %w", err)
+	}
+	defer rows.Close()
+
+	achievements := []models.Achievement{}
+	for rows.Next() {
+		a := models.Achievement{}
+		err := rows.Scan(&a.ID, &a.Code, &a.Title, &a.Description, &a.Event, &a.Metric, &a.Threshold, &a.Reward)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan GetDueAchievements: %w", err)
+		}
+		achievements = append(achievements, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetDueAchievements: %w", err)
+	}
+
+	return achievements, nil
+}
+
+// AwardAchievement выдаёт достижение. false, если оно уже выдано, в том числе конкурентной транзакцией.
+func (r *repository) AwardAchievement(ctx context.Context, username string, achievementID int64) (bool, error) {
+	query := `
+		INSERT INTO
+			shop."user_achievement" (user_id, achievement_id)
+		SELECT
+			u.id, a.id
+		FROM
+			shop."user" u
+		INNER JOIN
+			shop."achievement" a
+		ON
+			a.org_id = u.org_id AND a.id = $3
+		WHERE
+			u.org_id = $1 AND u.username = $2
+		ON CONFLICT DO NOTHING
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, orgID(ctx), username, achievementID)
+	if err != nil {
+		return false, fmt.Errorf("AwardAchievement failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+func (r *repository) GetUserAchievements(ctx context.Context, username string) ([]models.UserAchievement, error) {
+	query := `
+		SELECT
+			a.code,
+			a.title,
+			a.description,
+			a.reward,
+			ua.awarded_at
+		FROM
+			shop."user_achievement" ua
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = ua.user_id
+		INNER JOIN
+			shop."achievement" a
+		ON
+			a.id = ua.achievement_id
+		WHERE
+			u.org_id = $1 AND u.username = $2
+		ORDER BY
+			ua.awarded_at, a.id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, orgID(ctx), username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetUserAchievements: %w", err)
+	}
+	defer rows.Close()
+
+	achievements := []models.UserAchievement{}
+	for rows.Next() {
+		a := models.UserAchievement{}
+		if err := rows.Scan(&a.Code, &a.Title, &a.Description, &a.Reward, &a.AwardedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan GetUserAchievements: %w", err)
+		}
+		achievements = append(achievements, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetUserAchievements: %w", err)
+	}
+
+	return achievements, nil
+}
@@ -0,0 +1,240 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Balance lot
+func (r *repository) CreateBalanceLot(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+	query := `
+		INSERT INTO
+			shop."balance_lot" (balance_id, amount, granted_at)
+		SELECT
+			b.id, $2, $3
+		FROM
+			shop."balance" b
+		WHERE
+			b.id = $1 AND b.org_id = $4
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, balanceID, amount, grantedAt, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("CreateBalanceLot failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows inserted CreateBalanceLot")
+	}
+
+	return nil
+}
+
+// SpendBalanceLots списывает amount с лотов баланса, начиная с самых старых.
+// Возвращает списанные части лотов с их датами начисления.
+func (r *repository) SpendBalanceLots(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+	query := `
+		SELECT
+			bl.id,
+			bl.balance_id,
+			bl.amount,
+			bl.granted_at,
+			bl.created_at
+		FROM
+			shop."balance_lot" bl
+		INNER JOIN
+			shop."balance" b
+		ON
+			b.id = bl.balance_id
+		WHERE
+			bl.balance_id = $1 AND b.org_id = $2
+		ORDER BY
+			bl.granted_at, bl.id
+		FOR UPDATE OF bl
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, balanceID, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query SpendBalanceLots: %w", err)
+	}
+	defer rows.Close()
+
+	spent := []models.BalanceLot{}
+	spentIDs := []int64{}
+	var partial models.BalanceLot
+	remaining := amount
+	for rows.Next() && remaining > 0 {
+		lotDB := models.BalanceLotDB{}
+		err := rows.Scan(&lotDB.ID, &lotDB.BalanceID, &lotDB.Amount, &lotDB.GrantedAt, &lotDB.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan SpendBalanceLots: %w", err)
+		}
+		lot := lotDB.ToModelBalanceLot()
+
+		if lot.Amount <= remaining {
+			spentIDs = append(spentIDs, lot.ID)
+		} else {
+			partial = lot
+			partial.Amount -= remaining
+			lot.Amount = remaining
+		}
+		remaining -= lot.Amount
+		spent = append(spent, lot)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows SpendBalanceLots: %w", err)
+	}
+	if remaining > 0 {
+		return nil, fmt.Errorf("balance lots do not cover %d coins SpendBalanceLots", amount)
+	}
+
+	if len(spentIDs) > 0 {
+		_, err = r.conn(ctx).Exec(ctx, `DELETE FROM shop."balance_lot" WHERE id = ANY($1)`, spentIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete lots SpendBalanceLots: %w", err)
+		}
+	}
+	if partial.ID != 0 {
+		_, err = r.conn(ctx).Exec(ctx, `UPDATE shop."balance_lot" SET amount = $1 WHERE id = $2`, partial.Amount, partial.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update lot SpendBalanceLots: %w", err)
+		}
+	}
+
+	return spent, nil
+}
+
+// GetBalanceLotsByUserID возвращает лоты всех кошельков пользователя, начисленные до grantedBefore
+func (r *repository) GetBalanceLotsByUserID(ctx context.Context, userID int64, grantedBefore time.Time) ([]models.BalanceLot, error) {
+	query := `
+		SELECT
+			bl.id,
+			bl.balance_id,
+			bl.amount,
+			bl.granted_at,
+			bl.created_at,
+			b.currency
+		FROM
+			shop."balance" b
+		INNER JOIN
+			shop."balance_lot" bl
+		ON
+			bl.balance_id = b.id
+		WHERE
+			b.user_id = $1 AND b.org_id = $3 AND bl.granted_at < $2
+		ORDER BY
+			bl.granted_at, bl.id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID, grantedBefore, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetBalanceLotsByUserID: %w", err)
+	}
+	defer rows.Close()
+
+	lots := []models.BalanceLot{}
+	for rows.Next() {
+		lotDB := models.BalanceLotDB{}
+		var currency string
+		err := rows.Scan(&lotDB.ID, &lotDB.BalanceID, &lotDB.Amount, &lotDB.GrantedAt, &lotDB.CreatedAt, &currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan GetBalanceLotsByUserID: %w", err)
+		}
+		lot := lotDB.ToModelBalanceLot()
+		lot.Currency = currency
+		lots = append(lots, lot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetBalanceLotsByUserID: %w", err)
+	}
+
+	return lots, nil
+}
+
+// GetExpiredLotOwners возвращает до limit балансов с лотами, начисленными до grantedBefore:
+// кошельки пользователей во всех валютах и кошельки команд
+func (r *repository) GetExpiredLotOwners(ctx context.Context, grantedBefore time.Time, limit int) ([]models.BalanceOwner, error) {
+	query := `
+		SELECT DISTINCT
+			b.id,
+			COALESCE(u.username, $4 || t.name),
+			b.currency
+		FROM
+			shop."balance_lot" bl
+		INNER JOIN
+			shop."balance" b
+		ON
+			b.id = bl.balance_id
+		LEFT JOIN
+			shop."user" u
+		ON
+			u.id = b.user_id
+		LEFT JOIN
+			shop."team" t
+		ON
+			t.balance_id = b.id
+		WHERE
+			b.org_id = $3
+			AND NOT b.is_system
+			AND bl.granted_at < $1
+			AND (u.id IS NOT NULL OR t.id IS NOT NULL)
+		ORDER BY
+			b.id
+		LIMIT $2
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, grantedBefore, limit, orgID(ctx), models.TeamAccountName(""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetExpiredLotOwners: %w", err)
+	}
+	defer rows.Close()
+
+	owners := []models.BalanceOwner{}
+	for rows.Next() {
+		owner := models.BalanceOwner{}
+		if err := rows.Scan(&owner.BalanceID, &owner.Username, &owner.Currency); err != nil {
+			return nil, fmt.Errorf("failed to scan GetExpiredLotOwners: %w", err)
+		}
+		owners = append(owners, owner)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetExpiredLotOwners: %w", err)
+	}
+
+	return owners, nil
+}
+
+// ExpireBalanceLots удаляет лоты баланса, начисленные до grantedBefore, и возвращает сгоревшую сумму
+func (r *repository) ExpireBalanceLots(ctx context.Context, balanceID int64, grantedBefore time.Time) (int64, error) {
+	var expired int64
+
+	query := `
+		WITH expired AS (
+			DELETE FROM
+				shop."balance_lot" bl
+			USING
+				shop."balance" b
+			WHERE
+				b.id = bl.balance_id AND bl.balance_id = $1 AND b.org_id = $3 AND bl.granted_at < $2
+			RETURNING
+				bl.amount
+		)
+		SELECT
+			COALESCE(SUM(amount), 0)
+		FROM
+			expired
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, balanceID, grantedBefore, orgID(ctx)).Scan(&expired)
+	if err != nil {
+		return 0, fmt.Errorf("ExpireBalanceLots failed: %w", err)
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,66 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Check in
+// GetLastCheckIn возвращает последнюю отметку пользователя или пустую, если он не отмечался
+func (r *repository) GetLastCheckIn(ctx context.Context, username string) (models.CheckIn, error) {
+	checkIn := models.CheckIn{}
+
+	query := `
+		SELECT
+			ci.day,
+			ci.streak,
+			ci.reward
+		FROM
+			shop."check_in" ci
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = ci.user_id
+		WHERE
+			u.org_id = $1 AND u.username = $2
+		ORDER BY
+			ci.day DESC
+		LIMIT 1
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, orgID(ctx), username).Scan(&checkIn.Day, &checkIn.Streak, &checkIn.Reward)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.CheckIn{}, nil
+		}
+		return models.CheckIn{}, fmt.Errorf("GetLastCheckIn failed: %w", err)
+	}
+
+	return checkIn, nil
+}
+
+// CreateCheckIn false - пользователь уже отмечался в эти сутки, в том числе в конкурентной транзакции
+func (r *repository) CreateCheckIn(ctx context.Context, username string, checkIn models.CheckIn) (bool, error) {
+	query := `
+		INSERT INTO
+			shop."check_in" (user_id, day, streak, reward)
+		SELECT
+			u.id, $3, $4, $5
+		FROM
+			shop."user" u
+		WHERE
+			u.org_id = $1 AND u.username = $2
+		ON CONFLICT DO NOTHING
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, orgID(ctx), username, checkIn.Day, checkIn.Streak, checkIn.Reward)
+	if err != nil {
+		return false, fmt.Errorf("CreateCheckIn failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,142 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Currency
+func (r *repository) IsCurrencyExist(ctx context.Context, code string) (bool, error) {
+	var exists bool
+
+	query := `
+		SELECT EXISTS (
+			SELECT
+				1
+			FROM
+				shop."currency" c
+			WHERE
+				c.code = $1
+		)
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, code).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("IsCurrencyExist failed: %w", err)
+	}
+
+	return exists, nil
+}
+
+// Wallet
+// GetOrCreateWallet возвращает кошелёк пользователя в валюте, создавая пустой при первом обращении
+func (r *repository) GetOrCreateWallet(ctx context.Context, username, currency string) (models.Balance, error) {
+	balanceDB := models.BalanceDB{}
+
+	query := `
+		SELECT
+			b.id,
+			b.amount,
+			b.deleted_at,
+			b.created_at
+		FROM
+			shop."balance" b
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = b.user_id
+		WHERE
+			u.org_id = $3 AND u.username = $1 AND b.currency = $2
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, username, currency, orgID(ctx)).Scan(
+		&balanceDB.ID,
+		&balanceDB.Amount,
+		&balanceDB.DeletedAt,
+		&balanceDB.CreatedAt,
+	)
+	if err == nil {
+		return balanceDB.ToModelBalance(), nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return models.Balance{}, fmt.Errorf("GetOrCreateWallet failed: %w", err)
+	}
+
+	query = `
+		INSERT INTO
+			shop."balance" (amount, currency, user_id, org_id)
+		SELECT
+			0, $2, u.id, u.org_id
+		FROM
+			shop."user" u
+		WHERE
+			u.org_id = $3 AND u.username = $1
+		ON CONFLICT (user_id, currency) DO UPDATE SET currency = EXCLUDED.currency
+		RETURNING
+			id,
+			amount,
+			deleted_at,
+			created_at
+	`
+
+	err = r.conn(ctx).QueryRow(ctx, query, username, currency, orgID(ctx)).Scan(
+		&balanceDB.ID,
+		&balanceDB.Amount,
+		&balanceDB.DeletedAt,
+		&balanceDB.CreatedAt,
+	)
+	if err != nil {
+		return models.Balance{}, fmt.Errorf("failed to create wallet GetOrCreateWallet: %w", err)
+	}
+
+	return balanceDB.ToModelBalance(), nil
+}
+
+// GetWalletsByUserID возвращает все кошельки пользователя, начиная с монет
+func (r *repository) GetWalletsByUserID(ctx context.Context, userID int64) ([]models.Wallet, error) {
+	query := `
+		SELECT
+			b.id,
+			b.currency,
+			b.amount,
+			COALESCE((
+				SELECT
+					SUM(h.amount)
+				FROM
+					shop."balance_hold" h
+				WHERE
+					h.balance_id = b.id AND h.status = 'active' AND h.expires_at > NOW()
+			), 0)
+		FROM
+			shop."balance" b
+		WHERE
+			b.user_id = $1 AND b.org_id = $2 AND b.deleted_at IS NULL
+		ORDER BY
+			b.currency <> 'coins', b.currency
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetWalletsByUserID: %w", err)
+	}
+	defer rows.Close()
+
+	wallets := []models.Wallet{}
+	for rows.Next() {
+		wallet := models.Wallet{}
+		if err := rows.Scan(&wallet.BalanceID, &wallet.Currency, &wallet.Amount, &wallet.Held); err != nil {
+			return nil, fmt.Errorf("failed to scan GetWalletsByUserID: %w", err)
+		}
+		wallets = append(wallets, wallet)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetWalletsByUserID: %w", err)
+	}
+
+	return wallets, nil
+}
@@ -0,0 +1,311 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const fraudCaseColumns = `
+			fc.id,
+			fc.rule,
+			fc.subject_key,
+			fc.usernames,
+			fc.details,
+			fc.status,
+			fc.resolved_by,
+			fc.resolved_at,
+			fc.created_at
+`
+
+func scanFraudCase(row pgx.Row) (models.FraudCase, error) {
+	fcdb := models.FraudCaseDB{}
+	err := row.Scan(
+		&fcdb.ID,
+		&fcdb.Rule,
+		&fcdb.SubjectKey,
+		&fcdb.Usernames,
+		&fcdb.Details,
+		&fcdb.Status,
+		&fcdb.ResolvedBy,
+		&fcdb.ResolvedAt,
+		&fcdb.CreatedAt,
+	)
+	if err != nil {
+		return models.FraudCase{}, err
+	}
+
+	return fcdb.ToModelFraudCase(), nil
+}
+
+// Fraud rules
+// FindTransferCycles ищет переводы по кругу длиной 2 и 3 начиная с since.
+// Каждый цикл возвращается один раз, начиная с наименьшего по имени участника.
+func (r *repository) FindTransferCycles(ctx context.Context, since time.Time) ([]models.FraudHit, error) {
+	query := `
+		WITH edges AS (
+			SELECT DISTINCT
+				bh.sender,
+				bh.recipient
+			FROM
+				shop."balance_history" bh
+			WHERE
+				bh.org_id = $2 AND bh.type = 'transfer' AND bh.created_at >= $1
+		)
+		SELECT
+			ARRAY[e1.sender, e1.recipient],
+			0::BIGINT
+		FROM
+			edges e1
+		INNER JOIN
+			edges e2
+		ON
+			e2.sender = e1.recipient AND e2.recipient = e1.sender
+		WHERE
+			e1.sender < e1.recipient
+		UNION
+		SELECT
+			ARRAY[e1.sender, e1.recipient, e2.recipient],
+			0::BIGINT
+		FROM
+			edges e1
+		INNER JOIN
+			edges e2
+		ON
+			e2.sender = e1.recipient
+		INNER JOIN
+			edges e3
+		ON
+			e3.sender = e2.recipient AND e3.recipient = e1.sender
+		WHERE
+			e1.sender < e1.recipient AND e1.sender < e2.recipient
+	`
+
+	return r.queryFraudHits(ctx, "FindTransferCycles", models.FraudRuleCycle, query, since, orgID(ctx))
+}
+
+// FindFunnels ищет получателей, которым перевели монеты не меньше minSenders аккаунтов
+// в течение newAccountAge после своей регистрации. Получатель идёт первым в списке.
+func (r *repository) FindFunnels(ctx context.Context, since time.Time, newAccountAge time.Duration, minSenders int) ([]models.FraudHit, error) {
+	query := `
+		SELECT
+			bh.recipient || ARRAY_AGG(DISTINCT bh.sender ORDER BY bh.sender),
+			SUM(bh.transaction_amount)
+		FROM
+			shop."balance_history" bh
+		INNER JOIN
+			shop."user" u
+		ON
+			u.balance_id = bh.balance_id AND u.username = bh.sender
+		WHERE
+			bh.org_id = $4
+			AND bh.type = 'transfer'
+			AND bh.created_at >= $1
+			AND bh.created_at < u.created_at + $2 * INTERVAL '1 second'
+		GROUP BY
+			bh.recipient
+		HAVING
+			COUNT(DISTINCT bh.sender) >= $3
+	`
+
+	return r.queryFraudHits(ctx, "FindFunnels", models.FraudRuleFunnel, query, since, int64(newAccountAge.Seconds()), minSenders, orgID(ctx))
+}
+
+// FindBursts ищет аккаунты, отправившие не меньше minAmount монет в течение window после регистрации
+func (r *repository) FindBursts(ctx context.Context, since time.Time, window time.Duration, minAmount int64) ([]models.FraudHit, error) {
+	query := `
+		SELECT
+			ARRAY[u.username],
+			SUM(bh.transaction_amount)
+		FROM
+			shop."user" u
+		INNER JOIN
+			shop."balance_history" bh
+		ON
+			bh.balance_id = u.balance_id AND bh.sender = u.username
+		WHERE
+			u.org_id = $4
+			AND bh.type = 'transfer'
+			AND bh.created_at >= $1
+			AND bh.created_at < u.created_at + $2 * INTERVAL '1 second'
+		GROUP BY
+			u.username
+		HAVING
+			SUM(bh.transaction_amount) >= $3
+	`
+
+	return r.queryFraudHits(ctx, "FindBursts", models.FraudRuleBurst, query, since, int64(window.Seconds()), minAmount, orgID(ctx))
+}
+
+// queryFraudHits выполняет запрос правила, возвращающий участников и сумму переводов
+func (r *repository) queryFraudHits(ctx context.Context, name, rule, query string, args ...any) ([]models.FraudHit, error) {
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	hits := []models.FraudHit{}
+	for rows.Next() {
+		hit := models.FraudHit{Rule: rule}
+		if err := rows.Scan(&hit.Usernames, &hit.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", name, err)
+		}
+		hits = append(hits, hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows %s: %w", name, err)
+	}
+
+	return hits, nil
+}
+
+// Fraud case
+// CreateFraudCase создаёт кейс, false - кейс по этому правилу и набору аккаунтов уже есть
+func (r *repository) CreateFraudCase(ctx context.Context, fc models.FraudCase) (bool, error) {
+	query := `
+		INSERT INTO
+			shop."fraud_case" (org_id, rule, subject_key, usernames, details)
+		VALUES
+			($5, $1, $2, $3, $4)
+		ON CONFLICT (org_id, rule, subject_key) DO NOTHING
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, fc.Rule, fc.SubjectKey, fc.Usernames, fc.Details, orgID(ctx))
+	if err != nil {
+		return false, fmt.Errorf("CreateFraudCase failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// GetFraudCases возвращает кейсы с указанным статусом, пустой статус - все кейсы
+func (r *repository) GetFraudCases(ctx context.Context, status string) ([]models.FraudCase, error) {
+	query := `
+		SELECT` + fraudCaseColumns + `
+		FROM
+			shop."fraud_case" fc
+		WHERE
+			fc.org_id = $2 AND ($1 = '' OR fc.status = $1)
+		ORDER BY
+			fc.id DESC
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, status, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetFraudCases: %w", err)
+	}
+	defer rows.Close()
+
+	cases := []models.FraudCase{}
+	for rows.Next() {
+		fc, err := scanFraudCase(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan GetFraudCases: %w", err)
+		}
+		cases = append(cases, fc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetFraudCases: %w", err)
+	}
+
+	return cases, nil
+}
+
+// GetFraudCaseByID блокирует кейс до конца транзакции, чтобы его не разобрали дважды
+func (r *repository) GetFraudCaseByID(ctx context.Context, caseID int64) (models.FraudCase, error) {
+	query := `
+		SELECT` + fraudCaseColumns + `
+		FROM
+			shop."fraud_case" fc
+		WHERE
+			fc.id = $1 AND fc.org_id = $2
+		FOR UPDATE
+	`
+
+	fc, err := scanFraudCase(r.conn(ctx).QueryRow(ctx, query, caseID, orgID(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.FraudCase{}, nil
+		}
+		return models.FraudCase{}, fmt.Errorf("GetFraudCaseByID failed: %w", err)
+	}
+
+	return fc, nil
+}
+
+func (r *repository) ResolveFraudCase(ctx context.Context, caseID int64, status, admin string) error {
+	query := `
+		UPDATE
+			shop."fraud_case"
+		SET
+			status = $1,
+			resolved_by = $2,
+			resolved_at = NOW()
+		WHERE
+			id = $3 AND org_id = $4
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, status, admin, caseID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("ResolveFraudCase failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows updated ResolveFraudCase")
+	}
+
+	return nil
+}
+
+// Fraud hold
+func (r *repository) SetFraudHold(ctx context.Context, usernames []string) error {
+	query := `
+		UPDATE
+			shop."user"
+		SET
+			fraud_hold = TRUE
+		WHERE
+			org_id = $2 AND username = ANY($1)
+	`
+
+	_, err := r.conn(ctx).Exec(ctx, query, usernames, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("SetFraudHold failed: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseFraudHold снимает блокировку с аккаунтов, которые не участвуют в других открытых или подтверждённых кейсах
+func (r *repository) ReleaseFraudHold(ctx context.Context, usernames []string) error {
+	query := `
+		UPDATE
+			shop."user" u
+		SET
+			fraud_hold = FALSE
+		WHERE
+			u.org_id = $2
+			AND u.username = ANY($1)
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					shop."fraud_case" fc
+				WHERE
+					fc.org_id = u.org_id AND fc.status IN ('open', 'confirmed') AND u.username = ANY(fc.usernames)
+			)
+	`
+
+	_, err := r.conn(ctx).Exec(ctx, query, usernames, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("ReleaseFraudHold failed: %w", err)
+	}
+
+	return nil
+}
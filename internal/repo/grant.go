@@ -0,0 +1,141 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// System account
+// GetSystemBalanceID возвращает баланс системного счёта, создавая его при первом обращении
+func (r *repository) GetSystemBalanceID(ctx context.Context, name, currency string) (int64, error) {
+	balanceID, err := r.selectSystemBalanceID(ctx, name, currency)
+	if err == nil {
+		return balanceID, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("GetSystemBalanceID failed: %w", err)
+	}
+
+	created, balanceID, err := r.createSystemAccount(ctx, name, currency)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create system account GetSystemBalanceID: %w", err)
+	}
+	if created {
+		return balanceID, nil
+	}
+
+	// счёт создан конкурентным запросом, его баланс уже закоммичен
+	balanceID, err = r.selectSystemBalanceID(ctx, name, currency)
+	if err != nil {
+		return 0, fmt.Errorf("GetSystemBalanceID failed: %w", err)
+	}
+
+	return balanceID, nil
+}
+
+func (r *repository) selectSystemBalanceID(ctx context.Context, name, currency string) (int64, error) {
+	var balanceID int64
+
+	query := `
+		SELECT
+			sa.balance_id
+		FROM
+			shop."system_account" sa
+		WHERE
+			sa.org_id = $3 AND sa.name = $1 AND sa.currency = $2
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, name, currency, orgID(ctx)).Scan(&balanceID)
+
+	return balanceID, err
+}
+
+// createSystemAccount создаёт системный счёт с балансом в отдельной (вложенной) транзакции.
+// Если счёт уже создан конкурентным запросом, транзакция откатывается вместе с новым балансом,
+// поэтому осиротевших балансов не остаётся.
+func (r *repository) createSystemAccount(ctx context.Context, name, currency string) (bool, int64, error) {
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var balanceID int64
+	query := `
+		INSERT INTO
+			shop."balance" (amount, is_system, currency, org_id)
+		VALUES
+			(0, TRUE, $1, $2)
+		RETURNING
+			id
+	`
+	if err := tx.QueryRow(ctx, query, currency, orgID(ctx)).Scan(&balanceID); err != nil {
+		return false, 0, err
+	}
+
+	query = `
+		INSERT INTO
+			shop."system_account" (org_id, name, currency, balance_id)
+		VALUES
+			($1, $2, $3, $4)
+		ON CONFLICT (org_id, name, currency) DO NOTHING
+	`
+	cmdTag, err := tx.Exec(ctx, query, orgID(ctx), name, currency, balanceID)
+	if err != nil {
+		return false, 0, err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return false, 0, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, 0, err
+	}
+
+	return true, balanceID, nil
+}
+
+// DebitSystemBalance списывает с системного баланса без проверки остатка
+func (r *repository) DebitSystemBalance(ctx context.Context, balanceID, amount int64) error {
+	query := `
+		UPDATE
+			shop."balance"
+		SET
+			amount = amount - $1
+		WHERE
+			id = $2 AND org_id = $3 AND is_system
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, amount, balanceID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("DebitSystemBalance failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no system balance rows updated DebitSystemBalance")
+	}
+
+	return nil
+}
+
+// Allowance
+// CreateAllowanceRun фиксирует выплату за период, false - выплата за период уже была
+func (r *repository) CreateAllowanceRun(ctx context.Context, periodStart time.Time, amount, usersCount int64) (bool, error) {
+	query := `
+		INSERT INTO
+			shop."allowance_run" (org_id, period_start, amount, users_count)
+		VALUES
+			($4, $1, $2, $3)
+		ON CONFLICT (org_id, period_start) DO NOTHING
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, periodStart, amount, usersCount, orgID(ctx))
+	if err != nil {
+		return false, fmt.Errorf("CreateAllowanceRun failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,245 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const holdColumns = `
+			h.id,
+			h.balance_id,
+			h.owner_id,
+			u.username,
+			h.recipient,
+			h.amount,
+			h.reference,
+			h.status,
+			h.expires_at,
+			h.resolved_at,
+			h.created_at
+`
+
+func scanHold(row pgx.Row) (models.Hold, error) {
+	hdb := models.HoldDB{}
+	err := row.Scan(
+		&hdb.ID,
+		&hdb.BalanceID,
+		&hdb.OwnerID,
+		&hdb.Owner,
+		&hdb.Recipient,
+		&hdb.Amount,
+		&hdb.Reference,
+		&hdb.Status,
+		&hdb.ExpiresAt,
+		&hdb.ResolvedAt,
+		&hdb.CreatedAt,
+	)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	return hdb.ToModelHold(), nil
+}
+
+// Hold
+func (r *repository) CreateHold(ctx context.Context, hold models.Hold) (int64, error) {
+	var reference *string
+	if hold.Reference != "" {
+		reference = &hold.Reference
+	}
+
+	var id int64
+	query := `
+		INSERT INTO
+			shop."balance_hold" (balance_id, owner_id, recipient, amount, reference, expires_at)
+		SELECT
+			b.id, u.id, $3, $4, $5, $6
+		FROM
+			shop."balance" b
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = $2 AND u.org_id = b.org_id
+		WHERE
+			b.id = $1 AND b.org_id = $7
+		RETURNING
+			id
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query,
+		hold.BalanceID,
+		hold.OwnerID,
+		hold.Recipient,
+		hold.Amount,
+		reference,
+		hold.ExpiresAt,
+		orgID(ctx),
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("CreateHold failed: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetHeldAmountByUserID возвращает сумму активных резервов пользователя
+func (r *repository) GetHeldAmountByUserID(ctx context.Context, userID int64) (int64, error) {
+	var held int64
+
+	query := `
+		SELECT
+			COALESCE(SUM(h.amount), 0)
+		FROM
+			shop."user" u
+		INNER JOIN
+			shop."balance_hold" h
+		ON
+			h.balance_id = u.balance_id
+		WHERE
+			u.id = $1 AND u.org_id = $2 AND h.status = 'active' AND h.expires_at > NOW()
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, userID, orgID(ctx)).Scan(&held)
+	if err != nil {
+		return 0, fmt.Errorf("GetHeldAmountByUserID failed: %w", err)
+	}
+
+	return held, nil
+}
+
+// GetHoldsByUser возвращает резервы, где пользователь владелец или получатель
+func (r *repository) GetHoldsByUser(ctx context.Context, userID int64, username string) ([]models.Hold, error) {
+	query := `
+		SELECT` + holdColumns + `
+		FROM
+			shop."balance_hold" h
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = h.owner_id
+		WHERE
+			u.org_id = $3 AND (h.owner_id = $1 OR h.recipient = $2)
+		ORDER BY
+			h.id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID, username, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetHoldsByUser: %w", err)
+	}
+	defer rows.Close()
+
+	holds := []models.Hold{}
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan GetHoldsByUser: %w", err)
+		}
+		holds = append(holds, hold)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetHoldsByUser: %w", err)
+	}
+
+	return holds, nil
+}
+
+// GetHoldByID блокирует резерв, возвращает пустой резерв, если его нет
+func (r *repository) GetHoldByID(ctx context.Context, holdID int64) (models.Hold, error) {
+	query := `
+		SELECT` + holdColumns + `
+		FROM
+			shop."balance_hold" h
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = h.owner_id
+		WHERE
+			h.id = $1 AND u.org_id = $2
+		FOR UPDATE OF h
+	`
+
+	hold, err := scanHold(r.conn(ctx).QueryRow(ctx, query, holdID, orgID(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Hold{}, nil
+		}
+		return models.Hold{}, fmt.Errorf("GetHoldByID failed: %w", err)
+	}
+
+	return hold, nil
+}
+
+func (r *repository) ResolveHold(ctx context.Context, holdID int64, status string) error {
+	query := `
+		UPDATE
+			shop."balance_hold" h
+		SET
+			status = $1,
+			resolved_at = NOW()
+		FROM
+			shop."user" u
+		WHERE
+			u.id = h.owner_id AND u.org_id = $3 AND h.id = $2 AND h.status = 'active'
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, status, holdID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("ResolveHold failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows updated ResolveHold")
+	}
+
+	return nil
+}
+
+// ReleaseExpiredHolds освобождает резервы с истёкшим сроком и возвращает их количество
+func (r *repository) ReleaseExpiredHolds(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		UPDATE
+			shop."balance_hold" h
+		SET
+			status = 'released',
+			resolved_at = NOW()
+		FROM
+			shop."user" u
+		WHERE
+			u.id = h.owner_id AND u.org_id = $2 AND h.status = 'active' AND h.expires_at <= $1
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, now, orgID(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("ReleaseExpiredHolds failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+// ReleaseHoldsByOwner освобождает все активные резервы владельца
+func (r *repository) ReleaseHoldsByOwner(ctx context.Context, ownerID int64) error {
+	query := `
+		UPDATE
+			shop."balance_hold" h
+		SET
+			status = 'released',
+			resolved_at = NOW()
+		FROM
+			shop."user" u
+		WHERE
+			u.id = h.owner_id AND u.org_id = $2 AND h.owner_id = $1 AND h.status = 'active'
+	`
+
+	_, err := r.conn(ctx).Exec(ctx, query, ownerID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("ReleaseHoldsByOwner failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,284 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Kudos
+func (r *repository) CreateKudos(ctx context.Context, kudos models.Kudos) (int64, error) {
+	var kudosID int64
+
+	query := `
+		INSERT INTO
+			shop."kudos" (org_id, sender_id, recipient_id, message, currency, amount)
+		SELECT
+			s.org_id, s.id, r.id, $3, $4, $5
+		FROM
+			shop."user" s
+		INNER JOIN
+			shop."user" r
+		ON
+			r.org_id = s.org_id AND r.username = $2
+		WHERE
+			s.org_id = $6 AND s.username = $1
+		RETURNING
+			id
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query,
+		kudos.Sender,
+		kudos.Recipient,
+		kudos.Message,
+		kudos.Currency,
+		kudos.Amount,
+		orgID(ctx),
+	).Scan(&kudosID)
+	if err != nil {
+		return 0, fmt.Errorf("CreateKudos failed: %w", err)
+	}
+
+	return kudosID, nil
+}
+
+// GetKudosByID возвращает благодарность, в том числе скрытую, или пустую, если она не найдена
+func (r *repository) GetKudosByID(ctx context.Context, kudosID int64) (models.Kudos, error) {
+	query := `
+		SELECT
+			k.id,
+			s.username,
+			r.username,
+			k.message,
+			k.currency,
+			k.amount,
+			k.boosted,
+			k.boosts,
+			k.hidden_at IS NOT NULL,
+			k.created_at
+		FROM
+			shop."kudos" k
+		INNER JOIN
+			shop."user" s
+		ON
+			s.id = k.sender_id
+		INNER JOIN
+			shop."user" r
+		ON
+			r.id = k.recipient_id
+		WHERE
+			k.id = $1 AND k.org_id = $2
+	`
+
+	kudos, err := scanKudos(r.conn(ctx).QueryRow(ctx, query, kudosID, orgID(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Kudos{}, nil
+		}
+		return models.Kudos{}, fmt.Errorf("GetKudosByID failed: %w", err)
+	}
+
+	return kudos, nil
+}
+
+// GetKudosFeed возвращает страницу ленты по убыванию id. Скрытые благодарности в ленту не попадают.
+func (r *repository) GetKudosFeed(ctx context.Context, filter models.KudosFeedFilter) ([]models.Kudos, error) {
+	query := `
+		SELECT
+			k.id,
+			s.username,
+			r.username,
+			k.message,
+			k.currency,
+			k.amount,
+			k.boosted,
+			k.boosts,
+			FALSE,
+			k.created_at
+		FROM
+			shop."kudos" k
+		INNER JOIN
+			shop."user" s
+		ON
+			s.id = k.sender_id
+		INNER JOIN
+			shop."user" r
+		ON
+			r.id = k.recipient_id
+		WHERE
+			k.org_id = $1 AND k.hidden_at IS NULL AND ($2::BIGINT = 0 OR k.id < $2)
+		ORDER BY
+			k.id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, orgID(ctx), filter.Before, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetKudosFeed: %w", err)
+	}
+	defer rows.Close()
+
+	feed := []models.Kudos{}
+	for rows.Next() {
+		kudos, err := scanKudos(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan GetKudosFeed: %w", err)
+		}
+		feed = append(feed, kudos)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetKudosFeed: %w", err)
+	}
+
+	return feed, nil
+}
+
+// scanKudos читает строку с колонками в порядке GetKudosByID
+func scanKudos(row pgx.Row) (models.Kudos, error) {
+	kudos := models.Kudos{}
+	err := row.Scan(
+		&kudos.ID,
+		&kudos.Sender,
+		&kudos.Recipient,
+		&kudos.Message,
+		&kudos.Currency,
+		&kudos.Amount,
+		&kudos.Boosted,
+		&kudos.Boosts,
+		&kudos.Hidden,
+		&kudos.CreatedAt,
+	)
+
+	return kudos, err
+}
+
+// UpdateKudosBoost добавляет к благодарности монеты сверху, отмена передаёт отрицательные значения
+func (r *repository) UpdateKudosBoost(ctx context.Context, kudosID, amount, boosts int64) error {
+	query := `
+		UPDATE
+			shop."kudos"
+		SET
+			boosted = boosted + $1,
+			boosts = boosts + $2
+		WHERE
+			id = $3 AND org_id = $4
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, amount, boosts, kudosID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("UpdateKudosBoost failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows updated UpdateKudosBoost")
+	}
+
+	return nil
+}
+
+func (r *repository) HideKudos(ctx context.Context, kudosID int64) error {
+	query := `
+		UPDATE
+			shop."kudos"
+		SET
+			hidden_at = NOW()
+		WHERE
+			id = $1 AND org_id = $2 AND hidden_at IS NULL
+	`
+
+	_, err := r.conn(ctx).Exec(ctx, query, kudosID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("HideKudos failed: %w", err)
+	}
+
+	return nil
+}
+
+// Kudos reaction
+// GetKudosReactions возвращает количество реакций для страницы ленты одним запросом
+func (r *repository) GetKudosReactions(ctx context.Context, kudosIDs []int64, userID int64) ([]models.KudosReaction, error) {
+	query := `
+		SELECT
+			kr.kudos_id,
+			kr.emoji,
+			COUNT(*),
+			BOOL_OR(kr.user_id = $2)
+		FROM
+			shop."kudos_reaction" kr
+		INNER JOIN
+			shop."kudos" k
+		ON
+			k.id = kr.kudos_id
+		WHERE
+			kr.kudos_id = ANY($1) AND k.org_id = $3
+		GROUP BY
+			kr.kudos_id, kr.emoji
+		ORDER BY
+			kr.kudos_id, MIN(kr.created_at), kr.emoji
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, kudosIDs, userID, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetKudosReactions: %w", err)
+	}
+	defer rows.Close()
+
+	reactions := []models.KudosReaction{}
+	for rows.Next() {
+		reaction := models.KudosReaction{}
+		if err := rows.Scan(&reaction.KudosID, &reaction.Emoji, &reaction.Count, &reaction.Reacted); err != nil {
+			return nil, fmt.Errorf("failed to scan GetKudosReactions: %w", err)
+		}
+		reactions = append(reactions, reaction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetKudosReactions: %w", err)
+	}
+
+	return reactions, nil
+}
+
+func (r *repository) AddKudosReaction(ctx context.Context, kudosID, userID int64, emoji string) error {
+	query := `
+		INSERT INTO
+			shop."kudos_reaction" (kudos_id, user_id, emoji)
+		SELECT
+			k.id, u.id, $3
+		FROM
+			shop."kudos" k
+		INNER JOIN
+			shop."user" u
+		ON
+			u.org_id = k.org_id AND u.id = $2
+		WHERE
+			k.id = $1 AND k.org_id = $4
+		ON CONFLICT DO NOTHING
+	`
+
+	_, err := r.conn(ctx).Exec(ctx, query, kudosID, userID, emoji, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("AddKudosReaction failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteKudosReaction(ctx context.Context, kudosID, userID int64, emoji string) error {
+	query := `
+		DELETE FROM
+			shop."kudos_reaction" kr
+		USING
+			shop."kudos" k
+		WHERE
+			k.id = kr.kudos_id AND kr.kudos_id = $1 AND kr.user_id = $2 AND kr.emoji = $3 AND k.org_id = $4
+	`
+
+	_, err := r.conn(ctx).Exec(ctx, query, kudosID, userID, emoji, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("DeleteKudosReaction failed: %w", err)
+	}
+
+	return nil
+}
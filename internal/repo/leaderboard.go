@@ -0,0 +1,174 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Столбцы агрегатов рейтинга. Метрика проверяется сервисом, в запрос попадает только столбец из этого списка.
+var leaderboardMetricColumns = map[string]string{
+	models.LeaderboardMetricReceived: "ls.received",
+	models.LeaderboardMetricGiven:    "ls.given",
+	models.LeaderboardMetricThanked:  "ls.thanked",
+}
+
+// Leaderboard
+// RecordLeaderboardTransfer добавляет перевод в агрегаты рейтинга каждого периода.
+// Первый перевод отправителя получателю за период увеличивает число поблагодарённых.
+// Отмена перевода передаёт отрицательную сумму и число поблагодарённых не меняет.
+// Если отправитель или получатель не пользователь организации, агрегаты не меняются.
+func (r *repository) RecordLeaderboardTransfer(ctx context.Context, sender, recipient string, amount int64, periods []models.LeaderboardPeriod) error {
+	names := make([]string, 0, len(periods))
+	starts := make([]time.Time, 0, len(periods))
+	for _, p := range periods {
+		names = append(names, p.Period)
+		starts = append(starts, p.Start)
+	}
+
+	query := `
+		WITH p AS (
+			SELECT
+				period, period_start
+			FROM
+				UNNEST($4::VARCHAR[], $5::TIMESTAMPTZ[]) AS p (period, period_start)
+		), u AS (
+			SELECT
+				s.id AS sender_id,
+				r.id AS recipient_id
+			FROM
+				shop."user" s
+			INNER JOIN
+				shop."user" r
+			ON
+				r.org_id = s.org_id AND r.username = $2
+			WHERE
+				s.org_id = $6 AND s.username = $1
+		), pairs AS (
+			INSERT INTO
+				shop."leaderboard_pair" (sender_id, recipient_id, period, period_start)
+			SELECT
+				u.sender_id, u.recipient_id, p.period, p.period_start
+			FROM
+				u
+			CROSS JOIN
+				p
+			WHERE
+				$3::BIGINT > 0
+			ON CONFLICT DO NOTHING
+			RETURNING
+				period, period_start
+		), given AS (
+			INSERT INTO
+				shop."leaderboard_stat" (user_id, period, period_start, given, thanked)
+			SELECT
+				u.sender_id, p.period, p.period_start, $3::BIGINT, CASE WHEN pairs.period IS NULL THEN 0 ELSE 1 END
+			FROM
+				u
+			CROSS JOIN
+				p
+			LEFT JOIN
+				pairs
+			ON
+				pairs.period = p.period AND pairs.period_start = p.period_start
+			ON CONFLICT (user_id, period, period_start)
+			DO UPDATE SET given = shop."leaderboard_stat".given + EXCLUDED.given, thanked = shop."leaderboard_stat".thanked + EXCLUDED.thanked
+		)
+		INSERT INTO
+			shop."leaderboard_stat" (user_id, period, period_start, received)
+		SELECT
+			u.recipient_id, p.period, p.period_start, $3::BIGINT
+		FROM
+			u
+		CROSS JOIN
+			p
+		ON CONFLICT (user_id, period, period_start)
+		DO UPDATE SET received = shop."leaderboard_stat".received + EXCLUDED.received
+	`
+
+	_, err := r.conn(ctx).Exec(ctx, query, sender, recipient, amount, names, starts, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("RecordLeaderboardTransfer failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetLeaderboard ранжирует пользователей по метрике за период. Пользователи, скрывшие себя,
+// удалённые и с нулевым значением метрики в рейтинг не попадают. Равные значения делят место.
+func (r *repository) GetLeaderboard(ctx context.Context, filter models.LeaderboardFilter) ([]models.LeaderboardEntry, error) {
+	column, ok := leaderboardMetricColumns[filter.Metric]
+	if !ok {
+		return nil, fmt.Errorf("GetLeaderboard failed: unknown metric %q", filter.Metric)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			RANK() OVER (ORDER BY %[1]s DESC),
+			u.username,
+			%[1]s
+		FROM
+			shop."leaderboard_stat" ls
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = ls.user_id
+		WHERE
+			u.org_id = $1 AND ls.period = $2 AND ls.period_start = $3 AND %[1]s > 0
+			AND NOT u.leaderboard_opt_out AND u.deleted_at IS NULL
+			AND ($4::BIGINT = 0 OR EXISTS (
+				SELECT
+					1
+				FROM
+					shop."team_member" tm
+				WHERE
+					tm.team_id = $4 AND tm.user_id = u.id
+			))
+		ORDER BY
+			%[1]s DESC, u.username
+		LIMIT $5
+	`, column)
+
+	rows, err := r.conn(ctx).Query(ctx, query, orgID(ctx), filter.Period, filter.PeriodStart, filter.TeamID, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetLeaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.LeaderboardEntry{}
+	for rows.Next() {
+		entry := models.LeaderboardEntry{}
+		if err := rows.Scan(&entry.Rank, &entry.Username, &entry.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan GetLeaderboard: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetLeaderboard: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *repository) SetLeaderboardOptOut(ctx context.Context, userID int64, optOut bool) error {
+	query := `
+		UPDATE
+			shop."user"
+		SET
+			leaderboard_opt_out = $1
+		WHERE
+			id = $2 AND org_id = $3
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, optOut, userID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("SetLeaderboardOptOut failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows updated SetLeaderboardOptOut")
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Notification
+// CreateNotification добавляет уведомление во входящие пользователя. Для имени, не принадлежащего
+// пользователю организации (кошелёк команды, казначейство), ничего не создаётся.
+func (r *repository) CreateNotification(ctx context.Context, username string, notification models.Notification) error {
+	query := `
+		INSERT INTO
+			shop."notification" (user_id, type, from_user, item, status, amount, currency, message)
+		SELECT
+			u.id, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), $7, $8, NULLIF($9, '')
+		FROM
+			shop."user" u
+		WHERE
+			u.org_id = $1 AND u.username = $2
+	`
+
+	_, err := r.conn(ctx).Exec(ctx, query,
+		orgID(ctx),
+		username,
+		notification.Type,
+		notification.FromUser,
+		notification.Item,
+		notification.Status,
+		notification.Amount,
+		notification.Currency,
+		notification.Message,
+	)
+	if err != nil {
+		return fmt.Errorf("CreateNotification failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetNotifications возвращает страницу входящих пользователя по убыванию id
+func (r *repository) GetNotifications(ctx context.Context, filter models.NotificationFilter) ([]models.Notification, error) {
+	query := `
+		SELECT
+			n.id,
+			n.type,
+			COALESCE(n.from_user, ''),
+			COALESCE(n.item, ''),
+			COALESCE(n.status, ''),
+			n.amount,
+			n.currency,
+			COALESCE(n.message, ''),
+			n.created_at,
+			n.read_at
+		FROM
+			shop."notification" n
+		WHERE
+			n.user_id = $1 AND (NOT $2 OR n.read_at IS NULL) AND ($3::BIGINT = 0 OR n.id < $3)
+		ORDER BY
+			n.id DESC
+		LIMIT $4
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, filter.UserID, filter.UnreadOnly, filter.Before, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetNotifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		notification := models.Notification{}
+		err := rows.Scan(
+			&notification.ID,
+			&notification.Type,
+			&notification.FromUser,
+			&notification.Item,
+			&notification.Status,
+			&notification.Amount,
+			&notification.Currency,
+			&notification.Message,
+			&notification.CreatedAt,
+			&notification.ReadAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan GetNotifications: %w", err)
+		}
+		notifications = append(notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetNotifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *repository) CountUnreadNotifications(ctx context.Context, userID int64) (int64, error) {
+	var unread int64
+
+	query := `
+		SELECT
+			COUNT(*)
+		FROM
+			shop."notification"
+		WHERE
+			user_id = $1 AND read_at IS NULL
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, userID).Scan(&unread)
+	if err != nil {
+		return 0, fmt.Errorf("CountUnreadNotifications failed: %w", err)
+	}
+
+	return unread, nil
+}
+
+// MarkNotificationsRead отмечает прочитанными уведомления из notificationIDs или все, если all.
+// Возвращает количество отмеченных, чужие и уже прочитанные уведомления не учитываются.
+func (r *repository) MarkNotificationsRead(ctx context.Context, userID int64, notificationIDs []int64, all bool) (int64, error) {
+	query := `
+		UPDATE
+			shop."notification"
+		SET
+			read_at = NOW()
+		WHERE
+			user_id = $1 AND read_at IS NULL AND ($2 OR id = ANY($3))
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, userID, all, notificationIDs)
+	if err != nil {
+		return 0, fmt.Errorf("MarkNotificationsRead failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+// GetNotificationPreferences возвращает настройки уведомлений или настройки по умолчанию, если они не менялись
+func (r *repository) GetNotificationPreferences(ctx context.Context, username string) (models.NotificationPreferences, error) {
+	preferences := models.NotificationPreferences{}
+
+	query := `
+		SELECT
+			np.coins_received,
+			np.purchase,
+			np.low_balance,
+			np.admin_grant,
+			np.low_balance_threshold
+		FROM
+			shop."notification_preference" np
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = np.user_id
+		WHERE
+			u.org_id = $1 AND u.username = $2
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, orgID(ctx), username).Scan(
+		&preferences.CoinsReceived,
+		&preferences.Purchase,
+		&preferences.LowBalance,
+		&preferences.Grant,
+		&preferences.LowBalanceThreshold,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.DefaultNotificationPreferences(), nil
+		}
+		return models.NotificationPreferences{}, fmt.Errorf("GetNotificationPreferences failed: %w", err)
+	}
+
+	return preferences, nil
+}
+
+func (r *repository) SetNotificationPreferences(ctx context.Context, userID int64, preferences models.NotificationPreferences) error {
+	query := `
+		INSERT INTO
+			shop."notification_preference" (user_id, coins_received, purchase, low_balance, admin_grant, low_balance_threshold)
+		VALUES
+			($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET
+			coins_received = EXCLUDED.coins_received,
+			purchase = EXCLUDED.purchase,
+			low_balance = EXCLUDED.low_balance,
+			admin_grant = EXCLUDED.admin_grant,
+			low_balance_threshold = EXCLUDED.low_balance_threshold,
+			updated_at = NOW()
+	`
+
+	_, err := r.conn(ctx).Exec(ctx, query,
+		userID,
+		preferences.CoinsReceived,
+		preferences.Purchase,
+		preferences.LowBalance,
+		preferences.Grant,
+		preferences.LowBalanceThreshold,
+	)
+	if err != nil {
+		return fmt.Errorf("SetNotificationPreferences failed: %w", err)
+	}
+
+	return nil
+}
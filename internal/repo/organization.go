@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+)
+
+// Organization
+// GetOrganizationIDs возвращает все организации: фоновые задачи обходят их по очереди
+func (r *repository) GetOrganizationIDs(ctx context.Context) ([]int64, error) {
+	query := `
+		SELECT
+			o.id
+		FROM
+			shop."organization" o
+		ORDER BY
+			o.id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetOrganizationIDs: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan GetOrganizationIDs: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetOrganizationIDs: %w", err)
+	}
+
+	return ids, nil
+}
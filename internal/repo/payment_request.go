@@ -0,0 +1,213 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const paymentRequestColumns = `
+			pr.id,
+			pr.requester_id,
+			u.username,
+			pr.payer,
+			pr.amount,
+			pr.memo,
+			pr.status,
+			pr.expires_at,
+			pr.resolved_at,
+			pr.created_at
+`
+
+func scanPaymentRequest(row pgx.Row) (models.PaymentRequest, error) {
+	prdb := models.PaymentRequestDB{}
+	err := row.Scan(
+		&prdb.ID,
+		&prdb.RequesterID,
+		&prdb.Requester,
+		&prdb.Payer,
+		&prdb.Amount,
+		&prdb.Memo,
+		&prdb.Status,
+		&prdb.ExpiresAt,
+		&prdb.ResolvedAt,
+		&prdb.CreatedAt,
+	)
+	if err != nil {
+		return models.PaymentRequest{}, err
+	}
+
+	return prdb.ToModelPaymentRequest(), nil
+}
+
+// Payment request
+// CreatePaymentRequest создаёт запрос монет. Если такой же запрос уже ожидает оплаты, возвращает ErrDuplicatePaymentRequest.
+func (r *repository) CreatePaymentRequest(ctx context.Context, pr models.PaymentRequest) (int64, error) {
+	// просроченные запросы не должны мешать созданию нового
+	query := `
+		UPDATE
+			shop."payment_request" pr
+		SET
+			status = 'expired'
+		FROM
+			shop."user" u
+		WHERE
+			u.id = pr.requester_id
+			AND u.org_id = $3
+			AND pr.requester_id = $1
+			AND pr.payer = $2
+			AND pr.status = 'pending'
+			AND pr.expires_at <= NOW()
+	`
+
+	_, err := r.conn(ctx).Exec(ctx, query, pr.RequesterID, pr.Payer, orgID(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire requests CreatePaymentRequest: %w", err)
+	}
+
+	var memo *string
+	if pr.Memo != "" {
+		memo = &pr.Memo
+	}
+
+	var id int64
+	query = `
+		INSERT INTO
+			shop."payment_request" (requester_id, payer, amount, memo, expires_at)
+		SELECT
+			u.id, $2, $3, $4, $5
+		FROM
+			shop."user" u
+		WHERE
+			u.id = $1 AND u.org_id = $6
+		ON CONFLICT (requester_id, payer, amount) WHERE status = 'pending' DO NOTHING
+		RETURNING
+			id
+	`
+
+	err = r.conn(ctx).QueryRow(ctx, query, pr.RequesterID, pr.Payer, pr.Amount, memo, pr.ExpiresAt, orgID(ctx)).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, errors.New(internalErrors.ErrDuplicatePaymentRequest)
+		}
+		return 0, fmt.Errorf("CreatePaymentRequest failed: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetIncomingPaymentRequests возвращает ожидающие оплаты запросы к пользователю
+func (r *repository) GetIncomingPaymentRequests(ctx context.Context, payer string, now time.Time) ([]models.PaymentRequest, error) {
+	query := `
+		SELECT` + paymentRequestColumns + `
+		FROM
+			shop."payment_request" pr
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = pr.requester_id
+		WHERE
+			u.org_id = $3 AND pr.payer = $1 AND pr.status = 'pending' AND pr.expires_at > $2
+		ORDER BY
+			pr.id
+	`
+
+	return r.queryPaymentRequests(ctx, "GetIncomingPaymentRequests", query, payer, now, orgID(ctx))
+}
+
+// GetOutgoingPaymentRequests возвращает все запросы, созданные пользователем
+func (r *repository) GetOutgoingPaymentRequests(ctx context.Context, requesterID int64) ([]models.PaymentRequest, error) {
+	query := `
+		SELECT` + paymentRequestColumns + `
+		FROM
+			shop."payment_request" pr
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = pr.requester_id
+		WHERE
+			pr.requester_id = $1 AND u.org_id = $2
+		ORDER BY
+			pr.id
+	`
+
+	return r.queryPaymentRequests(ctx, "GetOutgoingPaymentRequests", query, requesterID, orgID(ctx))
+}
+
+func (r *repository) queryPaymentRequests(ctx context.Context, method, query string, args ...any) ([]models.PaymentRequest, error) {
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", method, err)
+	}
+	defer rows.Close()
+
+	requests := []models.PaymentRequest{}
+	for rows.Next() {
+		pr, err := scanPaymentRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", method, err)
+		}
+		requests = append(requests, pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows %s: %w", method, err)
+	}
+
+	return requests, nil
+}
+
+// GetPaymentRequestForPayer блокирует запрос к плательщику, возвращает пустой запрос, если его нет
+func (r *repository) GetPaymentRequestForPayer(ctx context.Context, payer string, requestID int64) (models.PaymentRequest, error) {
+	query := `
+		SELECT` + paymentRequestColumns + `
+		FROM
+			shop."payment_request" pr
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = pr.requester_id
+		WHERE
+			pr.id = $1 AND pr.payer = $2 AND u.org_id = $3
+		FOR UPDATE OF pr
+	`
+
+	pr, err := scanPaymentRequest(r.conn(ctx).QueryRow(ctx, query, requestID, payer, orgID(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.PaymentRequest{}, nil
+		}
+		return models.PaymentRequest{}, fmt.Errorf("GetPaymentRequestForPayer failed: %w", err)
+	}
+
+	return pr, nil
+}
+
+func (r *repository) ResolvePaymentRequest(ctx context.Context, requestID int64, status string) error {
+	query := `
+		UPDATE
+			shop."payment_request" pr
+		SET
+			status = $1,
+			resolved_at = NOW()
+		FROM
+			shop."user" u
+		WHERE
+			u.id = pr.requester_id AND u.org_id = $3 AND pr.id = $2 AND pr.status = 'pending'
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, status, requestID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("ResolvePaymentRequest failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows updated ResolvePaymentRequest")
+	}
+
+	return nil
+}
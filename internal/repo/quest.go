@@ -0,0 +1,360 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Quest
+func (r *repository) CreateQuest(ctx context.Context, quest models.Quest) (int64, error) {
+	var questID int64
+
+	query := `
+		INSERT INTO
+			shop."quest" (org_id, title, description, event, metric, other_team, target, reward, starts_at, ends_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING
+			id
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query,
+		orgID(ctx),
+		quest.Title,
+		quest.Description,
+		quest.Event,
+		quest.Metric,
+		quest.OtherTeam,
+		quest.Target,
+		quest.Reward,
+		quest.StartsAt,
+		quest.EndsAt,
+	).Scan(&questID)
+	if err != nil {
+		return 0, fmt.Errorf("CreateQuest failed: %w", err)
+	}
+
+	return questID, nil
+}
+
+// UpdateQuest false - задание не найдено или удалено
+func (r *repository) UpdateQuest(ctx context.Context, quest models.Quest) (bool, error) {
+	query := `
+		UPDATE
+			shop."quest"
+		SET
+			title = $1,
+			description = $2,
+			event = $3,
+			metric = $4,
+			other_team = $5,
+			target = $6,
+			reward = $7,
+			starts_at = $8,
+			ends_at = $9
+		WHERE
+			id = $10 AND org_id = $11 AND deleted_at IS NULL
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query,
+		quest.Title,
+		quest.Description,
+		quest.Event,
+		quest.Metric,
+		quest.OtherTeam,
+		quest.Target,
+		quest.Reward,
+		quest.StartsAt,
+		quest.EndsAt,
+		quest.ID,
+		orgID(ctx),
+	)
+	if err != nil {
+		return false, fmt.Errorf("UpdateQuest failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// DeleteQuest скрывает задание, прогресс и выплаченные награды сохраняются. false - задание не найдено.
+func (r *repository) DeleteQuest(ctx context.Context, questID int64) (bool, error) {
+	query := `
+		UPDATE
+			shop."quest"
+		SET
+			deleted_at = NOW()
+		WHERE
+			id = $1 AND org_id = $2 AND deleted_at IS NULL
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, questID, orgID(ctx))
+	if err != nil {
+		return false, fmt.Errorf("DeleteQuest failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// GetQuests возвращает все неудалённые задания организации, начиная с последних
+func (r *repository) GetQuests(ctx context.Context) ([]models.Quest, error) {
+	query := `
+		SELECT
+			q.id,
+			q.title,
+			q.description,
+			q.event,
+			q.metric,
+			q.other_team,
+			q.target,
+			q.reward,
+			q.starts_at,
+			q.ends_at
+		FROM
+			shop."quest" q
+		WHERE
+			q.org_id = $1 AND q.deleted_at IS NULL
+		ORDER BY
+			q.starts_at DESC, q.id DESC
+	`
+
+	return r.queryQuests(ctx, "GetQuests", query, orgID(ctx))
+}
+
+// GetActiveQuests возвращает задания события, период которых включает at
+func (r *repository) GetActiveQuests(ctx context.Context, event string, at time.Time) ([]models.Quest, error) {
+	query := `
+		SELECT
+			q.id,
+			q.title,
+			q.description,
+			q.event,
+			q.metric,
+			q.other_team,
+			q.target,
+			q.reward,
+			q.starts_at,
+			q.ends_at
+		FROM
+			shop."quest" q
+		WHERE
+			q.org_id = $1 AND q.event = $2 AND q.deleted_at IS NULL AND q.starts_at <= $3 AND q.ends_at > $3
+		ORDER BY
+			q.id
+	`
+
+	return r.queryQuests(ctx, "GetActiveQuests", query, orgID(ctx), event, at)
+}
+
+func (r *repository) queryQuests(ctx context.Context, name, query string, args ...any) ([]models.Quest, error) {
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	quests := []models.Quest{}
+	for rows.Next() {
+		quest, err := scanQuest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", name, err)
+		}
+		quests = append(quests, quest)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows %s: %w", name, err)
+	}
+
+	return quests, nil
+}
+
+// scanQuest читает строку с колонками в порядке GetQuests, за которыми могут идти дополнительные
+func scanQuest(row pgx.Row, dest ...any) (models.Quest, error) {
+	quest := models.Quest{}
+	err := row.Scan(append([]any{
+		&quest.ID,
+		&quest.Title,
+		&quest.Description,
+		&quest.Event,
+		&quest.Metric,
+		&quest.OtherTeam,
+		&quest.Target,
+		&quest.Reward,
+		&quest.StartsAt,
+		&quest.EndsAt,
+	}, dest...)...)
+
+	return quest, err
+}
+
+// GetUserQuests возвращает задания, период которых включает at, с прогрессом пользователя
+func (r *repository) GetUserQuests(ctx context.Context, username string, at time.Time) ([]models.UserQuest, error) {
+	query := `
+		SELECT
+			q.id,
+			q.title,
+			q.description,
+			q.event,
+			q.metric,
+			q.other_team,
+			q.target,
+			q.reward,
+			q.starts_at,
+			q.ends_at,
+			COALESCE(qp.progress, 0),
+			qp.completed_at
+		FROM
+			shop."quest" q
+		INNER JOIN
+			shop."user" u
+		ON
+			u.org_id = q.org_id AND u.username = $2
+		LEFT JOIN
+			shop."quest_progress" qp
+		ON
+			qp.quest_id = q.id AND qp.user_id = u.id
+		WHERE
+			q.org_id = $1 AND q.deleted_at IS NULL AND q.starts_at <= $3 AND q.ends_at > $3
+		ORDER BY
+			q.ends_at, q.id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, orgID(ctx), username, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetUserQuests: %w", err)
+	}
+	defer rows.Close()
+
+	quests := []models.UserQuest{}
+	for rows.Next() {
+		userQuest := models.UserQuest{}
+		userQuest.Quest, err = scanQuest(rows, &userQuest.Progress, &userQuest.CompletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan GetUserQuests: %w", err)
+		}
+		quests = append(quests, userQuest)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetUserQuests: %w", err)
+	}
+
+	return quests, nil
+}
+
+// ShareTeam true, если пользователи состоят хотя бы в одной общей команде
+func (r *repository) ShareTeam(ctx context.Context, username, otherUsername string) (bool, error) {
+	var shared bool
+
+	query := `
+		SELECT EXISTS (
+			SELECT
+				1
+			FROM
+				shop."team_member" tm
+			INNER JOIN
+				shop."team_member" otm
+			ON
+				otm.team_id = tm.team_id
+			INNER JOIN
+				shop."user" u
+			ON
+				u.id = tm.user_id
+			INNER JOIN
+				shop."user" ou
+			ON
+				ou.id = otm.user_id
+			WHERE
+				u.org_id = $1 AND u.username = $2 AND ou.org_id = $1 AND ou.username = $3
+		)
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, orgID(ctx), username, otherUsername).Scan(&shared)
+	if err != nil {
+		return false, fmt.Errorf("ShareTeam failed: %w", err)
+	}
+
+	return shared, nil
+}
+
+// AddQuestRecipient false - пользователь уже переводил монеты этому получателю в рамках задания
+func (r *repository) AddQuestRecipient(ctx context.Context, questID int64, username, recipient string) (bool, error) {
+	query := `
+		INSERT INTO
+			shop."quest_recipient" (quest_id, user_id, recipient_id)
+		SELECT
+			$1, u.id, ru.id
+		FROM
+			shop."user" u
+		INNER JOIN
+			shop."user" ru
+		ON
+			ru.org_id = u.org_id AND ru.username = $4
+		WHERE
+			u.org_id = $2 AND u.username = $3
+		ON CONFLICT DO NOTHING
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, questID, orgID(ctx), username, recipient)
+	if err != nil {
+		return false, fmt.Errorf("AddQuestRecipient failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// AddQuestProgress увеличивает прогресс пользователя и возвращает новое значение
+func (r *repository) AddQuestProgress(ctx context.Context, questID int64, username string, amount int64) (models.UserQuest, error) {
+	progress := models.UserQuest{}
+
+	query := `
+		INSERT INTO
+			shop."quest_progress" (quest_id, user_id, progress)
+		SELECT
+			$1, u.id, $4
+		FROM
+			shop."user" u
+		WHERE
+			u.org_id = $2 AND u.username = $3
+		ON CONFLICT (quest_id, user_id) DO UPDATE SET
+			progress = quest_progress.progress + EXCLUDED.progress,
+			updated_at = NOW()
+		RETURNING
+			progress,
+			completed_at
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, questID, orgID(ctx), username, amount).Scan(&progress.Progress, &progress.CompletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserQuest{}, nil
+		}
+		return models.UserQuest{}, fmt.Errorf("AddQuestProgress failed: %w", err)
+	}
+
+	return progress, nil
+}
+
+// CompleteQuest отмечает задание выполненным. false - задание уже выполнено в конкурентной транзакции.
+func (r *repository) CompleteQuest(ctx context.Context, questID int64, username string) (bool, error) {
+	query := `
+		UPDATE
+			shop."quest_progress" qp
+		SET
+			completed_at = NOW()
+		FROM
+			shop."user" u
+		WHERE
+			u.id = qp.user_id AND u.org_id = $2 AND u.username = $3 AND qp.quest_id = $1 AND qp.completed_at IS NULL
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, questID, orgID(ctx), username)
+	if err != nil {
+		return false, fmt.Errorf("CompleteQuest failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Referral
+func (r *repository) GetReferralCode(ctx context.Context, username string) (string, error) {
+	code := ""
+
+	query := `
+		SELECT
+			u.referral_code
+		FROM
+			shop."user" u
+		WHERE
+			u.org_id = $1 AND u.username = $2
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, orgID(ctx), username).Scan(&code)
+	if err != nil {
+		return "", fmt.Errorf("GetReferralCode failed: %w", err)
+	}
+
+	return code, nil
+}
+
+// GetReferrals возвращает приглашения пользователя, начиная с последних
+func (r *repository) GetReferrals(ctx context.Context, username string) ([]models.Referral, error) {
+	query := `
+		SELECT
+			rf.id,
+			rr.username,
+			re.username,
+			rf.bonus,
+			rf.created_at,
+			rf.qualified_at
+		FROM
+			shop."referral" rf
+		INNER JOIN
+			shop."user" rr
+		ON
+			rr.id = rf.referrer_id
+		INNER JOIN
+			shop."user" re
+		ON
+			re.id = rf.referee_id
+		WHERE
+			rf.org_id = $1 AND rr.username = $2
+		ORDER BY
+			rf.created_at DESC, rf.id DESC
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, orgID(ctx), username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetReferrals: %w", err)
+	}
+	defer rows.Close()
+
+	referrals := []models.Referral{}
+	for rows.Next() {
+		referral, err := scanReferral(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan GetReferrals: %w", err)
+		}
+		referrals = append(referrals, referral)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetReferrals: %w", err)
+	}
+
+	return referrals, nil
+}
+
+// GetPendingReferral возвращает невыплаченное приглашение пользователя или пустое, если его нет
+func (r *repository) GetPendingReferral(ctx context.Context, username string) (models.Referral, error) {
+	query := `
+		SELECT
+			rf.id,
+			rr.username,
+			re.username,
+			rf.bonus,
+			rf.created_at,
+			rf.qualified_at
+		FROM
+			shop."referral" rf
+		INNER JOIN
+			shop."user" rr
+		ON
+			rr.id = rf.referrer_id
+		INNER JOIN
+			shop."user" re
+		ON
+			re.id = rf.referee_id
+		WHERE
+			rf.org_id = $1 AND re.username = $2 AND rf.qualified_at IS NULL
+	`
+
+	referral, err := scanReferral(r.conn(ctx).QueryRow(ctx, query, orgID(ctx), username))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Referral{}, nil
+		}
+		return models.Referral{}, fmt.Errorf("GetPendingReferral failed: %w", err)
+	}
+
+	return referral, nil
+}
+
+// scanReferral читает строку с колонками в порядке GetReferrals
+func scanReferral(row pgx.Row) (models.Referral, error) {
+	referral := models.Referral{}
+	err := row.Scan(
+		&referral.ID,
+		&referral.Referrer,
+		&referral.Referee,
+		&referral.Bonus,
+		&referral.CreatedAt,
+		&referral.QualifiedAt,
+	)
+
+	return referral, err
+}
+
+// QualifyReferral отмечает приглашение выплаченным. false - приглашение уже выплачено конкурентной транзакцией.
+func (r *repository) QualifyReferral(ctx context.Context, referralID, bonus int64) (bool, error) {
+	query := `
+		UPDATE
+			shop."referral"
+		SET
+			bonus = $1,
+			qualified_at = NOW()
+		WHERE
+			id = $2 AND org_id = $3 AND qualified_at IS NULL
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, bonus, referralID, orgID(ctx))
+	if err != nil {
+		return false, fmt.Errorf("QualifyReferral failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
@@ -4,15 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
-	"github.com/devWaylander/coins_store/pkg/log"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/metrics"
 	"github.com/devWaylander/coins_store/pkg/models"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-const shopUser = "AvitoShop"
-
 type repository struct {
 	db *pgxpool.Pool
 }
@@ -21,14 +21,24 @@ func New(db *pgxpool.Pool) *repository {
 	return &repository{db: db}
 }
 
-func (r *repository) txRollback(ctx context.Context, tx pgx.Tx, err error) {
-	if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-		err = fmt.Errorf("failed to rollback transaction: %w", err)
-		log.Logger.Err(rollbackErr).Msg(err.Error())
+// conn возвращает транзакцию, открытую TxManager, либо пул соединений
+func (r *repository) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
 	}
+
+	return r.db
+}
+
+// orgID организация из claims токена, которой ограничен каждый запрос репозитория.
+// Без организации в контексте запросы не находят и не меняют ни одной строки.
+func orgID(ctx context.Context) int64 {
+	id, _ := ctx.Value(models.OrgIDKey).(int64)
+	return id
 }
 
 // User
+// IsUserExist false для удалённых и заблокированных (suspended) пользователей: они не могут получать монеты
 func (r *repository) IsUserExist(ctx context.Context, username string) (bool, error) {
 	var userID int64
 
@@ -38,9 +48,9 @@ func (r *repository) IsUserExist(ctx context.Context, username string) (bool, er
 		FROM
 			shop."user" u
 		WHERE
-			u.username = $1
+			u.org_id = $1 AND u.username = $2 AND u.deleted_at IS NULL AND u.status <> 'suspended'
 	`
-	row := r.db.QueryRow(ctx, query, username)
+	row := r.conn(ctx).QueryRow(ctx, query, orgID(ctx), username)
 	err := row.Scan(&userID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -61,10 +71,10 @@ func (r *repository) GetBalanceIDByUsername(ctx context.Context, username string
 		FROM
 			shop."user" u
 		WHERE
-			u.username = $1
+			u.org_id = $1 AND u.username = $2
 	`
 
-	row := r.db.QueryRow(ctx, query, username)
+	row := r.conn(ctx).QueryRow(ctx, query, orgID(ctx), username)
 	err := row.Scan(&balanceID)
 	if err != nil {
 		return 0, fmt.Errorf("GetBalanceIDByUsername failed: %w", err)
@@ -73,6 +83,62 @@ func (r *repository) GetBalanceIDByUsername(ctx context.Context, username string
 	return balanceID, nil
 }
 
+func (r *repository) GetUserRoleByUsername(ctx context.Context, username string) (string, error) {
+	var role string
+
+	query := `
+		SELECT
+			u.role
+		FROM
+			shop."user" u
+		WHERE
+			u.org_id = $1 AND u.username = $2
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, orgID(ctx), username).Scan(&role)
+	if err != nil {
+		return "", fmt.Errorf("GetUserRoleByUsername failed: %w", err)
+	}
+
+	return role, nil
+}
+
+// GetActiveUsernames возвращает пользователей, которым начисляются гранты и пособие:
+// удалённые, замороженные и заблокированные пропускаются
+func (r *repository) GetActiveUsernames(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT
+			u.username
+		FROM
+			shop."user" u
+		WHERE
+			u.org_id = $1 AND u.deleted_at IS NULL AND u.status = $2
+		ORDER BY
+			u.id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, orgID(ctx), models.UserStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetActiveUsernames: %w", err)
+	}
+	defer rows.Close()
+
+	usernames := []string{}
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, fmt.Errorf("failed to scan GetActiveUsernames: %w", err)
+		}
+		usernames = append(usernames, username)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetActiveUsernames: %w", err)
+	}
+
+	return usernames, nil
+}
+
 // Balance
 func (r *repository) GetBalanceByUserID(ctx context.Context, userID int64) (models.Balance, error) {
 	balanceDB := models.BalanceDB{}
@@ -90,10 +156,10 @@ func (r *repository) GetBalanceByUserID(ctx context.Context, userID int64) (mode
 		ON
 			u.balance_id = b.id
 		WHERE
-			u.id = $1 AND u.deleted_at IS NULL AND b.deleted_at IS NULL
+			u.id = $1 AND u.org_id = $2 AND u.deleted_at IS NULL AND b.deleted_at IS NULL
 	`
 
-	row := r.db.QueryRow(ctx, query, userID)
+	row := r.conn(ctx).QueryRow(ctx, query, userID, orgID(ctx))
 	err := row.Scan(
 		&balanceDB.ID,
 		&balanceDB.Amount,
@@ -120,10 +186,10 @@ func (r *repository) GetBalanceAmountByUserID(ctx context.Context, userID int64)
 		ON
 			u.balance_id = b.id
 		WHERE
-			u.id = $1 AND u.deleted_at IS NULL AND b.deleted_at IS NULL
+			u.id = $1 AND u.org_id = $2 AND u.deleted_at IS NULL AND b.deleted_at IS NULL
 	`
 
-	row := r.db.QueryRow(ctx, query, userID)
+	row := r.conn(ctx).QueryRow(ctx, query, userID, orgID(ctx))
 	err := row.Scan(&amount)
 	if err != nil {
 		return 0, fmt.Errorf("GetBalanceAmountByUserID failed: %w", err)
@@ -132,6 +198,115 @@ func (r *repository) GetBalanceAmountByUserID(ctx context.Context, userID int64)
 	return amount, nil
 }
 
+// LockBalances блокирует строки балансов до конца транзакции.
+// Блокировки всегда берутся по возрастанию id, поэтому встречные переводы не приводят к дедлоку.
+func (r *repository) LockBalances(ctx context.Context, balanceIDs ...int64) error {
+	query := `
+		SELECT
+			b.id
+		FROM
+			shop."balance" b
+		WHERE
+			b.id = ANY($1) AND b.org_id = $2
+		ORDER BY
+			b.id
+		FOR UPDATE
+	`
+
+	start := time.Now()
+	rows, err := r.conn(ctx).Query(ctx, query, balanceIDs, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to query LockBalances: %w", err)
+	}
+	defer rows.Close()
+
+	locked := 0
+	for rows.Next() {
+		locked++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read rows LockBalances: %w", err)
+	}
+
+	metrics.BalanceLocks.Add(int64(locked))
+	metrics.BalanceLockWaitMicros.Add(time.Since(start).Microseconds())
+
+	return nil
+}
+
+// DebitBalance списывает монеты, только если их хватает без учёта активных резервов.
+// Резервы читаются в снимке запроса, поэтому баланс должен быть заблокирован через LockBalances.
+func (r *repository) DebitBalance(ctx context.Context, balanceID, amount int64) error {
+	query := `
+		UPDATE
+			shop."balance" b
+		SET
+			amount = b.amount - $1
+		WHERE
+			b.id = $2 AND b.org_id = $3 AND b.amount - (
+				SELECT
+					COALESCE(SUM(h.amount), 0)
+				FROM
+					shop."balance_hold" h
+				WHERE
+					h.balance_id = b.id AND h.status = 'active' AND h.expires_at > NOW()
+			) >= $1
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, amount, balanceID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("DebitBalance failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.New(internalErrors.ErrNotEnoughCoins)
+	}
+
+	return nil
+}
+
+// DebitBalanceIgnoringHolds списывает монеты без учёта резервов, например при сгорании
+func (r *repository) DebitBalanceIgnoringHolds(ctx context.Context, balanceID, amount int64) error {
+	query := `
+		UPDATE
+			shop."balance"
+		SET
+			amount = amount - $1
+		WHERE
+			id = $2 AND org_id = $3 AND amount >= $1
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, amount, balanceID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("DebitBalanceIgnoringHolds failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.New(internalErrors.ErrNotEnoughCoins)
+	}
+
+	return nil
+}
+
+func (r *repository) CreditBalance(ctx context.Context, balanceID, amount int64) error {
+	query := `
+		UPDATE
+			shop."balance"
+		SET
+			amount = amount + $1
+		WHERE
+			id = $2 AND org_id = $3
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, amount, balanceID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("CreditBalance failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no balance rows updated CreditBalance")
+	}
+
+	return nil
+}
+
 // Balance History
 func (r *repository) GetBalanceHistoryByUserID(ctx context.Context, userID int64) ([]models.BalanceHistory, error) {
 	var balanceHistoryDB []models.BalanceHistoryDB
@@ -143,35 +318,36 @@ func (r *repository) GetBalanceHistoryByUserID(ctx context.Context, userID int64
 			bh.transaction_amount,
 			bh.sender,
 			bh.recipient,
+			bh.type,
+			bh.reason,
+			bh.reversal_of,
+			bh.actor,
+			bh.merch_name,
+			bh.kudos_id,
+			bh.transfer_id,
+			bh.currency,
+			EXISTS (SELECT 1 FROM shop."balance_history" r WHERE r.reversal_of = bh.id) AS reversed,
 			bh.deleted_at,
 			bh.created_at
 		FROM
 			shop."balance_history" bh
 		INNER JOIN
-			shop."user" u
+			shop."balance" b
 		ON
-			u.balance_id = bh.balance_id
+			b.id = bh.balance_id
 		WHERE
-			u.id = $1
+			b.user_id = $1 AND bh.org_id = $2
 	`
 
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := r.conn(ctx).Query(ctx, query, userID, orgID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query GetBalanceHistoryByUserID: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		bh := models.BalanceHistoryDB{}
-		if err := rows.Scan(
-			&bh.ID,
-			&bh.BalanceID,
-			&bh.TransactionAmount,
-			&bh.Sender,
-			&bh.Recipient,
-			&bh.DeletedAt,
-			&bh.CreatedAt,
-		); err != nil {
+		bh, err := scanBalanceHistory(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan GetBalanceHistoryByUserID: %w", err)
 		}
 		balanceHistoryDB = append(balanceHistoryDB, bh)
@@ -189,92 +365,161 @@ func (r *repository) GetBalanceHistoryByUserID(ctx context.Context, userID int64
 	return balanceHistory, nil
 }
 
-func (r *repository) SendCoinsTX(ctx context.Context, userID, senderBalanceID, recipientBalanceID, amount int64, sender, recipient string) error {
-	tx, err := r.db.Begin(ctx)
+// scanBalanceHistory читает строку с колонками в порядке GetBalanceHistoryByUserID
+func scanBalanceHistory(row pgx.Row) (models.BalanceHistoryDB, error) {
+	bh := models.BalanceHistoryDB{}
+	err := row.Scan(
+		&bh.ID,
+		&bh.BalanceID,
+		&bh.TransactionAmount,
+		&bh.Sender,
+		&bh.Recipient,
+		&bh.Type,
+		&bh.Reason,
+		&bh.ReversalOf,
+		&bh.Actor,
+		&bh.MerchName,
+		&bh.KudosID,
+		&bh.TransferID,
+		&bh.Currency,
+		&bh.Reversed,
+		&bh.DeletedAt,
+		&bh.CreatedAt,
+	)
+
+	return bh, err
+}
+
+// NextTransferID выдаёт идентификатор, общий для обеих записей перевода
+func (r *repository) NextTransferID(ctx context.Context) (int64, error) {
+	var transferID int64
+	err := r.conn(ctx).QueryRow(ctx, `SELECT nextval('shop."balance_history_transfer_id_seq"')`).Scan(&transferID)
 	if err != nil {
-		return err
+		return 0, fmt.Errorf("NextTransferID failed: %w", err)
 	}
 
-	// списание баланса отправителя
-	query := `
-		UPDATE
-			shop."balance"
-		SET
-			amount = CASE
-				WHEN amount - $1 >= 0 THEN amount - $1
-				ELSE amount
-			END
-		WHERE
-			id = $2
-	`
+	return transferID, nil
+}
 
-	cmdTag, err := tx.Exec(ctx, query, amount, senderBalanceID)
-	if err != nil {
-		r.txRollback(ctx, tx, err)
-		return fmt.Errorf("failed to execute query SendCoinsTX: %v", err)
+func (r *repository) CreateBalanceHistory(ctx context.Context, entry models.BalanceHistory) error {
+	var reason, actor, merchName *string
+	var reversalOf, kudosID, transferID *int64
+	if entry.Reason != "" {
+		reason = &entry.Reason
 	}
-	if cmdTag.RowsAffected() == 0 {
-		return fmt.Errorf("no sender balance rows updated SendCoinsTX")
+	if entry.Actor != "" {
+		actor = &entry.Actor
+	}
+	if entry.ReversalOf != 0 {
+		reversalOf = &entry.ReversalOf
+	}
+	if entry.MerchName != "" {
+		merchName = &entry.MerchName
 	}
-	// создание записи в истории транзакций отправителя
-	query = `
+	if entry.KudosID != 0 {
+		kudosID = &entry.KudosID
+	}
+	if entry.TransferID != 0 {
+		transferID = &entry.TransferID
+	}
+
+	// валюта и организация записи берутся из баланса, поэтому вызывающему коду не нужно её передавать
+	query := `
 		INSERT INTO
-			shop."balance_history" (balance_id, transaction_amount, sender, recipient)
-		VALUES
-			($1, $2, $3, $4)
+			shop."balance_history" (balance_id, transaction_amount, sender, recipient, type, reason, reversal_of, actor, merch_name, kudos_id, transfer_id, currency, org_id)
+		SELECT
+			b.id, $2, $3, $4, $5, $6, $7, $8, $9, $10, $12, b.currency, b.org_id
+		FROM
+			shop."balance" b
+		WHERE
+			b.id = $1 AND b.org_id = $11
 	`
-	cmdTag, err = tx.Exec(ctx, query, senderBalanceID, amount, sender, recipient)
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query,
+		entry.BalanceID,
+		entry.TransactionAmount,
+		entry.Sender,
+		entry.Recipient,
+		entry.Type,
+		reason,
+		reversalOf,
+		actor,
+		merchName,
+		kudosID,
+		orgID(ctx),
+		transferID,
+	)
 	if err != nil {
-		r.txRollback(ctx, tx, err)
-		return fmt.Errorf("failed to execute query SendCoinsTX: %v", err)
+		return fmt.Errorf("CreateBalanceHistory failed: %w", err)
 	}
 	if cmdTag.RowsAffected() == 0 {
-		r.txRollback(ctx, tx, err)
-		return fmt.Errorf("no rows inserted sender balance history SendCoinsTX")
+		return fmt.Errorf("no rows inserted CreateBalanceHistory")
 	}
 
-	// пополнение баланса получателя
-	query = `
-		UPDATE
-			shop."balance"
-		SET
-			amount = amount + $1
+	return nil
+}
+
+// GetSentTransferUsage возвращает сумму и количество переводов, отправленных username начиная с since:
+// со своего баланса balanceID и из кошельков команд от имени участника. Пополнение команды участником
+// учитывается один раз, по записи его баланса.
+func (r *repository) GetSentTransferUsage(ctx context.Context, balanceID int64, username string, since time.Time) (models.TransferUsage, error) {
+	usage := models.TransferUsage{}
+
+	query := `
+		SELECT
+			COALESCE(SUM(bh.transaction_amount), 0) AS amount,
+			COUNT(*) AS count,
+			COALESCE(MIN(bh.created_at), $3) AS oldest_at
+		FROM
+			shop."balance_history" bh
 		WHERE
-			id = $2
+			bh.org_id = $4
+			AND bh.type = 'transfer'
+			AND bh.created_at >= $3
+			AND (
+				(bh.balance_id = $1 AND bh.sender = $2)
+				OR (
+					bh.actor = $2
+					AND bh.sender <> $2
+					AND bh.balance_id IN (SELECT t.balance_id FROM shop."team" t WHERE t.org_id = $4)
+				)
+			)
 	`
-	cmdTag, err = tx.Exec(ctx, query, amount, recipientBalanceID)
+
+	err := r.conn(ctx).QueryRow(ctx, query, balanceID, username, since, orgID(ctx)).Scan(&usage.Amount, &usage.Count, &usage.OldestAt)
 	if err != nil {
-		r.txRollback(ctx, tx, err)
-		return fmt.Errorf("failed to execute query SendCoinsTX: %v", err)
+		return usage, fmt.Errorf("GetSentTransferUsage failed: %w", err)
 	}
-	if cmdTag.RowsAffected() == 0 {
-		r.txRollback(ctx, tx, err)
-		return fmt.Errorf("no recipient balance rows updated SendCoinsTX")
-	}
-	// создание записи в истории транзакций получателя
-	query = `
-	INSERT INTO
-		shop."balance_history" (balance_id, transaction_amount, sender, recipient)
-	VALUES
-		($1, $2, $3, $4)
+
+	return usage, nil
+}
+
+// GetTransferUsage возвращает сумму и количество переводов от sender в истории баланса начиная с since.
+// Используется для полученных от отправителя переводов, balanceID - баланс получателя.
+func (r *repository) GetTransferUsage(ctx context.Context, balanceID int64, sender string, since time.Time) (models.TransferUsage, error) {
+	usage := models.TransferUsage{}
+
+	query := `
+		SELECT
+			COALESCE(SUM(bh.transaction_amount), 0) AS amount,
+			COUNT(*) AS count,
+			COALESCE(MIN(bh.created_at), $3) AS oldest_at
+		FROM
+			shop."balance_history" bh
+		WHERE
+			bh.balance_id = $1
+			AND bh.org_id = $4
+			AND bh.type = 'transfer'
+			AND bh.sender = $2
+			AND bh.created_at >= $3
 	`
-	cmdTag, err = tx.Exec(ctx, query, recipientBalanceID, amount, sender, recipient)
-	if err != nil {
-		r.txRollback(ctx, tx, err)
-		return fmt.Errorf("failed to execute query SendCoinsTX: %v", err)
-	}
-	if cmdTag.RowsAffected() == 0 {
-		r.txRollback(ctx, tx, err)
-		return fmt.Errorf("no rows inserted recipient balance history SendCoinsTX")
-	}
 
-	if err := tx.Commit(ctx); err != nil {
-		err = fmt.Errorf("failed to commit transaction SendCoinsTX: %w", err)
-		r.txRollback(ctx, tx, err)
-		return err
+	err := r.conn(ctx).QueryRow(ctx, query, balanceID, sender, since, orgID(ctx)).Scan(&usage.Amount, &usage.Count, &usage.OldestAt)
+	if err != nil {
+		return usage, fmt.Errorf("GetTransferUsage failed: %w", err)
 	}
 
-	return nil
+	return usage, nil
 }
 
 // Inventory
@@ -286,11 +531,15 @@ func (r *repository) GetInventoryIDByUserID(ctx context.Context, userID int64) (
 			i.id
 		FROM
 			shop."inventory" i
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = i.user_id
 		WHERE
-			i.user_id = $1
+			i.user_id = $1 AND u.org_id = $2
 	`
 
-	row := r.db.QueryRow(ctx, query, userID)
+	row := r.conn(ctx).QueryRow(ctx, query, userID, orgID(ctx))
 	err := row.Scan(&inventoryID)
 	if err != nil {
 		return 0, fmt.Errorf("GetInventoryIDByUserID failed: %w", err)
@@ -316,11 +565,15 @@ func (r *repository) GetInventoryMerchItems(ctx context.Context, userID int64) (
 			shop."inventory" i 
 		ON 
 			im.inventory_id = i.id
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = i.user_id
 		WHERE 
-			i.user_id = $1 AND im.deleted_at IS NULL
+			i.user_id = $1 AND u.org_id = $2 AND im.deleted_at IS NULL
 	`
 
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := r.conn(ctx).Query(ctx, query, userID, orgID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query GetInventoryMerchItems: %w", err)
 	}
@@ -353,74 +606,39 @@ func (r *repository) GetInventoryMerchItems(ctx context.Context, userID int64) (
 	return inventoryMerch, nil
 }
 
-func (r *repository) BuyItemTX(ctx context.Context, userID, balanceID, inventoryID, merchID, price int64, username, item string) error {
-	tx, err := r.db.Begin(ctx)
-	if err != nil {
-		return err
-	}
-
-	// списание баланса
+func (r *repository) AddInventoryMerch(ctx context.Context, inventoryID, merchID int64, item string) error {
+	// создание записи-связки для инвентаря с данным предметом, инвентарь и мерч должны быть из одной организации
 	query := `
-		UPDATE
-			shop."balance"
-		SET
-			amount = CASE
-				WHEN amount - $1 >= 0 THEN amount - $1
-				ELSE amount
-			END
-		WHERE
-			id = $2
-	`
-	cmdTag, err := tx.Exec(ctx, query, price, balanceID)
-	if err != nil {
-		r.txRollback(ctx, tx, err)
-		return fmt.Errorf("failed to execute query BuyItemTX: %v", err)
-	}
-	if cmdTag.RowsAffected() == 0 {
-		r.txRollback(ctx, tx, err)
-		return fmt.Errorf("no balance rows updated BuyItemTX")
-	}
-
-	// создание записи в истории транзакций
-	query = `
-		INSERT INTO
-			shop."balance_history" (balance_id, transaction_amount, sender, recipient)
-		VALUES
-			($1, $2, $3, $4)
-	`
-	cmdTag, err = tx.Exec(ctx, query, balanceID, price, username, shopUser)
-	if err != nil {
-		r.txRollback(ctx, tx, err)
-		return fmt.Errorf("failed to execute query BuyItemTX: %v", err)
-	}
-	if cmdTag.RowsAffected() == 0 {
-		r.txRollback(ctx, tx, err)
-		return fmt.Errorf("no rows inserted balance history BuyItemTX")
-	}
-
-	// создание записи-связки для инвентаря с данным предметом
-	query = `
 		INSERT INTO
 			shop."inventory_merch" (inventory_id, merch_id, name, count)
-		VALUES
-			($1, $2, $3, 1)
+		SELECT
+			i.id, m.id, $3, 1
+		FROM
+			shop."inventory" i
+		LEFT JOIN
+			shop."user" u
+		ON
+			u.id = i.user_id
+		LEFT JOIN
+			shop."team" t
+		ON
+			t.id = i.team_id
+		INNER JOIN
+			shop."merch" m
+		ON
+			m.id = $2 AND m.org_id = $4
+		WHERE
+			i.id = $1 AND COALESCE(u.org_id, t.org_id) = $4
 		ON CONFLICT (inventory_id, merch_id)
 		DO UPDATE SET count = shop."inventory_merch".count + 1
 	`
-	cmdTag, err = tx.Exec(ctx, query, inventoryID, merchID, item)
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, inventoryID, merchID, item, orgID(ctx))
 	if err != nil {
-		r.txRollback(ctx, tx, err)
-		return fmt.Errorf("failed to execute query BuyItemTX: %v", err)
+		return fmt.Errorf("AddInventoryMerch failed: %w", err)
 	}
 	if cmdTag.RowsAffected() == 0 {
-		r.txRollback(ctx, tx, err)
-		return fmt.Errorf("no rows inserted inventory merch BuyItemTX")
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		err := fmt.Errorf("failed to commit transaction BuyItemTX: %w", err)
-		r.txRollback(ctx, tx, err)
-		return err
+		return fmt.Errorf("no rows inserted AddInventoryMerch")
 	}
 
 	return nil
@@ -435,19 +653,21 @@ func (r *repository) GetMerchByName(ctx context.Context, name string) (models.Me
 			m.id,
 			m.name,
 			m.price,
+			m.currency,
 			m.deleted_at,
 			m.created_at
 		FROM
 			shop."merch" m
 		WHERE
-			m.name = $1
+			m.org_id = $1 AND m.name = $2
 	`
 
-	row := r.db.QueryRow(ctx, query, name)
+	row := r.conn(ctx).QueryRow(ctx, query, orgID(ctx), name)
 	err := row.Scan(
 		&merchDB.ID,
 		&merchDB.Name,
 		&merchDB.Price,
+		&merchDB.Currency,
 		&merchDB.DeletedAt,
 		&merchDB.CreatedAt,
 	)
@@ -0,0 +1,177 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Reversal
+// GetTransactionsByUsername возвращает историю баланса пользователя, начиная с последних записей
+func (r *repository) GetTransactionsByUsername(ctx context.Context, username string) ([]models.BalanceHistory, error) {
+	query := `
+		SELECT
+			bh.id,
+			bh.balance_id,
+			bh.transaction_amount,
+			bh.sender,
+			bh.recipient,
+			bh.type,
+			bh.reason,
+			bh.reversal_of,
+			bh.actor,
+			bh.merch_name,
+			bh.kudos_id,
+			bh.transfer_id,
+			bh.currency,
+			EXISTS (SELECT 1 FROM shop."balance_history" r WHERE r.reversal_of = bh.id) AS reversed,
+			bh.deleted_at,
+			bh.created_at
+		FROM
+			shop."balance_history" bh
+		INNER JOIN
+			shop."balance" b
+		ON
+			b.id = bh.balance_id
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = b.user_id
+		WHERE
+			u.org_id = $1 AND u.username = $2
+		ORDER BY
+			bh.id DESC
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, orgID(ctx), username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetTransactionsByUsername: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []models.BalanceHistory{}
+	for rows.Next() {
+		bh, err := scanBalanceHistory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan GetTransactionsByUsername: %w", err)
+		}
+		transactions = append(transactions, bh.ToModelBalanceHistory())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetTransactionsByUsername: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// GetBalanceHistoryByID возвращает запись истории или пустую запись, если она не найдена
+func (r *repository) GetBalanceHistoryByID(ctx context.Context, entryID int64) (models.BalanceHistory, error) {
+	query := `
+		SELECT
+			bh.id,
+			bh.balance_id,
+			bh.transaction_amount,
+			bh.sender,
+			bh.recipient,
+			bh.type,
+			bh.reason,
+			bh.reversal_of,
+			bh.actor,
+			bh.merch_name,
+			bh.kudos_id,
+			bh.transfer_id,
+			bh.currency,
+			EXISTS (SELECT 1 FROM shop."balance_history" r WHERE r.reversal_of = bh.id) AS reversed,
+			bh.deleted_at,
+			bh.created_at
+		FROM
+			shop."balance_history" bh
+		WHERE
+			bh.id = $1 AND bh.org_id = $2
+	`
+
+	bh, err := scanBalanceHistory(r.conn(ctx).QueryRow(ctx, query, entryID, orgID(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.BalanceHistory{}, nil
+		}
+		return models.BalanceHistory{}, fmt.Errorf("GetBalanceHistoryByID failed: %w", err)
+	}
+
+	return bh.ToModelBalanceHistory(), nil
+}
+
+// GetTransferCounterpart находит неотменённую запись того же перевода на другом балансе по общему transfer_id.
+// Записи, созданные до появления transfer_id, сопоставляются по сторонам, сумме, валюте и created_at.
+// Если запись не найдена, возвращается пустая запись.
+func (r *repository) GetTransferCounterpart(ctx context.Context, entry models.BalanceHistory) (models.BalanceHistory, error) {
+	query := `
+		SELECT
+			bh.id,
+			bh.balance_id,
+			bh.transaction_amount,
+			bh.sender,
+			bh.recipient,
+			bh.type,
+			bh.reason,
+			bh.reversal_of,
+			bh.actor,
+			bh.merch_name,
+			bh.kudos_id,
+			bh.transfer_id,
+			bh.currency,
+			FALSE AS reversed,
+			bh.deleted_at,
+			bh.created_at
+		FROM
+			shop."balance_history" bh
+		WHERE
+			bh.balance_id <> $1
+			AND bh.org_id = $8
+			AND (
+				bh.transfer_id = $9
+				OR (
+					$9::BIGINT IS NULL
+					AND bh.transfer_id IS NULL
+					AND bh.sender = $2
+					AND bh.recipient = $3
+					AND bh.transaction_amount = $4
+					AND bh.type = $5
+					AND bh.created_at = $6
+					AND bh.currency = $7
+				)
+			)
+			AND NOT EXISTS (SELECT 1 FROM shop."balance_history" r WHERE r.reversal_of = bh.id)
+		ORDER BY
+			bh.id
+		LIMIT 1
+	`
+
+	var transferID *int64
+	if entry.TransferID != 0 {
+		transferID = &entry.TransferID
+	}
+
+	bh, err := scanBalanceHistory(r.conn(ctx).QueryRow(ctx, query,
+		entry.BalanceID,
+		entry.Sender,
+		entry.Recipient,
+		entry.TransactionAmount,
+		entry.Type,
+		entry.CreatedAt,
+		entry.Currency,
+		orgID(ctx),
+		transferID,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.BalanceHistory{}, nil
+		}
+		return models.BalanceHistory{}, fmt.Errorf("GetTransferCounterpart failed: %w", err)
+	}
+
+	return bh.ToModelBalanceHistory(), nil
+}
@@ -0,0 +1,331 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const scheduleColumns = `
+			ts.id,
+			ts.user_id,
+			u.username,
+			ts.recipient,
+			ts.amount,
+			ts.interval_seconds,
+			ts.next_run_at,
+			ts.consecutive_failures,
+			ts.paused_at,
+			ts.completed_at,
+			ts.deleted_at,
+			ts.created_at
+`
+
+func scanSchedule(row pgx.Row) (models.Schedule, error) {
+	sdb := models.ScheduleDB{}
+	err := row.Scan(
+		&sdb.ID,
+		&sdb.UserID,
+		&sdb.Sender,
+		&sdb.Recipient,
+		&sdb.Amount,
+		&sdb.IntervalSeconds,
+		&sdb.NextRunAt,
+		&sdb.ConsecutiveFailures,
+		&sdb.PausedAt,
+		&sdb.CompletedAt,
+		&sdb.DeletedAt,
+		&sdb.CreatedAt,
+	)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+
+	return sdb.ToModelSchedule(), nil
+}
+
+func intervalSeconds(interval time.Duration) *int64 {
+	if interval <= 0 {
+		return nil
+	}
+	seconds := int64(interval / time.Second)
+
+	return &seconds
+}
+
+// Schedule
+func (r *repository) CreateSchedule(ctx context.Context, schedule models.Schedule) (int64, error) {
+	var scheduleID int64
+
+	query := `
+		INSERT INTO
+			shop."transfer_schedule" (user_id, recipient, amount, interval_seconds, next_run_at)
+		SELECT
+			u.id, $2, $3, $4, $5
+		FROM
+			shop."user" u
+		WHERE
+			u.id = $1 AND u.org_id = $6
+		RETURNING
+			id
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query,
+		schedule.UserID,
+		schedule.Recipient,
+		schedule.Amount,
+		intervalSeconds(schedule.Interval),
+		schedule.NextRunAt,
+		orgID(ctx),
+	).Scan(&scheduleID)
+	if err != nil {
+		return 0, fmt.Errorf("CreateSchedule failed: %w", err)
+	}
+
+	return scheduleID, nil
+}
+
+func (r *repository) GetSchedulesByUserID(ctx context.Context, userID int64) ([]models.Schedule, error) {
+	query := `
+		SELECT` + scheduleColumns + `
+		FROM
+			shop."transfer_schedule" ts
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = ts.user_id
+		WHERE
+			ts.user_id = $1 AND u.org_id = $2 AND ts.deleted_at IS NULL
+		ORDER BY
+			ts.id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetSchedulesByUserID: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []models.Schedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan GetSchedulesByUserID: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetSchedulesByUserID: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// GetScheduleByID возвращает пустое расписание, если у пользователя его нет
+func (r *repository) GetScheduleByID(ctx context.Context, userID, scheduleID int64) (models.Schedule, error) {
+	query := `
+		SELECT` + scheduleColumns + `
+		FROM
+			shop."transfer_schedule" ts
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = ts.user_id
+		WHERE
+			ts.id = $1 AND ts.user_id = $2 AND u.org_id = $3 AND ts.deleted_at IS NULL
+		FOR UPDATE OF ts
+	`
+
+	schedule, err := scanSchedule(r.conn(ctx).QueryRow(ctx, query, scheduleID, userID, orgID(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Schedule{}, nil
+		}
+		return models.Schedule{}, fmt.Errorf("GetScheduleByID failed: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// ClaimDueSchedule блокирует одно расписание, которое пора выполнить.
+// Заблокированные другими репликами расписания пропускаются, поэтому каждое срабатывание выполняется один раз.
+func (r *repository) ClaimDueSchedule(ctx context.Context, now time.Time) (models.Schedule, error) {
+	query := `
+		SELECT` + scheduleColumns + `
+		FROM
+			shop."transfer_schedule" ts
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = ts.user_id
+		WHERE
+			u.org_id = $2
+			AND ts.next_run_at <= $1
+			AND ts.paused_at IS NULL
+			AND ts.completed_at IS NULL
+			AND ts.deleted_at IS NULL
+		ORDER BY
+			ts.next_run_at
+		LIMIT 1
+		FOR UPDATE OF ts SKIP LOCKED
+	`
+
+	schedule, err := scanSchedule(r.conn(ctx).QueryRow(ctx, query, now, orgID(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Schedule{}, nil
+		}
+		return models.Schedule{}, fmt.Errorf("ClaimDueSchedule failed: %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (r *repository) UpdateSchedule(ctx context.Context, schedule models.Schedule) error {
+	query := `
+		UPDATE
+			shop."transfer_schedule" ts
+		SET
+			amount = $1,
+			interval_seconds = $2,
+			next_run_at = $3,
+			consecutive_failures = $4,
+			paused_at = CASE WHEN $5 THEN COALESCE(ts.paused_at, NOW()) ELSE NULL END,
+			completed_at = CASE WHEN $6 THEN COALESCE(ts.completed_at, NOW()) ELSE NULL END
+		FROM
+			shop."user" u
+		WHERE
+			u.id = ts.user_id AND u.org_id = $8 AND ts.id = $7 AND ts.deleted_at IS NULL
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query,
+		schedule.Amount,
+		intervalSeconds(schedule.Interval),
+		schedule.NextRunAt,
+		schedule.ConsecutiveFailures,
+		schedule.Paused,
+		schedule.Completed,
+		schedule.ID,
+		orgID(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("UpdateSchedule failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows updated UpdateSchedule")
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteSchedule(ctx context.Context, userID, scheduleID int64) (bool, error) {
+	query := `
+		UPDATE
+			shop."transfer_schedule" ts
+		SET
+			deleted_at = NOW()
+		FROM
+			shop."user" u
+		WHERE
+			u.id = ts.user_id AND u.org_id = $3 AND ts.id = $1 AND ts.user_id = $2 AND ts.deleted_at IS NULL
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, scheduleID, userID, orgID(ctx))
+	if err != nil {
+		return false, fmt.Errorf("DeleteSchedule failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// Schedule run
+func (r *repository) CreateScheduleRun(ctx context.Context, run models.ScheduleRun) error {
+	var runError *string
+	if run.Error != "" {
+		runError = &run.Error
+	}
+
+	query := `
+		INSERT INTO
+			shop."transfer_schedule_run" (schedule_id, scheduled_at, status, error)
+		SELECT
+			ts.id, $2, $3, $4
+		FROM
+			shop."transfer_schedule" ts
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = ts.user_id
+		WHERE
+			ts.id = $1 AND u.org_id = $5
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, run.ScheduleID, run.ScheduledAt, run.Status, runError, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("CreateScheduleRun failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows inserted CreateScheduleRun")
+	}
+
+	return nil
+}
+
+func (r *repository) GetScheduleRuns(ctx context.Context, scheduleID int64) ([]models.ScheduleRun, error) {
+	query := `
+		SELECT
+			tsr.id,
+			tsr.schedule_id,
+			tsr.scheduled_at,
+			tsr.status,
+			tsr.error,
+			tsr.created_at
+		FROM
+			shop."transfer_schedule_run" tsr
+		INNER JOIN
+			shop."transfer_schedule" ts
+		ON
+			ts.id = tsr.schedule_id
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = ts.user_id
+		WHERE
+			tsr.schedule_id = $1 AND u.org_id = $2
+		ORDER BY
+			tsr.scheduled_at DESC
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, scheduleID, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetScheduleRuns: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.ScheduleRun{}
+	for rows.Next() {
+		srdb := models.ScheduleRunDB{}
+		if err := rows.Scan(
+			&srdb.ID,
+			&srdb.ScheduleID,
+			&srdb.ScheduledAt,
+			&srdb.Status,
+			&srdb.Error,
+			&srdb.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan GetScheduleRuns: %w", err)
+		}
+		runs = append(runs, srdb.ToModelScheduleRun())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetScheduleRuns: %w", err)
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,142 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Snapshot
+// GetLastSnapshotDay возвращает последние сутки со снимками или нулевое время, если снимков нет
+func (r *repository) GetLastSnapshotDay(ctx context.Context) (time.Time, error) {
+	var day *time.Time
+
+	query := `
+		SELECT
+			MAX(s.day)
+		FROM
+			shop."balance_snapshot" s
+		INNER JOIN
+			shop."balance" b
+		ON
+			b.id = s.balance_id
+		WHERE
+			b.org_id = $1
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, orgID(ctx)).Scan(&day)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("GetLastSnapshotDay failed: %w", err)
+	}
+	if day == nil {
+		return time.Time{}, nil
+	}
+
+	return *day, nil
+}
+
+// CreateBalanceSnapshots сохраняет остатки пользователей, команд и системных счетов на конец суток day:
+// текущий остаток за вычетом операций, совершённых начиная с dayEnd.
+// Существующие снимки не перезаписываются, поэтому параллельный запуск безопасен.
+func (r *repository) CreateBalanceSnapshots(ctx context.Context, day, dayEnd time.Time) (int64, error) {
+	query := `
+		INSERT INTO
+			shop."balance_snapshot" (balance_id, day, amount)
+		SELECT
+			b.id,
+			$1::DATE,
+			b.amount - COALESCE(SUM(
+				CASE WHEN bh.recipient = o.name THEN bh.transaction_amount ELSE -bh.transaction_amount END
+			), 0)
+		FROM
+			shop."balance" b
+		INNER JOIN (
+			SELECT w.id AS balance_id, u.username AS name, u.created_at FROM shop."user" u
+			INNER JOIN shop."balance" w ON w.user_id = u.id
+			UNION ALL
+			SELECT sa.balance_id, sa.name, sa.created_at FROM shop."system_account" sa
+			UNION ALL
+			SELECT t.balance_id, 'team:' || t.name, t.created_at FROM shop."team" t
+		) o
+		ON
+			o.balance_id = b.id
+		LEFT JOIN
+			shop."balance_history" bh
+		ON
+			bh.balance_id = b.id AND bh.created_at >= $2
+		WHERE
+			b.org_id = $3 AND o.created_at < $2
+		GROUP BY
+			b.id
+		ON CONFLICT (balance_id, day) DO NOTHING
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, day, dayEnd, orgID(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("CreateBalanceSnapshots failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+// GetNearestSnapshot возвращает последний снимок баланса пользователя не позже суток maxDay.
+// Если снимка нет, возвращается пустой снимок.
+func (r *repository) GetNearestSnapshot(ctx context.Context, username string, maxDay time.Time) (models.BalanceSnapshot, error) {
+	snapshot := models.BalanceSnapshot{}
+
+	query := `
+		SELECT
+			s.balance_id,
+			s.day,
+			s.amount
+		FROM
+			shop."balance_snapshot" s
+		INNER JOIN
+			shop."user" u
+		ON
+			u.balance_id = s.balance_id
+		WHERE
+			u.org_id = $3 AND u.username = $1 AND s.day <= $2
+		ORDER BY
+			s.day DESC
+		LIMIT 1
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, username, maxDay, orgID(ctx)).Scan(&snapshot.BalanceID, &snapshot.Day, &snapshot.Amount)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.BalanceSnapshot{}, nil
+		}
+		return models.BalanceSnapshot{}, fmt.Errorf("GetNearestSnapshot failed: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// GetBalanceChange возвращает изменение баланса пользователя username за [from, to) и количество операций
+func (r *repository) GetBalanceChange(ctx context.Context, balanceID int64, username string, from, to time.Time) (models.BalanceChange, error) {
+	change := models.BalanceChange{}
+
+	query := `
+		SELECT
+			COALESCE(SUM(
+				CASE WHEN bh.recipient = $2 THEN bh.transaction_amount ELSE -bh.transaction_amount END
+			), 0),
+			COUNT(*)
+		FROM
+			shop."balance_history" bh
+		WHERE
+			bh.balance_id = $1 AND bh.org_id = $5 AND bh.created_at >= $3 AND bh.created_at < $4
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, balanceID, username, from, to, orgID(ctx)).Scan(&change.Amount, &change.Count)
+	if err != nil {
+		return change, fmt.Errorf("GetBalanceChange failed: %w", err)
+	}
+
+	return change, nil
+}
@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Statement
+// GetStatementOpening возвращает баланс пользователя и его значение на момент from:
+// текущий остаток за вычетом операций, совершённых начиная с from.
+// Если пользователь не найден, возвращается пустой результат.
+func (r *repository) GetStatementOpening(ctx context.Context, username string, from time.Time) (models.StatementOpening, error) {
+	opening := models.StatementOpening{}
+
+	query := `
+		SELECT
+			b.id,
+			u.created_at,
+			b.amount - COALESCE(SUM(
+				CASE WHEN bh.recipient = u.username THEN bh.transaction_amount ELSE -bh.transaction_amount END
+			), 0)
+		FROM
+			shop."user" u
+		INNER JOIN
+			shop."balance" b
+		ON
+			b.id = u.balance_id
+		LEFT JOIN
+			shop."balance_history" bh
+		ON
+			bh.balance_id = b.id AND bh.created_at >= $2
+		WHERE
+			u.org_id = $3 AND u.username = $1
+		GROUP BY
+			b.id, u.created_at
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, username, from, orgID(ctx)).Scan(&opening.BalanceID, &opening.CreatedAt, &opening.Amount)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return opening, nil
+		}
+		return opening, fmt.Errorf("GetStatementOpening failed: %w", err)
+	}
+
+	return opening, nil
+}
+
+// StreamBalanceHistory передаёт fn записи истории баланса за [from, to) в хронологическом порядке,
+// не загружая всю историю в память
+func (r *repository) StreamBalanceHistory(ctx context.Context, balanceID int64, from, to time.Time, fn func(entry models.BalanceHistory) error) error {
+	query := `
+		SELECT
+			bh.id,
+			bh.balance_id,
+			bh.transaction_amount,
+			bh.sender,
+			bh.recipient,
+			bh.type,
+			bh.reason,
+			bh.reversal_of,
+			bh.actor,
+			bh.merch_name,
+			bh.kudos_id,
+			bh.transfer_id,
+			bh.currency,
+			EXISTS (SELECT 1 FROM shop."balance_history" r WHERE r.reversal_of = bh.id) AS reversed,
+			bh.deleted_at,
+			bh.created_at
+		FROM
+			shop."balance_history" bh
+		WHERE
+			bh.balance_id = $1 AND bh.org_id = $4 AND bh.created_at >= $2 AND bh.created_at < $3
+		ORDER BY
+			bh.created_at, bh.id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, balanceID, from, to, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to query StreamBalanceHistory: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		bh, err := scanBalanceHistory(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan StreamBalanceHistory: %w", err)
+		}
+		if err := fn(bh.ToModelBalanceHistory()); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read rows StreamBalanceHistory: %w", err)
+	}
+
+	return nil
+}
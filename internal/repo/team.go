@@ -0,0 +1,375 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Team
+// CreateTeam создаёт команду с пустым кошельком, инвентарём и владельцем. Если имя занято, возвращает 0.
+func (r *repository) CreateTeam(ctx context.Context, name string, ownerID int64) (int64, error) {
+	var teamID int64
+
+	query := `
+		WITH b AS (
+			INSERT INTO
+				shop."balance" (org_id, amount)
+			VALUES
+				($3, 0)
+			RETURNING
+				id
+		), t AS (
+			INSERT INTO
+				shop."team" (org_id, name, balance_id)
+			SELECT
+				$3, $1, b.id
+			FROM
+				b
+			ON CONFLICT (org_id, name) DO NOTHING
+			RETURNING
+				id
+		), m AS (
+			INSERT INTO
+				shop."team_member" (team_id, user_id, role, can_spend)
+			SELECT
+				t.id, $2, 'owner', TRUE
+			FROM
+				t
+		), i AS (
+			INSERT INTO
+				shop."inventory" (team_id)
+			SELECT
+				t.id
+			FROM
+				t
+		)
+		SELECT
+			t.id
+		FROM
+			t
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, name, ownerID, orgID(ctx)).Scan(&teamID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("CreateTeam failed: %w", err)
+	}
+
+	return teamID, nil
+}
+
+const teamColumns = `
+			t.id,
+			t.name,
+			t.balance_id,
+			i.id,
+			b.amount,
+			t.created_at
+		FROM
+			shop."team" t
+		INNER JOIN
+			shop."balance" b
+		ON
+			b.id = t.balance_id
+		INNER JOIN
+			shop."inventory" i
+		ON
+			i.team_id = t.id`
+
+func scanTeam(row pgx.Row) (models.TeamDB, error) {
+	tdb := models.TeamDB{}
+	err := row.Scan(
+		&tdb.ID,
+		&tdb.Name,
+		&tdb.BalanceID,
+		&tdb.InventoryID,
+		&tdb.Amount,
+		&tdb.CreatedAt,
+	)
+
+	return tdb, err
+}
+
+// GetTeamByID возвращает пустую команду, если она не найдена
+func (r *repository) GetTeamByID(ctx context.Context, teamID int64) (models.Team, error) {
+	query := `
+		SELECT` + teamColumns + `
+		WHERE
+			t.id = $1 AND t.org_id = $2
+	`
+
+	tdb, err := scanTeam(r.conn(ctx).QueryRow(ctx, query, teamID, orgID(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Team{}, nil
+		}
+		return models.Team{}, fmt.Errorf("GetTeamByID failed: %w", err)
+	}
+
+	return tdb.ToModelTeam(), nil
+}
+
+func (r *repository) GetTeamsByUserID(ctx context.Context, userID int64) ([]models.Team, error) {
+	query := `
+		SELECT` + teamColumns + `
+		INNER JOIN
+			shop."team_member" tm
+		ON
+			tm.team_id = t.id
+		WHERE
+			tm.user_id = $1 AND t.org_id = $2
+		ORDER BY
+			t.id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetTeamsByUserID: %w", err)
+	}
+	defer rows.Close()
+
+	teams := []models.Team{}
+	for rows.Next() {
+		tdb, err := scanTeam(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan GetTeamsByUserID: %w", err)
+		}
+		teams = append(teams, tdb.ToModelTeam())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetTeamsByUserID: %w", err)
+	}
+
+	return teams, nil
+}
+
+// LockTeam блокирует команду, чтобы изменения состава не оставили её без владельца
+func (r *repository) LockTeam(ctx context.Context, teamID int64) error {
+	query := `
+		SELECT
+			t.id
+		FROM
+			shop."team" t
+		WHERE
+			t.id = $1 AND t.org_id = $2
+		FOR UPDATE
+	`
+
+	var id int64
+	err := r.conn(ctx).QueryRow(ctx, query, teamID, orgID(ctx)).Scan(&id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("LockTeam failed: %w", err)
+	}
+
+	return nil
+}
+
+// Team member
+// GetTeamMember возвращает пустого участника, если пользователь не состоит в команде
+func (r *repository) GetTeamMember(ctx context.Context, teamID int64, username string) (models.TeamMember, error) {
+	member := models.TeamMember{}
+
+	query := `
+		SELECT
+			tm.team_id,
+			tm.user_id,
+			u.username,
+			tm.role,
+			tm.can_spend
+		FROM
+			shop."team_member" tm
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = tm.user_id
+		WHERE
+			tm.team_id = $1 AND u.org_id = $3 AND u.username = $2
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, teamID, username, orgID(ctx)).Scan(
+		&member.TeamID,
+		&member.UserID,
+		&member.Username,
+		&member.Role,
+		&member.CanSpend,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.TeamMember{}, nil
+		}
+		return models.TeamMember{}, fmt.Errorf("GetTeamMember failed: %w", err)
+	}
+
+	return member, nil
+}
+
+func (r *repository) GetTeamMembers(ctx context.Context, teamID int64) ([]models.TeamMember, error) {
+	query := `
+		SELECT
+			tm.team_id,
+			tm.user_id,
+			u.username,
+			tm.role,
+			tm.can_spend
+		FROM
+			shop."team_member" tm
+		INNER JOIN
+			shop."user" u
+		ON
+			u.id = tm.user_id
+		WHERE
+			tm.team_id = $1 AND u.org_id = $2
+		ORDER BY
+			tm.created_at, tm.user_id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, teamID, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetTeamMembers: %w", err)
+	}
+	defer rows.Close()
+
+	members := []models.TeamMember{}
+	for rows.Next() {
+		member := models.TeamMember{}
+		if err := rows.Scan(&member.TeamID, &member.UserID, &member.Username, &member.Role, &member.CanSpend); err != nil {
+			return nil, fmt.Errorf("failed to scan GetTeamMembers: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetTeamMembers: %w", err)
+	}
+
+	return members, nil
+}
+
+// UpsertTeamMember добавляет участника или меняет его роль и права.
+// Возвращает false, если пользователь не найден или удалён.
+func (r *repository) UpsertTeamMember(ctx context.Context, member models.TeamMember) (bool, error) {
+	query := `
+		INSERT INTO
+			shop."team_member" (team_id, user_id, role, can_spend)
+		SELECT
+			$1, u.id, $3, $4
+		FROM
+			shop."user" u
+		INNER JOIN
+			shop."team" t
+		ON
+			t.id = $1 AND t.org_id = u.org_id
+		WHERE
+			u.org_id = $5 AND u.username = $2 AND u.deleted_at IS NULL
+		ON CONFLICT (team_id, user_id)
+		DO UPDATE SET role = EXCLUDED.role, can_spend = EXCLUDED.can_spend
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, member.TeamID, member.Username, member.Role, member.CanSpend, orgID(ctx))
+	if err != nil {
+		return false, fmt.Errorf("UpsertTeamMember failed: %w", err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+func (r *repository) DeleteTeamMember(ctx context.Context, teamID, userID int64) error {
+	query := `
+		DELETE FROM
+			shop."team_member" tm
+		USING
+			shop."team" t
+		WHERE
+			t.id = tm.team_id AND tm.team_id = $1 AND tm.user_id = $2 AND t.org_id = $3
+	`
+
+	cmdTag, err := r.conn(ctx).Exec(ctx, query, teamID, userID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("DeleteTeamMember failed: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows deleted DeleteTeamMember")
+	}
+
+	return nil
+}
+
+func (r *repository) CountTeamOwners(ctx context.Context, teamID int64) (int64, error) {
+	var owners int64
+
+	query := `
+		SELECT
+			COUNT(*)
+		FROM
+			shop."team_member" tm
+		INNER JOIN
+			shop."team" t
+		ON
+			t.id = tm.team_id
+		WHERE
+			tm.team_id = $1 AND t.org_id = $2 AND tm.role = 'owner'
+	`
+
+	err := r.conn(ctx).QueryRow(ctx, query, teamID, orgID(ctx)).Scan(&owners)
+	if err != nil {
+		return 0, fmt.Errorf("CountTeamOwners failed: %w", err)
+	}
+
+	return owners, nil
+}
+
+// Team inventory
+func (r *repository) GetInventoryMerchItemsByInventoryID(ctx context.Context, inventoryID int64) ([]models.InventoryMerch, error) {
+	query := `
+		SELECT
+			im.inventory_id,
+			im.merch_id,
+			im.name,
+			im.count,
+			im.deleted_at,
+			im.created_at
+		FROM
+			shop."inventory_merch" im
+		INNER JOIN
+			shop."inventory" i
+		ON
+			i.id = im.inventory_id
+		LEFT JOIN
+			shop."user" u
+		ON
+			u.id = i.user_id
+		LEFT JOIN
+			shop."team" t
+		ON
+			t.id = i.team_id
+		WHERE
+			im.inventory_id = $1 AND COALESCE(u.org_id, t.org_id) = $2 AND im.deleted_at IS NULL
+		ORDER BY
+			im.merch_id
+	`
+
+	rows, err := r.conn(ctx).Query(ctx, query, inventoryID, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GetInventoryMerchItemsByInventoryID: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.InventoryMerch{}
+	for rows.Next() {
+		im := models.InventoryMerchDB{}
+		if err := rows.Scan(&im.InventoryID, &im.MerchID, &im.Name, &im.Count, &im.DeletedAt, &im.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan GetInventoryMerchItemsByInventoryID: %w", err)
+		}
+		items = append(items, im.ToModelInventoryMerch())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows GetInventoryMerchItemsByInventoryID: %w", err)
+	}
+
+	return items, nil
+}
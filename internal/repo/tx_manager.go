@@ -0,0 +1,144 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/metrics"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	txMaxRetries  = 5
+	txBaseBackoff = 10 * time.Millisecond
+	txMaxBackoff  = 500 * time.Millisecond
+
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+type txKey struct{}
+
+// querier общий интерфейс пула и транзакции, позволяет методам репозитория
+// работать как внутри транзакции TxManager, так и без неё
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txManager struct {
+	db *pgxpool.Pool
+}
+
+func NewTxManager(db *pgxpool.Pool) *txManager {
+	return &txManager{db: db}
+}
+
+// Do выполняет fn в одной транзакции БД. Все вызовы репозитория с переданным в fn контекстом
+// используют эту транзакцию. При ошибке сериализации или дедлоке транзакция повторяется
+// с экспоненциальной задержкой, поэтому fn не должна иметь побочных эффектов вне БД.
+// Вложенный вызов Do выполняется в точке сохранения внешней транзакции:
+// ошибка fn откатывает только изменения вложенного вызова.
+func (m *txManager) Do(ctx context.Context, opts models.TxOptions, fn func(ctx context.Context) error) error {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return m.runNested(ctx, tx, fn)
+	}
+
+	backoff := txBaseBackoff
+	for attempt := 0; ; attempt++ {
+		err := m.run(ctx, opts, fn)
+		code, retryable := retryableTxErrCode(err)
+		if !retryable {
+			return err
+		}
+		if attempt >= txMaxRetries {
+			metrics.TxRetriesExhausted.Add(1)
+			return err
+		}
+
+		metrics.TxRetries.Add(code, 1)
+		log.Logger.Warn().Msgf("transaction retry %d: %v", attempt+1, err)
+
+		// полный джиттер, чтобы конкурирующие транзакции не повторялись синхронно
+		delay := time.Duration(rand.Int64N(int64(backoff))) + time.Millisecond
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		backoff = min(backoff*2, txMaxBackoff)
+	}
+}
+
+func (m *txManager) run(ctx context.Context, opts models.TxOptions, fn func(ctx context.Context) error) error {
+	txOpts := pgx.TxOptions{IsoLevel: pgx.TxIsoLevel(opts.IsoLevel)}
+	if opts.ReadOnly {
+		txOpts.AccessMode = pgx.ReadOnly
+	}
+
+	tx, err := m.db.BeginTx(ctx, txOpts)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			log.Logger.Err(rollbackErr).Msg(fmt.Errorf("failed to rollback transaction: %w", err).Error())
+		}
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (m *txManager) runNested(ctx context.Context, tx pgx.Tx, fn func(ctx context.Context) error) error {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, savepoint)); err != nil {
+		if rollbackErr := savepoint.Rollback(ctx); rollbackErr != nil {
+			log.Logger.Err(rollbackErr).Msg(fmt.Errorf("failed to rollback savepoint: %w", err).Error())
+		}
+		return err
+	}
+
+	if err := savepoint.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+
+	return nil
+}
+
+// Retryable true для ошибок сериализации и дедлоков, которые Do повторяет сам
+func (m *txManager) Retryable(err error) bool {
+	_, retryable := retryableTxErrCode(err)
+
+	return retryable
+}
+
+func retryableTxErrCode(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgSerializationFailure, pgDeadlockDetected:
+			return pgErr.Code, true
+		}
+	}
+
+	return "", false
+}
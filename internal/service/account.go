@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Статус аккаунта после действия администратора
+var accountActionStatuses = map[string]string{
+	models.AccountActionFreeze:   models.UserStatusFrozen,
+	models.AccountActionSuspend:  models.UserStatusSuspended,
+	models.AccountActionActivate: models.UserStatusActive,
+}
+
+// Account
+// ChangeAccountStatus замораживает, блокирует или восстанавливает аккаунт с записью причины в аудит
+func (s *service) ChangeAccountStatus(ctx context.Context, qp models.AccountQuery) (models.AccountDTO, error) {
+	status, ok := accountActionStatuses[qp.Action]
+	if !ok || qp.Reason == "" || qp.Username == qp.Admin {
+		return models.AccountDTO{}, errors.New(internalErrors.ErrInvalidAccountReqParams)
+	}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		user, err := getAccountForUpdate(ctx, repo, qp.Username)
+		if err != nil {
+			return err
+		}
+		if user.Status == status {
+			return errors.New(internalErrors.ErrAccountStatusUnchanged)
+		}
+
+		if err := repo.UpdateUserStatus(ctx, user.ID, status); err != nil {
+			return err
+		}
+
+		return repo.CreateAccountAudit(ctx, models.AccountAudit{
+			UserID: user.ID,
+			Action: qp.Action,
+			Reason: qp.Reason,
+			Actor:  qp.Admin,
+		})
+	})
+	if err != nil {
+		return models.AccountDTO{}, err
+	}
+
+	return models.AccountDTO{Username: qp.Username, Status: status}, nil
+}
+
+// OffboardAccount мягко удаляет аккаунт: вход запрещается, а имя нельзя занять заново.
+// Активные резервы освобождаются, остаток монет по запросу переводится в казначейство.
+func (s *service) OffboardAccount(ctx context.Context, qp models.AccountQuery) (models.AccountDTO, error) {
+	if qp.Reason == "" || qp.Username == qp.Admin {
+		return models.AccountDTO{}, errors.New(internalErrors.ErrInvalidAccountReqParams)
+	}
+
+	accountDTO := models.AccountDTO{Username: qp.Username, Offboarded: true}
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		user, err := getAccountForUpdate(ctx, repo, qp.Username)
+		if err != nil {
+			return err
+		}
+		accountDTO.Status = user.Status
+
+		if err := repo.ReleaseHoldsByOwner(ctx, user.ID); err != nil {
+			return err
+		}
+
+		if qp.SweepCoins {
+			accountDTO.SweptAmount, err = s.sweepToTreasury(ctx, repo, user, qp.Reason)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := repo.OffboardUser(ctx, user.ID); err != nil {
+			return err
+		}
+
+		return repo.CreateAccountAudit(ctx, models.AccountAudit{
+			UserID:      user.ID,
+			Action:      models.AccountActionOffboard,
+			Reason:      qp.Reason,
+			Actor:       qp.Admin,
+			SweptAmount: accountDTO.SweptAmount,
+		})
+	})
+	if err != nil {
+		return models.AccountDTO{}, err
+	}
+
+	return accountDTO, nil
+}
+
+// sweepToTreasury переводит весь баланс монет пользователя в казначейство и возвращает сумму.
+// Кошельки в других валютах остаются на удалённом аккаунте.
+func (s *service) sweepToTreasury(ctx context.Context, repo Repository, user models.User, reason string) (int64, error) {
+	treasuryBalanceID, err := repo.GetSystemBalanceID(ctx, models.TreasuryAccount, models.CurrencyCoins)
+	if err != nil {
+		return 0, err
+	}
+	if err := repo.LockBalances(ctx, user.BalanceID, treasuryBalanceID); err != nil {
+		return 0, err
+	}
+
+	balance, err := repo.GetBalanceByUserID(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+	if balance.Amount == 0 {
+		return 0, nil
+	}
+
+	if err := repo.DebitBalanceIgnoringHolds(ctx, balance.ID, balance.Amount); err != nil {
+		return 0, err
+	}
+	if _, err := repo.SpendBalanceLots(ctx, balance.ID, balance.Amount); err != nil {
+		return 0, err
+	}
+	if err := repo.CreditBalance(ctx, treasuryBalanceID, balance.Amount); err != nil {
+		return 0, err
+	}
+
+	err = createTransferHistory(ctx, repo, balance.ID, treasuryBalanceID, models.BalanceHistory{
+		TransactionAmount: balance.Amount,
+		Sender:            user.Username,
+		Recipient:         models.TreasuryAccount,
+		Type:              models.HistoryTypeSweep,
+		Reason:            reason,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return balance.Amount, nil
+}
+
+func (s *service) GetAccountAudit(ctx context.Context, username string) ([]models.AccountAuditDTO, error) {
+	audit, err := s.repo.GetAccountAudit(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	auditDTO := make([]models.AccountAuditDTO, 0, len(audit))
+	for _, entry := range audit {
+		auditDTO = append(auditDTO, entry.ToModelAccountAuditDTO())
+	}
+
+	return auditDTO, nil
+}
+
+// getAccountForUpdate блокирует аккаунт, удалённый аккаунт считается ненайденным
+func getAccountForUpdate(ctx context.Context, repo Repository, username string) (models.User, error) {
+	user, err := repo.GetUserForUpdate(ctx, username)
+	if err != nil {
+		return models.User{}, err
+	}
+	if user.ID == 0 || user.Deleted {
+		return models.User{}, errors.New(internalErrors.ErrUserNotFound)
+	}
+
+	return user, nil
+}
+
+// checkAccountCanSpend запрещает отправку монет и покупки замороженным, заблокированным,
+// удалённым аккаунтам и аккаунтам, заблокированным по результатам проверки на мошенничество
+func checkAccountCanSpend(ctx context.Context, repo Repository, username string) error {
+	state, err := repo.GetAccountState(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case state.Deleted:
+		return errors.New(internalErrors.ErrAccountOffboarded)
+	case state.Status == models.UserStatusSuspended:
+		return errors.New(internalErrors.ErrAccountSuspended)
+	case state.Status == models.UserStatusFrozen:
+		return errors.New(internalErrors.ErrAccountFrozen)
+	case state.FraudHold:
+		return errors.New(internalErrors.ErrAccountOnFraudHold)
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func Test_service_ChangeAccountStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       models.User
+		qp         models.AccountQuery
+		wantErr    string
+		wantStatus string
+	}{
+		{
+			name:       "success_-_account_frozen",
+			user:       models.User{ID: 2, Username: "user2", Status: models.UserStatusActive},
+			qp:         models.AccountQuery{Admin: "admin", Username: "user2", Action: models.AccountActionFreeze, Reason: "compromised"},
+			wantStatus: models.UserStatusFrozen,
+		},
+		{
+			name:       "success_-_suspended_account_activated",
+			user:       models.User{ID: 2, Username: "user2", Status: models.UserStatusSuspended},
+			qp:         models.AccountQuery{Admin: "admin", Username: "user2", Action: models.AccountActionActivate, Reason: "password reset"},
+			wantStatus: models.UserStatusActive,
+		},
+		{
+			name:    "error_-_status_unchanged",
+			user:    models.User{ID: 2, Username: "user2", Status: models.UserStatusFrozen},
+			qp:      models.AccountQuery{Admin: "admin", Username: "user2", Action: models.AccountActionFreeze, Reason: "compromised"},
+			wantErr: internalErrors.ErrAccountStatusUnchanged,
+		},
+		{
+			name:    "error_-_reason_required",
+			qp:      models.AccountQuery{Admin: "admin", Username: "user2", Action: models.AccountActionSuspend},
+			wantErr: internalErrors.ErrInvalidAccountReqParams,
+		},
+		{
+			name:    "error_-_admin_can't_change_own_account",
+			qp:      models.AccountQuery{Admin: "admin", Username: "admin", Action: models.AccountActionSuspend, Reason: "test"},
+			wantErr: internalErrors.ErrInvalidAccountReqParams,
+		},
+		{
+			name:    "error_-_offboarded_account_not_found",
+			user:    models.User{ID: 2, Username: "user2", Status: models.UserStatusActive, Deleted: true},
+			qp:      models.AccountQuery{Admin: "admin", Username: "user2", Action: models.AccountActionFreeze, Reason: "compromised"},
+			wantErr: internalErrors.ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := models.AccountAudit{}
+			s := &service{
+				repo: &MockRepository{
+					GetUserForUpdateFunc: func(ctx context.Context, username string) (models.User, error) {
+						return tt.user, nil
+					},
+					UpdateUserStatusFunc: func(ctx context.Context, userID int64, status string) error {
+						return nil
+					},
+					CreateAccountAuditFunc: func(ctx context.Context, a models.AccountAudit) error {
+						audit = a
+						return nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.ChangeAccountStatus(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.ChangeAccountStatus() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.ChangeAccountStatus() error = %v", err)
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("service.ChangeAccountStatus() status = %v, want %v", got.Status, tt.wantStatus)
+			}
+			if audit.Action != tt.qp.Action || audit.Reason != tt.qp.Reason || audit.Actor != tt.qp.Admin {
+				t.Errorf("service.ChangeAccountStatus() audit = %v", audit)
+			}
+		})
+	}
+}
+
+func Test_service_OffboardAccount(t *testing.T) {
+	tests := []struct {
+		name       string
+		sweepCoins bool
+		wantSwept  int64
+	}{
+		{name: "success_-_coins_swept_to_treasury", sweepCoins: true, wantSwept: 700},
+		{name: "success_-_coins_kept", sweepCoins: false, wantSwept: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var credited int64
+			offboarded, holdsReleased := false, false
+			audit := models.AccountAudit{}
+			s := &service{
+				repo: &MockRepository{
+					NextTransferIDFunc: nextTransferID,
+					GetUserForUpdateFunc: func(ctx context.Context, username string) (models.User, error) {
+						return models.User{ID: 2, BalanceID: 2, Username: "user2", Status: models.UserStatusFrozen}, nil
+					},
+					ReleaseHoldsByOwnerFunc: func(ctx context.Context, ownerID int64) error {
+						holdsReleased = true
+						return nil
+					},
+					GetSystemBalanceIDFunc: func(ctx context.Context, name, currency string) (int64, error) {
+						return 100, nil
+					},
+					LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+						return nil
+					},
+					GetBalanceByUserIDFunc: func(ctx context.Context, userID int64) (models.Balance, error) {
+						return models.Balance{ID: 2, Amount: 700}, nil
+					},
+					DebitBalanceIgnoringHoldsFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return nil
+					},
+					SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+						return []models.BalanceLot{{BalanceID: balanceID, Amount: amount, GrantedAt: time.Now()}}, nil
+					},
+					CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						credited += amount
+						return nil
+					},
+					CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+						return nil
+					},
+					OffboardUserFunc: func(ctx context.Context, userID int64) error {
+						offboarded = true
+						return nil
+					},
+					CreateAccountAuditFunc: func(ctx context.Context, a models.AccountAudit) error {
+						audit = a
+						return nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.OffboardAccount(context.Background(), models.AccountQuery{
+				Admin:      "admin",
+				Username:   "user2",
+				Reason:     "left the company",
+				SweepCoins: tt.sweepCoins,
+			})
+			if err != nil {
+				t.Fatalf("service.OffboardAccount() error = %v", err)
+			}
+			if !offboarded || !holdsReleased || !got.Offboarded {
+				t.Errorf("service.OffboardAccount() offboarded = %v, holds released = %v", offboarded, holdsReleased)
+			}
+			if got.SweptAmount != tt.wantSwept || credited != tt.wantSwept || audit.SweptAmount != tt.wantSwept {
+				t.Errorf("service.OffboardAccount() swept = %v, credited = %v, audit = %v, want %v", got.SweptAmount, credited, audit.SweptAmount, tt.wantSwept)
+			}
+		})
+	}
+}
+
+func Test_checkAccountCanSpend(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   models.AccountState
+		wantErr string
+	}{
+		{name: "active", state: models.AccountState{Status: models.UserStatusActive}},
+		{name: "frozen", state: models.AccountState{Status: models.UserStatusFrozen}, wantErr: internalErrors.ErrAccountFrozen},
+		{name: "suspended", state: models.AccountState{Status: models.UserStatusSuspended}, wantErr: internalErrors.ErrAccountSuspended},
+		{name: "offboarded", state: models.AccountState{Status: models.UserStatusActive, Deleted: true}, wantErr: internalErrors.ErrAccountOffboarded},
+		{name: "fraud_hold", state: models.AccountState{Status: models.UserStatusActive, FraudHold: true}, wantErr: internalErrors.ErrAccountOnFraudHold},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{
+				GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+					return tt.state, nil
+				},
+			}
+
+			err := checkAccountCanSpend(context.Background(), repo, "user1")
+			if (err == nil && tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Errorf("checkAccountCanSpend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Achievement
+// GetProfile возвращает профиль пользователя организации с выданными достижениями
+func (s *service) GetProfile(ctx context.Context, username string) (models.ProfileDTO, error) {
+	profile := models.ProfileDTO{Username: username}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted, ReadOnly: true}, func(ctx context.Context, repo Repository) error {
+		exist, err := repo.IsUserExist(ctx, username)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return errors.New(internalErrors.ErrUserNotFound)
+		}
+
+		profile.Achievements, err = s.getAchievements(ctx, repo, username)
+		return err
+	})
+	if err != nil {
+		return models.ProfileDTO{}, err
+	}
+
+	return profile, nil
+}
+
+func (s *service) getAchievements(ctx context.Context, repo Repository, username string) ([]models.AchievementDTO, error) {
+	achievements, err := repo.GetUserAchievements(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	achievementsDTO := make([]models.AchievementDTO, 0, len(achievements))
+	for _, achievement := range achievements {
+		achievementsDTO = append(achievementsDTO, achievement.ToModelAchievementDTO())
+	}
+
+	return achievementsDTO, nil
+}
+
+// RecordSignIn учитывает вход пользователя и выдаёт достижения за входы
+func (s *service) RecordSignIn(ctx context.Context, username string) error {
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		if err := repo.IncrementUserActivity(ctx, username, models.ActivitySignIns); err != nil {
+			return err
+		}
+
+		return s.awardAchievements(ctx, repo, username, models.AchievementEventSignIn)
+	})
+}
+
+// recordTransferAchievements учитывает перевод монет между пользователями и выдаёт достижения обеим сторонам
+func (s *service) recordTransferAchievements(ctx context.Context, repo Repository, entry models.BalanceHistory) error {
+	if entry.Currency != models.CurrencyCoins {
+		return nil
+	}
+
+	if err := repo.IncrementUserActivity(ctx, entry.Sender, models.ActivityTransfersSent); err != nil {
+		return err
+	}
+	if err := s.awardAchievements(ctx, repo, entry.Sender, models.AchievementEventTransfer); err != nil {
+		return err
+	}
+
+	return s.awardAchievements(ctx, repo, entry.Recipient, models.AchievementEventTransfer)
+}
+
+// recordPurchaseAchievements учитывает покупку и выдаёт достижения покупателю
+func (s *service) recordPurchaseAchievements(ctx context.Context, repo Repository, username string) error {
+	if err := repo.IncrementUserActivity(ctx, username, models.ActivityPurchases); err != nil {
+		return err
+	}
+
+	return s.awardAchievements(ctx, repo, username, models.AchievementEventPurchase)
+}
+
+// awardAchievements выдаёт достижения события, порог которых достигнут, и начисляет награды из казначейства.
+// Вызывается в транзакции события: повтор транзакции откатывает и выдачу, а уже выданное достижение
+// не выдаётся и не награждается повторно.
+func (s *service) awardAchievements(ctx context.Context, repo Repository, username, event string) error {
+	achievements, err := repo.GetDueAchievements(ctx, username, event)
+	if err != nil {
+		return err
+	}
+
+	for _, achievement := range achievements {
+		awarded, err := repo.AwardAchievement(ctx, username, achievement.ID)
+		if err != nil {
+			return err
+		}
+		if !awarded {
+			continue
+		}
+
+		if achievement.Reward > 0 {
+			recipients := []models.GrantRecipient{{Username: username, Amount: achievement.Reward}}
+			err := s.creditFromTreasury(ctx, repo, recipients, models.CurrencyCoins, models.HistoryTypeAchievement, achievement.Title)
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Logger.Info().Msgf("user %s earned achievement %s", username, achievement.Code)
+	}
+
+	return nil
+}
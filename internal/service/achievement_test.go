@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/go-openapi/strfmt"
+)
+
+func incrementUserActivityNoop(ctx context.Context, username, activity string) error {
+	return nil
+}
+
+func getNoDueAchievements(ctx context.Context, username, event string) ([]models.Achievement, error) {
+	return []models.Achievement{}, nil
+}
+
+func getNoUserAchievements(ctx context.Context, username string) ([]models.UserAchievement, error) {
+	return []models.UserAchievement{}, nil
+}
+
+func Test_service_awardAchievements(t *testing.T) {
+	firstTransfer := models.Achievement{ID: 1, Code: "first-transfer", Title: "Первый перевод", Event: models.AchievementEventTransfer, Metric: "transfers_sent", Threshold: 1}
+	thanked := models.Achievement{ID: 2, Code: "thanked-10", Title: "Щедрая душа", Event: models.AchievementEventTransfer, Metric: "people_thanked", Threshold: 10, Reward: 50}
+
+	tests := []struct {
+		name        string
+		due         []models.Achievement
+		awarded     map[int64]bool
+		wantAwarded []int64
+		wantReward  []models.BalanceHistory
+		wantErr     bool
+	}{
+		{
+			name:        "success_-_achievements_awarded_and_rewarded",
+			due:         []models.Achievement{firstTransfer, thanked},
+			awarded:     map[int64]bool{1: true, 2: true},
+			wantAwarded: []int64{1, 2},
+			wantReward: []models.BalanceHistory{
+				{BalanceID: 100, TransactionAmount: 50, Sender: models.TreasuryAccount, Recipient: "user1", Type: models.HistoryTypeAchievement, Reason: "Щедрая душа", TransferID: 1},
+				{BalanceID: 5, TransactionAmount: 50, Sender: models.TreasuryAccount, Recipient: "user1", Type: models.HistoryTypeAchievement, Reason: "Щедрая душа", TransferID: 1},
+			},
+		},
+		{
+			name:        "success_-_already_awarded_by_concurrent_tx_is_not_rewarded_twice",
+			due:         []models.Achievement{thanked},
+			awarded:     map[int64]bool{},
+			wantAwarded: []int64{2},
+		},
+		{
+			name:        "success_-_nothing_due",
+			wantAwarded: []int64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAwarded := []int64{}
+			gotReward := []models.BalanceHistory{}
+			repo := &MockRepository{
+				NextTransferIDFunc: nextTransferID,
+				GetDueAchievementsFunc: func(ctx context.Context, username, event string) ([]models.Achievement, error) {
+					return tt.due, nil
+				},
+				AwardAchievementFunc: func(ctx context.Context, username string, achievementID int64) (bool, error) {
+					gotAwarded = append(gotAwarded, achievementID)
+					return tt.awarded[achievementID], nil
+				},
+				GetSystemBalanceIDFunc: func(ctx context.Context, name, currency string) (int64, error) {
+					return 100, nil
+				},
+				GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+					return models.Balance{ID: 5}, nil
+				},
+				LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+					return nil
+				},
+				CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+					return nil
+				},
+				CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+					return nil
+				},
+				CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+					gotReward = append(gotReward, entry)
+					return nil
+				},
+				DebitSystemBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+					return nil
+				},
+			}
+			s := &service{repo: repo}
+
+			err := s.awardAchievements(context.Background(), repo, "user1", models.AchievementEventTransfer)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("service.awardAchievements() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gotAwarded, tt.wantAwarded) {
+				t.Errorf("service.awardAchievements() awarded = %v, want %v", gotAwarded, tt.wantAwarded)
+			}
+			if tt.wantReward == nil {
+				tt.wantReward = []models.BalanceHistory{}
+			}
+			if !reflect.DeepEqual(gotReward, tt.wantReward) {
+				t.Errorf("service.awardAchievements() reward = %v, want %v", gotReward, tt.wantReward)
+			}
+		})
+	}
+}
+
+func Test_service_recordTransferAchievements(t *testing.T) {
+	tests := []struct {
+		name          string
+		entry         models.BalanceHistory
+		wantActivity  []string
+		wantEvaluated []string
+	}{
+		{
+			name:          "success_-_both_sides_evaluated",
+			entry:         models.BalanceHistory{Sender: "user1", Recipient: "user2", Currency: models.CurrencyCoins},
+			wantActivity:  []string{"user1"},
+			wantEvaluated: []string{"user1", "user2"},
+		},
+		{
+			name:          "success_-_other_currency_skipped",
+			entry:         models.BalanceHistory{Sender: "user1", Recipient: "user2", Currency: "kudos"},
+			wantActivity:  []string{},
+			wantEvaluated: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotActivity := []string{}
+			gotEvaluated := []string{}
+			repo := &MockRepository{
+				IncrementUserActivityFunc: func(ctx context.Context, username, activity string) error {
+					if activity != models.ActivityTransfersSent {
+						t.Errorf("IncrementUserActivity() activity = %v", activity)
+					}
+					gotActivity = append(gotActivity, username)
+					return nil
+				},
+				GetDueAchievementsFunc: func(ctx context.Context, username, event string) ([]models.Achievement, error) {
+					if event != models.AchievementEventTransfer {
+						t.Errorf("GetDueAchievements() event = %v", event)
+					}
+					gotEvaluated = append(gotEvaluated, username)
+					return []models.Achievement{}, nil
+				},
+			}
+			s := &service{repo: repo}
+
+			if err := s.recordTransferAchievements(context.Background(), repo, tt.entry); err != nil {
+				t.Fatalf("service.recordTransferAchievements() error = %v", err)
+			}
+			if !reflect.DeepEqual(gotActivity, tt.wantActivity) {
+				t.Errorf("service.recordTransferAchievements() activity = %v, want %v", gotActivity, tt.wantActivity)
+			}
+			if !reflect.DeepEqual(gotEvaluated, tt.wantEvaluated) {
+				t.Errorf("service.recordTransferAchievements() evaluated = %v, want %v", gotEvaluated, tt.wantEvaluated)
+			}
+		})
+	}
+}
+
+func Test_service_GetProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		exist   bool
+		want    models.ProfileDTO
+		wantErr string
+	}{
+		{
+			name:  "success_-_profile_with_achievements",
+			exist: true,
+			want: models.ProfileDTO{
+				Username: "user2",
+				Achievements: []models.AchievementDTO{
+					{Code: "first-purchase", Title: "Первая покупка", AwardedAt: strfmt.DateTime(time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC))},
+				},
+			},
+		},
+		{
+			name:    "error_-_user_not_found",
+			wantErr: internalErrors.ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{
+				repo: &MockRepository{
+					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+						return tt.exist, nil
+					},
+					GetUserAchievementsFunc: func(ctx context.Context, username string) ([]models.UserAchievement, error) {
+						return []models.UserAchievement{
+							{Code: "first-purchase", Title: "Первая покупка", AwardedAt: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)},
+						}, nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.GetProfile(context.Background(), "user2")
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.GetProfile() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.GetProfile() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("service.GetProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+const maxBulkTransfers = 500
+
+// Bulk send coins
+// BulkSendCoins выполняет все переводы в одной транзакции либо ни одного.
+// При ошибках валидации возвращает ErrInvalidBulkTransfers и список неверных переводов.
+func (s *service) BulkSendCoins(ctx context.Context, qp models.BulkCoinsQuery) (models.BulkSendCoinsResultDTO, error) {
+	result := models.BulkSendCoinsResultDTO{
+		Transfers: []models.BulkTransferItem{},
+		Invalid:   []models.BulkTransferInvalidDTO{},
+	}
+	if len(qp.Transfers) == 0 {
+		return result, errors.New(internalErrors.ErrInvalidSendCoinsReqParams)
+	}
+	if len(qp.Transfers) > maxBulkTransfers {
+		return result, errors.New(internalErrors.ErrTooManyBulkTransfers)
+	}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		// проверка всех получателей до любых списаний
+		recipientBalanceIDs := map[string]int64{}
+		for i, item := range qp.Transfers {
+			invalid := models.BulkTransferInvalidDTO{Index: i, ToUser: item.Recipient}
+			switch {
+			case item.Amount < 1:
+				invalid.Error = internalErrors.ErrInvalidSendCoinsReqParams
+			case item.Recipient == qp.Sender:
+				invalid.Error = internalErrors.ErrInvalidRecipientYourself
+			default:
+				if _, ok := recipientBalanceIDs[item.Recipient]; ok {
+					break
+				}
+
+				validRecipient, err := repo.IsUserExist(ctx, item.Recipient)
+				if err != nil {
+					return err
+				}
+				if !validRecipient {
+					invalid.Error = internalErrors.ErrInvalidRecipient
+					break
+				}
+
+				recipientBalanceIDs[item.Recipient], err = repo.GetBalanceIDByUsername(ctx, item.Recipient)
+				if err != nil {
+					return err
+				}
+			}
+
+			if invalid.Error != "" {
+				result.Invalid = append(result.Invalid, invalid)
+				continue
+			}
+			result.Transfers = append(result.Transfers, item)
+			result.Total += item.Amount
+		}
+		if len(result.Invalid) > 0 {
+			return errors.New(internalErrors.ErrInvalidBulkTransfers)
+		}
+
+		senderBalance, err := repo.GetBalanceByUserID(ctx, qp.UserID)
+		if err != nil {
+			return err
+		}
+		if senderBalance.Amount < result.Total {
+			return errors.New(internalErrors.ErrNotEnoughCoins)
+		}
+
+		balanceIDs := make([]int64, 0, len(recipientBalanceIDs)+1)
+		balanceIDs = append(balanceIDs, senderBalance.ID)
+		for _, balanceID := range recipientBalanceIDs {
+			balanceIDs = append(balanceIDs, balanceID)
+		}
+		if err := repo.LockBalances(ctx, balanceIDs...); err != nil {
+			return err
+		}
+
+		for _, item := range result.Transfers {
+			err := s.transfer(ctx, repo, senderBalance.ID, recipientBalanceIDs[item.Recipient], models.BalanceHistory{
+				TransactionAmount: item.Amount,
+				Sender:            qp.Sender,
+				Recipient:         item.Recipient,
+				Type:              models.HistoryTypeTransfer,
+				Reason:            item.Memo,
+				Currency:          models.CurrencyCoins,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
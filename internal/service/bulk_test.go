@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func Test_service_BulkSendCoins(t *testing.T) {
+	tests := []struct {
+		name        string
+		transfers   []models.BulkTransferItem
+		wantErr     string
+		wantInvalid int
+		wantCredits int64
+	}{
+		{
+			name: "success_-_all_transfers_executed",
+			transfers: []models.BulkTransferItem{
+				{Recipient: "user2", Amount: 100, Memo: "thanks"},
+				{Recipient: "user3", Amount: 200},
+				{Recipient: "user2", Amount: 50},
+			},
+			wantCredits: 350,
+		},
+		{
+			name: "error_-_invalid_recipients_reported",
+			transfers: []models.BulkTransferItem{
+				{Recipient: "user2", Amount: 100},
+				{Recipient: "user_invalid", Amount: 100},
+				{Recipient: "user1", Amount: 100},
+				{Recipient: "user3", Amount: 0},
+			},
+			wantErr:     internalErrors.ErrInvalidBulkTransfers,
+			wantInvalid: 3,
+		},
+		{
+			name: "error_-_total_exceeds_balance",
+			transfers: []models.BulkTransferItem{
+				{Recipient: "user2", Amount: 600},
+				{Recipient: "user3", Amount: 600},
+			},
+			wantErr: internalErrors.ErrNotEnoughCoins,
+		},
+		{
+			name:    "error_-_no_transfers",
+			wantErr: internalErrors.ErrInvalidSendCoinsReqParams,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var credited int64
+			s := &service{
+				repo: &MockRepository{
+					NextTransferIDFunc:             nextTransferID,
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
+					RecordLeaderboardTransferFunc:  recordLeaderboardTransferNoop,
+					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+						return username != "user_invalid", nil
+					},
+					GetBalanceIDByUsernameFunc: func(ctx context.Context, username string) (int64, error) {
+						return int64(len(username)), nil
+					},
+					GetBalanceByUserIDFunc: func(ctx context.Context, userID int64) (models.Balance, error) {
+						return models.Balance{ID: 1, Amount: 1000}, nil
+					},
+					GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+						return models.AccountState{Status: models.UserStatusActive}, nil
+					},
+					LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+						return nil
+					},
+					DebitBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return nil
+					},
+					SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+						return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+					},
+					CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						credited += amount
+						return nil
+					},
+					CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+						return nil
+					},
+					CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+						return nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.BulkSendCoins(context.Background(), models.BulkCoinsQuery{UserID: 1, Sender: "user1", Transfers: tt.transfers})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.BulkSendCoins() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if len(got.Invalid) != tt.wantInvalid {
+					t.Errorf("service.BulkSendCoins() invalid = %v, want %v", got.Invalid, tt.wantInvalid)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.BulkSendCoins() error = %v", err)
+			}
+			if got.Total != tt.wantCredits || credited != tt.wantCredits {
+				t.Errorf("service.BulkSendCoins() total = %v, credited = %v, want %v", got.Total, credited, tt.wantCredits)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Check in
+// CheckIn отмечает пользователя за текущие сутки компании и начисляет награду с прибавкой за серию
+func (s *service) CheckIn(ctx context.Context, username string) (models.CheckInDTO, error) {
+	return s.checkIn(ctx, username, time.Now())
+}
+
+func (s *service) checkIn(ctx context.Context, username string, now time.Time) (models.CheckInDTO, error) {
+	if s.cfg.CheckIn.Reward <= 0 {
+		return models.CheckInDTO{}, errors.New(internalErrors.ErrCheckInDisabled)
+	}
+
+	checkIn := models.CheckIn{Day: checkInDay(now, s.cfg.CheckIn.Location()), Streak: 1}
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		last, err := repo.GetLastCheckIn(ctx, username)
+		if err != nil {
+			return err
+		}
+		if last.Day.Equal(checkIn.Day) {
+			return errors.New(internalErrors.ErrAlreadyCheckedIn)
+		}
+		// серия продолжается, только если пользователь отметился накануне
+		if last.Day.Equal(checkIn.Day.AddDate(0, 0, -1)) {
+			checkIn.Streak = last.Streak + 1
+		}
+		checkIn.Reward = s.checkInReward(checkIn.Streak)
+
+		// конкурентная отметка за те же сутки ждёт фиксации первой и ничего не вставляет
+		created, err := repo.CreateCheckIn(ctx, username, checkIn)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return errors.New(internalErrors.ErrAlreadyCheckedIn)
+		}
+
+		recipients := []models.GrantRecipient{{Username: username, Amount: checkIn.Reward}}
+		reason := fmt.Sprintf("День %d подряд", checkIn.Streak)
+
+		return s.creditFromTreasury(ctx, repo, recipients, models.CurrencyCoins, models.HistoryTypeCheckIn, reason)
+	})
+	if err != nil {
+		return models.CheckInDTO{}, err
+	}
+
+	return models.CheckInDTO{
+		Day:        checkIn.Day.Format(models.CheckInDayLayout),
+		Streak:     checkIn.Streak,
+		Reward:     checkIn.Reward,
+		NextReward: s.checkInReward(checkIn.Streak + 1),
+	}, nil
+}
+
+// checkInReward награда за день серии: базовая и прибавка за каждый день после первого, не больше предела
+func (s *service) checkInReward(streak int) int64 {
+	bonus := int64(streak-1) * s.cfg.CheckIn.StreakBonus
+	if s.cfg.CheckIn.StreakBonusMax > 0 {
+		bonus = min(bonus, s.cfg.CheckIn.StreakBonusMax)
+	}
+
+	return s.cfg.CheckIn.Reward + max(bonus, 0)
+}
+
+// checkInDay сутки момента now в часовом поясе компании, представленные полночью в UTC
+func checkInDay(now time.Time, loc *time.Location) time.Time {
+	year, month, day := now.In(loc).Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func Test_checkInDay(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		loc  *time.Location
+		want time.Time
+	}{
+		{
+			name: "success_-_utc_day",
+			now:  time.Date(2026, 10, 19, 22, 30, 0, 0, time.UTC),
+			loc:  time.UTC,
+			want: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "success_-_company_day_already_started",
+			now:  time.Date(2026, 10, 19, 22, 30, 0, 0, time.UTC),
+			loc:  moscow,
+			want: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "success_-_company_day_not_started_yet",
+			now:  time.Date(2026, 10, 19, 20, 59, 0, 0, time.UTC),
+			loc:  moscow,
+			want: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkInDay(tt.now, tt.loc); !got.Equal(tt.want) {
+				t.Errorf("checkInDay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_service_checkInReward(t *testing.T) {
+	s := &service{cfg: config.Config{CheckIn: config.CheckIn{Reward: 5, StreakBonus: 2, StreakBonusMax: 6}}}
+
+	for streak, want := range map[int]int64{1: 5, 2: 7, 3: 9, 4: 11, 5: 11, 30: 11} {
+		if got := s.checkInReward(streak); got != want {
+			t.Errorf("service.checkInReward(%d) = %v, want %v", streak, got, want)
+		}
+	}
+}
+
+func Test_service_checkIn(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	today := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		cfg         config.CheckIn
+		last        models.CheckIn
+		created     bool
+		want        models.CheckInDTO
+		wantCreated []models.CheckIn
+		wantReward  []models.BalanceHistory
+		wantErr     string
+	}{
+		{
+			name:        "success_-_first_check_in",
+			cfg:         config.CheckIn{Reward: 5, StreakBonus: 1, StreakBonusMax: 10},
+			created:     true,
+			want:        models.CheckInDTO{Day: "2026-10-19", Streak: 1, Reward: 5, NextReward: 6},
+			wantCreated: []models.CheckIn{{Day: today, Streak: 1, Reward: 5}},
+			wantReward: []models.BalanceHistory{
+				{BalanceID: 100, TransactionAmount: 5, Sender: models.TreasuryAccount, Recipient: "user1", Type: models.HistoryTypeCheckIn, Reason: "День 1 подряд", TransferID: 1},
+				{BalanceID: 5, TransactionAmount: 5, Sender: models.TreasuryAccount, Recipient: "user1", Type: models.HistoryTypeCheckIn, Reason: "День 1 подряд", TransferID: 1},
+			},
+		},
+		{
+			name:        "success_-_streak_continues_after_yesterday",
+			cfg:         config.CheckIn{Reward: 5, StreakBonus: 1, StreakBonusMax: 10},
+			last:        models.CheckIn{Day: today.AddDate(0, 0, -1), Streak: 3, Reward: 7},
+			created:     true,
+			want:        models.CheckInDTO{Day: "2026-10-19", Streak: 4, Reward: 8, NextReward: 9},
+			wantCreated: []models.CheckIn{{Day: today, Streak: 4, Reward: 8}},
+			wantReward: []models.BalanceHistory{
+				{BalanceID: 100, TransactionAmount: 8, Sender: models.TreasuryAccount, Recipient: "user1", Type: models.HistoryTypeCheckIn, Reason: "День 4 подряд", TransferID: 1},
+				{BalanceID: 5, TransactionAmount: 8, Sender: models.TreasuryAccount, Recipient: "user1", Type: models.HistoryTypeCheckIn, Reason: "День 4 подряд", TransferID: 1},
+			},
+		},
+		{
+			name:        "success_-_missed_day_resets_streak",
+			cfg:         config.CheckIn{Reward: 5, StreakBonus: 1, StreakBonusMax: 10},
+			last:        models.CheckIn{Day: today.AddDate(0, 0, -2), Streak: 3, Reward: 7},
+			created:     true,
+			want:        models.CheckInDTO{Day: "2026-10-19", Streak: 1, Reward: 5, NextReward: 6},
+			wantCreated: []models.CheckIn{{Day: today, Streak: 1, Reward: 5}},
+			wantReward: []models.BalanceHistory{
+				{BalanceID: 100, TransactionAmount: 5, Sender: models.TreasuryAccount, Recipient: "user1", Type: models.HistoryTypeCheckIn, Reason: "День 1 подряд", TransferID: 1},
+				{BalanceID: 5, TransactionAmount: 5, Sender: models.TreasuryAccount, Recipient: "user1", Type: models.HistoryTypeCheckIn, Reason: "День 1 подряд", TransferID: 1},
+			},
+		},
+		{
+			name:        "fail_-_already_checked_in_today",
+			cfg:         config.CheckIn{Reward: 5},
+			last:        models.CheckIn{Day: today, Streak: 1, Reward: 5},
+			wantCreated: []models.CheckIn{},
+			wantReward:  []models.BalanceHistory{},
+			wantErr:     internalErrors.ErrAlreadyCheckedIn,
+		},
+		{
+			name:        "fail_-_concurrent_check_in_won",
+			cfg:         config.CheckIn{Reward: 5},
+			created:     false,
+			wantCreated: []models.CheckIn{{Day: today, Streak: 1, Reward: 5}},
+			wantReward:  []models.BalanceHistory{},
+			wantErr:     internalErrors.ErrAlreadyCheckedIn,
+		},
+		{
+			name:        "fail_-_check_in_disabled",
+			wantCreated: []models.CheckIn{},
+			wantReward:  []models.BalanceHistory{},
+			wantErr:     internalErrors.ErrCheckInDisabled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCreated := []models.CheckIn{}
+			gotReward := []models.BalanceHistory{}
+			s := &service{
+				repo: &MockRepository{
+					NextTransferIDFunc: nextTransferID,
+					GetLastCheckInFunc: func(ctx context.Context, username string) (models.CheckIn, error) {
+						return tt.last, nil
+					},
+					CreateCheckInFunc: func(ctx context.Context, username string, checkIn models.CheckIn) (bool, error) {
+						gotCreated = append(gotCreated, checkIn)
+						return tt.created, nil
+					},
+					GetSystemBalanceIDFunc: func(ctx context.Context, name, currency string) (int64, error) {
+						return 100, nil
+					},
+					GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+						return models.Balance{ID: 5}, nil
+					},
+					LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+						return nil
+					},
+					CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return nil
+					},
+					CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+						return nil
+					},
+					CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+						gotReward = append(gotReward, entry)
+						return nil
+					},
+					DebitSystemBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return nil
+					},
+				},
+				txManager: &MockTxManager{},
+				cfg:       config.Config{CheckIn: tt.cfg},
+			}
+
+			got, err := s.checkIn(context.Background(), "user1", now)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.checkIn() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("service.checkIn() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("service.checkIn() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotCreated, tt.wantCreated) {
+				t.Errorf("service.checkIn() created = %v, want %v", gotCreated, tt.wantCreated)
+			}
+			if !reflect.DeepEqual(gotReward, tt.wantReward) {
+				t.Errorf("service.checkIn() reward = %v, want %v", gotReward, tt.wantReward)
+			}
+		})
+	}
+}
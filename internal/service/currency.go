@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// resolveCurrency возвращает код валюты операции: пустой код означает монеты
+func resolveCurrency(ctx context.Context, repo Repository, code string) (string, error) {
+	if code == "" || code == models.CurrencyCoins {
+		return models.CurrencyCoins, nil
+	}
+
+	exist, err := repo.IsCurrencyExist(ctx, code)
+	if err != nil {
+		return "", err
+	}
+	if !exist {
+		return "", errors.New(internalErrors.ErrUnknownCurrency)
+	}
+
+	return code, nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func Test_resolveCurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		want    string
+		wantErr string
+	}{
+		{name: "success_-_empty_means_coins", code: "", want: models.CurrencyCoins},
+		{name: "success_-_coins", code: models.CurrencyCoins, want: models.CurrencyCoins},
+		{name: "success_-_known_currency", code: "kudos", want: "kudos"},
+		{name: "error_-_unknown_currency", code: "gold", wantErr: internalErrors.ErrUnknownCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{
+				IsCurrencyExistFunc: func(ctx context.Context, code string) (bool, error) {
+					return code == "kudos", nil
+				},
+			}
+
+			got, err := resolveCurrency(context.Background(), repo, tt.code)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("resolveCurrency() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("resolveCurrency() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func Test_service_SendCoins_currency(t *testing.T) {
+	tests := []struct {
+		name         string
+		currency     string
+		wantCurrency string
+	}{
+		{name: "success_-_coins_by_default", currency: "", wantCurrency: models.CurrencyCoins},
+		{name: "success_-_kudos_wallets_used", currency: "kudos", wantCurrency: "kudos"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wallets := map[string]string{}
+			s := &service{
+				repo: &MockRepository{
+					NextTransferIDFunc:             nextTransferID,
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
+					RecordLeaderboardTransferFunc:  recordLeaderboardTransferNoop,
+					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+						return true, nil
+					},
+					IsCurrencyExistFunc: func(ctx context.Context, code string) (bool, error) {
+						return true, nil
+					},
+					GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+						wallets[username] = currency
+						if username == "user2" {
+							return models.Balance{ID: 2}, nil
+						}
+						return models.Balance{ID: 1, Amount: 100}, nil
+					},
+					GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+						return models.AccountState{Status: models.UserStatusActive}, nil
+					},
+					LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+						return nil
+					},
+					DebitBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return nil
+					},
+					SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+						return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+					},
+					CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return nil
+					},
+					CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+						return nil
+					},
+					CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+						return nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			err := s.SendCoins(context.Background(), models.CoinsQuery{
+				UserID:    1,
+				Amount:    10,
+				Sender:    "user1",
+				Recipient: "user2",
+				Currency:  tt.currency,
+			})
+			if err != nil {
+				t.Fatalf("service.SendCoins() error = %v", err)
+			}
+			if wallets["user1"] != tt.wantCurrency || wallets["user2"] != tt.wantCurrency {
+				t.Errorf("service.SendCoins() wallets = %v, want %v", wallets, tt.wantCurrency)
+			}
+		})
+	}
+}
+
+func Test_service_BuyItem_currency(t *testing.T) {
+	walletCurrency := ""
+	var debited int64
+	s := &service{
+		repo: &MockRepository{
+			GetNotificationPreferencesFunc: getNoNotificationPreferences,
+			GetActiveQuestsFunc:            getNoActiveQuests,
+			GetPendingReferralFunc:         getNoPendingReferral,
+			GetDueAchievementsFunc:         getNoDueAchievements,
+			IncrementUserActivityFunc:      incrementUserActivityNoop,
+			GetMerchByNameFunc: func(ctx context.Context, name string) (models.Merch, error) {
+				return models.Merch{ID: 1, Name: "sticker", Price: 5, Currency: "kudos"}, nil
+			},
+			GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+				walletCurrency = currency
+				return models.Balance{ID: 7, Amount: 5}, nil
+			},
+			GetInventoryIDByUserIDFunc: func(ctx context.Context, userID int64) (int64, error) {
+				return 10, nil
+			},
+			GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+				return models.AccountState{Status: models.UserStatusActive}, nil
+			},
+			LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+				return nil
+			},
+			DebitBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+				if balanceID == 7 {
+					debited += amount
+				}
+				return nil
+			},
+			SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+				return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+			},
+			CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+				return nil
+			},
+			AddInventoryMerchFunc: func(ctx context.Context, inventoryID, merchID int64, item string) error {
+				return nil
+			},
+		},
+		txManager: &MockTxManager{},
+	}
+
+	err := s.BuyItem(context.Background(), models.ItemQuery{UserID: 1, Username: "user1", Item: "sticker"})
+	if err != nil {
+		t.Fatalf("service.BuyItem() error = %v", err)
+	}
+	if walletCurrency != "kudos" || debited != 5 {
+		t.Errorf("service.BuyItem() wallet currency = %v, debited = %v", walletCurrency, debited)
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+const expirationReason = "coins expired"
+
+// Expiration
+// ExpireCoins переводит в казначейство монеты из лотов, срок действия которых истёк
+func (s *service) ExpireCoins(ctx context.Context) error {
+	if s.cfg.Expiration.Months <= 0 {
+		return nil
+	}
+
+	grantedBefore := time.Now().AddDate(0, -s.cfg.Expiration.Months, 0)
+
+	return s.forEachOrganization(ctx, func(ctx context.Context) error {
+		return s.expireOrganizationCoins(ctx, grantedBefore)
+	})
+}
+
+func (s *service) expireOrganizationCoins(ctx context.Context, grantedBefore time.Time) error {
+	owners, err := s.repo.GetExpiredLotOwners(ctx, grantedBefore, s.cfg.Expiration.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, owner := range owners {
+		if err := s.expireBalanceCoins(ctx, owner, grantedBefore); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expireBalanceCoins сжигает просроченные лоты одного баланса пользователя или команды. Параллельная реплика,
+// обработавшая тот же баланс, уже удалила лоты, поэтому повторного списания не будет.
+func (s *service) expireBalanceCoins(ctx context.Context, owner models.BalanceOwner, grantedBefore time.Time) error {
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		// монеты сгорают в казначейство той же валюты
+		treasuryBalanceID, err := repo.GetSystemBalanceID(ctx, models.TreasuryAccount, currencyOrCoins(owner.Currency))
+		if err != nil {
+			return err
+		}
+		if err := repo.LockBalances(ctx, owner.BalanceID, treasuryBalanceID); err != nil {
+			return err
+		}
+
+		expired, err := repo.ExpireBalanceLots(ctx, owner.BalanceID, grantedBefore)
+		if err != nil {
+			return err
+		}
+		if expired == 0 {
+			return nil
+		}
+
+		// монеты сгорают, даже если они зарезервированы
+		if err := repo.DebitBalanceIgnoringHolds(ctx, owner.BalanceID, expired); err != nil {
+			return err
+		}
+		if err := repo.CreditBalance(ctx, treasuryBalanceID, expired); err != nil {
+			return err
+		}
+
+		err = createTransferHistory(ctx, repo, owner.BalanceID, treasuryBalanceID, models.BalanceHistory{
+			TransactionAmount: expired,
+			Sender:            owner.Username,
+			Recipient:         models.TreasuryAccount,
+			Type:              models.HistoryTypeExpiration,
+			Reason:            expirationReason,
+		})
+		if err != nil {
+			return err
+		}
+
+		log.Logger.Info().Msgf("%d %s of %s expired", expired, currencyOrCoins(owner.Currency), owner.Username)
+
+		return nil
+	})
+}
+
+// lotExpiresAt возвращает момент сгорания лота
+func lotExpiresAt(lot models.BalanceLot, months int) time.Time {
+	return lot.GrantedAt.AddDate(0, months, 0)
+}
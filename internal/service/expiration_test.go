@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func Test_service_ExpireCoins(t *testing.T) {
+	tests := []struct {
+		name          string
+		months        int
+		owner         models.BalanceOwner
+		expired       int64
+		wantDebited   int64
+		wantTreasury  int64
+		wantHistories int
+		wantCurrency  string
+	}{
+		{
+			name:          "success_-_expired_coins_moved_to_treasury",
+			months:        6,
+			owner:         models.BalanceOwner{BalanceID: 1, Username: "user1", Currency: models.CurrencyCoins},
+			expired:       300,
+			wantDebited:   300,
+			wantTreasury:  300,
+			wantHistories: 2,
+			wantCurrency:  models.CurrencyCoins,
+		},
+		{
+			name:          "success_-_team_wallet_expired",
+			months:        6,
+			owner:         models.BalanceOwner{BalanceID: 5, Username: models.TeamAccountName("qa"), Currency: models.CurrencyCoins},
+			expired:       120,
+			wantDebited:   120,
+			wantTreasury:  120,
+			wantHistories: 2,
+			wantCurrency:  models.CurrencyCoins,
+		},
+		{
+			name:          "success_-_currency_wallet_expired_to_currency_treasury",
+			months:        6,
+			owner:         models.BalanceOwner{BalanceID: 7, Username: "user1", Currency: "kudos"},
+			expired:       40,
+			wantDebited:   40,
+			wantTreasury:  40,
+			wantHistories: 2,
+			wantCurrency:  "kudos",
+		},
+		{
+			name:         "success_-_lots_already_expired_by_other_replica",
+			months:       6,
+			owner:        models.BalanceOwner{BalanceID: 1, Username: "user1", Currency: models.CurrencyCoins},
+			expired:      0,
+			wantCurrency: models.CurrencyCoins,
+		},
+		{
+			name:   "success_-_expiration_disabled",
+			months: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var debited, treasuryCredited int64
+			histories := 0
+			treasuryCurrency := ""
+			repo := &MockRepository{
+				NextTransferIDFunc:     nextTransferID,
+				GetOrganizationIDsFunc: getSingleOrganization,
+				GetExpiredLotOwnersFunc: func(ctx context.Context, grantedBefore time.Time, limit int) ([]models.BalanceOwner, error) {
+					return []models.BalanceOwner{tt.owner}, nil
+				},
+				GetSystemBalanceIDFunc: func(ctx context.Context, name, currency string) (int64, error) {
+					treasuryCurrency = currency
+					return 100, nil
+				},
+				LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+					return nil
+				},
+				ExpireBalanceLotsFunc: func(ctx context.Context, balanceID int64, grantedBefore time.Time) (int64, error) {
+					return tt.expired, nil
+				},
+				DebitBalanceIgnoringHoldsFunc: func(ctx context.Context, balanceID, amount int64) error {
+					if balanceID == tt.owner.BalanceID {
+						debited += amount
+					}
+					return nil
+				},
+				CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+					if balanceID == 100 {
+						treasuryCredited += amount
+					}
+					return nil
+				},
+				CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+					histories++
+					return nil
+				},
+			}
+			s := &service{
+				repo:      repo,
+				txManager: &MockTxManager{},
+				cfg:       config.Config{Expiration: config.Expiration{Months: tt.months, BatchSize: 10}},
+			}
+
+			if err := s.ExpireCoins(context.Background()); err != nil {
+				t.Errorf("service.ExpireCoins() error = %v", err)
+				return
+			}
+			if debited != tt.wantDebited || treasuryCredited != tt.wantTreasury || histories != tt.wantHistories {
+				t.Errorf("debited = %v, treasury = %v, histories = %v, want %v, %v, %v",
+					debited, treasuryCredited, histories, tt.wantDebited, tt.wantTreasury, tt.wantHistories)
+			}
+			if treasuryCurrency != tt.wantCurrency {
+				t.Errorf("treasury currency = %v, want %v", treasuryCurrency, tt.wantCurrency)
+			}
+		})
+	}
+}
+
+func Test_service_getExpiringSoon(t *testing.T) {
+	now := time.Date(2026, 10, 22, 12, 0, 0, 0, time.UTC)
+	lots := []models.BalanceLot{
+		{Amount: 100, GrantedAt: time.Date(2026, 4, 25, 9, 0, 0, 0, time.UTC), Currency: models.CurrencyCoins},
+		{Amount: 20, GrantedAt: time.Date(2026, 4, 25, 12, 0, 0, 0, time.UTC), Currency: "kudos"},
+		{Amount: 50, GrantedAt: time.Date(2026, 4, 25, 18, 0, 0, 0, time.UTC), Currency: models.CurrencyCoins},
+		{Amount: 30, GrantedAt: time.Date(2026, 5, 2, 9, 0, 0, 0, time.UTC), Currency: models.CurrencyCoins},
+	}
+
+	s := &service{
+		repo: &MockRepository{
+			GetBalanceLotsByUserIDFunc: func(ctx context.Context, userID int64, grantedBefore time.Time) ([]models.BalanceLot, error) {
+				return lots, nil
+			},
+		},
+		cfg: config.Config{Expiration: config.Expiration{Months: 6, SoonWindow: 30 * 24 * time.Hour}},
+	}
+
+	got, err := s.getExpiringSoon(context.Background(), 1, now)
+	if err != nil {
+		t.Fatalf("service.getExpiringSoon() error = %v", err)
+	}
+	if len(got) != 3 || got[0].Amount != 150 || got[1].Amount != 20 || got[2].Amount != 30 {
+		t.Fatalf("service.getExpiringSoon() = %v, want amounts [150 20 30]", got)
+	}
+	if got[0].Currency != models.CurrencyCoins || got[1].Currency != "kudos" {
+		t.Errorf("service.getExpiringSoon() currencies = %v, %v", got[0].Currency, got[1].Currency)
+	}
+	if !time.Time(got[0].ExpiresAt).Equal(time.Date(2026, 10, 25, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("service.getExpiringSoon() expiresAt = %v", got[0].ExpiresAt)
+	}
+}
+
+func Test_service_moveBalanceLots(t *testing.T) {
+	grantedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	lots := []models.BalanceLot{
+		{Amount: 40, GrantedAt: grantedAt},
+		{Amount: 60, GrantedAt: grantedAt.AddDate(0, 1, 0)},
+	}
+
+	tests := []struct {
+		name      string
+		policy    string
+		wantLots  int
+		wantKeeps bool
+	}{
+		{name: "keep_granted_at", policy: config.ExpirationTransferKeep, wantLots: 2, wantKeeps: true},
+		{name: "reset_granted_at", policy: config.ExpirationTransferReset, wantLots: 1, wantKeeps: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := []models.BalanceLot{}
+			repo := &MockRepository{
+				CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+					created = append(created, models.BalanceLot{BalanceID: balanceID, Amount: amount, GrantedAt: grantedAt})
+					return nil
+				},
+			}
+			s := &service{cfg: config.Config{Expiration: config.Expiration{TransferPolicy: tt.policy}}}
+
+			if err := s.moveBalanceLots(context.Background(), repo, 2, lots); err != nil {
+				t.Fatalf("service.moveBalanceLots() error = %v", err)
+			}
+			if len(created) != tt.wantLots {
+				t.Fatalf("created lots = %v, want %v", len(created), tt.wantLots)
+			}
+			if created[0].GrantedAt.Equal(grantedAt) != tt.wantKeeps {
+				t.Errorf("created lot granted at = %v, keep = %v", created[0].GrantedAt, tt.wantKeeps)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Fraud
+// DetectFraud проверяет правила по истории переводов за последнее окно и создаёт кейсы для администраторов.
+// Повторное срабатывание правила на тот же набор аккаунтов кейс не дублирует.
+func (s *service) DetectFraud(ctx context.Context) error {
+	return s.forEachOrganization(ctx, s.detectOrganizationFraud)
+}
+
+func (s *service) detectOrganizationFraud(ctx context.Context) error {
+	now := time.Now()
+	since := now.Add(-s.cfg.Fraud.Window)
+	created := 0
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		cycles, err := repo.FindTransferCycles(ctx, since)
+		if err != nil {
+			return err
+		}
+		funnels, err := repo.FindFunnels(ctx, since, s.cfg.Fraud.NewAccountAge, s.cfg.Fraud.FunnelMinSenders)
+		if err != nil {
+			return err
+		}
+		bursts, err := repo.FindBursts(ctx, since, s.cfg.Fraud.BurstWindow, s.cfg.Fraud.BurstAmount)
+		if err != nil {
+			return err
+		}
+
+		hits := append(append(cycles, funnels...), bursts...)
+		for _, hit := range hits {
+			fc := s.newFraudCase(hit)
+
+			ok, err := repo.CreateFraudCase(ctx, fc)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			created++
+
+			if s.cfg.Fraud.AutoHold {
+				if err := repo.SetFraudHold(ctx, fc.Usernames); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if created > 0 {
+		log.Logger.Warn().Msgf("%d fraud cases created", created)
+	}
+
+	return nil
+}
+
+// newFraudCase описывает срабатывание правила для администратора
+func (s *service) newFraudCase(hit models.FraudHit) models.FraudCase {
+	fc := models.FraudCase{
+		Rule:       hit.Rule,
+		SubjectKey: strings.Join(hit.Usernames, ","),
+		Usernames:  hit.Usernames,
+		Status:     models.FraudCaseStatusOpen,
+	}
+
+	switch hit.Rule {
+	case models.FraudRuleCycle:
+		fc.Details = fmt.Sprintf("circular transfers %s -> %s", strings.Join(hit.Usernames, " -> "), hit.Usernames[0])
+	case models.FraudRuleFunnel:
+		// новый отправитель меняет набор аккаунтов, поэтому создаёт новый кейс, а не теряется в старом.
+		// Отправителей может быть сколько угодно, поэтому ключ хешируется, а сами имена остаются в Usernames
+		senders := slices.Clone(hit.Usernames[1:])
+		slices.Sort(senders)
+		fc.SubjectKey = hashSubjectKey(append([]string{hit.Usernames[0]}, senders...))
+		fc.Details = fmt.Sprintf("%d new accounts sent %d coins to %s: %s",
+			len(hit.Usernames)-1, hit.Amount, hit.Usernames[0], strings.Join(hit.Usernames[1:], ", "))
+	case models.FraudRuleBurst:
+		fc.Details = fmt.Sprintf("%s sent %d coins within %s after registration", hit.Usernames[0], hit.Amount, s.cfg.Fraud.BurstWindow)
+	}
+
+	return fc
+}
+
+// hashSubjectKey ключ кейса фиксированной длины для набора аккаунтов неограниченного размера
+func hashSubjectKey(usernames []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(usernames, ",")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *service) GetFraudCases(ctx context.Context, status string) ([]models.FraudCaseDTO, error) {
+	switch status {
+	case "", models.FraudCaseStatusOpen, models.FraudCaseStatusDismissed, models.FraudCaseStatusConfirmed:
+	default:
+		return nil, errors.New(internalErrors.ErrInvalidFraudCaseReqParams)
+	}
+
+	cases, err := s.repo.GetFraudCases(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+
+	casesDTO := make([]models.FraudCaseDTO, 0, len(cases))
+	for _, fc := range cases {
+		casesDTO = append(casesDTO, fc.ToModelFraudCaseDTO())
+	}
+
+	return casesDTO, nil
+}
+
+// ResolveFraudCase закрывает открытый кейс. Подтверждение блокирует аккаунты кейса,
+// отклонение снимает блокировку, если аккаунты не участвуют в других открытых или подтверждённых кейсах.
+func (s *service) ResolveFraudCase(ctx context.Context, qp models.FraudCaseQuery) (models.FraudCaseDTO, error) {
+	if qp.Status != models.FraudCaseStatusDismissed && qp.Status != models.FraudCaseStatusConfirmed {
+		return models.FraudCaseDTO{}, errors.New(internalErrors.ErrInvalidFraudCaseReqParams)
+	}
+
+	fc := models.FraudCase{}
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		var err error
+		fc, err = repo.GetFraudCaseByID(ctx, qp.ID)
+		if err != nil {
+			return err
+		}
+		if fc.ID == 0 {
+			return errors.New(internalErrors.ErrFraudCaseNotFound)
+		}
+		if fc.Status != models.FraudCaseStatusOpen {
+			return errors.New(internalErrors.ErrFraudCaseResolved)
+		}
+
+		if err := repo.ResolveFraudCase(ctx, fc.ID, qp.Status, qp.Admin); err != nil {
+			return err
+		}
+		now := time.Now()
+		fc.Status = qp.Status
+		fc.ResolvedBy = qp.Admin
+		fc.ResolvedAt = &now
+
+		if qp.Status == models.FraudCaseStatusConfirmed {
+			return repo.SetFraudHold(ctx, fc.Usernames)
+		}
+
+		return repo.ReleaseFraudHold(ctx, fc.Usernames)
+	})
+	if err != nil {
+		return models.FraudCaseDTO{}, err
+	}
+
+	return fc.ToModelFraudCaseDTO(), nil
+}
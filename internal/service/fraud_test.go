@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func Test_service_DetectFraud(t *testing.T) {
+	tests := []struct {
+		name       string
+		autoHold   bool
+		funnel     []string
+		existing   map[string]bool
+		wantCases  []string
+		wantOnHold []string
+	}{
+		{
+			name:      "success_-_cases_created_without_hold",
+			wantCases: []string{"cycle:user1,user2,user3", "funnel:" + hashSubjectKey([]string{"collector", "new1", "new2"}), "burst:user9"},
+		},
+		{
+			name:      "success_-_funnel_with_new_sender_creates_case",
+			funnel:    []string{"collector", "new3", "new1", "new2"},
+			existing:  map[string]bool{"funnel:" + hashSubjectKey([]string{"collector", "new1", "new2"}): true},
+			wantCases: []string{"cycle:user1,user2,user3", "funnel:" + hashSubjectKey([]string{"collector", "new1", "new2", "new3"}), "burst:user9"},
+		},
+		{
+			name:       "success_-_new_cases_put_accounts_on_hold",
+			autoHold:   true,
+			existing:   map[string]bool{"funnel:" + hashSubjectKey([]string{"collector", "new1", "new2"}): true},
+			wantCases:  []string{"cycle:user1,user2,user3", "burst:user9"},
+			wantOnHold: []string{"user1", "user2", "user3", "user9"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := []string{}
+			onHold := []string{}
+			funnel := tt.funnel
+			if funnel == nil {
+				funnel = []string{"collector", "new1", "new2"}
+			}
+			s := &service{
+				repo: &MockRepository{
+					GetOrganizationIDsFunc: getSingleOrganization,
+					FindTransferCyclesFunc: func(ctx context.Context, since time.Time) ([]models.FraudHit, error) {
+						return []models.FraudHit{{Rule: models.FraudRuleCycle, Usernames: []string{"user1", "user2", "user3"}}}, nil
+					},
+					FindFunnelsFunc: func(ctx context.Context, since time.Time, newAccountAge time.Duration, minSenders int) ([]models.FraudHit, error) {
+						return []models.FraudHit{{Rule: models.FraudRuleFunnel, Usernames: funnel, Amount: 2000}}, nil
+					},
+					FindBurstsFunc: func(ctx context.Context, since time.Time, window time.Duration, minAmount int64) ([]models.FraudHit, error) {
+						return []models.FraudHit{{Rule: models.FraudRuleBurst, Usernames: []string{"user9"}, Amount: 900}}, nil
+					},
+					CreateFraudCaseFunc: func(ctx context.Context, fc models.FraudCase) (bool, error) {
+						key := fc.Rule + ":" + fc.SubjectKey
+						if tt.existing[key] {
+							return false, nil
+						}
+						created = append(created, key)
+						return true, nil
+					},
+					SetFraudHoldFunc: func(ctx context.Context, usernames []string) error {
+						onHold = append(onHold, usernames...)
+						return nil
+					},
+				},
+				txManager: &MockTxManager{},
+				cfg:       config.Config{Fraud: config.Fraud{Window: 24 * time.Hour, AutoHold: tt.autoHold}},
+			}
+
+			if err := s.DetectFraud(context.Background()); err != nil {
+				t.Fatalf("service.DetectFraud() error = %v", err)
+			}
+			if !reflect.DeepEqual(created, tt.wantCases) {
+				t.Errorf("service.DetectFraud() cases = %v, want %v", created, tt.wantCases)
+			}
+			if len(onHold) != len(tt.wantOnHold) || (len(onHold) > 0 && !reflect.DeepEqual(onHold, tt.wantOnHold)) {
+				t.Errorf("service.DetectFraud() on hold = %v, want %v", onHold, tt.wantOnHold)
+			}
+		})
+	}
+}
+
+func Test_service_ResolveFraudCase(t *testing.T) {
+	openCase := models.FraudCase{ID: 1, Rule: models.FraudRuleBurst, Usernames: []string{"user9"}, Status: models.FraudCaseStatusOpen}
+
+	tests := []struct {
+		name        string
+		fraudCase   models.FraudCase
+		status      string
+		wantErr     string
+		wantHold    bool
+		wantRelease bool
+	}{
+		{
+			name:      "success_-_confirmed_case_holds_accounts",
+			fraudCase: openCase,
+			status:    models.FraudCaseStatusConfirmed,
+			wantHold:  true,
+		},
+		{
+			name:        "success_-_dismissed_case_releases_accounts",
+			fraudCase:   openCase,
+			status:      models.FraudCaseStatusDismissed,
+			wantRelease: true,
+		},
+		{
+			name:    "error_-_case_not_found",
+			status:  models.FraudCaseStatusDismissed,
+			wantErr: internalErrors.ErrFraudCaseNotFound,
+		},
+		{
+			name:      "error_-_case_already_resolved",
+			fraudCase: models.FraudCase{ID: 1, Status: models.FraudCaseStatusDismissed},
+			status:    models.FraudCaseStatusConfirmed,
+			wantErr:   internalErrors.ErrFraudCaseResolved,
+		},
+		{
+			name:      "error_-_invalid_status",
+			fraudCase: openCase,
+			status:    models.FraudCaseStatusOpen,
+			wantErr:   internalErrors.ErrInvalidFraudCaseReqParams,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			held, released := false, false
+			s := &service{
+				repo: &MockRepository{
+					GetFraudCaseByIDFunc: func(ctx context.Context, caseID int64) (models.FraudCase, error) {
+						return tt.fraudCase, nil
+					},
+					ResolveFraudCaseFunc: func(ctx context.Context, caseID int64, status, admin string) error {
+						return nil
+					},
+					SetFraudHoldFunc: func(ctx context.Context, usernames []string) error {
+						held = true
+						return nil
+					},
+					ReleaseFraudHoldFunc: func(ctx context.Context, usernames []string) error {
+						released = true
+						return nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.ResolveFraudCase(context.Background(), models.FraudCaseQuery{ID: 1, Admin: "admin", Status: tt.status})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.ResolveFraudCase() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.ResolveFraudCase() error = %v", err)
+			}
+			if got.Status != tt.status || got.ResolvedBy != "admin" {
+				t.Errorf("service.ResolveFraudCase() = %v", got)
+			}
+			if held != tt.wantHold || released != tt.wantRelease {
+				t.Errorf("service.ResolveFraudCase() held = %v, released = %v, want %v, %v", held, released, tt.wantHold, tt.wantRelease)
+			}
+		})
+	}
+}
+
+func Test_service_SendCoins_fraudHold(t *testing.T) {
+	s := &service{
+		repo: &MockRepository{
+			IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+				return true, nil
+			},
+			GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+				if username == "user2" {
+					return models.Balance{ID: 2}, nil
+				}
+				return models.Balance{ID: 1, Amount: 1000}, nil
+			},
+			LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+				return nil
+			},
+			GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+				return models.AccountState{Status: models.UserStatusActive, FraudHold: true}, nil
+			},
+		},
+		txManager: &MockTxManager{},
+	}
+
+	err := s.SendCoins(context.Background(), models.CoinsQuery{UserID: 1, Amount: 100, Sender: "user1", Recipient: "user2"})
+	if err == nil || err.Error() != internalErrors.ErrAccountOnFraudHold {
+		t.Errorf("service.SendCoins() error = %v, wantErr %v", err, internalErrors.ErrAccountOnFraudHold)
+	}
+}
+
+func Test_service_newFraudCase_funnelKeyLength(t *testing.T) {
+	usernames := []string{"collector"}
+	for i := 0; i < 100; i++ {
+		usernames = append(usernames, fmt.Sprintf("%s%03d", strings.Repeat("a", 60), i))
+	}
+
+	s := &service{}
+	fc := s.newFraudCase(models.FraudHit{Rule: models.FraudRuleFunnel, Usernames: usernames, Amount: 100})
+	if len(fc.SubjectKey) != sha256.Size*2 {
+		t.Errorf("service.newFraudCase() subject key length = %v, want %v", len(fc.SubjectKey), sha256.Size*2)
+	}
+	if len(fc.Usernames) != len(usernames) {
+		t.Errorf("service.newFraudCase() usernames = %v, want %v", len(fc.Usernames), len(usernames))
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Grant
+// GrantCoins начисляет монеты из казначейства выбранным пользователям или всем сразу.
+// Валюта начисления необязательна, по умолчанию монеты. В режиме dry-run возвращает предпросмотр без начисления.
+func (s *service) GrantCoins(ctx context.Context, qp models.GrantQuery) (models.GrantResultDTO, error) {
+	if qp.Reason == "" || (!qp.All && len(qp.Recipients) == 0 && len(qp.Invalid) == 0) {
+		return models.GrantResultDTO{}, errors.New(internalErrors.ErrInvalidGrantReqParams)
+	}
+	if qp.All && qp.Amount < 1 {
+		return models.GrantResultDTO{}, errors.New(internalErrors.ErrInvalidGrantReqParams)
+	}
+
+	result := models.GrantResultDTO{
+		DryRun:  qp.DryRun,
+		Granted: []models.GrantDTO{},
+		Invalid: append([]models.GrantInvalidDTO{}, qp.Invalid...),
+	}
+
+	currency := models.CurrencyCoins
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		var err error
+		currency, err = resolveCurrency(ctx, repo, qp.Currency)
+		if err != nil {
+			return err
+		}
+
+		recipients := qp.Recipients
+		if qp.All {
+			usernames, err := repo.GetActiveUsernames(ctx)
+			if err != nil {
+				return err
+			}
+			recipients = make([]models.GrantRecipient, 0, len(usernames))
+			for _, username := range usernames {
+				recipients = append(recipients, models.GrantRecipient{Username: username, Amount: qp.Amount})
+			}
+		}
+
+		valid := make([]models.GrantRecipient, 0, len(recipients))
+		for _, recipient := range recipients {
+			if recipient.Amount < 1 {
+				result.Invalid = append(result.Invalid, models.GrantInvalidDTO{
+					Line:   recipient.Line,
+					ToUser: recipient.Username,
+					Error:  internalErrors.ErrInvalidGrantAmount,
+				})
+				continue
+			}
+
+			exist, err := repo.IsUserExist(ctx, recipient.Username)
+			if err != nil {
+				return err
+			}
+			if !exist {
+				result.Invalid = append(result.Invalid, models.GrantInvalidDTO{
+					Line:   recipient.Line,
+					ToUser: recipient.Username,
+					Error:  internalErrors.ErrInvalidRecipient,
+				})
+				continue
+			}
+
+			valid = append(valid, recipient)
+			result.Granted = append(result.Granted, models.GrantDTO{ToUser: recipient.Username, Amount: recipient.Amount})
+			result.Total += recipient.Amount
+		}
+
+		if qp.DryRun || len(valid) == 0 {
+			return nil
+		}
+
+		if err := s.creditFromTreasury(ctx, repo, valid, currency, models.HistoryTypeGrant, qp.Reason); err != nil {
+			return err
+		}
+
+		for _, recipient := range valid {
+			err := s.notify(ctx, repo, recipient.Username, models.Notification{
+				Type:     models.NotificationTypeGrant,
+				FromUser: models.TreasuryAccount,
+				Amount:   recipient.Amount,
+				Currency: currency,
+				Message:  qp.Reason,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.GrantResultDTO{}, err
+	}
+
+	if !qp.DryRun {
+		log.Logger.Info().Msgf("admin %s granted %d %s to %d users: %s", qp.Admin, result.Total, currency, len(result.Granted), qp.Reason)
+	}
+
+	return result, nil
+}
+
+// creditFromTreasury начисляет валюту получателям, списывая её с баланса казначейства в той же валюте
+func (s *service) creditFromTreasury(ctx context.Context, repo Repository, recipients []models.GrantRecipient, currency, historyType, reason string) error {
+	treasuryBalanceID, err := repo.GetSystemBalanceID(ctx, models.TreasuryAccount, currency)
+	if err != nil {
+		return err
+	}
+
+	balanceIDs := make([]int64, 0, len(recipients)+1)
+	balanceIDs = append(balanceIDs, treasuryBalanceID)
+	recipientBalanceIDs := make([]int64, 0, len(recipients))
+	for _, recipient := range recipients {
+		wallet, err := repo.GetOrCreateWallet(ctx, recipient.Username, currency)
+		if err != nil {
+			return err
+		}
+		balanceIDs = append(balanceIDs, wallet.ID)
+		recipientBalanceIDs = append(recipientBalanceIDs, wallet.ID)
+	}
+
+	if err := repo.LockBalances(ctx, balanceIDs...); err != nil {
+		return err
+	}
+
+	var total int64
+	for i, recipient := range recipients {
+		if err := repo.CreditBalance(ctx, recipientBalanceIDs[i], recipient.Amount); err != nil {
+			return err
+		}
+		if err := repo.CreateBalanceLot(ctx, recipientBalanceIDs[i], recipient.Amount, time.Now()); err != nil {
+			return err
+		}
+
+		err := createTransferHistory(ctx, repo, treasuryBalanceID, recipientBalanceIDs[i], models.BalanceHistory{
+			TransactionAmount: recipient.Amount,
+			Sender:            models.TreasuryAccount,
+			Recipient:         recipient.Username,
+			Type:              historyType,
+			Reason:            reason,
+		})
+		if err != nil {
+			return err
+		}
+		total += recipient.Amount
+	}
+
+	return repo.DebitSystemBalance(ctx, treasuryBalanceID, total)
+}
+
+// Allowance
+// PayAllowance начисляет периодическое пособие всем активным пользователям не чаще раза за период
+func (s *service) PayAllowance(ctx context.Context) error {
+	amount := s.cfg.Allowance.Amount
+	if amount <= 0 {
+		return nil
+	}
+
+	start := periodStart(time.Now().UTC(), s.cfg.Allowance.Period)
+
+	return s.forEachOrganization(ctx, func(ctx context.Context) error {
+		return s.payOrganizationAllowance(ctx, start, amount)
+	})
+}
+
+// payOrganizationAllowance выплачивает пособие за период один раз в пределах организации
+func (s *service) payOrganizationAllowance(ctx context.Context, start time.Time, amount int64) error {
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		usernames, err := repo.GetActiveUsernames(ctx)
+		if err != nil {
+			return err
+		}
+
+		// параллельная реплика, начавшая выплату за тот же период, заблокирует вставку до своего коммита
+		created, err := repo.CreateAllowanceRun(ctx, start, amount, int64(len(usernames)))
+		if err != nil {
+			return err
+		}
+		if !created || len(usernames) == 0 {
+			return nil
+		}
+
+		recipients := make([]models.GrantRecipient, 0, len(usernames))
+		for _, username := range usernames {
+			recipients = append(recipients, models.GrantRecipient{Username: username, Amount: amount})
+		}
+
+		reason := fmt.Sprintf("%s allowance %s", s.cfg.Allowance.Period, start.Format(time.DateOnly))
+		if err := s.creditFromTreasury(ctx, repo, recipients, models.CurrencyCoins, models.HistoryTypeAllowance, reason); err != nil {
+			return err
+		}
+
+		log.Logger.Info().Msgf("allowance %d coins paid to %d users for period %s", amount, len(usernames), start.Format(time.DateOnly))
+
+		return nil
+	})
+}
+
+// periodStart начало календарного периода daily, weekly или monthly, в который попадает now
+func periodStart(now time.Time, period string) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch period {
+	case config.AllowancePeriodDaily:
+		return day
+	case config.AllowancePeriodWeekly:
+		// неделя начинается с понедельника
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	default:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	}
+}
+
+// periodEnd начало следующего периода
+func periodEnd(start time.Time, period string) time.Time {
+	switch period {
+	case config.AllowancePeriodDaily:
+		return start.AddDate(0, 0, 1)
+	case config.AllowancePeriodWeekly:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
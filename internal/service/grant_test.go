@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func Test_service_GrantCoins(t *testing.T) {
+	grantRepo := func(credited *int64, treasuryDebited *int64) *MockRepository {
+		return &MockRepository{
+			NextTransferIDFunc:             nextTransferID,
+			GetNotificationPreferencesFunc: getNoNotificationPreferences,
+			GetActiveUsernamesFunc: func(ctx context.Context) ([]string, error) {
+				return []string{"user1", "user2"}, nil
+			},
+			IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+				return username != "user_invalid", nil
+			},
+			GetSystemBalanceIDFunc: func(ctx context.Context, name, currency string) (int64, error) {
+				return 100, nil
+			},
+			IsCurrencyExistFunc: func(ctx context.Context, code string) (bool, error) {
+				return code == "kudos", nil
+			},
+			GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+				return models.Balance{ID: 1}, nil
+			},
+			LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+				return nil
+			},
+			CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+				*credited += amount
+				return nil
+			},
+			CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+				return nil
+			},
+			CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+				return nil
+			},
+			DebitSystemBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+				*treasuryDebited += amount
+				return nil
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		qp           models.GrantQuery
+		wantErr      bool
+		wantTotal    int64
+		wantInvalid  int
+		wantCredited int64
+	}{
+		{
+			name: "success_-_coins_granted_to_users",
+			qp: models.GrantQuery{
+				Admin:      "admin",
+				Recipients: []models.GrantRecipient{{Username: "user1", Amount: 100}, {Username: "user2", Amount: 50}},
+				Reason:     "hackathon",
+			},
+			wantTotal:    150,
+			wantCredited: 150,
+		},
+		{
+			name:         "success_-_coins_granted_to_all_users",
+			qp:           models.GrantQuery{Admin: "admin", All: true, Amount: 10, Reason: "new year"},
+			wantTotal:    20,
+			wantCredited: 20,
+		},
+		{
+			name: "success_-_dry_run_does_not_credit",
+			qp: models.GrantQuery{
+				Admin:      "admin",
+				Recipients: []models.GrantRecipient{{Username: "user1", Amount: 100}},
+				Reason:     "hackathon",
+				DryRun:     true,
+			},
+			wantTotal: 100,
+		},
+		{
+			name: "success_-_invalid_lines_reported",
+			qp: models.GrantQuery{
+				Admin: "admin",
+				Recipients: []models.GrantRecipient{
+					{Line: 1, Username: "user1", Amount: 100},
+					{Line: 2, Username: "user_invalid", Amount: 100},
+					{Line: 3, Username: "user2", Amount: 0},
+				},
+				Reason: "hackathon",
+			},
+			wantTotal:    100,
+			wantInvalid:  2,
+			wantCredited: 100,
+		},
+		{
+			name: "success_-_kudos_granted",
+			qp: models.GrantQuery{
+				Admin:      "admin",
+				Recipients: []models.GrantRecipient{{Username: "user1", Amount: 5}},
+				Reason:     "thanks",
+				Currency:   "kudos",
+			},
+			wantTotal:    5,
+			wantCredited: 5,
+		},
+		{
+			name: "error_-_unknown_currency",
+			qp: models.GrantQuery{
+				Admin:      "admin",
+				Recipients: []models.GrantRecipient{{Username: "user1", Amount: 5}},
+				Reason:     "thanks",
+				Currency:   "gold",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "error_-_reason_is_required",
+			qp:      models.GrantQuery{Admin: "admin", Recipients: []models.GrantRecipient{{Username: "user1", Amount: 100}}},
+			wantErr: true,
+		},
+		{
+			name:    "error_-_no_recipients",
+			qp:      models.GrantQuery{Admin: "admin", Reason: "hackathon"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var credited, treasuryDebited int64
+			s := &service{
+				repo:      grantRepo(&credited, &treasuryDebited),
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.GrantCoins(context.Background(), tt.qp)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("service.GrantCoins() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Total != tt.wantTotal || len(got.Invalid) != tt.wantInvalid {
+				t.Errorf("service.GrantCoins() total = %v, invalid = %v, want %v, %v", got.Total, len(got.Invalid), tt.wantTotal, tt.wantInvalid)
+			}
+			if credited != tt.wantCredited || treasuryDebited != tt.wantCredited {
+				t.Errorf("credited = %v, treasury debited = %v, want %v", credited, treasuryDebited, tt.wantCredited)
+			}
+		})
+	}
+}
+
+func Test_periodStart(t *testing.T) {
+	// 2026-10-22 - четверг
+	now := time.Date(2026, 10, 22, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		period string
+		want   time.Time
+	}{
+		{name: "daily", period: config.AllowancePeriodDaily, want: time.Date(2026, 10, 22, 0, 0, 0, 0, time.UTC)},
+		{name: "weekly_starts_on_monday", period: config.AllowancePeriodWeekly, want: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{name: "monthly", period: config.AllowancePeriodMonthly, want: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := periodStart(now, tt.period); !got.Equal(tt.want) {
+				t.Errorf("periodStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Hold
+// CreateHold резервирует монеты пользователя в пользу получателя.
+// Зарезервированные монеты нельзя потратить, пока резерв не захвачен, не освобождён или не истёк.
+func (s *service) CreateHold(ctx context.Context, qp models.HoldQuery) (models.HoldDTO, error) {
+	if qp.TTL == 0 {
+		qp.TTL = s.cfg.Hold.DefaultTTL
+	}
+	if qp.Amount < 1 || qp.TTL < 0 || qp.TTL > s.cfg.Hold.MaxTTL || qp.Recipient == "" {
+		return models.HoldDTO{}, errors.New(internalErrors.ErrInvalidHoldReqParams)
+	}
+	if qp.Recipient == qp.Username {
+		return models.HoldDTO{}, errors.New(internalErrors.ErrInvalidRecipientYourself)
+	}
+
+	now := time.Now()
+	hold := models.Hold{
+		OwnerID:   qp.UserID,
+		Owner:     qp.Username,
+		Recipient: qp.Recipient,
+		Amount:    qp.Amount,
+		Reference: qp.Reference,
+		Status:    models.HoldStatusActive,
+		ExpiresAt: now.Add(qp.TTL),
+		CreatedAt: now,
+	}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		// резерв блокирует монеты так же, как трата, поэтому действуют те же ограничения аккаунта
+		if err := checkAccountCanSpend(ctx, repo, qp.Username); err != nil {
+			return err
+		}
+
+		validRecipient, err := repo.IsUserExist(ctx, hold.Recipient)
+		if err != nil {
+			return err
+		}
+		if !validRecipient {
+			return errors.New(internalErrors.ErrInvalidRecipient)
+		}
+
+		hold.BalanceID, err = repo.GetBalanceIDByUsername(ctx, hold.Owner)
+		if err != nil {
+			return err
+		}
+		if err := repo.LockBalances(ctx, hold.BalanceID); err != nil {
+			return err
+		}
+
+		// баланс и резервы читаются после блокировки, поэтому конкурентные списания уже учтены
+		balance, err := repo.GetBalanceByUserID(ctx, hold.OwnerID)
+		if err != nil {
+			return err
+		}
+		held, err := repo.GetHeldAmountByUserID(ctx, hold.OwnerID)
+		if err != nil {
+			return err
+		}
+		if balance.Amount-held < hold.Amount {
+			return errors.New(internalErrors.ErrNotEnoughCoins)
+		}
+
+		hold.ID, err = repo.CreateHold(ctx, hold)
+		return err
+	})
+	if err != nil {
+		return models.HoldDTO{}, err
+	}
+
+	return hold.ToModelHoldDTO(now), nil
+}
+
+func (s *service) GetHolds(ctx context.Context, userID int64, username string) ([]models.HoldDTO, error) {
+	holds, err := s.repo.GetHoldsByUser(ctx, userID, username)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	holdsDTO := make([]models.HoldDTO, 0, len(holds))
+	for _, hold := range holds {
+		holdsDTO = append(holdsDTO, hold.ToModelHoldDTO(now))
+	}
+
+	return holdsDTO, nil
+}
+
+// CaptureHold превращает резерв в обычный перевод получателю. Захватить резерв может только владелец.
+func (s *service) CaptureHold(ctx context.Context, qp models.HoldQuery) (models.HoldDTO, error) {
+	now := time.Now()
+	hold := models.Hold{}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		var err error
+		hold, err = s.getActiveHold(ctx, repo, qp.ID, now, func(hold models.Hold) bool {
+			return hold.OwnerID == qp.UserID
+		})
+		if err != nil {
+			return err
+		}
+
+		// резерв закрывается до списания, иначе он сам не даст потратить свои монеты
+		if err := repo.ResolveHold(ctx, hold.ID, models.HoldStatusCaptured); err != nil {
+			return err
+		}
+		hold.Status = models.HoldStatusCaptured
+
+		return s.SendCoins(ctx, models.CoinsQuery{
+			UserID:    hold.OwnerID,
+			Amount:    hold.Amount,
+			Sender:    hold.Owner,
+			Recipient: hold.Recipient,
+			Memo:      hold.Reference,
+		})
+	})
+	if err != nil {
+		return models.HoldDTO{}, err
+	}
+
+	return hold.ToModelHoldDTO(now), nil
+}
+
+// ReleaseHold возвращает зарезервированные монеты владельцу. Освободить резерв может владелец или получатель.
+func (s *service) ReleaseHold(ctx context.Context, qp models.HoldQuery) (models.HoldDTO, error) {
+	now := time.Now()
+	hold := models.Hold{}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		var err error
+		hold, err = s.getActiveHold(ctx, repo, qp.ID, now, func(hold models.Hold) bool {
+			return hold.OwnerID == qp.UserID || hold.Recipient == qp.Username
+		})
+		if err != nil {
+			return err
+		}
+
+		hold.Status = models.HoldStatusReleased
+		return repo.ResolveHold(ctx, hold.ID, models.HoldStatusReleased)
+	})
+	if err != nil {
+		return models.HoldDTO{}, err
+	}
+
+	return hold.ToModelHoldDTO(now), nil
+}
+
+// getActiveHold блокирует резерв. Чужой резерв считается ненайденным.
+func (s *service) getActiveHold(ctx context.Context, repo Repository, holdID int64, now time.Time, allowed func(hold models.Hold) bool) (models.Hold, error) {
+	hold, err := repo.GetHoldByID(ctx, holdID)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	if hold.ID == 0 || !allowed(hold) {
+		return models.Hold{}, errors.New(internalErrors.ErrHoldNotFound)
+	}
+	if !hold.IsActive(now) {
+		return models.Hold{}, errors.New(internalErrors.ErrHoldNotActive)
+	}
+
+	return hold, nil
+}
+
+// ReleaseExpiredHolds освобождает резервы с истёкшим сроком
+func (s *service) ReleaseExpiredHolds(ctx context.Context) error {
+	return s.forEachOrganization(ctx, s.releaseOrganizationExpiredHolds)
+}
+
+func (s *service) releaseOrganizationExpiredHolds(ctx context.Context) error {
+	released, err := s.repo.ReleaseExpiredHolds(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if released > 0 {
+		log.Logger.Info().Msgf("%d expired holds released", released)
+	}
+
+	return nil
+}
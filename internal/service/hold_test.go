@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func Test_service_CreateHold(t *testing.T) {
+	tests := []struct {
+		name    string
+		qp      models.HoldQuery
+		held    int64
+		state   models.AccountState
+		wantErr string
+	}{
+		{
+			name: "success_-_hold_created_with_default_ttl",
+			qp:   models.HoldQuery{UserID: 1, Username: "user1", Recipient: "user2", Amount: 300, Reference: "auction:1"},
+			held: 500,
+		},
+		{
+			name:    "error_-_not_enough_available_coins",
+			qp:      models.HoldQuery{UserID: 1, Username: "user1", Recipient: "user2", Amount: 600},
+			held:    500,
+			wantErr: internalErrors.ErrNotEnoughCoins,
+		},
+		{
+			name:    "error_-_ttl_too_long",
+			qp:      models.HoldQuery{UserID: 1, Username: "user1", Recipient: "user2", Amount: 100, TTL: 48 * time.Hour},
+			wantErr: internalErrors.ErrInvalidHoldReqParams,
+		},
+		{
+			name:    "error_-_recipient_is_yourself",
+			qp:      models.HoldQuery{UserID: 1, Username: "user1", Recipient: "user1", Amount: 100},
+			wantErr: internalErrors.ErrInvalidRecipientYourself,
+		},
+		{
+			name:    "error_-_frozen_account",
+			qp:      models.HoldQuery{UserID: 1, Username: "user1", Recipient: "user2", Amount: 100},
+			state:   models.AccountState{Status: models.UserStatusFrozen},
+			wantErr: internalErrors.ErrAccountFrozen,
+		},
+		{
+			name:    "error_-_account_on_fraud_hold",
+			qp:      models.HoldQuery{UserID: 1, Username: "user1", Recipient: "user2", Amount: 100},
+			state:   models.AccountState{Status: models.UserStatusActive, FraudHold: true},
+			wantErr: internalErrors.ErrAccountOnFraudHold,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := models.Hold{}
+			s := &service{
+				repo: &MockRepository{
+					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+						return true, nil
+					},
+					GetBalanceIDByUsernameFunc: func(ctx context.Context, username string) (int64, error) {
+						return 1, nil
+					},
+					GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+						if tt.state.Status != "" {
+							return tt.state, nil
+						}
+						return models.AccountState{Status: models.UserStatusActive}, nil
+					},
+					LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+						return nil
+					},
+					GetBalanceByUserIDFunc: func(ctx context.Context, userID int64) (models.Balance, error) {
+						return models.Balance{ID: 1, Amount: 1000}, nil
+					},
+					GetHeldAmountByUserIDFunc: func(ctx context.Context, userID int64) (int64, error) {
+						return tt.held, nil
+					},
+					CreateHoldFunc: func(ctx context.Context, hold models.Hold) (int64, error) {
+						created = hold
+						return 1, nil
+					},
+				},
+				txManager: &MockTxManager{},
+				cfg:       config.Config{Hold: config.Hold{DefaultTTL: time.Hour, MaxTTL: 24 * time.Hour}},
+			}
+
+			got, err := s.CreateHold(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.CreateHold() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.CreateHold() error = %v", err)
+			}
+			if got.Status != models.HoldStatusActive || created.BalanceID != 1 || created.Amount != tt.qp.Amount {
+				t.Errorf("service.CreateHold() = %v, created = %v", got, created)
+			}
+		})
+	}
+}
+
+func Test_service_CaptureHold(t *testing.T) {
+	active := models.Hold{
+		ID:        1,
+		BalanceID: 1,
+		OwnerID:   1,
+		Owner:     "user1",
+		Recipient: "user2",
+		Amount:    300,
+		Reference: "auction:1",
+		Status:    models.HoldStatusActive,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	expired := active
+	expired.ExpiresAt = time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		hold    models.Hold
+		userID  int64
+		wantErr string
+	}{
+		{name: "success_-_hold_captured", hold: active, userID: 1},
+		{name: "error_-_recipient_cannot_capture", hold: active, userID: 2, wantErr: internalErrors.ErrHoldNotFound},
+		{name: "error_-_hold_expired", hold: expired, userID: 1, wantErr: internalErrors.ErrHoldNotActive},
+		{name: "error_-_hold_not_found", hold: models.Hold{}, userID: 1, wantErr: internalErrors.ErrHoldNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved := ""
+			var credited int64
+			s := &service{
+				repo: &MockRepository{
+					NextTransferIDFunc:             nextTransferID,
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
+					RecordLeaderboardTransferFunc:  recordLeaderboardTransferNoop,
+					GetHoldByIDFunc: func(ctx context.Context, holdID int64) (models.Hold, error) {
+						return tt.hold, nil
+					},
+					ResolveHoldFunc: func(ctx context.Context, holdID int64, status string) error {
+						resolved = status
+						return nil
+					},
+					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+						return true, nil
+					},
+					GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+						if username == "user1" {
+							return models.Balance{ID: 1, Amount: 1000}, nil
+						}
+						return models.Balance{ID: 2}, nil
+					},
+					GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+						return models.AccountState{Status: models.UserStatusActive}, nil
+					},
+					LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+						return nil
+					},
+					DebitBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return nil
+					},
+					SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+						return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+					},
+					CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						credited += amount
+						return nil
+					},
+					CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+						return nil
+					},
+					CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+						return nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.CaptureHold(context.Background(), models.HoldQuery{ID: 1, UserID: tt.userID})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.CaptureHold() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.CaptureHold() error = %v", err)
+			}
+			if got.Status != models.HoldStatusCaptured || resolved != models.HoldStatusCaptured || credited != tt.hold.Amount {
+				t.Errorf("service.CaptureHold() status = %v, resolved = %v, credited = %v", got.Status, resolved, credited)
+			}
+		})
+	}
+}
+
+func Test_service_ReleaseHold(t *testing.T) {
+	hold := models.Hold{
+		ID:        1,
+		OwnerID:   1,
+		Owner:     "user1",
+		Recipient: "user2",
+		Amount:    300,
+		Status:    models.HoldStatusActive,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name    string
+		qp      models.HoldQuery
+		wantErr bool
+	}{
+		{name: "success_-_owner_releases", qp: models.HoldQuery{ID: 1, UserID: 1, Username: "user1"}},
+		{name: "success_-_recipient_releases", qp: models.HoldQuery{ID: 1, UserID: 2, Username: "user2"}},
+		{name: "error_-_stranger_cannot_release", qp: models.HoldQuery{ID: 1, UserID: 3, Username: "user3"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{
+				repo: &MockRepository{
+					GetHoldByIDFunc: func(ctx context.Context, holdID int64) (models.Hold, error) {
+						return hold, nil
+					},
+					ResolveHoldFunc: func(ctx context.Context, holdID int64, status string) error {
+						return nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.ReleaseHold(context.Background(), tt.qp)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("service.ReleaseHold() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.Status != models.HoldStatusReleased {
+				t.Errorf("service.ReleaseHold() status = %v", got.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+const (
+	defaultKudosFeedLimit = 20
+	maxKudosFeedLimit     = 100
+)
+
+// Kudos
+// GetKudosFeed возвращает страницу ленты публичных благодарностей организации, начиная с последних.
+// Курсор непрозрачен для клиента и указывает на последнюю благодарность предыдущей страницы.
+func (s *service) GetKudosFeed(ctx context.Context, qp models.KudosFeedQuery) (models.KudosFeedDTO, error) {
+	if qp.Limit == 0 {
+		qp.Limit = defaultKudosFeedLimit
+	}
+	if qp.Limit < 1 || qp.Limit > maxKudosFeedLimit {
+		return models.KudosFeedDTO{}, errors.New(internalErrors.ErrInvalidKudosReqParams)
+	}
+	before, err := decodeIDCursor(qp.Cursor)
+	if err != nil {
+		return models.KudosFeedDTO{}, errors.New(internalErrors.ErrInvalidKudosReqParams)
+	}
+
+	feedDTO := models.KudosFeedDTO{Items: []models.KudosDTO{}}
+	err = s.inTx(ctx, models.TxOptions{IsoLevel: models.RepeatableRead, ReadOnly: true}, func(ctx context.Context, repo Repository) error {
+		// лишняя запись показывает, что за страницей есть продолжение
+		feed, err := repo.GetKudosFeed(ctx, models.KudosFeedFilter{Before: before, Limit: qp.Limit + 1})
+		if err != nil {
+			return err
+		}
+		if len(feed) > qp.Limit {
+			feed = feed[:qp.Limit]
+			feedDTO.NextCursor = encodeIDCursor(feed[len(feed)-1].ID)
+		}
+		if len(feed) == 0 {
+			return nil
+		}
+
+		kudosIDs := make([]int64, 0, len(feed))
+		positions := make(map[int64]int, len(feed))
+		for _, kudos := range feed {
+			positions[kudos.ID] = len(feedDTO.Items)
+			kudosIDs = append(kudosIDs, kudos.ID)
+			feedDTO.Items = append(feedDTO.Items, kudos.ToModelKudosDTO())
+		}
+
+		reactions, err := repo.GetKudosReactions(ctx, kudosIDs, qp.UserID)
+		if err != nil {
+			return err
+		}
+		for _, reaction := range reactions {
+			position, ok := positions[reaction.KudosID]
+			if !ok {
+				continue
+			}
+			item := &feedDTO.Items[position]
+			item.Reactions = append(item.Reactions, models.KudosReactionDTO{
+				Emoji:   reaction.Emoji,
+				Count:   reaction.Count,
+				Reacted: reaction.Reacted,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.KudosFeedDTO{}, err
+	}
+
+	return feedDTO, nil
+}
+
+// BoostKudos добавляет монеты сверху к чужой благодарности: это обычный перевод получателю благодарности
+// в её валюте, на который действуют лимиты и ограничения аккаунта.
+func (s *service) BoostKudos(ctx context.Context, qp models.KudosBoostQuery) error {
+	if qp.KudosID < 1 || qp.Amount < 1 {
+		return errors.New(internalErrors.ErrInvalidKudosReqParams)
+	}
+
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		kudos, err := getVisibleKudos(ctx, repo, qp.KudosID)
+		if err != nil {
+			return err
+		}
+		if kudos.Sender == qp.Username {
+			return errors.New(internalErrors.ErrKudosBoostOwn)
+		}
+		if kudos.Recipient == qp.Username {
+			return errors.New(internalErrors.ErrInvalidRecipientYourself)
+		}
+
+		validRecipient, err := repo.IsUserExist(ctx, kudos.Recipient)
+		if err != nil {
+			return err
+		}
+		if !validRecipient {
+			return errors.New(internalErrors.ErrInvalidRecipient)
+		}
+
+		err = s.sendCoins(ctx, repo, models.BalanceHistory{
+			TransactionAmount: qp.Amount,
+			Sender:            qp.Username,
+			Recipient:         kudos.Recipient,
+			Type:              models.HistoryTypeTransfer,
+			Reason:            kudos.Message,
+			Currency:          kudos.Currency,
+			KudosID:           kudos.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return repo.UpdateKudosBoost(ctx, kudos.ID, qp.Amount, 1)
+	})
+}
+
+// ReactToKudos ставит реакцию на благодарность. Повторная реакция тем же эмодзи ничего не меняет.
+func (s *service) ReactToKudos(ctx context.Context, qp models.KudosReactionQuery) error {
+	if _, ok := models.KudosReactions[qp.Emoji]; !ok {
+		return errors.New(internalErrors.ErrInvalidKudosReaction)
+	}
+
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		if _, err := getVisibleKudos(ctx, repo, qp.KudosID); err != nil {
+			return err
+		}
+
+		return repo.AddKudosReaction(ctx, qp.KudosID, qp.UserID, qp.Emoji)
+	})
+}
+
+func (s *service) RemoveKudosReaction(ctx context.Context, qp models.KudosReactionQuery) error {
+	if _, ok := models.KudosReactions[qp.Emoji]; !ok {
+		return errors.New(internalErrors.ErrInvalidKudosReaction)
+	}
+
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		if _, err := getVisibleKudos(ctx, repo, qp.KudosID); err != nil {
+			return err
+		}
+
+		return repo.DeleteKudosReaction(ctx, qp.KudosID, qp.UserID, qp.Emoji)
+	})
+}
+
+// reverseKudosTransfer отражает отмену перевода в благодарности: отмена исходного перевода
+// скрывает благодарность из ленты, отмена добавленных сверху монет вычитает их.
+func (s *service) reverseKudosTransfer(ctx context.Context, repo Repository, entry models.BalanceHistory) error {
+	if entry.KudosID == 0 {
+		return nil
+	}
+
+	kudos, err := repo.GetKudosByID(ctx, entry.KudosID)
+	if err != nil {
+		return err
+	}
+	// автор не может добавлять монеты к своей благодарности, поэтому его перевод - исходный
+	if entry.Sender == kudos.Sender {
+		return repo.HideKudos(ctx, kudos.ID)
+	}
+
+	return repo.UpdateKudosBoost(ctx, kudos.ID, -entry.TransactionAmount, -1)
+}
+
+func getVisibleKudos(ctx context.Context, repo Repository, kudosID int64) (models.Kudos, error) {
+	kudos, err := repo.GetKudosByID(ctx, kudosID)
+	if err != nil {
+		return models.Kudos{}, err
+	}
+	if kudos.ID == 0 || kudos.Hidden {
+		return models.Kudos{}, errors.New(internalErrors.ErrKudosNotFound)
+	}
+
+	return kudos, nil
+}
+
+// encodeIDCursor курсор страниц, упорядоченных по убыванию id: id последней записи страницы
+func encodeIDCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+// decodeIDCursor пустой курсор означает первую страницу
+func decodeIDCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if id < 1 {
+		return 0, errors.New("cursor out of range")
+	}
+
+	return id, nil
+}
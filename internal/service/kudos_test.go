@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/go-openapi/strfmt"
+)
+
+// newKudosTransferRepo мок репозитория, через который проходит перевод монет между user1 и user2
+func newKudosTransferRepo(history *[]models.BalanceHistory) *MockRepository {
+	return &MockRepository{
+		GetDueAchievementsFunc:         getNoDueAchievements,
+		IncrementUserActivityFunc:      incrementUserActivityNoop,
+		RecordLeaderboardTransferFunc:  recordLeaderboardTransferNoop,
+		GetPendingReferralFunc:         getNoPendingReferral,
+		GetActiveQuestsFunc:            getNoActiveQuests,
+		GetNotificationPreferencesFunc: getNoNotificationPreferences,
+		NextTransferIDFunc:             nextTransferID,
+		IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+			return true, nil
+		},
+		IsCurrencyExistFunc: func(ctx context.Context, code string) (bool, error) {
+			return true, nil
+		},
+		GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+			if username == "user2" {
+				return models.Balance{ID: 2}, nil
+			}
+			return models.Balance{ID: 1, Amount: 100}, nil
+		},
+		GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+			return models.AccountState{Status: models.UserStatusActive}, nil
+		},
+		LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+			return nil
+		},
+		DebitBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+			return nil
+		},
+		SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+			return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+		},
+		CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+			return nil
+		},
+		CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+			return nil
+		},
+		CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+			*history = append(*history, entry)
+			return nil
+		},
+	}
+}
+
+func Test_service_SendCoins_public(t *testing.T) {
+	tests := []struct {
+		name        string
+		public      bool
+		wantKudos   []models.Kudos
+		wantKudosID int64
+	}{
+		{
+			name:        "success_-_public_transfer_creates_kudos",
+			public:      true,
+			wantKudos:   []models.Kudos{{Sender: "user1", Recipient: "user2", Message: "спасибо за ревью", Currency: models.CurrencyCoins, Amount: 10}},
+			wantKudosID: 7,
+		},
+		{
+			name:      "success_-_private_transfer_is_not_shown",
+			public:    false,
+			wantKudos: []models.Kudos{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := []models.BalanceHistory{}
+			gotKudos := []models.Kudos{}
+			repo := newKudosTransferRepo(&history)
+			repo.CreateKudosFunc = func(ctx context.Context, kudos models.Kudos) (int64, error) {
+				gotKudos = append(gotKudos, kudos)
+				return 7, nil
+			}
+			s := &service{repo: repo, txManager: &MockTxManager{}}
+
+			err := s.SendCoins(context.Background(), models.CoinsQuery{
+				UserID:    1,
+				Amount:    10,
+				Sender:    "user1",
+				Recipient: "user2",
+				Memo:      "спасибо за ревью",
+				Public:    tt.public,
+			})
+			if err != nil {
+				t.Fatalf("service.SendCoins() error = %v", err)
+			}
+			if !reflect.DeepEqual(gotKudos, tt.wantKudos) {
+				t.Errorf("service.SendCoins() kudos = %v, want %v", gotKudos, tt.wantKudos)
+			}
+			if len(history) == 0 {
+				t.Fatalf("service.SendCoins() history not recorded")
+			}
+			for _, entry := range history {
+				if entry.KudosID != tt.wantKudosID {
+					t.Errorf("service.SendCoins() history kudos id = %v, want %v", entry.KudosID, tt.wantKudosID)
+				}
+			}
+		})
+	}
+}
+
+func Test_service_GetKudosFeed(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	feed := []models.Kudos{
+		{ID: 30, Sender: "user1", Recipient: "user2", Message: "спасибо", Currency: models.CurrencyCoins, Amount: 10, CreatedAt: createdAt},
+		{ID: 20, Sender: "user2", Recipient: "user1", Message: "thanks", Currency: "kudos", Amount: 5, Boosted: 3, Boosts: 1, CreatedAt: createdAt},
+		{ID: 10, Sender: "user1", Recipient: "user3", Message: "ура", Currency: models.CurrencyCoins, Amount: 1, CreatedAt: createdAt},
+	}
+
+	tests := []struct {
+		name       string
+		qp         models.KudosFeedQuery
+		wantFilter models.KudosFeedFilter
+		want       models.KudosFeedDTO
+		wantErr    string
+	}{
+		{
+			name:       "success_-_first_page_has_next_cursor",
+			qp:         models.KudosFeedQuery{UserID: 1, Limit: 2},
+			wantFilter: models.KudosFeedFilter{Before: 0, Limit: 3},
+			want: models.KudosFeedDTO{
+				Items: []models.KudosDTO{
+					{ID: 30, FromUser: "user1", ToUser: "user2", Message: "спасибо", Amount: 10, Reactions: []models.KudosReactionDTO{
+						{Emoji: "🎉", Count: 2, Reacted: true},
+					}, CreatedAt: strfmt.DateTime(createdAt)},
+					{ID: 20, FromUser: "user2", ToUser: "user1", Message: "thanks", Currency: "kudos", Amount: 5, Boosted: 3, Boosts: 1, Reactions: []models.KudosReactionDTO{}, CreatedAt: strfmt.DateTime(createdAt)},
+				},
+				NextCursor: encodeIDCursor(20),
+			},
+		},
+		{
+			name:       "success_-_last_page_without_cursor",
+			qp:         models.KudosFeedQuery{UserID: 1, Cursor: encodeIDCursor(20)},
+			wantFilter: models.KudosFeedFilter{Before: 20, Limit: defaultKudosFeedLimit + 1},
+			want: models.KudosFeedDTO{
+				Items: []models.KudosDTO{
+					{ID: 10, FromUser: "user1", ToUser: "user3", Message: "ура", Amount: 1, Reactions: []models.KudosReactionDTO{}, CreatedAt: strfmt.DateTime(createdAt)},
+				},
+			},
+		},
+		{
+			name:    "fail_-_invalid_cursor",
+			qp:      models.KudosFeedQuery{UserID: 1, Cursor: "not-a-cursor"},
+			wantErr: internalErrors.ErrInvalidKudosReqParams,
+		},
+		{
+			name:    "fail_-_limit_too_large",
+			qp:      models.KudosFeedQuery{UserID: 1, Limit: maxKudosFeedLimit + 1},
+			wantErr: internalErrors.ErrInvalidKudosReqParams,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFilter := models.KudosFeedFilter{}
+			s := &service{
+				repo: &MockRepository{
+					GetKudosFeedFunc: func(ctx context.Context, filter models.KudosFeedFilter) ([]models.Kudos, error) {
+						gotFilter = filter
+						page := []models.Kudos{}
+						for _, kudos := range feed {
+							if (filter.Before == 0 || kudos.ID < filter.Before) && len(page) < filter.Limit {
+								page = append(page, kudos)
+							}
+						}
+						return page, nil
+					},
+					GetKudosReactionsFunc: func(ctx context.Context, kudosIDs []int64, userID int64) ([]models.KudosReaction, error) {
+						return []models.KudosReaction{{KudosID: 30, Emoji: "🎉", Count: 2, Reacted: true}}, nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.GetKudosFeed(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.GetKudosFeed() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.GetKudosFeed() error = %v", err)
+			}
+			if gotFilter != tt.wantFilter {
+				t.Errorf("service.GetKudosFeed() filter = %v, want %v", gotFilter, tt.wantFilter)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("service.GetKudosFeed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_service_BoostKudos(t *testing.T) {
+	visible := models.Kudos{ID: 7, Sender: "user3", Recipient: "user2", Message: "спасибо", Currency: models.CurrencyCoins, Amount: 10}
+
+	tests := []struct {
+		name        string
+		kudos       models.Kudos
+		qp          models.KudosBoostQuery
+		wantHistory []models.BalanceHistory
+		wantBoost   bool
+		wantErr     string
+	}{
+		{
+			name:  "success_-_boost_transfers_to_recipient",
+			kudos: visible,
+			qp:    models.KudosBoostQuery{UserID: 1, Username: "user1", KudosID: 7, Amount: 5},
+			wantHistory: []models.BalanceHistory{
+				{BalanceID: 1, TransactionAmount: 5, Sender: "user1", Recipient: "user2", Type: models.HistoryTypeTransfer, Reason: "спасибо", Currency: models.CurrencyCoins, KudosID: 7, TransferID: 1},
+				{BalanceID: 2, TransactionAmount: 5, Sender: "user1", Recipient: "user2", Type: models.HistoryTypeTransfer, Reason: "спасибо", Currency: models.CurrencyCoins, KudosID: 7, TransferID: 1},
+			},
+			wantBoost: true,
+		},
+		{
+			name:    "fail_-_own_kudos",
+			kudos:   visible,
+			qp:      models.KudosBoostQuery{UserID: 3, Username: "user3", KudosID: 7, Amount: 5},
+			wantErr: internalErrors.ErrKudosBoostOwn,
+		},
+		{
+			name:    "fail_-_recipient_boosts_themself",
+			kudos:   visible,
+			qp:      models.KudosBoostQuery{UserID: 2, Username: "user2", KudosID: 7, Amount: 5},
+			wantErr: internalErrors.ErrInvalidRecipientYourself,
+		},
+		{
+			name:    "fail_-_hidden_kudos",
+			kudos:   models.Kudos{ID: 7, Sender: "user3", Recipient: "user2", Hidden: true},
+			qp:      models.KudosBoostQuery{UserID: 1, Username: "user1", KudosID: 7, Amount: 5},
+			wantErr: internalErrors.ErrKudosNotFound,
+		},
+		{
+			name:    "fail_-_kudos_not_found",
+			qp:      models.KudosBoostQuery{UserID: 1, Username: "user1", KudosID: 8, Amount: 5},
+			wantErr: internalErrors.ErrKudosNotFound,
+		},
+		{
+			name:    "fail_-_invalid_amount",
+			kudos:   visible,
+			qp:      models.KudosBoostQuery{UserID: 1, Username: "user1", KudosID: 7},
+			wantErr: internalErrors.ErrInvalidKudosReqParams,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := []models.BalanceHistory{}
+			boosted := false
+			repo := newKudosTransferRepo(&history)
+			repo.GetKudosByIDFunc = func(ctx context.Context, kudosID int64) (models.Kudos, error) {
+				if kudosID != tt.kudos.ID {
+					return models.Kudos{}, nil
+				}
+				return tt.kudos, nil
+			}
+			repo.UpdateKudosBoostFunc = func(ctx context.Context, kudosID, amount, boosts int64) error {
+				boosted = kudosID == tt.qp.KudosID && amount == tt.qp.Amount && boosts == 1
+				return nil
+			}
+			s := &service{repo: repo, txManager: &MockTxManager{}}
+
+			err := s.BoostKudos(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.BoostKudos() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.BoostKudos() error = %v", err)
+			}
+			if !reflect.DeepEqual(history, tt.wantHistory) {
+				t.Errorf("service.BoostKudos() history = %v, want %v", history, tt.wantHistory)
+			}
+			if boosted != tt.wantBoost {
+				t.Errorf("service.BoostKudos() boosted = %v, want %v", boosted, tt.wantBoost)
+			}
+		})
+	}
+}
+
+func Test_service_ReactToKudos(t *testing.T) {
+	tests := []struct {
+		name      string
+		kudos     models.Kudos
+		emoji     string
+		wantAdded bool
+		wantErr   string
+	}{
+		{name: "success_-_reaction_added", kudos: models.Kudos{ID: 7}, emoji: "🎉", wantAdded: true},
+		{name: "fail_-_emoji_not_allowed", kudos: models.Kudos{ID: 7}, emoji: "💩", wantErr: internalErrors.ErrInvalidKudosReaction},
+		{name: "fail_-_hidden_kudos", kudos: models.Kudos{ID: 7, Hidden: true}, emoji: "🎉", wantErr: internalErrors.ErrKudosNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added := false
+			s := &service{
+				repo: &MockRepository{
+					GetKudosByIDFunc: func(ctx context.Context, kudosID int64) (models.Kudos, error) {
+						return tt.kudos, nil
+					},
+					AddKudosReactionFunc: func(ctx context.Context, kudosID, userID int64, emoji string) error {
+						added = true
+						return nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			err := s.ReactToKudos(context.Background(), models.KudosReactionQuery{UserID: 1, KudosID: 7, Emoji: tt.emoji})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.ReactToKudos() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.ReactToKudos() error = %v", err)
+			}
+			if added != tt.wantAdded {
+				t.Errorf("service.ReactToKudos() added = %v, want %v", added, tt.wantAdded)
+			}
+		})
+	}
+}
+
+func Test_service_reverseKudosTransfer(t *testing.T) {
+	tests := []struct {
+		name       string
+		entry      models.BalanceHistory
+		wantHidden bool
+		wantBoost  []int64
+	}{
+		{
+			name:       "success_-_original_transfer_hides_kudos",
+			entry:      models.BalanceHistory{TransactionAmount: 10, Sender: "user1", Recipient: "user2", KudosID: 7},
+			wantHidden: true,
+		},
+		{
+			name:      "success_-_boost_is_subtracted",
+			entry:     models.BalanceHistory{TransactionAmount: 5, Sender: "user3", Recipient: "user2", KudosID: 7},
+			wantBoost: []int64{-5, -1},
+		},
+		{
+			name:  "success_-_private_transfer_ignored",
+			entry: models.BalanceHistory{TransactionAmount: 5, Sender: "user1", Recipient: "user2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hidden := false
+			var gotBoost []int64
+			repo := &MockRepository{
+				GetKudosByIDFunc: func(ctx context.Context, kudosID int64) (models.Kudos, error) {
+					return models.Kudos{ID: kudosID, Sender: "user1", Recipient: "user2"}, nil
+				},
+				HideKudosFunc: func(ctx context.Context, kudosID int64) error {
+					hidden = true
+					return nil
+				},
+				UpdateKudosBoostFunc: func(ctx context.Context, kudosID, amount, boosts int64) error {
+					gotBoost = []int64{amount, boosts}
+					return nil
+				},
+			}
+			s := &service{repo: repo}
+
+			if err := s.reverseKudosTransfer(context.Background(), repo, tt.entry); err != nil {
+				t.Fatalf("service.reverseKudosTransfer() error = %v", err)
+			}
+			if hidden != tt.wantHidden {
+				t.Errorf("service.reverseKudosTransfer() hidden = %v, want %v", hidden, tt.wantHidden)
+			}
+			if !reflect.DeepEqual(gotBoost, tt.wantBoost) {
+				t.Errorf("service.reverseKudosTransfer() boost = %v, want %v", gotBoost, tt.wantBoost)
+			}
+		})
+	}
+}
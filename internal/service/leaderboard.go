@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	defaultLeaderboardLimit = 10
+	maxLeaderboardLimit     = 100
+)
+
+// Leaderboard
+// GetLeaderboard возвращает рейтинг организации или команды за текущую неделю, месяц или всё время.
+// Рейтинг по команде видят только её участники.
+func (s *service) GetLeaderboard(ctx context.Context, qp models.LeaderboardQuery) (models.LeaderboardDTO, error) {
+	if qp.Metric == "" {
+		qp.Metric = models.LeaderboardMetricReceived
+	}
+	if qp.Period == "" {
+		qp.Period = models.LeaderboardPeriodWeek
+	}
+	if qp.Limit == 0 {
+		qp.Limit = defaultLeaderboardLimit
+	}
+	switch qp.Metric {
+	case models.LeaderboardMetricReceived, models.LeaderboardMetricGiven, models.LeaderboardMetricThanked:
+	default:
+		return models.LeaderboardDTO{}, errors.New(internalErrors.ErrInvalidLeaderboardReqParams)
+	}
+	switch qp.Period {
+	case models.LeaderboardPeriodWeek, models.LeaderboardPeriodMonth, models.LeaderboardPeriodAll:
+	default:
+		return models.LeaderboardDTO{}, errors.New(internalErrors.ErrInvalidLeaderboardReqParams)
+	}
+	if qp.Limit < 1 || qp.Limit > maxLeaderboardLimit || qp.TeamID < 0 {
+		return models.LeaderboardDTO{}, errors.New(internalErrors.ErrInvalidLeaderboardReqParams)
+	}
+
+	start := leaderboardPeriodStart(qp.Period, time.Now().UTC())
+	leaderboardDTO := models.LeaderboardDTO{Metric: qp.Metric, Period: qp.Period, Entries: []models.LeaderboardEntryDTO{}}
+	if qp.Period != models.LeaderboardPeriodAll {
+		from := strfmt.DateTime(start)
+		leaderboardDTO.From = &from
+	}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted, ReadOnly: true}, func(ctx context.Context, repo Repository) error {
+		if qp.TeamID != 0 {
+			member, err := repo.GetTeamMember(ctx, qp.TeamID, qp.Username)
+			if err != nil {
+				return err
+			}
+			if member.UserID == 0 {
+				return errors.New(internalErrors.ErrTeamNotFound)
+			}
+		}
+
+		entries, err := repo.GetLeaderboard(ctx, models.LeaderboardFilter{
+			Metric:      qp.Metric,
+			Period:      qp.Period,
+			PeriodStart: start,
+			TeamID:      qp.TeamID,
+			Limit:       qp.Limit,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			leaderboardDTO.Entries = append(leaderboardDTO.Entries, models.LeaderboardEntryDTO(entry))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.LeaderboardDTO{}, err
+	}
+
+	return leaderboardDTO, nil
+}
+
+// SetLeaderboardOptOut скрывает пользователя из рейтинга или возвращает его туда.
+// Агрегаты продолжают копиться, поэтому вернувшийся пользователь сразу видит свои позиции.
+func (s *service) SetLeaderboardOptOut(ctx context.Context, userID int64, optOut bool) error {
+	return s.repo.SetLeaderboardOptOut(ctx, userID, optOut)
+}
+
+// recordLeaderboardTransfer обновляет агрегаты рейтинга в транзакции перевода.
+// В рейтинге учитываются только монеты, at определяет периоды, в которые попадает перевод.
+func (s *service) recordLeaderboardTransfer(ctx context.Context, repo Repository, entry models.BalanceHistory, amount int64, at time.Time) error {
+	if entry.Currency != models.CurrencyCoins {
+		return nil
+	}
+
+	return repo.RecordLeaderboardTransfer(ctx, entry.Sender, entry.Recipient, amount, leaderboardPeriods(at.UTC()))
+}
+
+func leaderboardPeriods(at time.Time) []models.LeaderboardPeriod {
+	periods := []string{models.LeaderboardPeriodWeek, models.LeaderboardPeriodMonth, models.LeaderboardPeriodAll}
+
+	result := make([]models.LeaderboardPeriod, 0, len(periods))
+	for _, period := range periods {
+		result = append(result, models.LeaderboardPeriod{Period: period, Start: leaderboardPeriodStart(period, at)})
+	}
+
+	return result
+}
+
+// leaderboardPeriodStart начало периода рейтинга в UTC. У периода "всё время" одно начало - начало эпохи.
+func leaderboardPeriodStart(period string, at time.Time) time.Time {
+	switch period {
+	case models.LeaderboardPeriodWeek:
+		return periodStart(at, config.AllowancePeriodWeekly)
+	case models.LeaderboardPeriodMonth:
+		return periodStart(at, config.AllowancePeriodMonthly)
+	default:
+		return time.Unix(0, 0).UTC()
+	}
+}
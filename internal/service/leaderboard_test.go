@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func recordLeaderboardTransferNoop(ctx context.Context, sender, recipient string, amount int64, periods []models.LeaderboardPeriod) error {
+	return nil
+}
+
+func Test_service_GetLeaderboard(t *testing.T) {
+	entries := []models.LeaderboardEntry{
+		{Rank: 1, Username: "user2", Value: 300},
+		{Rank: 2, Username: "user3", Value: 100},
+	}
+
+	tests := []struct {
+		name       string
+		qp         models.LeaderboardQuery
+		member     models.TeamMember
+		wantFilter models.LeaderboardFilter
+		want       []models.LeaderboardEntryDTO
+		wantErr    string
+	}{
+		{
+			name:       "success_-_defaults_to_received_this_week",
+			qp:         models.LeaderboardQuery{Username: "user1"},
+			wantFilter: models.LeaderboardFilter{Metric: models.LeaderboardMetricReceived, Period: models.LeaderboardPeriodWeek, Limit: defaultLeaderboardLimit},
+			want: []models.LeaderboardEntryDTO{
+				{Rank: 1, Username: "user2", Value: 300},
+				{Rank: 2, Username: "user3", Value: 100},
+			},
+		},
+		{
+			name:       "success_-_team_leaderboard_for_member",
+			qp:         models.LeaderboardQuery{Username: "user1", Metric: models.LeaderboardMetricThanked, Period: models.LeaderboardPeriodAll, TeamID: 7, Limit: 5},
+			member:     models.TeamMember{TeamID: 7, UserID: 1, Username: "user1", Role: models.TeamRoleMember},
+			wantFilter: models.LeaderboardFilter{Metric: models.LeaderboardMetricThanked, Period: models.LeaderboardPeriodAll, TeamID: 7, Limit: 5},
+			want: []models.LeaderboardEntryDTO{
+				{Rank: 1, Username: "user2", Value: 300},
+				{Rank: 2, Username: "user3", Value: 100},
+			},
+		},
+		{
+			name:    "error_-_team_leaderboard_for_stranger",
+			qp:      models.LeaderboardQuery{Username: "user1", TeamID: 7},
+			wantErr: internalErrors.ErrTeamNotFound,
+		},
+		{
+			name:    "error_-_unknown_metric",
+			qp:      models.LeaderboardQuery{Username: "user1", Metric: "spent"},
+			wantErr: internalErrors.ErrInvalidLeaderboardReqParams,
+		},
+		{
+			name:    "error_-_unknown_period",
+			qp:      models.LeaderboardQuery{Username: "user1", Period: "year"},
+			wantErr: internalErrors.ErrInvalidLeaderboardReqParams,
+		},
+		{
+			name:    "error_-_limit_too_large",
+			qp:      models.LeaderboardQuery{Username: "user1", Limit: maxLeaderboardLimit + 1},
+			wantErr: internalErrors.ErrInvalidLeaderboardReqParams,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotFilter models.LeaderboardFilter
+			s := &service{
+				repo: &MockRepository{
+					GetTeamMemberFunc: func(ctx context.Context, teamID int64, username string) (models.TeamMember, error) {
+						return tt.member, nil
+					},
+					GetLeaderboardFunc: func(ctx context.Context, filter models.LeaderboardFilter) ([]models.LeaderboardEntry, error) {
+						gotFilter = filter
+						return entries, nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.GetLeaderboard(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.GetLeaderboard() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.GetLeaderboard() unexpected error = %v", err)
+			}
+
+			wantStart := leaderboardPeriodStart(tt.wantFilter.Period, time.Now().UTC())
+			tt.wantFilter.PeriodStart = wantStart
+			if !reflect.DeepEqual(gotFilter, tt.wantFilter) {
+				t.Errorf("service.GetLeaderboard() filter = %+v, want %+v", gotFilter, tt.wantFilter)
+			}
+			if !reflect.DeepEqual(got.Entries, tt.want) {
+				t.Errorf("service.GetLeaderboard() entries = %v, want %v", got.Entries, tt.want)
+			}
+			if (got.From == nil) != (tt.wantFilter.Period == models.LeaderboardPeriodAll) {
+				t.Errorf("service.GetLeaderboard() from = %v, period %v", got.From, tt.wantFilter.Period)
+			}
+		})
+	}
+}
+
+func Test_leaderboardPeriods(t *testing.T) {
+	at := time.Date(2026, time.October, 15, 13, 45, 0, 0, time.UTC)
+
+	want := []models.LeaderboardPeriod{
+		{Period: models.LeaderboardPeriodWeek, Start: time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC)},
+		{Period: models.LeaderboardPeriodMonth, Start: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)},
+		{Period: models.LeaderboardPeriodAll, Start: time.Unix(0, 0).UTC()},
+	}
+
+	got := leaderboardPeriods(at)
+	if len(got) != len(want) {
+		t.Fatalf("leaderboardPeriods() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].Period != want[i].Period || !got[i].Start.Equal(want[i].Start) {
+			t.Errorf("leaderboardPeriods()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func Test_service_recordLeaderboardTransfer(t *testing.T) {
+	at := time.Date(2026, time.October, 15, 13, 45, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		entry      models.BalanceHistory
+		amount     int64
+		recordErr  error
+		wantCalled bool
+		wantErr    bool
+	}{
+		{
+			name:       "success_-_coins_transfer_recorded",
+			entry:      models.BalanceHistory{Sender: "user1", Recipient: "user2", Currency: models.CurrencyCoins},
+			amount:     100,
+			wantCalled: true,
+		},
+		{
+			name:       "success_-_reversal_recorded_with_negative_amount",
+			entry:      models.BalanceHistory{Sender: "user1", Recipient: "user2", Currency: models.CurrencyCoins},
+			amount:     -100,
+			wantCalled: true,
+		},
+		{
+			name:   "success_-_other_currency_skipped",
+			entry:  models.BalanceHistory{Sender: "user1", Recipient: "user2", Currency: "kudos"},
+			amount: 100,
+		},
+		{
+			name:   "success_-_team_deposit_skipped",
+			entry:  models.BalanceHistory{Sender: "user1", Recipient: "team:qa"},
+			amount: 100,
+		},
+		{
+			name:       "error_-_repo_failed",
+			entry:      models.BalanceHistory{Sender: "user1", Recipient: "user2", Currency: models.CurrencyCoins},
+			amount:     100,
+			recordErr:  errors.New("db down"),
+			wantCalled: true,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			repo := &MockRepository{
+				RecordLeaderboardTransferFunc: func(ctx context.Context, sender, recipient string, amount int64, periods []models.LeaderboardPeriod) error {
+					called = true
+					if sender != tt.entry.Sender || recipient != tt.entry.Recipient || amount != tt.amount {
+						t.Errorf("RecordLeaderboardTransfer() got %s -> %s %d", sender, recipient, amount)
+					}
+					if len(periods) != 3 {
+						t.Errorf("RecordLeaderboardTransfer() periods = %v", periods)
+					}
+					return tt.recordErr
+				},
+			}
+			s := &service{repo: repo}
+
+			err := s.recordLeaderboardTransfer(context.Background(), repo, tt.entry, tt.amount, at)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("service.recordLeaderboardTransfer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if called != tt.wantCalled {
+				t.Errorf("service.recordLeaderboardTransfer() called = %v, want %v", called, tt.wantCalled)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// checkTransferLimits проверяет лимиты отправителя перед переводом.
+// Вызывается после блокировки баланса отправителя, поэтому параллельные переводы не обходят лимиты,
+// а переводы внутри одной транзакции учитываются по уже созданной истории.
+func (s *service) checkTransferLimits(ctx context.Context, repo Repository, senderBalanceID, recipientBalanceID int64, entry models.BalanceHistory, now time.Time) error {
+	if !s.cfg.TransferLimit.Enabled() {
+		return nil
+	}
+
+	role, err := repo.GetUserRoleByUsername(ctx, entry.Sender)
+	if err != nil {
+		return err
+	}
+	limits := s.cfg.TransferLimit.ForRole(role)
+
+	if limits.MaxAmount > 0 && entry.TransactionAmount > limits.MaxAmount {
+		return &internalErrors.LimitError{Code: internalErrors.ErrTransferAmountLimit}
+	}
+
+	if limits.HourlyCount > 0 {
+		// скользящее окно: пачка переводов на стыке часов не обходит лимит
+		usage, err := repo.GetSentTransferUsage(ctx, senderBalanceID, entry.Sender, now.Add(-time.Hour))
+		if err != nil {
+			return err
+		}
+		if usage.Count+1 > limits.HourlyCount {
+			return &internalErrors.LimitError{Code: internalErrors.ErrHourlyTransferCountLimit, ResetAt: usage.OldestAt.Add(time.Hour)}
+		}
+	}
+
+	totals := []struct {
+		limit  int64
+		period string
+		code   string
+	}{
+		{limits.DailyTotal, config.AllowancePeriodDaily, internalErrors.ErrDailyTransferLimit},
+		{limits.WeeklyTotal, config.AllowancePeriodWeekly, internalErrors.ErrWeeklyTransferLimit},
+	}
+	for _, total := range totals {
+		if total.limit <= 0 {
+			continue
+		}
+
+		since := periodStart(now, total.period)
+		usage, err := repo.GetSentTransferUsage(ctx, senderBalanceID, entry.Sender, since)
+		if err != nil {
+			return err
+		}
+		if usage.Amount+entry.TransactionAmount > total.limit {
+			return &internalErrors.LimitError{Code: total.code, ResetAt: periodEnd(since, total.period)}
+		}
+	}
+
+	if limits.ReceivedTotal > 0 {
+		period := s.cfg.TransferLimit.ReceivedPeriod
+		since := periodStart(now, period)
+		usage, err := repo.GetTransferUsage(ctx, recipientBalanceID, entry.Sender, since)
+		if err != nil {
+			return err
+		}
+		if usage.Amount+entry.TransactionAmount > limits.ReceivedTotal {
+			return &internalErrors.LimitError{Code: internalErrors.ErrReceivedFromSenderLimit, ResetAt: periodEnd(since, period)}
+		}
+	}
+
+	return nil
+}
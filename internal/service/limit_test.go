@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func Test_service_checkTransferLimits(t *testing.T) {
+	now := time.Date(2026, 10, 21, 15, 30, 0, 0, time.UTC)
+	entry := models.BalanceHistory{TransactionAmount: 100, Sender: "user1", Recipient: "user2", Type: models.HistoryTypeTransfer}
+
+	tests := []struct {
+		name        string
+		limit       config.TransferLimit
+		role        string
+		sent        models.TransferUsage
+		sentAt      time.Time
+		received    models.TransferUsage
+		wantCode    string
+		wantResetAt time.Time
+	}{
+		{
+			name: "success_-_limits_disabled",
+		},
+		{
+			name:  "success_-_within_limits",
+			limit: config.TransferLimit{Default: config.TransferLimits{MaxAmount: 500, DailyTotal: 1000, HourlyCount: 5}},
+			sent:  models.TransferUsage{Amount: 900, Count: 4},
+		},
+		{
+			name:     "error_-_max_amount_exceeded",
+			limit:    config.TransferLimit{Default: config.TransferLimits{MaxAmount: 50}},
+			wantCode: internalErrors.ErrTransferAmountLimit,
+		},
+		{
+			name:        "error_-_hourly_count_exceeded",
+			limit:       config.TransferLimit{Default: config.TransferLimits{HourlyCount: 3}},
+			sent:        models.TransferUsage{Amount: 30, Count: 3},
+			sentAt:      time.Date(2026, 10, 21, 15, 0, 0, 0, time.UTC),
+			wantCode:    internalErrors.ErrHourlyTransferCountLimit,
+			wantResetAt: time.Date(2026, 10, 21, 16, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "error_-_hourly_count_exceeded_across_hour_boundary",
+			limit:       config.TransferLimit{Default: config.TransferLimits{HourlyCount: 3}},
+			sent:        models.TransferUsage{Amount: 30, Count: 3},
+			sentAt:      time.Date(2026, 10, 21, 14, 45, 0, 0, time.UTC),
+			wantCode:    internalErrors.ErrHourlyTransferCountLimit,
+			wantResetAt: time.Date(2026, 10, 21, 15, 45, 0, 0, time.UTC),
+		},
+		{
+			name:   "success_-_transfers_older_than_hour_not_counted",
+			limit:  config.TransferLimit{Default: config.TransferLimits{HourlyCount: 3}},
+			sent:   models.TransferUsage{Amount: 30, Count: 3},
+			sentAt: time.Date(2026, 10, 21, 14, 29, 0, 0, time.UTC),
+		},
+		{
+			name:        "error_-_daily_total_exceeded",
+			limit:       config.TransferLimit{Default: config.TransferLimits{DailyTotal: 1000}},
+			sent:        models.TransferUsage{Amount: 950, Count: 2},
+			wantCode:    internalErrors.ErrDailyTransferLimit,
+			wantResetAt: time.Date(2026, 10, 22, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "error_-_weekly_total_exceeded",
+			limit:       config.TransferLimit{Default: config.TransferLimits{WeeklyTotal: 1000}},
+			sent:        models.TransferUsage{Amount: 950, Count: 2},
+			wantCode:    internalErrors.ErrWeeklyTransferLimit,
+			wantResetAt: time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "error_-_received_from_sender_exceeded",
+			limit:       config.TransferLimit{Default: config.TransferLimits{ReceivedTotal: 150}, ReceivedPeriod: config.AllowancePeriodMonthly},
+			received:    models.TransferUsage{Amount: 100, Count: 1},
+			wantCode:    internalErrors.ErrReceivedFromSenderLimit,
+			wantResetAt: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "success_-_admin_override_disables_limit",
+			limit: config.TransferLimit{Default: config.TransferLimits{MaxAmount: 50}, Admin: config.TransferLimits{MaxAmount: -1}},
+			role:  models.RoleAdmin,
+		},
+		{
+			name:     "error_-_admin_inherits_default_limit",
+			limit:    config.TransferLimit{Default: config.TransferLimits{MaxAmount: 50}, Admin: config.TransferLimits{DailyTotal: 5000}},
+			role:     models.RoleAdmin,
+			wantCode: internalErrors.ErrTransferAmountLimit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := tt.role
+			if role == "" {
+				role = models.RoleUser
+			}
+			s := &service{
+				repo: &MockRepository{
+					GetUserRoleByUsernameFunc: func(ctx context.Context, username string) (string, error) {
+						return role, nil
+					},
+					GetTransferUsageFunc: func(ctx context.Context, balanceID int64, sender string, since time.Time) (models.TransferUsage, error) {
+						return tt.received, nil
+					},
+					GetSentTransferUsageFunc: func(ctx context.Context, balanceID int64, username string, since time.Time) (models.TransferUsage, error) {
+						// переводы отправлены в sentAt и попадают только в окна, которые его включают
+						if !tt.sentAt.IsZero() && since.After(tt.sentAt) {
+							return models.TransferUsage{OldestAt: since}, nil
+						}
+						usage := tt.sent
+						usage.OldestAt = tt.sentAt
+						return usage, nil
+					},
+				},
+				cfg: config.Config{TransferLimit: tt.limit},
+			}
+
+			err := s.checkTransferLimits(context.Background(), s.repo, 1, 2, entry, now)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("service.checkTransferLimits() error = %v, want nil", err)
+				}
+				return
+			}
+
+			limitErr := &internalErrors.LimitError{}
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("service.checkTransferLimits() error = %v, want LimitError", err)
+			}
+			if limitErr.Code != tt.wantCode || !limitErr.ResetAt.Equal(tt.wantResetAt) {
+				t.Errorf("service.checkTransferLimits() = %v %v, want %v %v", limitErr.Code, limitErr.ResetAt, tt.wantCode, tt.wantResetAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+const (
+	defaultNotificationsLimit = 20
+	maxNotificationsLimit     = 100
+	maxNotificationsReadIDs   = 100
+)
+
+// Notification
+// GetNotifications возвращает страницу входящих пользователя, начиная с последних
+func (s *service) GetNotifications(ctx context.Context, qp models.NotificationsQuery) (models.NotificationsDTO, error) {
+	if qp.Limit == 0 {
+		qp.Limit = defaultNotificationsLimit
+	}
+	if qp.Limit < 1 || qp.Limit > maxNotificationsLimit {
+		return models.NotificationsDTO{}, errors.New(internalErrors.ErrInvalidNotificationReqParams)
+	}
+	before, err := decodeIDCursor(qp.Cursor)
+	if err != nil {
+		return models.NotificationsDTO{}, errors.New(internalErrors.ErrInvalidNotificationReqParams)
+	}
+
+	// лишняя запись показывает, что за страницей есть продолжение
+	notifications, err := s.repo.GetNotifications(ctx, models.NotificationFilter{
+		UserID:     qp.UserID,
+		UnreadOnly: qp.UnreadOnly,
+		Before:     before,
+		Limit:      qp.Limit + 1,
+	})
+	if err != nil {
+		return models.NotificationsDTO{}, err
+	}
+
+	notificationsDTO := models.NotificationsDTO{Items: make([]models.NotificationDTO, 0, len(notifications))}
+	if len(notifications) > qp.Limit {
+		notifications = notifications[:qp.Limit]
+		notificationsDTO.NextCursor = encodeIDCursor(notifications[len(notifications)-1].ID)
+	}
+	for _, notification := range notifications {
+		notificationsDTO.Items = append(notificationsDTO.Items, notification.ToModelNotificationDTO())
+	}
+
+	return notificationsDTO, nil
+}
+
+func (s *service) CountUnreadNotifications(ctx context.Context, userID int64) (models.UnreadNotificationsDTO, error) {
+	unread, err := s.repo.CountUnreadNotifications(ctx, userID)
+	if err != nil {
+		return models.UnreadNotificationsDTO{}, err
+	}
+
+	return models.UnreadNotificationsDTO{Unread: unread}, nil
+}
+
+// MarkNotificationsRead отмечает уведомления прочитанными и возвращает оставшееся количество непрочитанных
+func (s *service) MarkNotificationsRead(ctx context.Context, qp models.NotificationsReadQuery) (models.UnreadNotificationsDTO, error) {
+	if qp.All == (len(qp.IDs) > 0) || len(qp.IDs) > maxNotificationsReadIDs {
+		return models.UnreadNotificationsDTO{}, errors.New(internalErrors.ErrInvalidNotificationReqParams)
+	}
+
+	unreadDTO := models.UnreadNotificationsDTO{}
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		if _, err := repo.MarkNotificationsRead(ctx, qp.UserID, qp.IDs, qp.All); err != nil {
+			return err
+		}
+
+		var err error
+		unreadDTO.Unread, err = repo.CountUnreadNotifications(ctx, qp.UserID)
+
+		return err
+	})
+	if err != nil {
+		return models.UnreadNotificationsDTO{}, err
+	}
+
+	return unreadDTO, nil
+}
+
+func (s *service) GetNotificationPreferences(ctx context.Context, username string) (models.NotificationPreferencesDTO, error) {
+	preferences, err := s.repo.GetNotificationPreferences(ctx, username)
+	if err != nil {
+		return models.NotificationPreferencesDTO{}, err
+	}
+
+	return s.toNotificationPreferencesDTO(preferences), nil
+}
+
+// UpdateNotificationPreferences меняет переданные настройки, остальные сохраняются
+func (s *service) UpdateNotificationPreferences(ctx context.Context, userID int64, username string, body models.NotificationPreferencesReqBody) (models.NotificationPreferencesDTO, error) {
+	if body.LowBalanceThreshold != nil && *body.LowBalanceThreshold < 0 {
+		return models.NotificationPreferencesDTO{}, errors.New(internalErrors.ErrInvalidNotificationReqParams)
+	}
+
+	preferences := models.NotificationPreferences{}
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		var err error
+		preferences, err = repo.GetNotificationPreferences(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		for _, field := range []struct {
+			value  *bool
+			target *bool
+		}{
+			{body.CoinsReceived, &preferences.CoinsReceived},
+			{body.Purchase, &preferences.Purchase},
+			{body.LowBalance, &preferences.LowBalance},
+			{body.Grant, &preferences.Grant},
+		} {
+			if field.value != nil {
+				*field.target = *field.value
+			}
+		}
+		if body.LowBalanceThreshold != nil {
+			preferences.LowBalanceThreshold = body.LowBalanceThreshold
+		}
+
+		return repo.SetNotificationPreferences(ctx, userID, preferences)
+	})
+	if err != nil {
+		return models.NotificationPreferencesDTO{}, err
+	}
+
+	return s.toNotificationPreferencesDTO(preferences), nil
+}
+
+func (s *service) toNotificationPreferencesDTO(preferences models.NotificationPreferences) models.NotificationPreferencesDTO {
+	return models.NotificationPreferencesDTO{
+		CoinsReceived:       preferences.CoinsReceived,
+		Purchase:            preferences.Purchase,
+		LowBalance:          preferences.LowBalance,
+		Grant:               preferences.Grant,
+		LowBalanceThreshold: s.lowBalanceThreshold(preferences),
+	}
+}
+
+// lowBalanceThreshold порог пользователя или порог из конфигурации, 0 - уведомление отключено
+func (s *service) lowBalanceThreshold(preferences models.NotificationPreferences) int64 {
+	if preferences.LowBalanceThreshold != nil {
+		return *preferences.LowBalanceThreshold
+	}
+
+	return max(s.cfg.Notification.LowBalanceThreshold, 0)
+}
+
+// notify добавляет уведомление во входящие, если пользователь его не отключил.
+// Вызывается в транзакции события, поэтому уведомление фиксируется или откатывается вместе с ним.
+func (s *service) notify(ctx context.Context, repo Repository, username string, notification models.Notification) error {
+	preferences, err := repo.GetNotificationPreferences(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !preferences.Enabled(notification.Type) {
+		return nil
+	}
+
+	return repo.CreateNotification(ctx, username, notification)
+}
+
+// notifyCoinsReceived уведомляет получателя перевода. Кошелёк команды уведомлений не получает.
+func (s *service) notifyCoinsReceived(ctx context.Context, repo Repository, entry models.BalanceHistory) error {
+	return s.notify(ctx, repo, entry.Recipient, models.Notification{
+		Type:     models.NotificationTypeCoinsReceived,
+		FromUser: entry.Sender,
+		Amount:   entry.TransactionAmount,
+		Currency: currencyOrCoins(entry.Currency),
+		Message:  entry.Reason,
+	})
+}
+
+// notifyLowBalance уведомляет, когда списание debited опустило баланс монет пользователя ниже порога.
+// Уведомление отправляется только при пересечении порога, а не при каждом списании ниже него.
+func (s *service) notifyLowBalance(ctx context.Context, repo Repository, username, currency string, debited int64) error {
+	if currencyOrCoins(currency) != models.CurrencyCoins {
+		return nil
+	}
+
+	preferences, err := repo.GetNotificationPreferences(ctx, username)
+	if err != nil {
+		return err
+	}
+	threshold := s.lowBalanceThreshold(preferences)
+	if !preferences.LowBalance || threshold == 0 {
+		return nil
+	}
+
+	wallet, err := repo.GetOrCreateWallet(ctx, username, models.CurrencyCoins)
+	if err != nil {
+		return err
+	}
+	if wallet.Amount >= threshold || wallet.Amount+debited < threshold {
+		return nil
+	}
+
+	return repo.CreateNotification(ctx, username, models.Notification{
+		Type:     models.NotificationTypeLowBalance,
+		Amount:   wallet.Amount,
+		Currency: models.CurrencyCoins,
+	})
+}
+
+// currencyOrCoins пустая валюта записи означает монеты, так записываются переводы с кошельками команд
+func currencyOrCoins(currency string) string {
+	if currency == "" {
+		return models.CurrencyCoins
+	}
+
+	return currency
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/devWaylander/coins_store/config"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func getNoNotificationPreferences(ctx context.Context, username string) (models.NotificationPreferences, error) {
+	return models.NotificationPreferences{}, nil
+}
+
+func Test_service_notify(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences models.NotificationPreferences
+		want        bool
+	}{
+		{
+			name:        "success_-_enabled_by_default",
+			preferences: models.DefaultNotificationPreferences(),
+			want:        true,
+		},
+		{
+			name: "success_-_disabled_type_skipped",
+			preferences: models.NotificationPreferences{
+				Purchase:   true,
+				LowBalance: true,
+				Grant:      true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := false
+			repo := &MockRepository{
+				GetNotificationPreferencesFunc: func(ctx context.Context, username string) (models.NotificationPreferences, error) {
+					return tt.preferences, nil
+				},
+				CreateNotificationFunc: func(ctx context.Context, username string, notification models.Notification) error {
+					got = true
+					return nil
+				},
+			}
+			s := &service{repo: repo}
+
+			err := s.notifyCoinsReceived(context.Background(), repo, models.BalanceHistory{
+				TransactionAmount: 10,
+				Sender:            "alice",
+				Recipient:         "bob",
+			})
+			if err != nil {
+				t.Fatalf("service.notifyCoinsReceived() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("service.notifyCoinsReceived() created = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_service_notifyLowBalance(t *testing.T) {
+	var (
+		custom   int64 = 50
+		disabled int64 = 0
+	)
+
+	tests := []struct {
+		name       string
+		threshold  *int64
+		lowBalance bool
+		currency   string
+		amount     int64
+		debited    int64
+		want       []models.Notification
+	}{
+		{
+			name:       "success_-_crossed_default_threshold",
+			lowBalance: true,
+			currency:   models.CurrencyCoins,
+			amount:     90,
+			debited:    20,
+			want:       []models.Notification{{Type: models.NotificationTypeLowBalance, Amount: 90, Currency: models.CurrencyCoins}},
+		},
+		{
+			name:       "success_-_team_transfer_without_currency",
+			lowBalance: true,
+			amount:     0,
+			debited:    100,
+			want:       []models.Notification{{Type: models.NotificationTypeLowBalance, Amount: 0, Currency: models.CurrencyCoins}},
+		},
+		{
+			name:       "success_-_already_below_threshold",
+			lowBalance: true,
+			currency:   models.CurrencyCoins,
+			amount:     80,
+			debited:    10,
+			want:       []models.Notification{},
+		},
+		{
+			name:       "success_-_still_above_threshold",
+			lowBalance: true,
+			currency:   models.CurrencyCoins,
+			amount:     100,
+			debited:    10,
+			want:       []models.Notification{},
+		},
+		{
+			name:       "success_-_custom_threshold",
+			threshold:  &custom,
+			lowBalance: true,
+			currency:   models.CurrencyCoins,
+			amount:     90,
+			debited:    20,
+			want:       []models.Notification{},
+		},
+		{
+			name:       "success_-_zero_threshold_disables",
+			threshold:  &disabled,
+			lowBalance: true,
+			currency:   models.CurrencyCoins,
+			amount:     0,
+			debited:    20,
+			want:       []models.Notification{},
+		},
+		{
+			name:     "success_-_disabled_by_user",
+			currency: models.CurrencyCoins,
+			amount:   90,
+			debited:  20,
+			want:     []models.Notification{},
+		},
+		{
+			name:       "success_-_other_currency_ignored",
+			lowBalance: true,
+			currency:   "stars",
+			amount:     0,
+			debited:    20,
+			want:       []models.Notification{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []models.Notification{}
+			repo := &MockRepository{
+				GetNotificationPreferencesFunc: func(ctx context.Context, username string) (models.NotificationPreferences, error) {
+					return models.NotificationPreferences{LowBalance: tt.lowBalance, LowBalanceThreshold: tt.threshold}, nil
+				},
+				GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+					return models.Balance{ID: 5, Amount: tt.amount}, nil
+				},
+				CreateNotificationFunc: func(ctx context.Context, username string, notification models.Notification) error {
+					got = append(got, notification)
+					return nil
+				},
+			}
+			s := &service{repo: repo, cfg: config.Config{Notification: config.Notification{LowBalanceThreshold: 100}}}
+
+			if err := s.notifyLowBalance(context.Background(), repo, "alice", tt.currency, tt.debited); err != nil {
+				t.Fatalf("service.notifyLowBalance() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("service.notifyLowBalance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_service_GetNotifications(t *testing.T) {
+	notifications := []models.Notification{
+		{ID: 30, Type: models.NotificationTypeGrant},
+		{ID: 20, Type: models.NotificationTypeCoinsReceived},
+		{ID: 10, Type: models.NotificationTypePurchase},
+	}
+
+	tests := []struct {
+		name           string
+		qp             models.NotificationsQuery
+		wantBefore     int64
+		wantIDs        []int64
+		wantNextCursor string
+		wantErr        string
+	}{
+		{
+			name:           "success_-_first_page_has_next",
+			qp:             models.NotificationsQuery{UserID: 1, Limit: 2},
+			wantIDs:        []int64{30, 20},
+			wantNextCursor: encodeIDCursor(20),
+		},
+		{
+			name:       "success_-_next_page",
+			qp:         models.NotificationsQuery{UserID: 1, Limit: 2, Cursor: encodeIDCursor(20)},
+			wantBefore: 20,
+			wantIDs:    []int64{10},
+		},
+		{
+			name:    "error_-_limit_too_large",
+			qp:      models.NotificationsQuery{UserID: 1, Limit: maxNotificationsLimit + 1},
+			wantErr: internalErrors.ErrInvalidNotificationReqParams,
+		},
+		{
+			name:    "error_-_invalid_cursor",
+			qp:      models.NotificationsQuery{UserID: 1, Cursor: "!"},
+			wantErr: internalErrors.ErrInvalidNotificationReqParams,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{
+				GetNotificationsFunc: func(ctx context.Context, filter models.NotificationFilter) ([]models.Notification, error) {
+					if filter.Before != tt.wantBefore {
+						t.Errorf("GetNotifications() before = %v, want %v", filter.Before, tt.wantBefore)
+					}
+					page := []models.Notification{}
+					for _, notification := range notifications {
+						if (filter.Before == 0 || notification.ID < filter.Before) && len(page) < filter.Limit {
+							page = append(page, notification)
+						}
+					}
+					return page, nil
+				},
+			}
+			s := &service{repo: repo}
+
+			got, err := s.GetNotifications(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("service.GetNotifications() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.GetNotifications() error = %v", err)
+			}
+
+			gotIDs := []int64{}
+			for _, item := range got.Items {
+				gotIDs = append(gotIDs, item.ID)
+			}
+			if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+				t.Errorf("service.GetNotifications() ids = %v, want %v", gotIDs, tt.wantIDs)
+			}
+			if got.NextCursor != tt.wantNextCursor {
+				t.Errorf("service.GetNotifications() nextCursor = %v, want %v", got.NextCursor, tt.wantNextCursor)
+			}
+		})
+	}
+}
+
+func Test_service_MarkNotificationsRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		qp      models.NotificationsReadQuery
+		wantErr string
+	}{
+		{
+			name: "success_-_selected",
+			qp:   models.NotificationsReadQuery{UserID: 1, IDs: []int64{1, 2}},
+		},
+		{
+			name: "success_-_all",
+			qp:   models.NotificationsReadQuery{UserID: 1, All: true},
+		},
+		{
+			name:    "error_-_nothing_selected",
+			qp:      models.NotificationsReadQuery{UserID: 1},
+			wantErr: internalErrors.ErrInvalidNotificationReqParams,
+		},
+		{
+			name:    "error_-_all_with_ids",
+			qp:      models.NotificationsReadQuery{UserID: 1, IDs: []int64{1}, All: true},
+			wantErr: internalErrors.ErrInvalidNotificationReqParams,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{
+				MarkNotificationsReadFunc: func(ctx context.Context, userID int64, notificationIDs []int64, all bool) (int64, error) {
+					if all != tt.qp.All || !reflect.DeepEqual(notificationIDs, tt.qp.IDs) {
+						t.Errorf("MarkNotificationsRead() ids = %v, all = %v", notificationIDs, all)
+					}
+					return int64(len(notificationIDs)), nil
+				},
+				CountUnreadNotificationsFunc: func(ctx context.Context, userID int64) (int64, error) {
+					return 3, nil
+				},
+			}
+			s := &service{repo: repo, txManager: &MockTxManager{}}
+
+			got, err := s.MarkNotificationsRead(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("service.MarkNotificationsRead() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.MarkNotificationsRead() error = %v", err)
+			}
+			if got.Unread != 3 {
+				t.Errorf("service.MarkNotificationsRead() unread = %v, want 3", got.Unread)
+			}
+		})
+	}
+}
+
+func Test_service_UpdateNotificationPreferences(t *testing.T) {
+	var (
+		off       = false
+		threshold = int64(30)
+		negative  = int64(-1)
+	)
+
+	tests := []struct {
+		name    string
+		body    models.NotificationPreferencesReqBody
+		want    models.NotificationPreferencesDTO
+		wantErr string
+	}{
+		{
+			name: "success_-_only_passed_fields_changed",
+			body: models.NotificationPreferencesReqBody{Purchase: &off},
+			want: models.NotificationPreferencesDTO{CoinsReceived: true, LowBalance: true, Grant: true, LowBalanceThreshold: 100},
+		},
+		{
+			name: "success_-_custom_threshold",
+			body: models.NotificationPreferencesReqBody{LowBalanceThreshold: &threshold},
+			want: models.NotificationPreferencesDTO{CoinsReceived: true, Purchase: true, LowBalance: true, Grant: true, LowBalanceThreshold: 30},
+		},
+		{
+			name:    "error_-_negative_threshold",
+			body:    models.NotificationPreferencesReqBody{LowBalanceThreshold: &negative},
+			wantErr: internalErrors.ErrInvalidNotificationReqParams,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := false
+			repo := &MockRepository{
+				GetNotificationPreferencesFunc: func(ctx context.Context, username string) (models.NotificationPreferences, error) {
+					return models.DefaultNotificationPreferences(), nil
+				},
+				SetNotificationPreferencesFunc: func(ctx context.Context, userID int64, preferences models.NotificationPreferences) error {
+					saved = true
+					return nil
+				},
+			}
+			s := &service{
+				repo:      repo,
+				txManager: &MockTxManager{},
+				cfg:       config.Config{Notification: config.Notification{LowBalanceThreshold: 100}},
+			}
+
+			got, err := s.UpdateNotificationPreferences(context.Background(), 1, "alice", tt.body)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("service.UpdateNotificationPreferences() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.UpdateNotificationPreferences() error = %v", err)
+			}
+			if !saved {
+				t.Errorf("service.UpdateNotificationPreferences() preferences not saved")
+			}
+			if got != tt.want {
+				t.Errorf("service.UpdateNotificationPreferences() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
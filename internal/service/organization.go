@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// forEachOrganization выполняет фоновую задачу отдельно для каждой организации.
+// У задач нет токена, поэтому организация кладётся в контекст так же, как это делает middleware.
+// Ошибка одной организации не останавливает задачу для остальных, ошибки возвращаются вместе.
+func (s *service) forEachOrganization(ctx context.Context, fn func(ctx context.Context) error) error {
+	orgIDs, err := s.repo.GetOrganizationIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for _, orgID := range orgIDs {
+		if err := fn(context.WithValue(ctx, models.OrgIDKey, orgID)); err != nil {
+			log.Logger.Err(err).Msgf("job failed for organization %d", orgID)
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
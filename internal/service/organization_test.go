@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func getSingleOrganization(ctx context.Context) ([]int64, error) {
+	return []int64{1}, nil
+}
+
+func Test_service_forEachOrganization(t *testing.T) {
+	tests := []struct {
+		name    string
+		failOn  int64
+		wantOrg []int64
+		wantErr bool
+	}{
+		{
+			name:    "success_-_job_runs_in_every_organization",
+			wantOrg: []int64{1, 2, 3},
+		},
+		{
+			name:    "error_-_job_continues_after_organization_error",
+			failOn:  2,
+			wantOrg: []int64{1, 2, 3},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{
+				repo: &MockRepository{
+					GetOrganizationIDsFunc: func(ctx context.Context) ([]int64, error) {
+						return []int64{1, 2, 3}, nil
+					},
+				},
+			}
+
+			got := []int64{}
+			err := s.forEachOrganization(context.Background(), func(ctx context.Context) error {
+				orgID, _ := ctx.Value(models.OrgIDKey).(int64)
+				got = append(got, orgID)
+				if orgID == tt.failOn {
+					return errors.New("job failed")
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("service.forEachOrganization() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.wantOrg) {
+				t.Errorf("service.forEachOrganization() organizations = %v, want %v", got, tt.wantOrg)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Payment request
+func (s *service) CreatePaymentRequest(ctx context.Context, qp models.PaymentRequestQuery) (models.PaymentRequestDTO, error) {
+	if qp.Amount < 1 || qp.Payer == "" {
+		return models.PaymentRequestDTO{}, errors.New(internalErrors.ErrInvalidPaymentRequestReqParams)
+	}
+	if qp.Payer == qp.Username {
+		return models.PaymentRequestDTO{}, errors.New(internalErrors.ErrInvalidRecipientYourself)
+	}
+
+	now := time.Now()
+	pr := models.PaymentRequest{
+		RequesterID: qp.UserID,
+		Requester:   qp.Username,
+		Payer:       qp.Payer,
+		Amount:      qp.Amount,
+		Memo:        qp.Memo,
+		Status:      models.PaymentRequestStatusPending,
+		ExpiresAt:   now.Add(s.cfg.PaymentRequest.TTL),
+		CreatedAt:   now,
+	}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		validPayer, err := repo.IsUserExist(ctx, pr.Payer)
+		if err != nil {
+			return err
+		}
+		if !validPayer {
+			return errors.New(internalErrors.ErrInvalidRecipient)
+		}
+
+		pr.ID, err = repo.CreatePaymentRequest(ctx, pr)
+		return err
+	})
+	if err != nil {
+		return models.PaymentRequestDTO{}, err
+	}
+
+	return pr.ToModelPaymentRequestDTO(now), nil
+}
+
+func (s *service) GetIncomingPaymentRequests(ctx context.Context, username string) ([]models.PaymentRequestDTO, error) {
+	now := time.Now()
+	requests, err := s.repo.GetIncomingPaymentRequests(ctx, username, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPaymentRequestsDTO(requests, now), nil
+}
+
+func (s *service) GetOutgoingPaymentRequests(ctx context.Context, userID int64) ([]models.PaymentRequestDTO, error) {
+	requests, err := s.repo.GetOutgoingPaymentRequests(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPaymentRequestsDTO(requests, time.Now()), nil
+}
+
+// AcceptPaymentRequest переводит запрошенные монеты и помечает запрос оплаченным в одной транзакции
+func (s *service) AcceptPaymentRequest(ctx context.Context, qp models.PaymentRequestQuery) (models.PaymentRequestDTO, error) {
+	return s.resolvePaymentRequest(ctx, qp, models.PaymentRequestStatusPaid)
+}
+
+func (s *service) DeclinePaymentRequest(ctx context.Context, qp models.PaymentRequestQuery) (models.PaymentRequestDTO, error) {
+	return s.resolvePaymentRequest(ctx, qp, models.PaymentRequestStatusDeclined)
+}
+
+func (s *service) resolvePaymentRequest(ctx context.Context, qp models.PaymentRequestQuery, status string) (models.PaymentRequestDTO, error) {
+	now := time.Now()
+	pr := models.PaymentRequest{}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		var err error
+		pr, err = repo.GetPaymentRequestForPayer(ctx, qp.Username, qp.ID)
+		if err != nil {
+			return err
+		}
+		if pr.ID == 0 {
+			return errors.New(internalErrors.ErrPaymentRequestNotFound)
+		}
+		if pr.IsExpired(now) {
+			return errors.New(internalErrors.ErrPaymentRequestExpired)
+		}
+		if pr.Status != models.PaymentRequestStatusPending {
+			return errors.New(internalErrors.ErrPaymentRequestResolved)
+		}
+
+		if status == models.PaymentRequestStatusPaid {
+			err := s.SendCoins(ctx, models.CoinsQuery{
+				UserID:    qp.UserID,
+				Amount:    pr.Amount,
+				Sender:    qp.Username,
+				Recipient: pr.Requester,
+				Memo:      pr.Memo,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		pr.Status = status
+		return repo.ResolvePaymentRequest(ctx, pr.ID, status)
+	})
+	if err != nil {
+		return models.PaymentRequestDTO{}, err
+	}
+
+	return pr.ToModelPaymentRequestDTO(now), nil
+}
+
+func toPaymentRequestsDTO(requests []models.PaymentRequest, now time.Time) []models.PaymentRequestDTO {
+	requestsDTO := make([]models.PaymentRequestDTO, 0, len(requests))
+	for _, pr := range requests {
+		requestsDTO = append(requestsDTO, pr.ToModelPaymentRequestDTO(now))
+	}
+
+	return requestsDTO
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func Test_service_CreatePaymentRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		qp        models.PaymentRequestQuery
+		payerOK   bool
+		createErr error
+		wantErr   string
+	}{
+		{
+			name:    "success_-_request_created",
+			qp:      models.PaymentRequestQuery{UserID: 1, Username: "user1", Payer: "user2", Amount: 50, Memo: "pizza"},
+			payerOK: true,
+		},
+		{
+			name:    "error_-_invalid_amount",
+			qp:      models.PaymentRequestQuery{UserID: 1, Username: "user1", Payer: "user2"},
+			wantErr: internalErrors.ErrInvalidPaymentRequestReqParams,
+		},
+		{
+			name:    "error_-_payer_is_yourself",
+			qp:      models.PaymentRequestQuery{UserID: 1, Username: "user1", Payer: "user1", Amount: 50},
+			wantErr: internalErrors.ErrInvalidRecipientYourself,
+		},
+		{
+			name:    "error_-_payer_does_not_exist",
+			qp:      models.PaymentRequestQuery{UserID: 1, Username: "user1", Payer: "user_invalid", Amount: 50},
+			wantErr: internalErrors.ErrInvalidRecipient,
+		},
+		{
+			name:      "error_-_duplicate_pending_request",
+			qp:        models.PaymentRequestQuery{UserID: 1, Username: "user1", Payer: "user2", Amount: 50},
+			payerOK:   true,
+			createErr: errors.New(internalErrors.ErrDuplicatePaymentRequest),
+			wantErr:   internalErrors.ErrDuplicatePaymentRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{
+				repo: &MockRepository{
+					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+						return tt.payerOK, nil
+					},
+					CreatePaymentRequestFunc: func(ctx context.Context, pr models.PaymentRequest) (int64, error) {
+						return 1, tt.createErr
+					},
+				},
+				txManager: &MockTxManager{},
+				cfg:       config.Config{PaymentRequest: config.PaymentRequest{TTL: time.Hour}},
+			}
+
+			got, err := s.CreatePaymentRequest(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.CreatePaymentRequest() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.CreatePaymentRequest() error = %v", err)
+			}
+			if got.Status != models.PaymentRequestStatusPending || got.FromUser != "user1" || got.ToUser != "user2" {
+				t.Errorf("service.CreatePaymentRequest() = %v", got)
+			}
+		})
+	}
+}
+
+func Test_service_AcceptPaymentRequest(t *testing.T) {
+	pending := models.PaymentRequest{
+		ID:        1,
+		Requester: "user1",
+		Payer:     "user2",
+		Amount:    50,
+		Memo:      "pizza",
+		Status:    models.PaymentRequestStatusPending,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	expired := pending
+	expired.ExpiresAt = time.Now().Add(-time.Hour)
+	declined := pending
+	declined.Status = models.PaymentRequestStatusDeclined
+
+	tests := []struct {
+		name         string
+		request      models.PaymentRequest
+		debitErr     error
+		wantErr      string
+		wantResolved bool
+	}{
+		{name: "success_-_request_paid", request: pending, wantResolved: true},
+		{name: "error_-_request_not_found", request: models.PaymentRequest{}, wantErr: internalErrors.ErrPaymentRequestNotFound},
+		{name: "error_-_request_expired", request: expired, wantErr: internalErrors.ErrPaymentRequestExpired},
+		{name: "error_-_request_already_resolved", request: declined, wantErr: internalErrors.ErrPaymentRequestResolved},
+		{
+			name:     "error_-_not_enough_coins",
+			request:  pending,
+			debitErr: errors.New(internalErrors.ErrNotEnoughCoins),
+			wantErr:  internalErrors.ErrNotEnoughCoins,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved := ""
+			var history models.BalanceHistory
+			s := &service{
+				repo: &MockRepository{
+					NextTransferIDFunc:             nextTransferID,
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
+					RecordLeaderboardTransferFunc:  recordLeaderboardTransferNoop,
+					GetPaymentRequestForPayerFunc: func(ctx context.Context, payer string, requestID int64) (models.PaymentRequest, error) {
+						return tt.request, nil
+					},
+					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+						return true, nil
+					},
+					GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+						if username == "user2" {
+							return models.Balance{ID: 2, Amount: 1000}, nil
+						}
+						return models.Balance{ID: 1}, nil
+					},
+					GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+						return models.AccountState{Status: models.UserStatusActive}, nil
+					},
+					LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+						return nil
+					},
+					DebitBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return tt.debitErr
+					},
+					SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+						return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+					},
+					CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return nil
+					},
+					CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+						return nil
+					},
+					CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+						history = entry
+						return nil
+					},
+					ResolvePaymentRequestFunc: func(ctx context.Context, requestID int64, status string) error {
+						resolved = status
+						return nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.AcceptPaymentRequest(context.Background(), models.PaymentRequestQuery{ID: 1, UserID: 2, Username: "user2"})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.AcceptPaymentRequest() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.AcceptPaymentRequest() error = %v", err)
+			}
+			if got.Status != models.PaymentRequestStatusPaid || resolved != models.PaymentRequestStatusPaid {
+				t.Errorf("service.AcceptPaymentRequest() status = %v, resolved = %v", got.Status, resolved)
+			}
+			if history.Sender != "user2" || history.Recipient != "user1" || history.Reason != "pizza" {
+				t.Errorf("transfer history = %v", history)
+			}
+		})
+	}
+}
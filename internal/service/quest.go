@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Quest
+func (s *service) CreateQuest(ctx context.Context, body models.QuestReqBody) (models.QuestDTO, error) {
+	quest, err := validateQuest(body.ToModelQuest(0))
+	if err != nil {
+		return models.QuestDTO{}, err
+	}
+
+	err = s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		quest.ID, err = repo.CreateQuest(ctx, quest)
+
+		return err
+	})
+	if err != nil {
+		return models.QuestDTO{}, err
+	}
+
+	return quest.ToModelQuestDTO(), nil
+}
+
+func (s *service) GetQuests(ctx context.Context) ([]models.QuestDTO, error) {
+	questsDTO := []models.QuestDTO{}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted, ReadOnly: true}, func(ctx context.Context, repo Repository) error {
+		quests, err := repo.GetQuests(ctx)
+		if err != nil {
+			return err
+		}
+		for _, quest := range quests {
+			questsDTO = append(questsDTO, quest.ToModelQuestDTO())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return questsDTO, nil
+}
+
+// UpdateQuest меняет условия задания. Накопленный прогресс сохраняется, уже выплаченные награды не пересчитываются.
+func (s *service) UpdateQuest(ctx context.Context, questID int64, body models.QuestReqBody) (models.QuestDTO, error) {
+	if questID < 1 {
+		return models.QuestDTO{}, errors.New(internalErrors.ErrInvalidQuestReqParams)
+	}
+	quest, err := validateQuest(body.ToModelQuest(questID))
+	if err != nil {
+		return models.QuestDTO{}, err
+	}
+
+	err = s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		updated, err := repo.UpdateQuest(ctx, quest)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return errors.New(internalErrors.ErrQuestNotFound)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.QuestDTO{}, err
+	}
+
+	return quest.ToModelQuestDTO(), nil
+}
+
+func (s *service) DeleteQuest(ctx context.Context, questID int64) error {
+	if questID < 1 {
+		return errors.New(internalErrors.ErrInvalidQuestReqParams)
+	}
+
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		deleted, err := repo.DeleteQuest(ctx, questID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return errors.New(internalErrors.ErrQuestNotFound)
+		}
+
+		return nil
+	})
+}
+
+// GetUserQuests возвращает активные задания с прогрессом пользователя
+func (s *service) GetUserQuests(ctx context.Context, username string) ([]models.UserQuestDTO, error) {
+	questsDTO := []models.UserQuestDTO{}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted, ReadOnly: true}, func(ctx context.Context, repo Repository) error {
+		quests, err := repo.GetUserQuests(ctx, username, time.Now())
+		if err != nil {
+			return err
+		}
+		for _, quest := range quests {
+			questsDTO = append(questsDTO, quest.ToModelUserQuestDTO())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return questsDTO, nil
+}
+
+// recordQuestTransfer учитывает перевод монет в заданиях отправителя
+func (s *service) recordQuestTransfer(ctx context.Context, repo Repository, entry models.BalanceHistory, at time.Time) error {
+	if entry.Currency != models.CurrencyCoins {
+		return nil
+	}
+
+	quests, err := repo.GetActiveQuests(ctx, models.QuestEventTransfer, at)
+	if err != nil {
+		return err
+	}
+
+	// общая команда нужна только заданиям с условием, поэтому проверяется один раз и по требованию
+	var sharesTeam *bool
+	for _, quest := range quests {
+		if quest.OtherTeam {
+			if sharesTeam == nil {
+				shared, err := repo.ShareTeam(ctx, entry.Sender, entry.Recipient)
+				if err != nil {
+					return err
+				}
+				sharesTeam = &shared
+			}
+			if *sharesTeam {
+				continue
+			}
+		}
+
+		amount := int64(1)
+		switch quest.Metric {
+		case models.QuestMetricCoinsSent:
+			amount = entry.TransactionAmount
+		case models.QuestMetricDistinctRecipients:
+			added, err := repo.AddQuestRecipient(ctx, quest.ID, entry.Sender, entry.Recipient)
+			if err != nil {
+				return err
+			}
+			if !added {
+				continue
+			}
+		}
+
+		if err := s.advanceQuest(ctx, repo, quest, entry.Sender, amount); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordQuestPurchase учитывает покупку в заданиях покупателя. Потраченными считаются только монеты.
+func (s *service) recordQuestPurchase(ctx context.Context, repo Repository, username string, merch models.Merch, at time.Time) error {
+	quests, err := repo.GetActiveQuests(ctx, models.QuestEventPurchase, at)
+	if err != nil {
+		return err
+	}
+
+	for _, quest := range quests {
+		amount := int64(1)
+		if quest.Metric == models.QuestMetricCoinsSpent {
+			if merch.Currency != models.CurrencyCoins {
+				continue
+			}
+			amount = merch.Price
+		}
+
+		if err := s.advanceQuest(ctx, repo, quest, username, amount); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// advanceQuest увеличивает прогресс и при достижении цели выплачивает награду из казначейства.
+// Вызывается в транзакции события: выполненное задание не награждается повторно.
+func (s *service) advanceQuest(ctx context.Context, repo Repository, quest models.Quest, username string, amount int64) error {
+	if amount < 1 {
+		return nil
+	}
+
+	progress, err := repo.AddQuestProgress(ctx, quest.ID, username, amount)
+	if err != nil {
+		return err
+	}
+	if progress.CompletedAt != nil || progress.Progress < quest.Target {
+		return nil
+	}
+
+	completed, err := repo.CompleteQuest(ctx, quest.ID, username)
+	if err != nil {
+		return err
+	}
+	if !completed || quest.Reward == 0 {
+		return nil
+	}
+
+	recipients := []models.GrantRecipient{{Username: username, Amount: quest.Reward}}
+
+	return s.creditFromTreasury(ctx, repo, recipients, models.CurrencyCoins, models.HistoryTypeQuest, quest.Title)
+}
+
+// validateQuest проверяет условия задания и определяет событие по метрике
+func validateQuest(quest models.Quest) (models.Quest, error) {
+	quest.Title = strings.TrimSpace(quest.Title)
+	quest.Description = strings.TrimSpace(quest.Description)
+
+	event, ok := models.QuestMetrics[quest.Metric]
+	switch {
+	case !ok,
+		quest.Title == "",
+		utf8.RuneCountInString(quest.Title) > models.QuestTitleMaxLength,
+		utf8.RuneCountInString(quest.Description) > models.QuestDescriptionMaxLength,
+		quest.OtherTeam && event != models.QuestEventTransfer,
+		quest.Target < 1,
+		quest.Reward < 0,
+		quest.StartsAt.IsZero(),
+		!quest.EndsAt.After(quest.StartsAt):
+		return models.Quest{}, errors.New(internalErrors.ErrInvalidQuestReqParams)
+	}
+	quest.Event = event
+
+	return quest, nil
+}
@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/go-openapi/strfmt"
+)
+
+func getNoActiveQuests(ctx context.Context, event string, at time.Time) ([]models.Quest, error) {
+	return nil, nil
+}
+
+func Test_validateQuest(t *testing.T) {
+	startsAt := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	valid := models.Quest{
+		Title:     " Поблагодари коллег ",
+		Metric:    models.QuestMetricDistinctRecipients,
+		OtherTeam: true,
+		Target:    3,
+		Reward:    50,
+		StartsAt:  startsAt,
+		EndsAt:    startsAt.AddDate(0, 0, 7),
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(quest *models.Quest)
+		wantEvent string
+		wantErr   bool
+	}{
+		{
+			name:      "success_-_transfer_metric",
+			modify:    func(quest *models.Quest) {},
+			wantEvent: models.QuestEventTransfer,
+		},
+		{
+			name: "success_-_purchase_metric",
+			modify: func(quest *models.Quest) {
+				quest.Metric = models.QuestMetricCoinsSpent
+				quest.OtherTeam = false
+			},
+			wantEvent: models.QuestEventPurchase,
+		},
+		{
+			name:    "error_-_unknown_metric",
+			modify:  func(quest *models.Quest) { quest.Metric = "logins" },
+			wantErr: true,
+		},
+		{
+			name:    "error_-_blank_title",
+			modify:  func(quest *models.Quest) { quest.Title = "  " },
+			wantErr: true,
+		},
+		{
+			name:    "error_-_other_team_for_purchase_metric",
+			modify:  func(quest *models.Quest) { quest.Metric = models.QuestMetricPurchases },
+			wantErr: true,
+		},
+		{
+			name:    "error_-_zero_target",
+			modify:  func(quest *models.Quest) { quest.Target = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "error_-_negative_reward",
+			modify:  func(quest *models.Quest) { quest.Reward = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "error_-_ends_before_start",
+			modify:  func(quest *models.Quest) { quest.EndsAt = quest.StartsAt },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quest := valid
+			tt.modify(&quest)
+
+			got, err := validateQuest(quest)
+			if tt.wantErr {
+				if err == nil || err.Error() != internalErrors.ErrInvalidQuestReqParams {
+					t.Fatalf("validateQuest() error = %v, want %v", err, internalErrors.ErrInvalidQuestReqParams)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateQuest() error = %v", err)
+			}
+			if got.Event != tt.wantEvent {
+				t.Errorf("validateQuest() event = %v, want %v", got.Event, tt.wantEvent)
+			}
+			if got.Title != "Поблагодари коллег" {
+				t.Errorf("validateQuest() title = %q, want trimmed", got.Title)
+			}
+		})
+	}
+}
+
+func Test_service_recordQuestTransfer(t *testing.T) {
+	now := time.Now()
+	quest := models.Quest{ID: 7, Title: "Спасибо другим командам", Target: 3, Reward: 50}
+
+	tests := []struct {
+		name          string
+		metric        string
+		otherTeam     bool
+		currency      string
+		sharesTeam    bool
+		newRecipient  bool
+		progress      int64
+		completedAt   *time.Time
+		completed     bool
+		wantAdded     int64
+		wantCompleted bool
+		wantCredited  []string
+	}{
+		{
+			name:         "success_-_transfer_counts_once",
+			metric:       models.QuestMetricTransfers,
+			currency:     models.CurrencyCoins,
+			progress:     1,
+			wantAdded:    1,
+			wantCredited: []string{},
+		},
+		{
+			name:         "success_-_coins_sent_counts_amount",
+			metric:       models.QuestMetricCoinsSent,
+			currency:     models.CurrencyCoins,
+			progress:     2,
+			wantAdded:    2,
+			wantCredited: []string{},
+		},
+		{
+			name:          "success_-_target_reached_pays_reward",
+			metric:        models.QuestMetricDistinctRecipients,
+			otherTeam:     true,
+			currency:      models.CurrencyCoins,
+			newRecipient:  true,
+			progress:      3,
+			completed:     true,
+			wantAdded:     1,
+			wantCompleted: true,
+			wantCredited:  []string{"alice"},
+		},
+		{
+			name:         "success_-_repeated_recipient_not_counted",
+			metric:       models.QuestMetricDistinctRecipients,
+			currency:     models.CurrencyCoins,
+			wantCredited: []string{},
+		},
+		{
+			name:         "success_-_teammate_not_counted",
+			metric:       models.QuestMetricTransfers,
+			otherTeam:    true,
+			currency:     models.CurrencyCoins,
+			sharesTeam:   true,
+			wantCredited: []string{},
+		},
+		{
+			name:          "success_-_completed_by_concurrent_tx",
+			metric:        models.QuestMetricTransfers,
+			currency:      models.CurrencyCoins,
+			progress:      3,
+			wantAdded:     1,
+			wantCompleted: true,
+			wantCredited:  []string{},
+		},
+		{
+			name:         "success_-_already_completed",
+			metric:       models.QuestMetricTransfers,
+			currency:     models.CurrencyCoins,
+			progress:     4,
+			completedAt:  &now,
+			wantAdded:    1,
+			wantCredited: []string{},
+		},
+		{
+			name:         "success_-_other_currency_ignored",
+			metric:       models.QuestMetricTransfers,
+			currency:     "stars",
+			wantCredited: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAdded int64
+			gotCompleted := false
+			gotCredited := []string{}
+			repo := &MockRepository{
+				NextTransferIDFunc: nextTransferID,
+				GetActiveQuestsFunc: func(ctx context.Context, event string, at time.Time) ([]models.Quest, error) {
+					if event != models.QuestEventTransfer {
+						t.Errorf("GetActiveQuests() event = %v, want %v", event, models.QuestEventTransfer)
+					}
+					q := quest
+					q.Metric = tt.metric
+					q.OtherTeam = tt.otherTeam
+					return []models.Quest{q}, nil
+				},
+				ShareTeamFunc: func(ctx context.Context, username, otherUsername string) (bool, error) {
+					return tt.sharesTeam, nil
+				},
+				AddQuestRecipientFunc: func(ctx context.Context, questID int64, username, recipient string) (bool, error) {
+					return tt.newRecipient, nil
+				},
+				AddQuestProgressFunc: func(ctx context.Context, questID int64, username string, amount int64) (models.UserQuest, error) {
+					gotAdded += amount
+					return models.UserQuest{Progress: tt.progress, CompletedAt: tt.completedAt}, nil
+				},
+				CompleteQuestFunc: func(ctx context.Context, questID int64, username string) (bool, error) {
+					gotCompleted = true
+					return tt.completed, nil
+				},
+				GetSystemBalanceIDFunc: func(ctx context.Context, name, currency string) (int64, error) {
+					return 100, nil
+				},
+				GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+					return models.Balance{ID: 5}, nil
+				},
+				LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+					return nil
+				},
+				CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+					return nil
+				},
+				CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+					return nil
+				},
+				CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+					if entry.Type != models.HistoryTypeQuest {
+						t.Errorf("CreateBalanceHistory() type = %v, want %v", entry.Type, models.HistoryTypeQuest)
+					}
+					// запись казначейства и запись получателя
+					if entry.BalanceID == 5 {
+						gotCredited = append(gotCredited, entry.Recipient)
+					}
+					return nil
+				},
+				DebitSystemBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+					return nil
+				},
+			}
+			s := &service{repo: repo}
+
+			err := s.recordQuestTransfer(context.Background(), repo, models.BalanceHistory{
+				TransactionAmount: 2,
+				Sender:            "alice",
+				Recipient:         "bob",
+				Currency:          tt.currency,
+			}, now)
+			if err != nil {
+				t.Fatalf("service.recordQuestTransfer() error = %v", err)
+			}
+			if gotAdded != tt.wantAdded {
+				t.Errorf("service.recordQuestTransfer() added = %v, want %v", gotAdded, tt.wantAdded)
+			}
+			if gotCompleted != tt.wantCompleted {
+				t.Errorf("service.recordQuestTransfer() completed = %v, want %v", gotCompleted, tt.wantCompleted)
+			}
+			if !reflect.DeepEqual(gotCredited, tt.wantCredited) {
+				t.Errorf("service.recordQuestTransfer() credited = %v, want %v", gotCredited, tt.wantCredited)
+			}
+		})
+	}
+}
+
+func Test_service_recordQuestPurchase(t *testing.T) {
+	tests := []struct {
+		name      string
+		metric    string
+		merch     models.Merch
+		wantAdded int64
+	}{
+		{
+			name:      "success_-_purchase_counts_once",
+			metric:    models.QuestMetricPurchases,
+			merch:     models.Merch{Name: "cup", Price: 20, Currency: "stars"},
+			wantAdded: 1,
+		},
+		{
+			name:      "success_-_coins_spent_counts_price",
+			metric:    models.QuestMetricCoinsSpent,
+			merch:     models.Merch{Name: "cup", Price: 20, Currency: models.CurrencyCoins},
+			wantAdded: 20,
+		},
+		{
+			name:   "success_-_other_currency_not_spent",
+			metric: models.QuestMetricCoinsSpent,
+			merch:  models.Merch{Name: "cup", Price: 20, Currency: "stars"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAdded int64
+			repo := &MockRepository{
+				GetActiveQuestsFunc: func(ctx context.Context, event string, at time.Time) ([]models.Quest, error) {
+					return []models.Quest{{ID: 1, Metric: tt.metric, Target: 100, Reward: 10}}, nil
+				},
+				AddQuestProgressFunc: func(ctx context.Context, questID int64, username string, amount int64) (models.UserQuest, error) {
+					gotAdded += amount
+					return models.UserQuest{Progress: amount}, nil
+				},
+			}
+			s := &service{repo: repo}
+
+			if err := s.recordQuestPurchase(context.Background(), repo, "alice", tt.merch, time.Now()); err != nil {
+				t.Fatalf("service.recordQuestPurchase() error = %v", err)
+			}
+			if gotAdded != tt.wantAdded {
+				t.Errorf("service.recordQuestPurchase() added = %v, want %v", gotAdded, tt.wantAdded)
+			}
+		})
+	}
+}
+
+func Test_service_UpdateQuest(t *testing.T) {
+	startsAt := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	body := models.QuestReqBody{
+		Title:    "Покупки",
+		Metric:   models.QuestMetricPurchases,
+		Target:   1,
+		StartsAt: strfmt.DateTime(startsAt),
+		EndsAt:   strfmt.DateTime(startsAt.AddDate(0, 1, 0)),
+	}
+
+	tests := []struct {
+		name    string
+		questID int64
+		updated bool
+		wantErr string
+	}{
+		{
+			name:    "success",
+			questID: 4,
+			updated: true,
+		},
+		{
+			name:    "error_-_not_found",
+			questID: 4,
+			wantErr: internalErrors.ErrQuestNotFound,
+		},
+		{
+			name:    "error_-_invalid_id",
+			wantErr: internalErrors.ErrInvalidQuestReqParams,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{
+				UpdateQuestFunc: func(ctx context.Context, quest models.Quest) (bool, error) {
+					if quest.ID != tt.questID || quest.Event != models.QuestEventPurchase {
+						t.Errorf("UpdateQuest() quest = %+v", quest)
+					}
+					return tt.updated, nil
+				},
+			}
+			s := &service{repo: repo, txManager: &MockTxManager{}}
+
+			got, err := s.UpdateQuest(context.Background(), tt.questID, body)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("service.UpdateQuest() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.UpdateQuest() error = %v", err)
+			}
+			if got.ID != tt.questID {
+				t.Errorf("service.UpdateQuest() id = %v, want %v", got.ID, tt.questID)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Referral
+// GetReferrals возвращает код приглашения пользователя, приглашённых им пользователей и полученные бонусы
+func (s *service) GetReferrals(ctx context.Context, username string) (models.ReferralsDTO, error) {
+	referralsDTO := models.ReferralsDTO{Items: []models.ReferralDTO{}}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.RepeatableRead, ReadOnly: true}, func(ctx context.Context, repo Repository) error {
+		code, err := repo.GetReferralCode(ctx, username)
+		if err != nil {
+			return err
+		}
+		referralsDTO.Code = code
+
+		referrals, err := repo.GetReferrals(ctx, username)
+		if err != nil {
+			return err
+		}
+		for _, referral := range referrals {
+			referralDTO := referral.ToModelReferralDTO()
+			if referralDTO.Status == models.ReferralStatusQualified {
+				referralsDTO.Qualified++
+				referralsDTO.BonusEarned += referral.Bonus
+			}
+			referralsDTO.Items = append(referralsDTO.Items, referralDTO)
+		}
+		referralsDTO.Referrals = int64(len(referrals))
+
+		return nil
+	})
+	if err != nil {
+		return models.ReferralsDTO{}, err
+	}
+
+	return referralsDTO, nil
+}
+
+// qualifyReferral выплачивает бонус приглашения после первой покупки или перевода приглашённого,
+// оплаченных заработанными монетами. Вызывается в транзакции события, counterparty - получатель перевода,
+// пустой для покупки, spent - списанные на событие лоты.
+func (s *service) qualifyReferral(ctx context.Context, repo Repository, username, counterparty string, spent []models.BalanceLot) error {
+	referral, err := repo.GetPendingReferral(ctx, username)
+	if err != nil {
+		return err
+	}
+	if referral.ID == 0 {
+		return nil
+	}
+	// перевод пригласившему не считается активностью, иначе бонус получается парой своих аккаунтов
+	if counterparty == referral.Referrer {
+		return nil
+	}
+	// трата одного стартового баланса не считается активностью, иначе бонус получает любой новый аккаунт
+	if earnedAmount(referral, spent) < max(s.cfg.Referral.MinAmount, 1) {
+		return nil
+	}
+
+	bonus := max(s.cfg.Referral.Bonus, 0)
+	qualified, err := repo.QualifyReferral(ctx, referral.ID, bonus)
+	if err != nil {
+		return err
+	}
+	if !qualified || bonus == 0 {
+		return nil
+	}
+
+	recipients := []models.GrantRecipient{{Username: referral.Referee, Amount: bonus}}
+	// удалённый или заблокированный пригласивший бонус не получает
+	referrerActive, err := repo.IsUserExist(ctx, referral.Referrer)
+	if err != nil {
+		return err
+	}
+	if referrerActive {
+		recipients = append(recipients, models.GrantRecipient{Username: referral.Referrer, Amount: bonus})
+	}
+
+	reason := fmt.Sprintf("Приглашение %s", referral.Referee)
+	if err := s.creditFromTreasury(ctx, repo, recipients, models.CurrencyCoins, models.HistoryTypeReferral, reason); err != nil {
+		return err
+	}
+
+	log.Logger.Info().Msgf("referral %s -> %s qualified, bonus %d", referral.Referrer, referral.Referee, bonus)
+
+	return nil
+}
+
+// earnedAmount сумма списанных монет не из стартового баланса приглашённого. Стартовый лот создаётся
+// в одной транзакции с приглашением, поэтому его дата начисления совпадает с датой приглашения.
+func earnedAmount(referral models.Referral, spent []models.BalanceLot) int64 {
+	var earned int64
+	for _, lot := range spent {
+		if !lot.GrantedAt.Equal(referral.CreatedAt) {
+			earned += lot.Amount
+		}
+	}
+
+	return earned
+}
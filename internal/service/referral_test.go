@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/go-openapi/strfmt"
+)
+
+func getNoPendingReferral(ctx context.Context, username string) (models.Referral, error) {
+	return models.Referral{}, nil
+}
+
+func Test_service_qualifyReferral(t *testing.T) {
+	registeredAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	pending := models.Referral{ID: 3, Referrer: "referrer", Referee: "newbie", CreatedAt: registeredAt}
+	startingLot := models.BalanceLot{Amount: 10, GrantedAt: registeredAt}
+	earnedLot := models.BalanceLot{Amount: 10, GrantedAt: registeredAt.Add(time.Hour)}
+
+	tests := []struct {
+		name           string
+		pending        models.Referral
+		counterparty   string
+		spent          []models.BalanceLot
+		minAmount      int64
+		bonus          int64
+		qualified      bool
+		referrerActive bool
+		wantQualified  bool
+		wantCredited   []string
+	}{
+		{
+			name:           "success_-_purchase_pays_both_sides",
+			pending:        pending,
+			spent:          []models.BalanceLot{earnedLot},
+			bonus:          100,
+			qualified:      true,
+			referrerActive: true,
+			wantQualified:  true,
+			wantCredited:   []string{"newbie", "referrer"},
+		},
+		{
+			name:           "success_-_transfer_to_other_user_pays_both_sides",
+			pending:        pending,
+			spent:          []models.BalanceLot{earnedLot},
+			counterparty:   "colleague",
+			bonus:          100,
+			qualified:      true,
+			referrerActive: true,
+			wantQualified:  true,
+			wantCredited:   []string{"newbie", "referrer"},
+		},
+		{
+			name:           "success_-_transfer_to_referrer_does_not_qualify",
+			pending:        pending,
+			spent:          []models.BalanceLot{earnedLot},
+			counterparty:   "referrer",
+			bonus:          100,
+			referrerActive: true,
+			wantCredited:   []string{},
+		},
+		{
+			name:           "success_-_already_qualified_by_concurrent_tx",
+			pending:        pending,
+			spent:          []models.BalanceLot{earnedLot},
+			bonus:          100,
+			qualified:      false,
+			referrerActive: true,
+			wantQualified:  true,
+			wantCredited:   []string{},
+		},
+		{
+			name:          "success_-_inactive_referrer_not_paid",
+			pending:       pending,
+			spent:         []models.BalanceLot{earnedLot},
+			bonus:         100,
+			qualified:     true,
+			wantQualified: true,
+			wantCredited:  []string{"newbie"},
+		},
+		{
+			name:           "success_-_zero_bonus_only_qualifies",
+			pending:        pending,
+			spent:          []models.BalanceLot{earnedLot},
+			qualified:      true,
+			referrerActive: true,
+			wantQualified:  true,
+			wantCredited:   []string{},
+		},
+		{
+			name:           "success_-_starting_balance_only_does_not_qualify",
+			pending:        pending,
+			spent:          []models.BalanceLot{startingLot},
+			bonus:          100,
+			referrerActive: true,
+			wantCredited:   []string{},
+		},
+		{
+			name:           "success_-_earned_coins_below_min_amount_do_not_qualify",
+			pending:        pending,
+			spent:          []models.BalanceLot{startingLot, earnedLot},
+			minAmount:      50,
+			bonus:          100,
+			referrerActive: true,
+			wantCredited:   []string{},
+		},
+		{
+			name:           "success_-_mixed_lots_with_earned_coins_qualify",
+			pending:        pending,
+			spent:          []models.BalanceLot{startingLot, earnedLot},
+			bonus:          100,
+			qualified:      true,
+			referrerActive: true,
+			wantQualified:  true,
+			wantCredited:   []string{"newbie", "referrer"},
+		},
+		{
+			name:         "success_-_no_pending_referral",
+			bonus:        100,
+			wantCredited: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotQualified := false
+			gotCredited := []string{}
+			repo := &MockRepository{
+				NextTransferIDFunc: nextTransferID,
+				GetPendingReferralFunc: func(ctx context.Context, username string) (models.Referral, error) {
+					return tt.pending, nil
+				},
+				QualifyReferralFunc: func(ctx context.Context, referralID, bonus int64) (bool, error) {
+					gotQualified = true
+					if bonus != tt.bonus {
+						t.Errorf("QualifyReferral() bonus = %v, want %v", bonus, tt.bonus)
+					}
+					return tt.qualified, nil
+				},
+				IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+					return tt.referrerActive, nil
+				},
+				GetSystemBalanceIDFunc: func(ctx context.Context, name, currency string) (int64, error) {
+					return 100, nil
+				},
+				GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+					return models.Balance{ID: 5}, nil
+				},
+				LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+					return nil
+				},
+				CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+					return nil
+				},
+				CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+					return nil
+				},
+				CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+					if entry.Type != models.HistoryTypeReferral {
+						t.Errorf("CreateBalanceHistory() type = %v, want %v", entry.Type, models.HistoryTypeReferral)
+					}
+					// запись казначейства и запись получателя
+					if entry.BalanceID == 5 {
+						gotCredited = append(gotCredited, entry.Recipient)
+					}
+					return nil
+				},
+				DebitSystemBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+					return nil
+				},
+			}
+			s := &service{repo: repo, cfg: config.Config{Referral: config.Referral{Bonus: tt.bonus, MinAmount: tt.minAmount}}}
+
+			if err := s.qualifyReferral(context.Background(), repo, "newbie", tt.counterparty, tt.spent); err != nil {
+				t.Fatalf("service.qualifyReferral() error = %v", err)
+			}
+			if gotQualified != tt.wantQualified {
+				t.Errorf("service.qualifyReferral() qualified = %v, want %v", gotQualified, tt.wantQualified)
+			}
+			if !reflect.DeepEqual(gotCredited, tt.wantCredited) {
+				t.Errorf("service.qualifyReferral() credited = %v, want %v", gotCredited, tt.wantCredited)
+			}
+		})
+	}
+}
+
+func Test_service_GetReferrals(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	qualifiedAt := createdAt.Add(time.Hour)
+
+	s := &service{
+		repo: &MockRepository{
+			GetReferralCodeFunc: func(ctx context.Context, username string) (string, error) {
+				return "AB12CD34EF", nil
+			},
+			GetReferralsFunc: func(ctx context.Context, username string) ([]models.Referral, error) {
+				return []models.Referral{
+					{ID: 2, Referrer: "user1", Referee: "user3", CreatedAt: createdAt},
+					{ID: 1, Referrer: "user1", Referee: "user2", Bonus: 100, CreatedAt: createdAt, QualifiedAt: &qualifiedAt},
+				}, nil
+			},
+		},
+		txManager: &MockTxManager{},
+	}
+
+	got, err := s.GetReferrals(context.Background(), "user1")
+	if err != nil {
+		t.Fatalf("service.GetReferrals() error = %v", err)
+	}
+
+	wantQualifiedAt := strfmt.DateTime(qualifiedAt)
+	want := models.ReferralsDTO{
+		Code:        "AB12CD34EF",
+		Referrals:   2,
+		Qualified:   1,
+		BonusEarned: 100,
+		Items: []models.ReferralDTO{
+			{Username: "user3", Status: models.ReferralStatusPending, CreatedAt: strfmt.DateTime(createdAt)},
+			{Username: "user2", Status: models.ReferralStatusQualified, Bonus: 100, CreatedAt: strfmt.DateTime(createdAt), QualifiedAt: &wantQualifiedAt},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("service.GetReferrals() = %v, want %v", got, want)
+	}
+}
@@ -2,21 +2,138 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/devWaylander/coins_store/pkg/models"
 )
 
 type MockRepository struct {
-	IsUserExistFunc               func(ctx context.Context, username string) (bool, error)
-	GetBalanceIDByUsernameFunc    func(ctx context.Context, username string) (int64, error)
-	GetBalanceByUserIDFunc        func(ctx context.Context, userID int64) (models.Balance, error)
-	GetBalanceAmountByUserIDFunc  func(ctx context.Context, userID int64) (int64, error)
-	GetBalanceHistoryByUserIDFunc func(ctx context.Context, userID int64) ([]models.BalanceHistory, error)
-	GetInventoryMerchItemsFunc    func(ctx context.Context, userID int64) ([]models.InventoryMerch, error)
-	GetInventoryIDByUserIDFunc    func(ctx context.Context, userID int64) (int64, error)
-	GetMerchByNameFunc            func(ctx context.Context, name string) (models.Merch, error)
-	BuyItemTXFunc                 func(ctx context.Context, userID, balanceID, inventoryID, merchID, price int64, username, item string) error
-	SendCoinsTXFunc               func(ctx context.Context, userID, senderBalanceID, recipientBalanceID, amount int64, sender, recipient string) error
+	GetOrganizationIDsFunc                  func(ctx context.Context) ([]int64, error)
+	IsUserExistFunc                         func(ctx context.Context, username string) (bool, error)
+	GetBalanceIDByUsernameFunc              func(ctx context.Context, username string) (int64, error)
+	GetUserRoleByUsernameFunc               func(ctx context.Context, username string) (string, error)
+	GetActiveUsernamesFunc                  func(ctx context.Context) ([]string, error)
+	GetAccountStateFunc                     func(ctx context.Context, username string) (models.AccountState, error)
+	GetUserForUpdateFunc                    func(ctx context.Context, username string) (models.User, error)
+	UpdateUserStatusFunc                    func(ctx context.Context, userID int64, status string) error
+	OffboardUserFunc                        func(ctx context.Context, userID int64) error
+	CreateAccountAuditFunc                  func(ctx context.Context, audit models.AccountAudit) error
+	GetAccountAuditFunc                     func(ctx context.Context, username string) ([]models.AccountAudit, error)
+	GetBalanceByUserIDFunc                  func(ctx context.Context, userID int64) (models.Balance, error)
+	GetBalanceAmountByUserIDFunc            func(ctx context.Context, userID int64) (int64, error)
+	LockBalancesFunc                        func(ctx context.Context, balanceIDs ...int64) error
+	DebitBalanceFunc                        func(ctx context.Context, balanceID, amount int64) error
+	DebitBalanceIgnoringHoldsFunc           func(ctx context.Context, balanceID, amount int64) error
+	CreditBalanceFunc                       func(ctx context.Context, balanceID, amount int64) error
+	CreateBalanceLotFunc                    func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error
+	SpendBalanceLotsFunc                    func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error)
+	GetBalanceLotsByUserIDFunc              func(ctx context.Context, userID int64, grantedBefore time.Time) ([]models.BalanceLot, error)
+	GetExpiredLotOwnersFunc                 func(ctx context.Context, grantedBefore time.Time, limit int) ([]models.BalanceOwner, error)
+	ExpireBalanceLotsFunc                   func(ctx context.Context, balanceID int64, grantedBefore time.Time) (int64, error)
+	IsCurrencyExistFunc                     func(ctx context.Context, code string) (bool, error)
+	GetOrCreateWalletFunc                   func(ctx context.Context, username, currency string) (models.Balance, error)
+	GetWalletsByUserIDFunc                  func(ctx context.Context, userID int64) ([]models.Wallet, error)
+	GetSystemBalanceIDFunc                  func(ctx context.Context, name, currency string) (int64, error)
+	DebitSystemBalanceFunc                  func(ctx context.Context, balanceID, amount int64) error
+	GetBalanceHistoryByUserIDFunc           func(ctx context.Context, userID int64) ([]models.BalanceHistory, error)
+	NextTransferIDFunc                      func(ctx context.Context) (int64, error)
+	CreateBalanceHistoryFunc                func(ctx context.Context, entry models.BalanceHistory) error
+	GetSentTransferUsageFunc                func(ctx context.Context, balanceID int64, username string, since time.Time) (models.TransferUsage, error)
+	GetTransferUsageFunc                    func(ctx context.Context, balanceID int64, sender string, since time.Time) (models.TransferUsage, error)
+	GetStatementOpeningFunc                 func(ctx context.Context, username string, from time.Time) (models.StatementOpening, error)
+	StreamBalanceHistoryFunc                func(ctx context.Context, balanceID int64, from, to time.Time, fn func(entry models.BalanceHistory) error) error
+	GetLastSnapshotDayFunc                  func(ctx context.Context) (time.Time, error)
+	CreateBalanceSnapshotsFunc              func(ctx context.Context, day, dayEnd time.Time) (int64, error)
+	GetNearestSnapshotFunc                  func(ctx context.Context, username string, maxDay time.Time) (models.BalanceSnapshot, error)
+	GetBalanceChangeFunc                    func(ctx context.Context, balanceID int64, username string, from, to time.Time) (models.BalanceChange, error)
+	GetTransactionsByUsernameFunc           func(ctx context.Context, username string) ([]models.BalanceHistory, error)
+	GetBalanceHistoryByIDFunc               func(ctx context.Context, entryID int64) (models.BalanceHistory, error)
+	GetTransferCounterpartFunc              func(ctx context.Context, entry models.BalanceHistory) (models.BalanceHistory, error)
+	CreateTeamFunc                          func(ctx context.Context, name string, ownerID int64) (int64, error)
+	GetTeamByIDFunc                         func(ctx context.Context, teamID int64) (models.Team, error)
+	GetTeamsByUserIDFunc                    func(ctx context.Context, userID int64) ([]models.Team, error)
+	LockTeamFunc                            func(ctx context.Context, teamID int64) error
+	GetTeamMemberFunc                       func(ctx context.Context, teamID int64, username string) (models.TeamMember, error)
+	GetTeamMembersFunc                      func(ctx context.Context, teamID int64) ([]models.TeamMember, error)
+	UpsertTeamMemberFunc                    func(ctx context.Context, member models.TeamMember) (bool, error)
+	DeleteTeamMemberFunc                    func(ctx context.Context, teamID, userID int64) error
+	CountTeamOwnersFunc                     func(ctx context.Context, teamID int64) (int64, error)
+	GetInventoryMerchItemsByInventoryIDFunc func(ctx context.Context, inventoryID int64) ([]models.InventoryMerch, error)
+	GetInventoryMerchItemsFunc              func(ctx context.Context, userID int64) ([]models.InventoryMerch, error)
+	GetInventoryIDByUserIDFunc              func(ctx context.Context, userID int64) (int64, error)
+	AddInventoryMerchFunc                   func(ctx context.Context, inventoryID, merchID int64, item string) error
+	GetMerchByNameFunc                      func(ctx context.Context, name string) (models.Merch, error)
+	CreateScheduleFunc                      func(ctx context.Context, schedule models.Schedule) (int64, error)
+	GetSchedulesByUserIDFunc                func(ctx context.Context, userID int64) ([]models.Schedule, error)
+	GetScheduleByIDFunc                     func(ctx context.Context, userID, scheduleID int64) (models.Schedule, error)
+	ClaimDueScheduleFunc                    func(ctx context.Context, now time.Time) (models.Schedule, error)
+	UpdateScheduleFunc                      func(ctx context.Context, schedule models.Schedule) error
+	DeleteScheduleFunc                      func(ctx context.Context, userID, scheduleID int64) (bool, error)
+	CreateScheduleRunFunc                   func(ctx context.Context, run models.ScheduleRun) error
+	GetScheduleRunsFunc                     func(ctx context.Context, scheduleID int64) ([]models.ScheduleRun, error)
+	CreateHoldFunc                          func(ctx context.Context, hold models.Hold) (int64, error)
+	GetHeldAmountByUserIDFunc               func(ctx context.Context, userID int64) (int64, error)
+	GetHoldsByUserFunc                      func(ctx context.Context, userID int64, username string) ([]models.Hold, error)
+	GetHoldByIDFunc                         func(ctx context.Context, holdID int64) (models.Hold, error)
+	ResolveHoldFunc                         func(ctx context.Context, holdID int64, status string) error
+	ReleaseExpiredHoldsFunc                 func(ctx context.Context, now time.Time) (int64, error)
+	ReleaseHoldsByOwnerFunc                 func(ctx context.Context, ownerID int64) error
+	CreatePaymentRequestFunc                func(ctx context.Context, pr models.PaymentRequest) (int64, error)
+	GetIncomingPaymentRequestsFunc          func(ctx context.Context, payer string, now time.Time) ([]models.PaymentRequest, error)
+	GetOutgoingPaymentRequestsFunc          func(ctx context.Context, requesterID int64) ([]models.PaymentRequest, error)
+	GetPaymentRequestForPayerFunc           func(ctx context.Context, payer string, requestID int64) (models.PaymentRequest, error)
+	ResolvePaymentRequestFunc               func(ctx context.Context, requestID int64, status string) error
+	FindTransferCyclesFunc                  func(ctx context.Context, since time.Time) ([]models.FraudHit, error)
+	FindFunnelsFunc                         func(ctx context.Context, since time.Time, newAccountAge time.Duration, minSenders int) ([]models.FraudHit, error)
+	FindBurstsFunc                          func(ctx context.Context, since time.Time, window time.Duration, minAmount int64) ([]models.FraudHit, error)
+	CreateFraudCaseFunc                     func(ctx context.Context, fc models.FraudCase) (bool, error)
+	GetFraudCasesFunc                       func(ctx context.Context, status string) ([]models.FraudCase, error)
+	GetFraudCaseByIDFunc                    func(ctx context.Context, caseID int64) (models.FraudCase, error)
+	ResolveFraudCaseFunc                    func(ctx context.Context, caseID int64, status, admin string) error
+	SetFraudHoldFunc                        func(ctx context.Context, usernames []string) error
+	ReleaseFraudHoldFunc                    func(ctx context.Context, usernames []string) error
+	CreateAllowanceRunFunc                  func(ctx context.Context, periodStart time.Time, amount, usersCount int64) (bool, error)
+	RecordLeaderboardTransferFunc           func(ctx context.Context, sender, recipient string, amount int64, periods []models.LeaderboardPeriod) error
+	GetLeaderboardFunc                      func(ctx context.Context, filter models.LeaderboardFilter) ([]models.LeaderboardEntry, error)
+	SetLeaderboardOptOutFunc                func(ctx context.Context, userID int64, optOut bool) error
+	IncrementUserActivityFunc               func(ctx context.Context, username, activity string) error
+	GetDueAchievementsFunc                  func(ctx context.Context, username, event string) ([]models.Achievement, error)
+	AwardAchievementFunc                    func(ctx context.Context, username string, achievementID int64) (bool, error)
+	GetUserAchievementsFunc                 func(ctx context.Context, username string) ([]models.UserAchievement, error)
+	CreateKudosFunc                         func(ctx context.Context, kudos models.Kudos) (int64, error)
+	GetKudosByIDFunc                        func(ctx context.Context, kudosID int64) (models.Kudos, error)
+	GetKudosFeedFunc                        func(ctx context.Context, filter models.KudosFeedFilter) ([]models.Kudos, error)
+	UpdateKudosBoostFunc                    func(ctx context.Context, kudosID, amount, boosts int64) error
+	HideKudosFunc                           func(ctx context.Context, kudosID int64) error
+	GetKudosReactionsFunc                   func(ctx context.Context, kudosIDs []int64, userID int64) ([]models.KudosReaction, error)
+	AddKudosReactionFunc                    func(ctx context.Context, kudosID, userID int64, emoji string) error
+	DeleteKudosReactionFunc                 func(ctx context.Context, kudosID, userID int64, emoji string) error
+	GetReferralCodeFunc                     func(ctx context.Context, username string) (string, error)
+	GetReferralsFunc                        func(ctx context.Context, username string) ([]models.Referral, error)
+	GetPendingReferralFunc                  func(ctx context.Context, username string) (models.Referral, error)
+	QualifyReferralFunc                     func(ctx context.Context, referralID, bonus int64) (bool, error)
+	GetLastCheckInFunc                      func(ctx context.Context, username string) (models.CheckIn, error)
+	CreateCheckInFunc                       func(ctx context.Context, username string, checkIn models.CheckIn) (bool, error)
+	CreateQuestFunc                         func(ctx context.Context, quest models.Quest) (int64, error)
+	UpdateQuestFunc                         func(ctx context.Context, quest models.Quest) (bool, error)
+	DeleteQuestFunc                         func(ctx context.Context, questID int64) (bool, error)
+	GetQuestsFunc                           func(ctx context.Context) ([]models.Quest, error)
+	GetActiveQuestsFunc                     func(ctx context.Context, event string, at time.Time) ([]models.Quest, error)
+	GetUserQuestsFunc                       func(ctx context.Context, username string, at time.Time) ([]models.UserQuest, error)
+	ShareTeamFunc                           func(ctx context.Context, username, otherUsername string) (bool, error)
+	AddQuestRecipientFunc                   func(ctx context.Context, questID int64, username, recipient string) (bool, error)
+	AddQuestProgressFunc                    func(ctx context.Context, questID int64, username string, amount int64) (models.UserQuest, error)
+	CompleteQuestFunc                       func(ctx context.Context, questID int64, username string) (bool, error)
+	CreateNotificationFunc                  func(ctx context.Context, username string, notification models.Notification) error
+	GetNotificationsFunc                    func(ctx context.Context, filter models.NotificationFilter) ([]models.Notification, error)
+	CountUnreadNotificationsFunc            func(ctx context.Context, userID int64) (int64, error)
+	MarkNotificationsReadFunc               func(ctx context.Context, userID int64, notificationIDs []int64, all bool) (int64, error)
+	GetNotificationPreferencesFunc          func(ctx context.Context, username string) (models.NotificationPreferences, error)
+	SetNotificationPreferencesFunc          func(ctx context.Context, userID int64, preferences models.NotificationPreferences) error
+}
+
+func (m *MockRepository) GetOrganizationIDs(ctx context.Context) ([]int64, error) {
+	return m.GetOrganizationIDsFunc(ctx)
 }
 
 func (m *MockRepository) IsUserExist(ctx context.Context, username string) (bool, error) {
@@ -27,6 +144,38 @@ func (m *MockRepository) GetBalanceIDByUsername(ctx context.Context, username st
 	return m.GetBalanceIDByUsernameFunc(ctx, username)
 }
 
+func (m *MockRepository) GetUserRoleByUsername(ctx context.Context, username string) (string, error) {
+	return m.GetUserRoleByUsernameFunc(ctx, username)
+}
+
+func (m *MockRepository) GetActiveUsernames(ctx context.Context) ([]string, error) {
+	return m.GetActiveUsernamesFunc(ctx)
+}
+
+func (m *MockRepository) GetAccountState(ctx context.Context, username string) (models.AccountState, error) {
+	return m.GetAccountStateFunc(ctx, username)
+}
+
+func (m *MockRepository) GetUserForUpdate(ctx context.Context, username string) (models.User, error) {
+	return m.GetUserForUpdateFunc(ctx, username)
+}
+
+func (m *MockRepository) UpdateUserStatus(ctx context.Context, userID int64, status string) error {
+	return m.UpdateUserStatusFunc(ctx, userID, status)
+}
+
+func (m *MockRepository) OffboardUser(ctx context.Context, userID int64) error {
+	return m.OffboardUserFunc(ctx, userID)
+}
+
+func (m *MockRepository) CreateAccountAudit(ctx context.Context, audit models.AccountAudit) error {
+	return m.CreateAccountAuditFunc(ctx, audit)
+}
+
+func (m *MockRepository) GetAccountAudit(ctx context.Context, username string) ([]models.AccountAudit, error) {
+	return m.GetAccountAuditFunc(ctx, username)
+}
+
 func (m *MockRepository) GetBalanceByUserID(ctx context.Context, userID int64) (models.Balance, error) {
 	return m.GetBalanceByUserIDFunc(ctx, userID)
 }
@@ -35,10 +184,158 @@ func (m *MockRepository) GetBalanceAmountByUserID(ctx context.Context, userID in
 	return m.GetBalanceAmountByUserIDFunc(ctx, userID)
 }
 
+func (m *MockRepository) LockBalances(ctx context.Context, balanceIDs ...int64) error {
+	return m.LockBalancesFunc(ctx, balanceIDs...)
+}
+
+func (m *MockRepository) DebitBalance(ctx context.Context, balanceID, amount int64) error {
+	return m.DebitBalanceFunc(ctx, balanceID, amount)
+}
+
+func (m *MockRepository) DebitBalanceIgnoringHolds(ctx context.Context, balanceID, amount int64) error {
+	return m.DebitBalanceIgnoringHoldsFunc(ctx, balanceID, amount)
+}
+
+func (m *MockRepository) CreditBalance(ctx context.Context, balanceID, amount int64) error {
+	return m.CreditBalanceFunc(ctx, balanceID, amount)
+}
+
+func (m *MockRepository) CreateBalanceLot(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+	return m.CreateBalanceLotFunc(ctx, balanceID, amount, grantedAt)
+}
+
+func (m *MockRepository) SpendBalanceLots(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+	return m.SpendBalanceLotsFunc(ctx, balanceID, amount)
+}
+
+func (m *MockRepository) GetBalanceLotsByUserID(ctx context.Context, userID int64, grantedBefore time.Time) ([]models.BalanceLot, error) {
+	return m.GetBalanceLotsByUserIDFunc(ctx, userID, grantedBefore)
+}
+
+func (m *MockRepository) GetExpiredLotOwners(ctx context.Context, grantedBefore time.Time, limit int) ([]models.BalanceOwner, error) {
+	return m.GetExpiredLotOwnersFunc(ctx, grantedBefore, limit)
+}
+
+func (m *MockRepository) ExpireBalanceLots(ctx context.Context, balanceID int64, grantedBefore time.Time) (int64, error) {
+	return m.ExpireBalanceLotsFunc(ctx, balanceID, grantedBefore)
+}
+
+func (m *MockRepository) IsCurrencyExist(ctx context.Context, code string) (bool, error) {
+	return m.IsCurrencyExistFunc(ctx, code)
+}
+
+func (m *MockRepository) GetOrCreateWallet(ctx context.Context, username, currency string) (models.Balance, error) {
+	return m.GetOrCreateWalletFunc(ctx, username, currency)
+}
+
+func (m *MockRepository) GetWalletsByUserID(ctx context.Context, userID int64) ([]models.Wallet, error) {
+	return m.GetWalletsByUserIDFunc(ctx, userID)
+}
+
+func (m *MockRepository) GetSystemBalanceID(ctx context.Context, name, currency string) (int64, error) {
+	return m.GetSystemBalanceIDFunc(ctx, name, currency)
+}
+
+func (m *MockRepository) DebitSystemBalance(ctx context.Context, balanceID, amount int64) error {
+	return m.DebitSystemBalanceFunc(ctx, balanceID, amount)
+}
+
 func (m *MockRepository) GetBalanceHistoryByUserID(ctx context.Context, userID int64) ([]models.BalanceHistory, error) {
 	return m.GetBalanceHistoryByUserIDFunc(ctx, userID)
 }
 
+func (m *MockRepository) NextTransferID(ctx context.Context) (int64, error) {
+	return m.NextTransferIDFunc(ctx)
+}
+
+func (m *MockRepository) CreateBalanceHistory(ctx context.Context, entry models.BalanceHistory) error {
+	return m.CreateBalanceHistoryFunc(ctx, entry)
+}
+
+func (m *MockRepository) GetSentTransferUsage(ctx context.Context, balanceID int64, username string, since time.Time) (models.TransferUsage, error) {
+	return m.GetSentTransferUsageFunc(ctx, balanceID, username, since)
+}
+
+func (m *MockRepository) GetTransferUsage(ctx context.Context, balanceID int64, sender string, since time.Time) (models.TransferUsage, error) {
+	return m.GetTransferUsageFunc(ctx, balanceID, sender, since)
+}
+
+func (m *MockRepository) GetStatementOpening(ctx context.Context, username string, from time.Time) (models.StatementOpening, error) {
+	return m.GetStatementOpeningFunc(ctx, username, from)
+}
+
+func (m *MockRepository) StreamBalanceHistory(ctx context.Context, balanceID int64, from, to time.Time, fn func(entry models.BalanceHistory) error) error {
+	return m.StreamBalanceHistoryFunc(ctx, balanceID, from, to, fn)
+}
+
+func (m *MockRepository) GetLastSnapshotDay(ctx context.Context) (time.Time, error) {
+	return m.GetLastSnapshotDayFunc(ctx)
+}
+
+func (m *MockRepository) CreateBalanceSnapshots(ctx context.Context, day, dayEnd time.Time) (int64, error) {
+	return m.CreateBalanceSnapshotsFunc(ctx, day, dayEnd)
+}
+
+func (m *MockRepository) GetNearestSnapshot(ctx context.Context, username string, maxDay time.Time) (models.BalanceSnapshot, error) {
+	return m.GetNearestSnapshotFunc(ctx, username, maxDay)
+}
+
+func (m *MockRepository) GetBalanceChange(ctx context.Context, balanceID int64, username string, from, to time.Time) (models.BalanceChange, error) {
+	return m.GetBalanceChangeFunc(ctx, balanceID, username, from, to)
+}
+
+func (m *MockRepository) GetTransactionsByUsername(ctx context.Context, username string) ([]models.BalanceHistory, error) {
+	return m.GetTransactionsByUsernameFunc(ctx, username)
+}
+
+func (m *MockRepository) GetBalanceHistoryByID(ctx context.Context, entryID int64) (models.BalanceHistory, error) {
+	return m.GetBalanceHistoryByIDFunc(ctx, entryID)
+}
+
+func (m *MockRepository) GetTransferCounterpart(ctx context.Context, entry models.BalanceHistory) (models.BalanceHistory, error) {
+	return m.GetTransferCounterpartFunc(ctx, entry)
+}
+
+func (m *MockRepository) CreateTeam(ctx context.Context, name string, ownerID int64) (int64, error) {
+	return m.CreateTeamFunc(ctx, name, ownerID)
+}
+
+func (m *MockRepository) GetTeamByID(ctx context.Context, teamID int64) (models.Team, error) {
+	return m.GetTeamByIDFunc(ctx, teamID)
+}
+
+func (m *MockRepository) GetTeamsByUserID(ctx context.Context, userID int64) ([]models.Team, error) {
+	return m.GetTeamsByUserIDFunc(ctx, userID)
+}
+
+func (m *MockRepository) LockTeam(ctx context.Context, teamID int64) error {
+	return m.LockTeamFunc(ctx, teamID)
+}
+
+func (m *MockRepository) GetTeamMember(ctx context.Context, teamID int64, username string) (models.TeamMember, error) {
+	return m.GetTeamMemberFunc(ctx, teamID, username)
+}
+
+func (m *MockRepository) GetTeamMembers(ctx context.Context, teamID int64) ([]models.TeamMember, error) {
+	return m.GetTeamMembersFunc(ctx, teamID)
+}
+
+func (m *MockRepository) UpsertTeamMember(ctx context.Context, member models.TeamMember) (bool, error) {
+	return m.UpsertTeamMemberFunc(ctx, member)
+}
+
+func (m *MockRepository) DeleteTeamMember(ctx context.Context, teamID, userID int64) error {
+	return m.DeleteTeamMemberFunc(ctx, teamID, userID)
+}
+
+func (m *MockRepository) CountTeamOwners(ctx context.Context, teamID int64) (int64, error) {
+	return m.CountTeamOwnersFunc(ctx, teamID)
+}
+
+func (m *MockRepository) GetInventoryMerchItemsByInventoryID(ctx context.Context, inventoryID int64) ([]models.InventoryMerch, error) {
+	return m.GetInventoryMerchItemsByInventoryIDFunc(ctx, inventoryID)
+}
+
 func (m *MockRepository) GetInventoryMerchItems(ctx context.Context, userID int64) ([]models.InventoryMerch, error) {
 	return m.GetInventoryMerchItemsFunc(ctx, userID)
 }
@@ -47,14 +344,278 @@ func (m *MockRepository) GetInventoryIDByUserID(ctx context.Context, userID int6
 	return m.GetInventoryIDByUserIDFunc(ctx, userID)
 }
 
+func (m *MockRepository) AddInventoryMerch(ctx context.Context, inventoryID, merchID int64, item string) error {
+	return m.AddInventoryMerchFunc(ctx, inventoryID, merchID, item)
+}
+
 func (m *MockRepository) GetMerchByName(ctx context.Context, name string) (models.Merch, error) {
 	return m.GetMerchByNameFunc(ctx, name)
 }
 
-func (m *MockRepository) BuyItemTX(ctx context.Context, userID, balanceID, inventoryID, merchID, price int64, username, item string) error {
-	return m.BuyItemTXFunc(ctx, userID, balanceID, inventoryID, merchID, price, username, item)
+func (m *MockRepository) CreateSchedule(ctx context.Context, schedule models.Schedule) (int64, error) {
+	return m.CreateScheduleFunc(ctx, schedule)
+}
+
+func (m *MockRepository) GetSchedulesByUserID(ctx context.Context, userID int64) ([]models.Schedule, error) {
+	return m.GetSchedulesByUserIDFunc(ctx, userID)
+}
+
+func (m *MockRepository) GetScheduleByID(ctx context.Context, userID, scheduleID int64) (models.Schedule, error) {
+	return m.GetScheduleByIDFunc(ctx, userID, scheduleID)
+}
+
+func (m *MockRepository) ClaimDueSchedule(ctx context.Context, now time.Time) (models.Schedule, error) {
+	return m.ClaimDueScheduleFunc(ctx, now)
+}
+
+func (m *MockRepository) UpdateSchedule(ctx context.Context, schedule models.Schedule) error {
+	return m.UpdateScheduleFunc(ctx, schedule)
+}
+
+func (m *MockRepository) DeleteSchedule(ctx context.Context, userID, scheduleID int64) (bool, error) {
+	return m.DeleteScheduleFunc(ctx, userID, scheduleID)
+}
+
+func (m *MockRepository) CreateScheduleRun(ctx context.Context, run models.ScheduleRun) error {
+	return m.CreateScheduleRunFunc(ctx, run)
+}
+
+func (m *MockRepository) GetScheduleRuns(ctx context.Context, scheduleID int64) ([]models.ScheduleRun, error) {
+	return m.GetScheduleRunsFunc(ctx, scheduleID)
+}
+
+func (m *MockRepository) CreateHold(ctx context.Context, hold models.Hold) (int64, error) {
+	return m.CreateHoldFunc(ctx, hold)
+}
+
+func (m *MockRepository) GetHeldAmountByUserID(ctx context.Context, userID int64) (int64, error) {
+	return m.GetHeldAmountByUserIDFunc(ctx, userID)
+}
+
+func (m *MockRepository) GetHoldsByUser(ctx context.Context, userID int64, username string) ([]models.Hold, error) {
+	return m.GetHoldsByUserFunc(ctx, userID, username)
+}
+
+func (m *MockRepository) GetHoldByID(ctx context.Context, holdID int64) (models.Hold, error) {
+	return m.GetHoldByIDFunc(ctx, holdID)
+}
+
+func (m *MockRepository) ResolveHold(ctx context.Context, holdID int64, status string) error {
+	return m.ResolveHoldFunc(ctx, holdID, status)
+}
+
+func (m *MockRepository) ReleaseExpiredHolds(ctx context.Context, now time.Time) (int64, error) {
+	return m.ReleaseExpiredHoldsFunc(ctx, now)
+}
+
+func (m *MockRepository) ReleaseHoldsByOwner(ctx context.Context, ownerID int64) error {
+	return m.ReleaseHoldsByOwnerFunc(ctx, ownerID)
+}
+
+func (m *MockRepository) CreatePaymentRequest(ctx context.Context, pr models.PaymentRequest) (int64, error) {
+	return m.CreatePaymentRequestFunc(ctx, pr)
+}
+
+func (m *MockRepository) GetIncomingPaymentRequests(ctx context.Context, payer string, now time.Time) ([]models.PaymentRequest, error) {
+	return m.GetIncomingPaymentRequestsFunc(ctx, payer, now)
+}
+
+func (m *MockRepository) GetOutgoingPaymentRequests(ctx context.Context, requesterID int64) ([]models.PaymentRequest, error) {
+	return m.GetOutgoingPaymentRequestsFunc(ctx, requesterID)
+}
+
+func (m *MockRepository) GetPaymentRequestForPayer(ctx context.Context, payer string, requestID int64) (models.PaymentRequest, error) {
+	return m.GetPaymentRequestForPayerFunc(ctx, payer, requestID)
+}
+
+func (m *MockRepository) ResolvePaymentRequest(ctx context.Context, requestID int64, status string) error {
+	return m.ResolvePaymentRequestFunc(ctx, requestID, status)
+}
+
+func (m *MockRepository) FindTransferCycles(ctx context.Context, since time.Time) ([]models.FraudHit, error) {
+	return m.FindTransferCyclesFunc(ctx, since)
+}
+
+func (m *MockRepository) FindFunnels(ctx context.Context, since time.Time, newAccountAge time.Duration, minSenders int) ([]models.FraudHit, error) {
+	return m.FindFunnelsFunc(ctx, since, newAccountAge, minSenders)
+}
+
+func (m *MockRepository) FindBursts(ctx context.Context, since time.Time, window time.Duration, minAmount int64) ([]models.FraudHit, error) {
+	return m.FindBurstsFunc(ctx, since, window, minAmount)
+}
+
+func (m *MockRepository) CreateFraudCase(ctx context.Context, fc models.FraudCase) (bool, error) {
+	return m.CreateFraudCaseFunc(ctx, fc)
+}
+
+func (m *MockRepository) GetFraudCases(ctx context.Context, status string) ([]models.FraudCase, error) {
+	return m.GetFraudCasesFunc(ctx, status)
+}
+
+func (m *MockRepository) GetFraudCaseByID(ctx context.Context, caseID int64) (models.FraudCase, error) {
+	return m.GetFraudCaseByIDFunc(ctx, caseID)
+}
+
+func (m *MockRepository) ResolveFraudCase(ctx context.Context, caseID int64, status, admin string) error {
+	return m.ResolveFraudCaseFunc(ctx, caseID, status, admin)
+}
+
+func (m *MockRepository) SetFraudHold(ctx context.Context, usernames []string) error {
+	return m.SetFraudHoldFunc(ctx, usernames)
+}
+
+func (m *MockRepository) ReleaseFraudHold(ctx context.Context, usernames []string) error {
+	return m.ReleaseFraudHoldFunc(ctx, usernames)
+}
+
+func (m *MockRepository) CreateAllowanceRun(ctx context.Context, periodStart time.Time, amount, usersCount int64) (bool, error) {
+	return m.CreateAllowanceRunFunc(ctx, periodStart, amount, usersCount)
+}
+
+func (m *MockRepository) RecordLeaderboardTransfer(ctx context.Context, sender, recipient string, amount int64, periods []models.LeaderboardPeriod) error {
+	return m.RecordLeaderboardTransferFunc(ctx, sender, recipient, amount, periods)
+}
+
+func (m *MockRepository) GetLeaderboard(ctx context.Context, filter models.LeaderboardFilter) ([]models.LeaderboardEntry, error) {
+	return m.GetLeaderboardFunc(ctx, filter)
+}
+
+func (m *MockRepository) SetLeaderboardOptOut(ctx context.Context, userID int64, optOut bool) error {
+	return m.SetLeaderboardOptOutFunc(ctx, userID, optOut)
+}
+
+func (m *MockRepository) IncrementUserActivity(ctx context.Context, username, activity string) error {
+	return m.IncrementUserActivityFunc(ctx, username, activity)
+}
+
+func (m *MockRepository) GetDueAchievements(ctx context.Context, username, event string) ([]models.Achievement, error) {
+	return m.GetDueAchievementsFunc(ctx, username, event)
+}
+
+func (m *MockRepository) AwardAchievement(ctx context.Context, username string, achievementID int64) (bool, error) {
+	return m.AwardAchievementFunc(ctx, username, achievementID)
+}
+
+func (m *MockRepository) GetUserAchievements(ctx context.Context, username string) ([]models.UserAchievement, error) {
+	return m.GetUserAchievementsFunc(ctx, username)
+}
+
+func (m *MockRepository) CreateKudos(ctx context.Context, kudos models.Kudos) (int64, error) {
+	return m.CreateKudosFunc(ctx, kudos)
+}
+
+func (m *MockRepository) GetKudosByID(ctx context.Context, kudosID int64) (models.Kudos, error) {
+	return m.GetKudosByIDFunc(ctx, kudosID)
+}
+
+func (m *MockRepository) GetKudosFeed(ctx context.Context, filter models.KudosFeedFilter) ([]models.Kudos, error) {
+	return m.GetKudosFeedFunc(ctx, filter)
+}
+
+func (m *MockRepository) UpdateKudosBoost(ctx context.Context, kudosID, amount, boosts int64) error {
+	return m.UpdateKudosBoostFunc(ctx, kudosID, amount, boosts)
+}
+
+func (m *MockRepository) HideKudos(ctx context.Context, kudosID int64) error {
+	return m.HideKudosFunc(ctx, kudosID)
+}
+
+func (m *MockRepository) GetKudosReactions(ctx context.Context, kudosIDs []int64, userID int64) ([]models.KudosReaction, error) {
+	return m.GetKudosReactionsFunc(ctx, kudosIDs, userID)
+}
+
+func (m *MockRepository) AddKudosReaction(ctx context.Context, kudosID, userID int64, emoji string) error {
+	return m.AddKudosReactionFunc(ctx, kudosID, userID, emoji)
+}
+
+func (m *MockRepository) DeleteKudosReaction(ctx context.Context, kudosID, userID int64, emoji string) error {
+	return m.DeleteKudosReactionFunc(ctx, kudosID, userID, emoji)
+}
+
+func (m *MockRepository) GetReferralCode(ctx context.Context, username string) (string, error) {
+	return m.GetReferralCodeFunc(ctx, username)
+}
+
+func (m *MockRepository) GetReferrals(ctx context.Context, username string) ([]models.Referral, error) {
+	return m.GetReferralsFunc(ctx, username)
+}
+
+func (m *MockRepository) GetPendingReferral(ctx context.Context, username string) (models.Referral, error) {
+	return m.GetPendingReferralFunc(ctx, username)
+}
+
+func (m *MockRepository) QualifyReferral(ctx context.Context, referralID, bonus int64) (bool, error) {
+	return m.QualifyReferralFunc(ctx, referralID, bonus)
+}
+
+func (m *MockRepository) GetLastCheckIn(ctx context.Context, username string) (models.CheckIn, error) {
+	return m.GetLastCheckInFunc(ctx, username)
+}
+
+func (m *MockRepository) CreateCheckIn(ctx context.Context, username string, checkIn models.CheckIn) (bool, error) {
+	return m.CreateCheckInFunc(ctx, username, checkIn)
+}
+
+func (m *MockRepository) CreateQuest(ctx context.Context, quest models.Quest) (int64, error) {
+	return m.CreateQuestFunc(ctx, quest)
+}
+
+func (m *MockRepository) UpdateQuest(ctx context.Context, quest models.Quest) (bool, error) {
+	return m.UpdateQuestFunc(ctx, quest)
+}
+
+func (m *MockRepository) DeleteQuest(ctx context.Context, questID int64) (bool, error) {
+	return m.DeleteQuestFunc(ctx, questID)
+}
+
+func (m *MockRepository) GetQuests(ctx context.Context) ([]models.Quest, error) {
+	return m.GetQuestsFunc(ctx)
+}
+
+func (m *MockRepository) GetActiveQuests(ctx context.Context, event string, at time.Time) ([]models.Quest, error) {
+	return m.GetActiveQuestsFunc(ctx, event, at)
+}
+
+func (m *MockRepository) GetUserQuests(ctx context.Context, username string, at time.Time) ([]models.UserQuest, error) {
+	return m.GetUserQuestsFunc(ctx, username, at)
+}
+
+func (m *MockRepository) ShareTeam(ctx context.Context, username, otherUsername string) (bool, error) {
+	return m.ShareTeamFunc(ctx, username, otherUsername)
+}
+
+func (m *MockRepository) AddQuestRecipient(ctx context.Context, questID int64, username, recipient string) (bool, error) {
+	return m.AddQuestRecipientFunc(ctx, questID, username, recipient)
+}
+
+func (m *MockRepository) AddQuestProgress(ctx context.Context, questID int64, username string, amount int64) (models.UserQuest, error) {
+	return m.AddQuestProgressFunc(ctx, questID, username, amount)
+}
+
+func (m *MockRepository) CompleteQuest(ctx context.Context, questID int64, username string) (bool, error) {
+	return m.CompleteQuestFunc(ctx, questID, username)
+}
+
+func (m *MockRepository) CreateNotification(ctx context.Context, username string, notification models.Notification) error {
+	return m.CreateNotificationFunc(ctx, username, notification)
+}
+
+func (m *MockRepository) GetNotifications(ctx context.Context, filter models.NotificationFilter) ([]models.Notification, error) {
+	return m.GetNotificationsFunc(ctx, filter)
+}
+
+func (m *MockRepository) CountUnreadNotifications(ctx context.Context, userID int64) (int64, error) {
+	return m.CountUnreadNotificationsFunc(ctx, userID)
+}
+
+func (m *MockRepository) MarkNotificationsRead(ctx context.Context, userID int64, notificationIDs []int64, all bool) (int64, error) {
+	return m.MarkNotificationsReadFunc(ctx, userID, notificationIDs, all)
+}
+
+func (m *MockRepository) GetNotificationPreferences(ctx context.Context, username string) (models.NotificationPreferences, error) {
+	return m.GetNotificationPreferencesFunc(ctx, username)
 }
 
-func (m *MockRepository) SendCoinsTX(ctx context.Context, userID, senderBalanceID, recipientBalanceID, amount int64, sender, recipient string) error {
-	return m.SendCoinsTXFunc(ctx, userID, senderBalanceID, recipientBalanceID, amount, sender, recipient)
+func (m *MockRepository) SetNotificationPreferences(ctx context.Context, userID int64, preferences models.NotificationPreferences) error {
+	return m.SetNotificationPreferencesFunc(ctx, userID, preferences)
 }
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+// Reversal
+// GetTransactions возвращает историю баланса пользователя с идентификаторами записей для отмены
+func (s *service) GetTransactions(ctx context.Context, username string) ([]models.TransactionDTO, error) {
+	transactions, err := s.repo.GetTransactionsByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionsDTO := make([]models.TransactionDTO, 0, len(transactions))
+	for _, t := range transactions {
+		transactionsDTO = append(transactionsDTO, t.ToModelTransactionDTO())
+	}
+
+	return transactionsDTO, nil
+}
+
+// ReverseTransaction возвращает монеты перевода отправителю компенсирующими записями, история не удаляется.
+// Если получатель уже потратил или зарезервировал монеты, отмена отклоняется, баланс не уходит в минус.
+// Лимиты переводов и ограничения аккаунтов на отмену не действуют. Переводы с кошельками команд не отменяются.
+func (s *service) ReverseTransaction(ctx context.Context, qp models.ReversalQuery) (models.ReversalDTO, error) {
+	qp.Reason = strings.TrimSpace(qp.Reason)
+	if qp.EntryID < 1 || qp.Reason == "" {
+		return models.ReversalDTO{}, errors.New(internalErrors.ErrInvalidReversalReqParams)
+	}
+
+	reversalDTO := models.ReversalDTO{}
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		entry, err := repo.GetBalanceHistoryByID(ctx, qp.EntryID)
+		if err != nil {
+			return err
+		}
+		if entry.ID == 0 {
+			return errors.New(internalErrors.ErrTransactionNotFound)
+		}
+		if entry.Type != models.HistoryTypeTransfer {
+			return errors.New(internalErrors.ErrTransactionNotReversible)
+		}
+		// кошелёк команды не принадлежит пользователю, переводы с ним отменяются вручную участниками команды
+		if models.IsTeamAccount(entry.Sender) || models.IsTeamAccount(entry.Recipient) {
+			return errors.New(internalErrors.ErrTransactionNotReversible)
+		}
+
+		for _, username := range []string{entry.Sender, entry.Recipient} {
+			state, err := repo.GetAccountState(ctx, username)
+			if err != nil {
+				return err
+			}
+			if state.Deleted {
+				return errors.New(internalErrors.ErrTransactionNotReversible)
+			}
+		}
+		// перевод отменяется в той же валюте, в которой был совершён
+		senderBalance, err := repo.GetOrCreateWallet(ctx, entry.Sender, entry.Currency)
+		if err != nil {
+			return err
+		}
+		recipientBalance, err := repo.GetOrCreateWallet(ctx, entry.Recipient, entry.Currency)
+		if err != nil {
+			return err
+		}
+		senderBalanceID, recipientBalanceID := senderBalance.ID, recipientBalance.ID
+
+		// отмена перевода затрагивает только эти два баланса, блокировка исключает повторную конкурентную отмену
+		if err := repo.LockBalances(ctx, senderBalanceID, recipientBalanceID); err != nil {
+			return err
+		}
+		entry, err = repo.GetBalanceHistoryByID(ctx, qp.EntryID)
+		if err != nil {
+			return err
+		}
+		if entry.Reversed {
+			return errors.New(internalErrors.ErrTransactionAlreadyReversed)
+		}
+		counterpart, err := repo.GetTransferCounterpart(ctx, entry)
+		if err != nil {
+			return err
+		}
+		if counterpart.ID == 0 {
+			return errors.New(internalErrors.ErrTransactionNotReversible)
+		}
+
+		senderEntry, recipientEntry := entry, counterpart
+		if entry.BalanceID == recipientBalanceID {
+			senderEntry, recipientEntry = counterpart, entry
+		}
+		if senderEntry.BalanceID != senderBalanceID || recipientEntry.BalanceID != recipientBalanceID {
+			return errors.New(internalErrors.ErrTransactionNotReversible)
+		}
+
+		amount := entry.TransactionAmount
+		if err := repo.DebitBalance(ctx, recipientBalanceID, amount); err != nil {
+			if err.Error() == internalErrors.ErrNotEnoughCoins {
+				return errors.New(internalErrors.ErrReversalNotEnoughCoins)
+			}
+			return err
+		}
+		spentLots, err := repo.SpendBalanceLots(ctx, recipientBalanceID, amount)
+		if err != nil {
+			return err
+		}
+		if err := repo.CreditBalance(ctx, senderBalanceID, amount); err != nil {
+			return err
+		}
+		if err := s.moveBalanceLots(ctx, repo, senderBalanceID, spentLots); err != nil {
+			return err
+		}
+
+		reversal := models.BalanceHistory{
+			TransactionAmount: amount,
+			Sender:            entry.Recipient,
+			Recipient:         entry.Sender,
+			Type:              models.HistoryTypeReversal,
+			Reason:            qp.Reason,
+			Actor:             qp.Admin,
+		}
+		for _, original := range []models.BalanceHistory{senderEntry, recipientEntry} {
+			reversal.BalanceID = original.BalanceID
+			reversal.ReversalOf = original.ID
+			if err := repo.CreateBalanceHistory(ctx, reversal); err != nil {
+				return err
+			}
+		}
+		// отменённый перевод уходит из рейтинга тех периодов, в которые он попал
+		if err := s.recordLeaderboardTransfer(ctx, repo, entry, -amount, entry.CreatedAt); err != nil {
+			return err
+		}
+		if err := s.reverseKudosTransfer(ctx, repo, entry); err != nil {
+			return err
+		}
+
+		reversalDTO = models.ReversalDTO{
+			FromUser:   entry.Recipient,
+			ToUser:     entry.Sender,
+			Amount:     amount,
+			Currency:   entry.Currency,
+			Reason:     qp.Reason,
+			ReversedBy: qp.Admin,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.ReversalDTO{}, err
+	}
+
+	return reversalDTO, nil
+}
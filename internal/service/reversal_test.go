@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func nextTransferID(ctx context.Context) (int64, error) {
+	return 1, nil
+}
+
+func Test_service_ReverseTransaction(t *testing.T) {
+	createdAt := time.Now()
+	// перевод user1 -> user2 на 500 монет, записи 10 (user1) и 11 (user2)
+	senderEntry := models.BalanceHistory{ID: 10, BalanceID: 1, TransactionAmount: 500, Sender: "user1", Recipient: "user2", Type: models.HistoryTypeTransfer, CreatedAt: createdAt}
+	recipientEntry := models.BalanceHistory{ID: 11, BalanceID: 2, TransactionAmount: 500, Sender: "user1", Recipient: "user2", Type: models.HistoryTypeTransfer, CreatedAt: createdAt}
+
+	tests := []struct {
+		name          string
+		qp            models.ReversalQuery
+		entries       map[int64]models.BalanceHistory
+		state         models.AccountState
+		debitErr      error
+		wantErr       string
+		wantReversals map[int64]int64
+	}{
+		{
+			name:          "success_-_reversed_by_sender_entry",
+			qp:            models.ReversalQuery{Admin: "admin", EntryID: 10, Reason: "mistaken transfer"},
+			entries:       map[int64]models.BalanceHistory{10: senderEntry, 11: recipientEntry},
+			wantReversals: map[int64]int64{1: 10, 2: 11},
+		},
+		{
+			name:          "success_-_reversed_by_recipient_entry",
+			qp:            models.ReversalQuery{Admin: "admin", EntryID: 11, Reason: "mistaken transfer"},
+			entries:       map[int64]models.BalanceHistory{10: senderEntry, 11: recipientEntry},
+			wantReversals: map[int64]int64{1: 10, 2: 11},
+		},
+		{
+			name:    "error_-_reason_required",
+			qp:      models.ReversalQuery{Admin: "admin", EntryID: 10, Reason: " "},
+			wantErr: internalErrors.ErrInvalidReversalReqParams,
+		},
+		{
+			name:    "error_-_transaction_not_found",
+			qp:      models.ReversalQuery{Admin: "admin", EntryID: 12, Reason: "mistaken transfer"},
+			entries: map[int64]models.BalanceHistory{10: senderEntry, 11: recipientEntry},
+			wantErr: internalErrors.ErrTransactionNotFound,
+		},
+		{
+			name: "error_-_purchase_not_reversible",
+			qp:   models.ReversalQuery{Admin: "admin", EntryID: 10, Reason: "mistaken purchase"},
+			entries: map[int64]models.BalanceHistory{
+				10: {ID: 10, BalanceID: 1, TransactionAmount: 500, Sender: "user1", Recipient: shopUser, Type: models.HistoryTypePurchase},
+			},
+			wantErr: internalErrors.ErrTransactionNotReversible,
+		},
+		{
+			name: "error_-_team_deposit_not_reversible",
+			qp:   models.ReversalQuery{Admin: "admin", EntryID: 10, Reason: "mistaken deposit"},
+			entries: map[int64]models.BalanceHistory{
+				10: {ID: 10, BalanceID: 1, TransactionAmount: 500, Sender: "user1", Recipient: models.TeamAccountName("backend"), Type: models.HistoryTypeTransfer, Actor: "user1"},
+			},
+			wantErr: internalErrors.ErrTransactionNotReversible,
+		},
+		{
+			name: "error_-_team_send_not_reversible",
+			qp:   models.ReversalQuery{Admin: "admin", EntryID: 10, Reason: "mistaken send"},
+			entries: map[int64]models.BalanceHistory{
+				10: {ID: 10, BalanceID: 100, TransactionAmount: 500, Sender: models.TeamAccountName("backend"), Recipient: "user2", Type: models.HistoryTypeTransfer, Actor: "user1"},
+			},
+			wantErr: internalErrors.ErrTransactionNotReversible,
+		},
+		{
+			name: "error_-_already_reversed",
+			qp:   models.ReversalQuery{Admin: "admin", EntryID: 10, Reason: "mistaken transfer"},
+			entries: map[int64]models.BalanceHistory{
+				10: {ID: 10, BalanceID: 1, TransactionAmount: 500, Sender: "user1", Recipient: "user2", Type: models.HistoryTypeTransfer, Reversed: true},
+			},
+			wantErr: internalErrors.ErrTransactionAlreadyReversed,
+		},
+		{
+			name:     "error_-_recipient_spent_coins",
+			qp:       models.ReversalQuery{Admin: "admin", EntryID: 10, Reason: "mistaken transfer"},
+			entries:  map[int64]models.BalanceHistory{10: senderEntry, 11: recipientEntry},
+			debitErr: errors.New(internalErrors.ErrNotEnoughCoins),
+			wantErr:  internalErrors.ErrReversalNotEnoughCoins,
+		},
+		{
+			name:    "error_-_offboarded_account",
+			qp:      models.ReversalQuery{Admin: "admin", EntryID: 10, Reason: "mistaken transfer"},
+			entries: map[int64]models.BalanceHistory{10: senderEntry, 11: recipientEntry},
+			state:   models.AccountState{Status: models.UserStatusActive, Deleted: true},
+			wantErr: internalErrors.ErrTransactionNotReversible,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balances := map[int64]int64{1: 500, 2: 1500}
+			reversals := map[int64]int64{}
+			s := &service{
+				repo: &MockRepository{
+					GetBalanceHistoryByIDFunc: func(ctx context.Context, entryID int64) (models.BalanceHistory, error) {
+						return tt.entries[entryID], nil
+					},
+					GetTransferCounterpartFunc: func(ctx context.Context, entry models.BalanceHistory) (models.BalanceHistory, error) {
+						for _, e := range tt.entries {
+							if e.ID != entry.ID {
+								return e, nil
+							}
+						}
+						return models.BalanceHistory{}, nil
+					},
+					GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+						return tt.state, nil
+					},
+					GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+						return models.Balance{ID: map[string]int64{"user1": 1, "user2": 2}[username]}, nil
+					},
+					LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+						return nil
+					},
+					DebitBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						if tt.debitErr != nil {
+							return tt.debitErr
+						}
+						balances[balanceID] -= amount
+						return nil
+					},
+					SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+						return []models.BalanceLot{{BalanceID: balanceID, Amount: amount, GrantedAt: createdAt}}, nil
+					},
+					CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						balances[balanceID] += amount
+						return nil
+					},
+					CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+						return nil
+					},
+					CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+						if entry.Type != models.HistoryTypeReversal || entry.Sender != "user2" || entry.Actor != "admin" {
+							t.Errorf("unexpected reversal entry %v", entry)
+						}
+						reversals[entry.BalanceID] = entry.ReversalOf
+						return nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.ReverseTransaction(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.ReverseTransaction() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.ReverseTransaction() error = %v", err)
+			}
+			if got.FromUser != "user2" || got.ToUser != "user1" || got.Amount != 500 || got.ReversedBy != "admin" {
+				t.Errorf("service.ReverseTransaction() = %v", got)
+			}
+			if balances[1] != 1000 || balances[2] != 1000 {
+				t.Errorf("service.ReverseTransaction() balances = %v", balances)
+			}
+			if len(reversals) != len(tt.wantReversals) {
+				t.Fatalf("service.ReverseTransaction() reversals = %v, want %v", reversals, tt.wantReversals)
+			}
+			for balanceID, reversalOf := range tt.wantReversals {
+				if reversals[balanceID] != reversalOf {
+					t.Errorf("service.ReverseTransaction() reversals = %v, want %v", reversals, tt.wantReversals)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+const minScheduleInterval = time.Minute
+
+// Ошибки перевода, которые записываются в результат запуска как есть. Остальные ошибки
+// записываются как ERR_SEND_COINS, чтобы не показывать пользователю внутренние детали.
+var scheduleRunErrors = map[string]*struct{}{
+	internalErrors.ErrNotEnoughCoins:     {},
+	internalErrors.ErrInvalidRecipient:   {},
+	internalErrors.ErrAccountOnFraudHold: {},
+	internalErrors.ErrAccountFrozen:      {},
+	internalErrors.ErrAccountSuspended:   {},
+	internalErrors.ErrAccountOffboarded:  {},
+	// лимиты переводов
+	internalErrors.ErrTransferAmountLimit:      {},
+	internalErrors.ErrDailyTransferLimit:       {},
+	internalErrors.ErrWeeklyTransferLimit:      {},
+	internalErrors.ErrHourlyTransferCountLimit: {},
+	internalErrors.ErrReceivedFromSenderLimit:  {},
+}
+
+// Schedule
+func (s *service) CreateSchedule(ctx context.Context, qp models.ScheduleQuery) (models.ScheduleDTO, error) {
+	schedule := models.Schedule{
+		UserID:    qp.UserID,
+		Sender:    qp.Username,
+		Recipient: qp.Recipient,
+		NextRunAt: time.Now(),
+	}
+	if qp.Amount != nil {
+		schedule.Amount = *qp.Amount
+	}
+	if qp.RunAt != nil {
+		schedule.NextRunAt = *qp.RunAt
+	}
+	if qp.Interval != nil {
+		schedule.Interval = *qp.Interval
+	}
+	if err := validateSchedule(schedule); err != nil {
+		return models.ScheduleDTO{}, err
+	}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		validRecipient, err := repo.IsUserExist(ctx, schedule.Recipient)
+		if err != nil {
+			return err
+		}
+		if !validRecipient {
+			return errors.New(internalErrors.ErrInvalidRecipient)
+		}
+
+		schedule.ID, err = repo.CreateSchedule(ctx, schedule)
+		return err
+	})
+	if err != nil {
+		return models.ScheduleDTO{}, err
+	}
+
+	return schedule.ToModelScheduleDTO(), nil
+}
+
+func (s *service) GetSchedules(ctx context.Context, userID int64) ([]models.ScheduleDTO, error) {
+	schedules, err := s.repo.GetSchedulesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedulesDTO := make([]models.ScheduleDTO, 0, len(schedules))
+	for _, schedule := range schedules {
+		schedulesDTO = append(schedulesDTO, schedule.ToModelScheduleDTO())
+	}
+
+	return schedulesDTO, nil
+}
+
+func (s *service) UpdateSchedule(ctx context.Context, qp models.ScheduleQuery) (models.ScheduleDTO, error) {
+	schedule := models.Schedule{}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		var err error
+		schedule, err = repo.GetScheduleByID(ctx, qp.UserID, qp.ID)
+		if err != nil {
+			return err
+		}
+		if schedule.ID == 0 {
+			return errors.New(internalErrors.ErrScheduleNotFound)
+		}
+		if schedule.Completed {
+			return errors.New(internalErrors.ErrScheduleCompleted)
+		}
+
+		if qp.Amount != nil {
+			schedule.Amount = *qp.Amount
+		}
+		if qp.RunAt != nil {
+			schedule.NextRunAt = *qp.RunAt
+		}
+		if qp.Interval != nil {
+			schedule.Interval = *qp.Interval
+		}
+		if qp.Paused != nil {
+			// возобновление сбрасывает счётчик неудачных запусков
+			if schedule.Paused && !*qp.Paused {
+				schedule.ConsecutiveFailures = 0
+			}
+			schedule.Paused = *qp.Paused
+		}
+		if err := validateSchedule(schedule); err != nil {
+			return err
+		}
+
+		return repo.UpdateSchedule(ctx, schedule)
+	})
+	if err != nil {
+		return models.ScheduleDTO{}, err
+	}
+
+	return schedule.ToModelScheduleDTO(), nil
+}
+
+func (s *service) DeleteSchedule(ctx context.Context, userID, scheduleID int64) error {
+	deleted, err := s.repo.DeleteSchedule(ctx, userID, scheduleID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New(internalErrors.ErrScheduleNotFound)
+	}
+
+	return nil
+}
+
+func (s *service) GetScheduleRuns(ctx context.Context, userID, scheduleID int64) ([]models.ScheduleRunDTO, error) {
+	runsDTO := []models.ScheduleRunDTO{}
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		schedule, err := repo.GetScheduleByID(ctx, userID, scheduleID)
+		if err != nil {
+			return err
+		}
+		if schedule.ID == 0 {
+			return errors.New(internalErrors.ErrScheduleNotFound)
+		}
+
+		runs, err := repo.GetScheduleRuns(ctx, schedule.ID)
+		if err != nil {
+			return err
+		}
+		for _, run := range runs {
+			runsDTO = append(runsDTO, run.ToModelScheduleRunDTO())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return runsDTO, nil
+}
+
+// ExecuteDueSchedules выполняет все расписания, срок которых наступил, но не больше BatchSize за вызов в каждой организации
+func (s *service) ExecuteDueSchedules(ctx context.Context) error {
+	return s.forEachOrganization(ctx, s.executeOrganizationDueSchedules)
+}
+
+func (s *service) executeOrganizationDueSchedules(ctx context.Context) error {
+	for i := 0; i < s.cfg.Scheduler.BatchSize; i++ {
+		executed, err := s.executeDueSchedule(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+		if !executed {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// executeDueSchedule выполняет перевод, запись результата и перенос следующего запуска в одной транзакции,
+// поэтому срабатывание расписания не может выполниться дважды
+func (s *service) executeDueSchedule(ctx context.Context, now time.Time) (bool, error) {
+	executed := false
+
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		schedule, err := repo.ClaimDueSchedule(ctx, now)
+		if err != nil {
+			return err
+		}
+		if schedule.ID == 0 {
+			return nil
+		}
+		executed = true
+
+		run := models.ScheduleRun{
+			ScheduleID:  schedule.ID,
+			ScheduledAt: schedule.NextRunAt,
+			Status:      models.ScheduleRunStatusSuccess,
+		}
+
+		err = s.SendCoins(ctx, models.CoinsQuery{
+			UserID:    schedule.UserID,
+			Amount:    schedule.Amount,
+			Sender:    schedule.Sender,
+			Recipient: schedule.Recipient,
+		})
+		switch {
+		case err == nil:
+			schedule.ConsecutiveFailures = 0
+		case s.txManager.Retryable(err) || ctx.Err() != nil:
+			// конфликт транзакций повторяется TxManager, отменённый контекст - на следующем тике
+			return err
+		case scheduleRunErrors[err.Error()] == nil:
+			// сбой инфраструктуры не говорит о проблеме расписания, поэтому не приближает паузу.
+			// Запуск записывается и откладывается, иначе расписание захватывалось бы снова и блокировало остальные.
+			log.Logger.Err(err).Msgf("schedule %d run failed", schedule.ID)
+			run.Status = models.ScheduleRunStatusFailed
+			run.Error = internalErrors.ErrSendCoins
+			if schedule.Interval == 0 {
+				// разовый перевод не теряется, а повторяется позже
+				schedule.NextRunAt = now.Add(s.cfg.Scheduler.RetryDelay)
+			} else {
+				schedule.NextRunAt = nextScheduleRun(schedule.NextRunAt, schedule.Interval, now)
+			}
+
+			if err := repo.CreateScheduleRun(ctx, run); err != nil {
+				return err
+			}
+
+			return repo.UpdateSchedule(ctx, schedule)
+		default:
+			// перевод выполнялся в точке сохранения, поэтому его откат не мешает записать неудачный запуск.
+			// Иначе расписание с постоянной ошибкой захватывалось бы снова и блокировало остальные.
+			run.Status = models.ScheduleRunStatusFailed
+			run.Error = err.Error()
+			schedule.ConsecutiveFailures++
+			if schedule.ConsecutiveFailures >= int64(s.cfg.Scheduler.MaxFailures) {
+				schedule.Paused = true
+				log.Logger.Warn().Msgf("schedule %d paused after %d failed runs", schedule.ID, schedule.ConsecutiveFailures)
+			}
+		}
+
+		if schedule.Interval == 0 {
+			schedule.Completed = true
+		} else {
+			schedule.NextRunAt = nextScheduleRun(schedule.NextRunAt, schedule.Interval, now)
+		}
+
+		if err := repo.CreateScheduleRun(ctx, run); err != nil {
+			return err
+		}
+
+		return repo.UpdateSchedule(ctx, schedule)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return executed, nil
+}
+
+// nextScheduleRun пропускает срабатывания, пропущенные во время простоя, чтобы не выполнять их пачкой
+func nextScheduleRun(prev time.Time, interval time.Duration, now time.Time) time.Time {
+	next := prev.Add(interval)
+	if next.After(now) {
+		return next
+	}
+
+	missed := now.Sub(next)/interval + 1
+
+	return next.Add(missed * interval)
+}
+
+func validateSchedule(schedule models.Schedule) error {
+	if schedule.Amount < 1 || schedule.Recipient == "" || schedule.Recipient == schedule.Sender {
+		return errors.New(internalErrors.ErrInvalidScheduleReqParams)
+	}
+	if schedule.Interval != 0 && schedule.Interval < minScheduleInterval {
+		return errors.New(internalErrors.ErrInvalidScheduleReqParams)
+	}
+
+	return nil
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func Test_service_CreateSchedule(t *testing.T) {
+	amount := int64(50)
+	zeroAmount := int64(0)
+	week := 7 * 24 * time.Hour
+	second := time.Second
+
+	type fields struct {
+		repo Repository
+	}
+	type args struct {
+		ctx context.Context
+		qp  models.ScheduleQuery
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "success_-_recurring_schedule_created",
+			fields: fields{
+				repo: &MockRepository{
+					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+						return true, nil
+					},
+					CreateScheduleFunc: func(ctx context.Context, schedule models.Schedule) (int64, error) {
+						return 1, nil
+					},
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				qp:  models.ScheduleQuery{UserID: 1, Username: "user1", Recipient: "user2", Amount: &amount, Interval: &week},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "error_-_invalid_amount",
+			fields: fields{repo: &MockRepository{}},
+			args: args{
+				ctx: context.Background(),
+				qp:  models.ScheduleQuery{UserID: 1, Username: "user1", Recipient: "user2", Amount: &zeroAmount},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "error_-_recipient_is_yourself",
+			fields: fields{repo: &MockRepository{}},
+			args: args{
+				ctx: context.Background(),
+				qp:  models.ScheduleQuery{UserID: 1, Username: "user1", Recipient: "user1", Amount: &amount},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "error_-_interval_too_short",
+			fields: fields{repo: &MockRepository{}},
+			args: args{
+				ctx: context.Background(),
+				qp:  models.ScheduleQuery{UserID: 1, Username: "user1", Recipient: "user2", Amount: &amount, Interval: &second},
+			},
+			wantErr: true,
+		},
+		{
+			name: "error_-_recipient_does_not_exist",
+			fields: fields{
+				repo: &MockRepository{
+					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+						return false, nil
+					},
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				qp:  models.ScheduleQuery{UserID: 1, Username: "user1", Recipient: "user_invalid", Amount: &amount},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{
+				repo:      tt.fields.repo,
+				txManager: &MockTxManager{},
+			}
+			if _, err := s.CreateSchedule(tt.args.ctx, tt.args.qp); (err != nil) != tt.wantErr {
+				t.Errorf("service.CreateSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// errSerialization ошибка, которую TxManager повторяет сам
+var errSerialization = errors.New("serialization failure")
+
+func Test_service_executeDueSchedule(t *testing.T) {
+	now := time.Date(2026, 10, 23, 10, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+
+	transferRepo := func(debitErr error, updated *models.Schedule, run *models.ScheduleRun, schedule models.Schedule) *MockRepository {
+		return &MockRepository{
+			GetNotificationPreferencesFunc: getNoNotificationPreferences,
+			NextTransferIDFunc:             nextTransferID,
+			GetActiveQuestsFunc:            getNoActiveQuests,
+			GetPendingReferralFunc:         getNoPendingReferral,
+			GetDueAchievementsFunc:         getNoDueAchievements,
+			IncrementUserActivityFunc:      incrementUserActivityNoop,
+			RecordLeaderboardTransferFunc:  recordLeaderboardTransferNoop,
+			ClaimDueScheduleFunc: func(ctx context.Context, now time.Time) (models.Schedule, error) {
+				return schedule, nil
+			},
+			IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+				return true, nil
+			},
+			GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+				if username == "user1" {
+					return models.Balance{ID: 1, Amount: 1000}, nil
+				}
+				return models.Balance{ID: 2}, nil
+			},
+			GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+				return models.AccountState{Status: models.UserStatusActive}, nil
+			},
+			LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+				return nil
+			},
+			DebitBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+				return debitErr
+			},
+			SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+				return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+			},
+			CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+				return nil
+			},
+			CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+				return nil
+			},
+			CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+				return nil
+			},
+			CreateScheduleRunFunc: func(ctx context.Context, r models.ScheduleRun) error {
+				*run = r
+				return nil
+			},
+			UpdateScheduleFunc: func(ctx context.Context, s models.Schedule) error {
+				*updated = s
+				return nil
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		schedule     models.Schedule
+		debitErr     error
+		wantErr      bool
+		wantRun      string
+		wantRunError string
+		wantPaused   bool
+		wantComplete bool
+		wantFailures int64
+		wantNextRun  time.Time
+	}{
+		{
+			name:        "success_-_recurring_transfer_executed",
+			schedule:    models.Schedule{ID: 1, UserID: 1, Sender: "user1", Recipient: "user2", Amount: 50, Interval: week, NextRunAt: now},
+			wantRun:     models.ScheduleRunStatusSuccess,
+			wantNextRun: now.Add(week),
+		},
+		{
+			name:         "success_-_one_off_transfer_completed",
+			schedule:     models.Schedule{ID: 1, UserID: 1, Sender: "user1", Recipient: "user2", Amount: 50, NextRunAt: now},
+			wantRun:      models.ScheduleRunStatusSuccess,
+			wantComplete: true,
+			wantNextRun:  now,
+		},
+		{
+			name:         "success_-_failed_run_recorded",
+			schedule:     models.Schedule{ID: 1, UserID: 1, Sender: "user1", Recipient: "user2", Amount: 50, Interval: week, NextRunAt: now},
+			debitErr:     errors.New(internalErrors.ErrNotEnoughCoins),
+			wantRun:      models.ScheduleRunStatusFailed,
+			wantFailures: 1,
+			wantNextRun:  now.Add(week),
+		},
+		{
+			name:         "success_-_paused_after_repeated_failures",
+			schedule:     models.Schedule{ID: 1, UserID: 1, Sender: "user1", Recipient: "user2", Amount: 50, Interval: week, NextRunAt: now, ConsecutiveFailures: 2},
+			debitErr:     errors.New(internalErrors.ErrNotEnoughCoins),
+			wantRun:      models.ScheduleRunStatusFailed,
+			wantPaused:   true,
+			wantFailures: 3,
+			wantNextRun:  now.Add(week),
+		},
+		{
+			name:         "success_-_infrastructure_error_recorded_without_failure",
+			schedule:     models.Schedule{ID: 1, UserID: 1, Sender: "user1", Recipient: "user2", Amount: 50, Interval: week, NextRunAt: now},
+			debitErr:     errors.New("db error"),
+			wantRun:      models.ScheduleRunStatusFailed,
+			wantRunError: internalErrors.ErrSendCoins,
+			wantNextRun:  now.Add(week),
+		},
+		{
+			name:         "success_-_infrastructure_errors_do_not_pause",
+			schedule:     models.Schedule{ID: 1, UserID: 1, Sender: "user1", Recipient: "user2", Amount: 50, Interval: week, NextRunAt: now, ConsecutiveFailures: 2},
+			debitErr:     errors.New("db error"),
+			wantRun:      models.ScheduleRunStatusFailed,
+			wantRunError: internalErrors.ErrSendCoins,
+			wantFailures: 2,
+			wantNextRun:  now.Add(week),
+		},
+		{
+			name:         "success_-_one_off_transfer_retried_after_infrastructure_error",
+			schedule:     models.Schedule{ID: 1, UserID: 1, Sender: "user1", Recipient: "user2", Amount: 50, NextRunAt: now},
+			debitErr:     errors.New("db error"),
+			wantRun:      models.ScheduleRunStatusFailed,
+			wantRunError: internalErrors.ErrSendCoins,
+			wantNextRun:  now.Add(time.Minute),
+		},
+		{
+			name:     "error_-_retryable_failure_is_not_recorded",
+			schedule: models.Schedule{ID: 1, UserID: 1, Sender: "user1", Recipient: "user2", Amount: 50, Interval: week, NextRunAt: now},
+			debitErr: errSerialization,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := models.Schedule{}
+			run := models.ScheduleRun{}
+			s := &service{
+				repo: transferRepo(tt.debitErr, &updated, &run, tt.schedule),
+				txManager: &MockTxManager{RetryableFunc: func(err error) bool {
+					return errors.Is(err, errSerialization)
+				}},
+				cfg: config.Config{Scheduler: config.Scheduler{MaxFailures: 3, BatchSize: 10, RetryDelay: time.Minute}},
+			}
+
+			executed, err := s.executeDueSchedule(context.Background(), now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("service.executeDueSchedule() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !executed {
+				t.Errorf("service.executeDueSchedule() executed = false, want true")
+			}
+			if run.Status != tt.wantRun {
+				t.Errorf("run status = %v, want %v", run.Status, tt.wantRun)
+			}
+			if tt.wantRunError != "" && run.Error != tt.wantRunError {
+				t.Errorf("run error = %v, want %v", run.Error, tt.wantRunError)
+			}
+			if updated.Paused != tt.wantPaused || updated.Completed != tt.wantComplete {
+				t.Errorf("schedule paused = %v, completed = %v, want %v, %v", updated.Paused, updated.Completed, tt.wantPaused, tt.wantComplete)
+			}
+			if updated.ConsecutiveFailures != tt.wantFailures {
+				t.Errorf("schedule failures = %v, want %v", updated.ConsecutiveFailures, tt.wantFailures)
+			}
+			if !updated.NextRunAt.Equal(tt.wantNextRun) {
+				t.Errorf("schedule next run = %v, want %v", updated.NextRunAt, tt.wantNextRun)
+			}
+		})
+	}
+}
+
+func Test_nextScheduleRun(t *testing.T) {
+	now := time.Date(2026, 10, 23, 10, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name string
+		prev time.Time
+		want time.Time
+	}{
+		{name: "next_in_future", prev: now, want: now.Add(day)},
+		{name: "missed_runs_skipped", prev: now.Add(-3*day - time.Hour), want: now.Add(day - time.Hour)},
+		{name: "due_exactly_now", prev: now.Add(-day), want: now.Add(day)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextScheduleRun(tt.prev, day, now); !got.Equal(tt.want) {
+				t.Errorf("nextScheduleRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,68 +3,262 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/devWaylander/coins_store/config"
 	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
 	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/go-openapi/strfmt"
 )
 
+const shopUser = "AvitoShop"
+
 type Repository interface {
+	// Organization
+	GetOrganizationIDs(ctx context.Context) ([]int64, error)
 	// User
 	IsUserExist(ctx context.Context, username string) (bool, error)
 	GetBalanceIDByUsername(ctx context.Context, username string) (int64, error)
+	GetUserRoleByUsername(ctx context.Context, username string) (string, error)
+	GetActiveUsernames(ctx context.Context) ([]string, error)
+	// Account
+	GetAccountState(ctx context.Context, username string) (models.AccountState, error)
+	GetUserForUpdate(ctx context.Context, username string) (models.User, error)
+	UpdateUserStatus(ctx context.Context, userID int64, status string) error
+	OffboardUser(ctx context.Context, userID int64) error
+	CreateAccountAudit(ctx context.Context, audit models.AccountAudit) error
+	GetAccountAudit(ctx context.Context, username string) ([]models.AccountAudit, error)
 	// Balance
 	GetBalanceByUserID(ctx context.Context, userID int64) (models.Balance, error)
 	GetBalanceAmountByUserID(ctx context.Context, userID int64) (int64, error)
+	LockBalances(ctx context.Context, balanceIDs ...int64) error
+	DebitBalance(ctx context.Context, balanceID, amount int64) error
+	DebitBalanceIgnoringHolds(ctx context.Context, balanceID, amount int64) error
+	CreditBalance(ctx context.Context, balanceID, amount int64) error
+	// Balance lot
+	CreateBalanceLot(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error
+	SpendBalanceLots(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error)
+	GetBalanceLotsByUserID(ctx context.Context, userID int64, grantedBefore time.Time) ([]models.BalanceLot, error)
+	GetExpiredLotOwners(ctx context.Context, grantedBefore time.Time, limit int) ([]models.BalanceOwner, error)
+	ExpireBalanceLots(ctx context.Context, balanceID int64, grantedBefore time.Time) (int64, error)
+	// Wallet
+	IsCurrencyExist(ctx context.Context, code string) (bool, error)
+	GetOrCreateWallet(ctx context.Context, username, currency string) (models.Balance, error)
+	GetWalletsByUserID(ctx context.Context, userID int64) ([]models.Wallet, error)
+	// System account
+	GetSystemBalanceID(ctx context.Context, name, currency string) (int64, error)
+	DebitSystemBalance(ctx context.Context, balanceID, amount int64) error
 	// Balance history
 	GetBalanceHistoryByUserID(ctx context.Context, userID int64) ([]models.BalanceHistory, error)
+	NextTransferID(ctx context.Context) (int64, error)
+	CreateBalanceHistory(ctx context.Context, entry models.BalanceHistory) error
+	GetSentTransferUsage(ctx context.Context, balanceID int64, username string, since time.Time) (models.TransferUsage, error)
+	GetTransferUsage(ctx context.Context, balanceID int64, sender string, since time.Time) (models.TransferUsage, error)
+	// Statement
+	GetStatementOpening(ctx context.Context, username string, from time.Time) (models.StatementOpening, error)
+	StreamBalanceHistory(ctx context.Context, balanceID int64, from, to time.Time, fn func(entry models.BalanceHistory) error) error
+	// Snapshot
+	GetLastSnapshotDay(ctx context.Context) (time.Time, error)
+	CreateBalanceSnapshots(ctx context.Context, day, dayEnd time.Time) (int64, error)
+	GetNearestSnapshot(ctx context.Context, username string, maxDay time.Time) (models.BalanceSnapshot, error)
+	GetBalanceChange(ctx context.Context, balanceID int64, username string, from, to time.Time) (models.BalanceChange, error)
+	// Reversal
+	GetTransactionsByUsername(ctx context.Context, username string) ([]models.BalanceHistory, error)
+	GetBalanceHistoryByID(ctx context.Context, entryID int64) (models.BalanceHistory, error)
+	GetTransferCounterpart(ctx context.Context, entry models.BalanceHistory) (models.BalanceHistory, error)
+	// Team
+	CreateTeam(ctx context.Context, name string, ownerID int64) (int64, error)
+	GetTeamByID(ctx context.Context, teamID int64) (models.Team, error)
+	GetTeamsByUserID(ctx context.Context, userID int64) ([]models.Team, error)
+	LockTeam(ctx context.Context, teamID int64) error
+	GetTeamMember(ctx context.Context, teamID int64, username string) (models.TeamMember, error)
+	GetTeamMembers(ctx context.Context, teamID int64) ([]models.TeamMember, error)
+	UpsertTeamMember(ctx context.Context, member models.TeamMember) (bool, error)
+	DeleteTeamMember(ctx context.Context, teamID, userID int64) error
+	CountTeamOwners(ctx context.Context, teamID int64) (int64, error)
+	GetInventoryMerchItemsByInventoryID(ctx context.Context, inventoryID int64) ([]models.InventoryMerch, error)
 	// Inventory
 	GetInventoryMerchItems(ctx context.Context, userID int64) ([]models.InventoryMerch, error)
 	GetInventoryIDByUserID(ctx context.Context, userID int64) (int64, error)
+	AddInventoryMerch(ctx context.Context, inventoryID, merchID int64, item string) error
 	// Merch
 	GetMerchByName(ctx context.Context, name string) (models.Merch, error)
-	BuyItemTX(ctx context.Context, userID, balanceID, inventoryID, merchID, price int64, username, item string) error
-	// Send coins
-	SendCoinsTX(ctx context.Context, userID, senderBalanceID, recipientBalanceID, amount int64, sender, recipient string) error
+	// Schedule
+	CreateSchedule(ctx context.Context, schedule models.Schedule) (int64, error)
+	GetSchedulesByUserID(ctx context.Context, userID int64) ([]models.Schedule, error)
+	GetScheduleByID(ctx context.Context, userID, scheduleID int64) (models.Schedule, error)
+	ClaimDueSchedule(ctx context.Context, now time.Time) (models.Schedule, error)
+	UpdateSchedule(ctx context.Context, schedule models.Schedule) error
+	DeleteSchedule(ctx context.Context, userID, scheduleID int64) (bool, error)
+	CreateScheduleRun(ctx context.Context, run models.ScheduleRun) error
+	GetScheduleRuns(ctx context.Context, scheduleID int64) ([]models.ScheduleRun, error)
+	// Hold
+	CreateHold(ctx context.Context, hold models.Hold) (int64, error)
+	GetHeldAmountByUserID(ctx context.Context, userID int64) (int64, error)
+	GetHoldsByUser(ctx context.Context, userID int64, username string) ([]models.Hold, error)
+	GetHoldByID(ctx context.Context, holdID int64) (models.Hold, error)
+	ResolveHold(ctx context.Context, holdID int64, status string) error
+	ReleaseExpiredHolds(ctx context.Context, now time.Time) (int64, error)
+	ReleaseHoldsByOwner(ctx context.Context, ownerID int64) error
+	// Payment request
+	CreatePaymentRequest(ctx context.Context, pr models.PaymentRequest) (int64, error)
+	GetIncomingPaymentRequests(ctx context.Context, payer string, now time.Time) ([]models.PaymentRequest, error)
+	GetOutgoingPaymentRequests(ctx context.Context, requesterID int64) ([]models.PaymentRequest, error)
+	GetPaymentRequestForPayer(ctx context.Context, payer string, requestID int64) (models.PaymentRequest, error)
+	ResolvePaymentRequest(ctx context.Context, requestID int64, status string) error
+	// Fraud
+	FindTransferCycles(ctx context.Context, since time.Time) ([]models.FraudHit, error)
+	FindFunnels(ctx context.Context, since time.Time, newAccountAge time.Duration, minSenders int) ([]models.FraudHit, error)
+	FindBursts(ctx context.Context, since time.Time, window time.Duration, minAmount int64) ([]models.FraudHit, error)
+	CreateFraudCase(ctx context.Context, fc models.FraudCase) (bool, error)
+	GetFraudCases(ctx context.Context, status string) ([]models.FraudCase, error)
+	GetFraudCaseByID(ctx context.Context, caseID int64) (models.FraudCase, error)
+	ResolveFraudCase(ctx context.Context, caseID int64, status, admin string) error
+	SetFraudHold(ctx context.Context, usernames []string) error
+	ReleaseFraudHold(ctx context.Context, usernames []string) error
+	// Allowance
+	CreateAllowanceRun(ctx context.Context, periodStart time.Time, amount, usersCount int64) (bool, error)
+	// Leaderboard
+	RecordLeaderboardTransfer(ctx context.Context, sender, recipient string, amount int64, periods []models.LeaderboardPeriod) error
+	GetLeaderboard(ctx context.Context, filter models.LeaderboardFilter) ([]models.LeaderboardEntry, error)
+	SetLeaderboardOptOut(ctx context.Context, userID int64, optOut bool) error
+	// Achievement
+	IncrementUserActivity(ctx context.Context, username, activity string) error
+	GetDueAchievements(ctx context.Context, username, event string) ([]models.Achievement, error)
+	AwardAchievement(ctx context.Context, username string, achievementID int64) (bool, error)
+	GetUserAchievements(ctx context.Context, username string) ([]models.UserAchievement, error)
+	// Kudos
+	CreateKudos(ctx context.Context, kudos models.Kudos) (int64, error)
+	GetKudosByID(ctx context.Context, kudosID int64) (models.Kudos, error)
+	GetKudosFeed(ctx context.Context, filter models.KudosFeedFilter) ([]models.Kudos, error)
+	UpdateKudosBoost(ctx context.Context, kudosID, amount, boosts int64) error
+	HideKudos(ctx context.Context, kudosID int64) error
+	GetKudosReactions(ctx context.Context, kudosIDs []int64, userID int64) ([]models.KudosReaction, error)
+	AddKudosReaction(ctx context.Context, kudosID, userID int64, emoji string) error
+	DeleteKudosReaction(ctx context.Context, kudosID, userID int64, emoji string) error
+	// Referral
+	GetReferralCode(ctx context.Context, username string) (string, error)
+	GetReferrals(ctx context.Context, username string) ([]models.Referral, error)
+	GetPendingReferral(ctx context.Context, username string) (models.Referral, error)
+	QualifyReferral(ctx context.Context, referralID, bonus int64) (bool, error)
+	// Check in
+	GetLastCheckIn(ctx context.Context, username string) (models.CheckIn, error)
+	CreateCheckIn(ctx context.Context, username string, checkIn models.CheckIn) (bool, error)
+	// Quest
+	CreateQuest(ctx context.Context, quest models.Quest) (int64, error)
+	UpdateQuest(ctx context.Context, quest models.Quest) (bool, error)
+	DeleteQuest(ctx context.Context, questID int64) (bool, error)
+	GetQuests(ctx context.Context) ([]models.Quest, error)
+	GetActiveQuests(ctx context.Context, event string, at time.Time) ([]models.Quest, error)
+	GetUserQuests(ctx context.Context, username string, at time.Time) ([]models.UserQuest, error)
+	ShareTeam(ctx context.Context, username, otherUsername string) (bool, error)
+	AddQuestRecipient(ctx context.Context, questID int64, username, recipient string) (bool, error)
+	AddQuestProgress(ctx context.Context, questID int64, username string, amount int64) (models.UserQuest, error)
+	CompleteQuest(ctx context.Context, questID int64, username string) (bool, error)
+	// Notification
+	CreateNotification(ctx context.Context, username string, notification models.Notification) error
+	GetNotifications(ctx context.Context, filter models.NotificationFilter) ([]models.Notification, error)
+	CountUnreadNotifications(ctx context.Context, userID int64) (int64, error)
+	MarkNotificationsRead(ctx context.Context, userID int64, notificationIDs []int64, all bool) (int64, error)
+	GetNotificationPreferences(ctx context.Context, username string) (models.NotificationPreferences, error)
+	SetNotificationPreferences(ctx context.Context, userID int64, preferences models.NotificationPreferences) error
+}
+
+// TxManager выполняет fn в одной транзакции БД, все вызовы Repository с контекстом fn
+// попадают в эту транзакцию. Ошибки сериализации повторяются автоматически.
+type TxManager interface {
+	Do(ctx context.Context, opts models.TxOptions, fn func(ctx context.Context) error) error
+	// Retryable true для ошибок, после которых Do повторяет транзакцию целиком
+	Retryable(err error) bool
 }
 
 type service struct {
-	repo Repository
+	repo      Repository
+	txManager TxManager
+	cfg       config.Config
 }
 
-func New(repo Repository) *service {
+func New(repo Repository, txManager TxManager, cfg config.Config) *service {
 	return &service{
-		repo: repo,
+		repo:      repo,
+		txManager: txManager,
+		cfg:       cfg,
 	}
 }
 
+// inTx запускает fn с транзакционным репозиторием
+func (s *service) inTx(ctx context.Context, opts models.TxOptions, fn func(ctx context.Context, repo Repository) error) error {
+	return s.txManager.Do(ctx, opts, func(ctx context.Context) error {
+		return fn(ctx, s.repo)
+	})
+}
+
 // UserInfo
 func (s *service) GetUserInfo(ctx context.Context, qp models.InfoQuery) (models.InfoDTO, error) {
 	info := models.InfoDTO{}
 
-	// Balance
-	amount, err := s.getBalanceAmount(ctx, qp.UserID)
-	if err != nil {
-		return models.InfoDTO{}, err
-	}
-	info.Coins = amount
+	// баланс, история и инвентарь читаются из одного снимка
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.RepeatableRead, ReadOnly: true}, func(ctx context.Context, _ Repository) error {
+		// Balance
+		amount, err := s.getBalanceAmount(ctx, qp.UserID)
+		if err != nil {
+			return err
+		}
+		info.Coins = amount
 
-	// CoinsHistory
-	balanceHistory, err := s.getBalanceHistory(ctx, qp.UserID, qp.Username)
-	if err != nil {
-		return models.InfoDTO{}, err
-	}
-	info.CoinsHistory = balanceHistory
+		held, err := s.repo.GetHeldAmountByUserID(ctx, qp.UserID)
+		if err != nil {
+			return err
+		}
+		info.Available = amount - held
 
-	// Inventory
-	inventory, err := s.getInventory(ctx, qp.UserID)
+		// Wallets
+		wallets, err := s.repo.GetWalletsByUserID(ctx, qp.UserID)
+		if err != nil {
+			return err
+		}
+		info.Wallets = make([]models.WalletDTO, 0, len(wallets))
+		for _, wallet := range wallets {
+			info.Wallets = append(info.Wallets, wallet.ToModelWalletDTO())
+		}
+
+		// CoinsHistory
+		balanceHistory, err := s.getBalanceHistory(ctx, qp.UserID, qp.Username)
+		if err != nil {
+			return err
+		}
+		info.CoinsHistory = balanceHistory
+
+		// ExpiringSoon
+		expiringSoon, err := s.getExpiringSoon(ctx, qp.UserID, time.Now())
+		if err != nil {
+			return err
+		}
+		info.ExpiringSoon = expiringSoon
+
+		// Inventory
+		inventory, err := s.getInventory(ctx, qp.UserID)
+		if err != nil {
+			return err
+		}
+		itemsDTO := make([]models.MerchDTO, 0, len(inventory.Items))
+		for _, item := range inventory.Items {
+			itemsDTO = append(itemsDTO, item.ToModelMerchDTO())
+		}
+		info.Inventory = itemsDTO
+
+		// Achievements
+		achievements, err := s.getAchievements(ctx, s.repo, qp.Username)
+		if err != nil {
+			return err
+		}
+		info.Achievements = achievements
+
+		return nil
+	})
 	if err != nil {
 		return models.InfoDTO{}, err
 	}
-	itemsDTO := make([]models.MerchDTO, 0, len(inventory.Items))
-	for _, item := range inventory.Items {
-		itemsDTO = append(itemsDTO, item.ToModelMerchDTO())
-	}
-	info.Inventory = itemsDTO
 
 	return info, nil
 }
@@ -87,23 +281,73 @@ func (s *service) getBalanceHistory(ctx context.Context, userID int64, username
 	var received = []models.ReceivedDTO{}
 	var sent = []models.SentDTO{}
 	for _, item := range balanceHistory {
+		// валюта указывается только для записей не в монетах, ответ для монет не меняется
+		currency := item.Currency
+		if currency == models.CurrencyCoins {
+			currency = ""
+		}
+
 		if item.Recipient == username {
 			received = append(received, models.ReceivedDTO{
 				FromUser: item.Sender,
 				Amount:   item.TransactionAmount,
+				Type:     item.Type,
+				Currency: currency,
+				Reversed: item.Reversed,
 			})
 			continue
 		}
 
 		sent = append(sent, models.SentDTO{
-			ToUser: item.Recipient,
-			Amount: item.TransactionAmount,
+			ToUser:   item.Recipient,
+			Amount:   item.TransactionAmount,
+			Type:     item.Type,
+			Currency: currency,
+			Reversed: item.Reversed,
 		})
 	}
 
 	return models.BalanceHistoryDTO{Received: received, Sent: sent}, nil
 }
 
+// getExpiringSoon возвращает монеты всех валют, которые сгорят в ближайшее окно, сгруппированные по дню сгорания и валюте
+func (s *service) getExpiringSoon(ctx context.Context, userID int64, now time.Time) ([]models.ExpiringCoinsDTO, error) {
+	expiringSoon := []models.ExpiringCoinsDTO{}
+	if s.cfg.Expiration.Months <= 0 {
+		return expiringSoon, nil
+	}
+
+	grantedBefore := now.Add(s.cfg.Expiration.SoonWindow).AddDate(0, -s.cfg.Expiration.Months, 0)
+	lots, err := s.repo.GetBalanceLotsByUserID(ctx, userID, grantedBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	// лоты разных валют в один день сгорают отдельными строками
+	type expiringDay struct {
+		day      time.Time
+		currency string
+	}
+	days := map[expiringDay]int{}
+	for _, lot := range lots {
+		expiresAt := lotExpiresAt(lot, s.cfg.Expiration.Months)
+		key := expiringDay{day: expiresAt.UTC().Truncate(24 * time.Hour), currency: currencyOrCoins(lot.Currency)}
+		if i, ok := days[key]; ok {
+			expiringSoon[i].Amount += lot.Amount
+			continue
+		}
+
+		days[key] = len(expiringSoon)
+		expiringSoon = append(expiringSoon, models.ExpiringCoinsDTO{
+			Amount:    lot.Amount,
+			Currency:  key.currency,
+			ExpiresAt: strfmt.DateTime(expiresAt),
+		})
+	}
+
+	return expiringSoon, nil
+}
+
 func (s *service) getInventory(ctx context.Context, userID int64) (models.Inventory, error) {
 	inventoryMerchItems, err := s.repo.GetInventoryMerchItems(ctx, userID)
 	if err != nil {
@@ -124,62 +368,246 @@ func (s *service) getInventory(ctx context.Context, userID int64) (models.Invent
 
 // BuyItem
 func (s *service) BuyItem(ctx context.Context, qp models.ItemQuery) error {
-	merch, err := s.repo.GetMerchByName(ctx, qp.Item)
-	if err != nil {
-		if merch.ID == 0 {
-			return errors.New(internalErrors.ErrItemDoesntExist)
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		merch, err := repo.GetMerchByName(ctx, qp.Item)
+		if err != nil {
+			if merch.ID == 0 {
+				return errors.New(internalErrors.ErrItemDoesntExist)
+			}
+
+			return err
 		}
 
-		return err
-	}
+		// мерч оплачивается из кошелька в валюте его цены
+		balance, err := repo.GetOrCreateWallet(ctx, qp.Username, merch.Currency)
+		if err != nil {
+			return err
+		}
+		if balance.Amount-merch.Price < 0 {
+			return errors.New(internalErrors.ErrNotEnoughCoins)
+		}
+
+		inventoryID, err := repo.GetInventoryIDByUserID(ctx, qp.UserID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkAccountCanSpend(ctx, repo, qp.Username); err != nil {
+			return err
+		}
+
+		// блокировка нужна, чтобы списание увидело резервы, созданные конкурентно
+		if err := repo.LockBalances(ctx, balance.ID); err != nil {
+			return err
+		}
+		// списание условное, поэтому конкурентная покупка не уведёт баланс в минус
+		if err := repo.DebitBalance(ctx, balance.ID, merch.Price); err != nil {
+			return err
+		}
+		spentLots, err := repo.SpendBalanceLots(ctx, balance.ID, merch.Price)
+		if err != nil {
+			return err
+		}
+		err = repo.CreateBalanceHistory(ctx, models.BalanceHistory{
+			BalanceID:         balance.ID,
+			TransactionAmount: merch.Price,
+			Sender:            qp.Username,
+			Recipient:         shopUser,
+			Type:              models.HistoryTypePurchase,
+			MerchName:         merch.Name,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := repo.AddInventoryMerch(ctx, inventoryID, merch.ID, merch.Name); err != nil {
+			return err
+		}
+
+		if err := s.recordPurchaseAchievements(ctx, repo, qp.Username); err != nil {
+			return err
+		}
+
+		if err := s.qualifyReferral(ctx, repo, qp.Username, "", spentLots); err != nil {
+			return err
+		}
+
+		if err := s.recordQuestPurchase(ctx, repo, qp.Username, merch, time.Now()); err != nil {
+			return err
+		}
 
-	balance, err := s.repo.GetBalanceByUserID(ctx, qp.UserID)
+		err = s.notify(ctx, repo, qp.Username, models.Notification{
+			Type:     models.NotificationTypePurchase,
+			Item:     merch.Name,
+			Status:   models.PurchaseStatusCompleted,
+			Amount:   merch.Price,
+			Currency: merch.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.notifyLowBalance(ctx, repo, qp.Username, merch.Currency, merch.Price)
+	})
+}
+
+// Send coins
+// SendCoins переводит монеты пользователю. Публичный перевод создаёт благодарность в ленте.
+func (s *service) SendCoins(ctx context.Context, qp models.CoinsQuery) error {
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		validRecipient, err := repo.IsUserExist(ctx, qp.Recipient)
+		if err != nil {
+			return err
+		}
+		if !validRecipient {
+			return errors.New(internalErrors.ErrInvalidRecipient)
+		}
+
+		currency, err := resolveCurrency(ctx, repo, qp.Currency)
+		if err != nil {
+			return err
+		}
+
+		entry := models.BalanceHistory{
+			TransactionAmount: qp.Amount,
+			Sender:            qp.Sender,
+			Recipient:         qp.Recipient,
+			Type:              models.HistoryTypeTransfer,
+			Reason:            qp.Memo,
+			Currency:          currency,
+		}
+		if qp.Public {
+			entry.KudosID, err = repo.CreateKudos(ctx, models.Kudos{
+				Sender:    qp.Sender,
+				Recipient: qp.Recipient,
+				Message:   qp.Memo,
+				Currency:  currency,
+				Amount:    qp.Amount,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.sendCoins(ctx, repo, entry)
+	})
+}
+
+// sendCoins переводит монеты между кошельками пользователей в валюте записи
+func (s *service) sendCoins(ctx context.Context, repo Repository, entry models.BalanceHistory) error {
+	senderBalance, err := repo.GetOrCreateWallet(ctx, entry.Sender, entry.Currency)
 	if err != nil {
 		return err
 	}
-	if balance.Amount-merch.Price < 0 {
+	if senderBalance.Amount-entry.TransactionAmount < 0 {
 		return errors.New(internalErrors.ErrNotEnoughCoins)
 	}
-
-	inventoryID, err := s.repo.GetInventoryIDByUserID(ctx, qp.UserID)
+	// кошелёк получателя в новой для него валюте создаётся при первом переводе
+	recipientBalance, err := repo.GetOrCreateWallet(ctx, entry.Recipient, entry.Currency)
 	if err != nil {
 		return err
 	}
 
-	err = s.repo.BuyItemTX(ctx, qp.UserID, balance.ID, inventoryID, merch.ID, merch.Price, qp.Username, merch.Name)
-	if err != nil {
+	if err := repo.LockBalances(ctx, senderBalance.ID, recipientBalance.ID); err != nil {
 		return err
 	}
 
-	return nil
+	return s.transfer(ctx, repo, senderBalance.ID, recipientBalance.ID, entry)
 }
 
-// Send coins
-func (s *service) SendCoins(ctx context.Context, qp models.CoinsQuery) error {
-	validRecipient, err := s.repo.IsUserExist(ctx, qp.Recipient)
-	if err != nil {
+// transfer переводит монеты между заблокированными балансами вместе с лотами, историей,
+// агрегатами рейтинга, достижениями, заданиями и уведомлениями
+func (s *service) transfer(ctx context.Context, repo Repository, senderBalanceID, recipientBalanceID int64, entry models.BalanceHistory) error {
+	if err := checkAccountCanSpend(ctx, repo, entry.Sender); err != nil {
 		return err
 	}
-	if !validRecipient {
-		return errors.New(internalErrors.ErrInvalidRecipient)
+	if err := s.checkTransferLimits(ctx, repo, senderBalanceID, recipientBalanceID, entry, time.Now().UTC()); err != nil {
+		return err
 	}
 
-	senderBalance, err := s.repo.GetBalanceByUserID(ctx, qp.UserID)
+	spentLots, err := s.moveCoins(ctx, repo, senderBalanceID, recipientBalanceID, entry)
 	if err != nil {
 		return err
 	}
-	if senderBalance.Amount-qp.Amount < 0 {
-		return errors.New(internalErrors.ErrNotEnoughCoins)
+
+	if err := s.recordLeaderboardTransfer(ctx, repo, entry, entry.TransactionAmount, time.Now()); err != nil {
+		return err
 	}
-	recipientBalanceID, err := s.repo.GetBalanceIDByUsername(ctx, qp.Recipient)
-	if err != nil {
+
+	if err := s.recordTransferAchievements(ctx, repo, entry); err != nil {
 		return err
 	}
 
-	err = s.repo.SendCoinsTX(ctx, qp.UserID, senderBalance.ID, recipientBalanceID, qp.Amount, qp.Sender, qp.Recipient)
-	if err != nil {
+	if err := s.qualifyReferral(ctx, repo, entry.Sender, entry.Recipient, spentLots); err != nil {
+		return err
+	}
+
+	if err := s.recordQuestTransfer(ctx, repo, entry, time.Now()); err != nil {
 		return err
 	}
 
+	return s.notifyLowBalance(ctx, repo, entry.Sender, entry.Currency, entry.TransactionAmount)
+}
+
+// moveCoins списывает монеты вместе с лотами, зачисляет их получателю, записывает историю обеих сторон
+// и уведомляет получателя. Возвращает списанные у отправителя лоты.
+func (s *service) moveCoins(ctx context.Context, repo Repository, senderBalanceID, recipientBalanceID int64, entry models.BalanceHistory) ([]models.BalanceLot, error) {
+	if err := repo.DebitBalance(ctx, senderBalanceID, entry.TransactionAmount); err != nil {
+		return nil, err
+	}
+	spentLots, err := repo.SpendBalanceLots(ctx, senderBalanceID, entry.TransactionAmount)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.CreditBalance(ctx, recipientBalanceID, entry.TransactionAmount); err != nil {
+		return nil, err
+	}
+	if err := s.moveBalanceLots(ctx, repo, recipientBalanceID, spentLots); err != nil {
+		return nil, err
+	}
+
+	if err := createTransferHistory(ctx, repo, senderBalanceID, recipientBalanceID, entry); err != nil {
+		return nil, err
+	}
+
+	return spentLots, s.notifyCoinsReceived(ctx, repo, entry)
+}
+
+// moveBalanceLots зачисляет получателю списанные у отправителя лоты.
+// В зависимости от политики лоты сохраняют дату начисления или получают текущую.
+func (s *service) moveBalanceLots(ctx context.Context, repo Repository, balanceID int64, lots []models.BalanceLot) error {
+	if s.cfg.Expiration.TransferPolicy == config.ExpirationTransferReset {
+		var amount int64
+		for _, lot := range lots {
+			amount += lot.Amount
+		}
+
+		return repo.CreateBalanceLot(ctx, balanceID, amount, time.Now())
+	}
+
+	for _, lot := range lots {
+		if err := repo.CreateBalanceLot(ctx, balanceID, lot.Amount, lot.GrantedAt); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// createTransferHistory создаёт записи истории перевода у отправителя и получателя
+func createTransferHistory(ctx context.Context, repo Repository, senderBalanceID, recipientBalanceID int64, entry models.BalanceHistory) error {
+	// обе записи связываются явно: одинаковые переводы в одной транзакции совпадают по всем остальным полям
+	transferID, err := repo.NextTransferID(ctx)
+	if err != nil {
+		return err
+	}
+	entry.TransferID = transferID
+
+	entry.BalanceID = senderBalanceID
+	if err := repo.CreateBalanceHistory(ctx, entry); err != nil {
+		return err
+	}
+
+	entry.BalanceID = recipientBalanceID
+	return repo.CreateBalanceHistory(ctx, entry)
+}
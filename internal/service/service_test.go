@@ -5,8 +5,11 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
 	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/go-openapi/strfmt"
 )
 
 func Test_service_GetUserInfo(t *testing.T) {
@@ -31,10 +34,20 @@ func Test_service_GetUserInfo(t *testing.T) {
 					GetBalanceAmountByUserIDFunc: func(ctx context.Context, userID int64) (int64, error) {
 						return 200, nil
 					},
+					GetHeldAmountByUserIDFunc: func(ctx context.Context, userID int64) (int64, error) {
+						return 50, nil
+					},
+					GetWalletsByUserIDFunc: func(ctx context.Context, userID int64) ([]models.Wallet, error) {
+						return []models.Wallet{
+							{BalanceID: 1, Currency: models.CurrencyCoins, Amount: 200, Held: 50},
+							{BalanceID: 7, Currency: "kudos", Amount: 30},
+						}, nil
+					},
 					GetBalanceHistoryByUserIDFunc: func(ctx context.Context, userID int64) ([]models.BalanceHistory, error) {
 						return []models.BalanceHistory{
-							{TransactionAmount: 100, Sender: "user2", Recipient: "user1"},
-							{TransactionAmount: 50, Sender: "user1", Recipient: "user2"},
+							{TransactionAmount: 100, Sender: "user2", Recipient: "user1", Currency: models.CurrencyCoins},
+							{TransactionAmount: 50, Sender: "user1", Recipient: "user2", Currency: models.CurrencyCoins},
+							{TransactionAmount: 10, Sender: "user3", Recipient: "user1", Currency: "kudos"},
 						}, nil
 					},
 					GetInventoryMerchItemsFunc: func(ctx context.Context, userID int64) ([]models.InventoryMerch, error) {
@@ -42,6 +55,11 @@ func Test_service_GetUserInfo(t *testing.T) {
 							{InventoryID: 1, MerchID: 201, Name: "t-shirt", Count: 15},
 						}, nil
 					},
+					GetUserAchievementsFunc: func(ctx context.Context, username string) ([]models.UserAchievement, error) {
+						return []models.UserAchievement{
+							{Code: "first-transfer", Title: "Первый перевод", AwardedAt: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)},
+						}, nil
+					},
 				},
 			},
 			args: args{
@@ -49,18 +67,28 @@ func Test_service_GetUserInfo(t *testing.T) {
 				qp:  models.InfoQuery{Username: "user1"},
 			},
 			want: models.InfoDTO{
-				Coins: 200,
+				Coins:     200,
+				Available: 150,
 				Inventory: []models.MerchDTO{
 					{Type: "t-shirt", Quantity: 15},
 				},
 				CoinsHistory: models.BalanceHistoryDTO{
 					Received: []models.ReceivedDTO{
 						{FromUser: "user2", Amount: 100},
+						{FromUser: "user3", Amount: 10, Currency: "kudos"},
 					},
 					Sent: []models.SentDTO{
 						{ToUser: "user2", Amount: 50},
 					},
 				},
+				ExpiringSoon: []models.ExpiringCoinsDTO{},
+				Wallets: []models.WalletDTO{
+					{Currency: models.CurrencyCoins, Amount: 200, Available: 150},
+					{Currency: "kudos", Amount: 30, Available: 30},
+				},
+				Achievements: []models.AchievementDTO{
+					{Code: "first-transfer", Title: "Первый перевод", AwardedAt: strfmt.DateTime(time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC))},
+				},
 			},
 			wantErr: false,
 		},
@@ -71,6 +99,12 @@ func Test_service_GetUserInfo(t *testing.T) {
 					GetBalanceAmountByUserIDFunc: func(ctx context.Context, userID int64) (int64, error) {
 						return 0, errors.New("fail")
 					},
+					GetWalletsByUserIDFunc: func(ctx context.Context, userID int64) ([]models.Wallet, error) {
+						return []models.Wallet{
+							{BalanceID: 1, Currency: models.CurrencyCoins, Amount: 200, Held: 50},
+							{BalanceID: 7, Currency: "kudos", Amount: 30},
+						}, nil
+					},
 					GetBalanceHistoryByUserIDFunc: func(ctx context.Context, userID int64) ([]models.BalanceHistory, error) {
 						return []models.BalanceHistory{
 							{TransactionAmount: 100, Sender: "user2", Recipient: "user1"},
@@ -98,6 +132,15 @@ func Test_service_GetUserInfo(t *testing.T) {
 					GetBalanceAmountByUserIDFunc: func(ctx context.Context, userID int64) (int64, error) {
 						return 200, nil
 					},
+					GetHeldAmountByUserIDFunc: func(ctx context.Context, userID int64) (int64, error) {
+						return 0, nil
+					},
+					GetWalletsByUserIDFunc: func(ctx context.Context, userID int64) ([]models.Wallet, error) {
+						return []models.Wallet{
+							{BalanceID: 1, Currency: models.CurrencyCoins, Amount: 200, Held: 50},
+							{BalanceID: 7, Currency: "kudos", Amount: 30},
+						}, nil
+					},
 					GetBalanceHistoryByUserIDFunc: func(ctx context.Context, userID int64) ([]models.BalanceHistory, error) {
 						return []models.BalanceHistory{}, errors.New("fail")
 					},
@@ -122,6 +165,15 @@ func Test_service_GetUserInfo(t *testing.T) {
 					GetBalanceAmountByUserIDFunc: func(ctx context.Context, userID int64) (int64, error) {
 						return 200, nil
 					},
+					GetHeldAmountByUserIDFunc: func(ctx context.Context, userID int64) (int64, error) {
+						return 0, nil
+					},
+					GetWalletsByUserIDFunc: func(ctx context.Context, userID int64) ([]models.Wallet, error) {
+						return []models.Wallet{
+							{BalanceID: 1, Currency: models.CurrencyCoins, Amount: 200, Held: 50},
+							{BalanceID: 7, Currency: "kudos", Amount: 30},
+						}, nil
+					},
 					GetBalanceHistoryByUserIDFunc: func(ctx context.Context, userID int64) ([]models.BalanceHistory, error) {
 						return []models.BalanceHistory{
 							{TransactionAmount: 100, Sender: "user2", Recipient: "user1"},
@@ -144,7 +196,8 @@ func Test_service_GetUserInfo(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &service{
-				repo: tt.fields.repo,
+				repo:      tt.fields.repo,
+				txManager: &MockTxManager{},
 			}
 			got, err := s.GetUserInfo(tt.args.ctx, tt.args.qp)
 			if (err != nil) != tt.wantErr {
@@ -176,16 +229,36 @@ func Test_service_BuyItem(t *testing.T) {
 			name: "success_-_item_purchased",
 			fields: fields{
 				repo: &MockRepository{
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
 					GetMerchByNameFunc: func(ctx context.Context, name string) (models.Merch, error) {
 						return models.Merch{ID: 1, Name: "t-shirt", Price: 500}, nil
 					},
-					GetBalanceByUserIDFunc: func(ctx context.Context, userID int64) (models.Balance, error) {
+					GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
 						return models.Balance{ID: 1, Amount: 1000}, nil
 					},
 					GetInventoryIDByUserIDFunc: func(ctx context.Context, userID int64) (int64, error) {
 						return 10, nil
 					},
-					BuyItemTXFunc: func(ctx context.Context, userID, balanceID, inventoryID, merchID, price int64, username, item string) error {
+					GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+						return models.AccountState{Status: models.UserStatusActive}, nil
+					},
+					LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+						return nil
+					},
+					DebitBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return nil
+					},
+					SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+						return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+					},
+					CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+						return nil
+					},
+					AddInventoryMerchFunc: func(ctx context.Context, inventoryID, merchID int64, item string) error {
 						return nil
 					},
 				},
@@ -200,6 +273,11 @@ func Test_service_BuyItem(t *testing.T) {
 			name: "error_-_item_doesn't_exist",
 			fields: fields{
 				repo: &MockRepository{
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
 					GetMerchByNameFunc: func(ctx context.Context, name string) (models.Merch, error) {
 						return models.Merch{}, errors.New("fail")
 					},
@@ -215,10 +293,15 @@ func Test_service_BuyItem(t *testing.T) {
 			name: "error_-_not_enough_coins",
 			fields: fields{
 				repo: &MockRepository{
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
 					GetMerchByNameFunc: func(ctx context.Context, name string) (models.Merch, error) {
 						return models.Merch{ID: 1, Name: "t-shirt", Price: 1000}, nil
 					},
-					GetBalanceByUserIDFunc: func(ctx context.Context, userID int64) (models.Balance, error) {
+					GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
 						return models.Balance{ID: 1, Amount: 200}, nil
 					},
 				},
@@ -229,14 +312,57 @@ func Test_service_BuyItem(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "error_-_not_enough_coins_on_debit",
+			fields: fields{
+				repo: &MockRepository{
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
+					GetMerchByNameFunc: func(ctx context.Context, name string) (models.Merch, error) {
+						return models.Merch{ID: 1, Name: "t-shirt", Price: 500}, nil
+					},
+					GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+						return models.Balance{ID: 1, Amount: 1000}, nil
+					},
+					GetInventoryIDByUserIDFunc: func(ctx context.Context, userID int64) (int64, error) {
+						return 10, nil
+					},
+					GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+						return models.AccountState{Status: models.UserStatusActive}, nil
+					},
+					LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+						return nil
+					},
+					DebitBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return errors.New(internalErrors.ErrNotEnoughCoins)
+					},
+					SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+						return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+					},
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				qp:  models.ItemQuery{UserID: 1, Username: "user1", Item: "t-shirt"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "error_-_database_error_on_balance_retrieval",
 			fields: fields{
 				repo: &MockRepository{
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
 					GetMerchByNameFunc: func(ctx context.Context, name string) (models.Merch, error) {
 						return models.Merch{ID: 1, Name: "t-shirt", Price: 100}, nil
 					},
-					GetBalanceByUserIDFunc: func(ctx context.Context, userID int64) (models.Balance, error) {
+					GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
 						return models.Balance{}, errors.New("db error")
 					},
 				},
@@ -251,7 +377,8 @@ func Test_service_BuyItem(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &service{
-				repo: tt.fields.repo,
+				repo:      tt.fields.repo,
+				txManager: &MockTxManager{},
 			}
 			if err := s.BuyItem(tt.args.ctx, tt.args.qp); (err != nil) != tt.wantErr {
 				t.Errorf("service.BuyItem() error = %v, wantErr %v", err, tt.wantErr)
@@ -278,16 +405,41 @@ func Test_service_SendCoins(t *testing.T) {
 			name: "success_-_send_coins",
 			fields: fields{
 				repo: &MockRepository{
+					NextTransferIDFunc:             nextTransferID,
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
+					RecordLeaderboardTransferFunc:  recordLeaderboardTransferNoop,
 					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
 						return true, nil
 					},
-					GetBalanceByUserIDFunc: func(ctx context.Context, userID int64) (models.Balance, error) {
+					GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+						if username == "user2" {
+							return models.Balance{ID: 2}, nil
+						}
 						return models.Balance{ID: 1, Amount: 1000}, nil
 					},
-					GetBalanceIDByUsernameFunc: func(ctx context.Context, username string) (int64, error) {
-						return 2, nil
+					GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+						return models.AccountState{Status: models.UserStatusActive}, nil
+					},
+					LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+						return nil
+					},
+					DebitBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return nil
+					},
+					SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+						return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+					},
+					CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return nil
+					},
+					CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+						return nil
 					},
-					SendCoinsTXFunc: func(ctx context.Context, userID, senderBalanceID, recipientBalanceID, amount int64, sender, recipient string) error {
+					CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
 						return nil
 					},
 				},
@@ -302,6 +454,13 @@ func Test_service_SendCoins(t *testing.T) {
 			name: "error_-_recipient_does_not_exist",
 			fields: fields{
 				repo: &MockRepository{
+					NextTransferIDFunc:             nextTransferID,
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
+					RecordLeaderboardTransferFunc:  recordLeaderboardTransferNoop,
 					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
 						return false, nil
 					},
@@ -317,10 +476,20 @@ func Test_service_SendCoins(t *testing.T) {
 			name: "error_-_not_enough_coins",
 			fields: fields{
 				repo: &MockRepository{
+					NextTransferIDFunc:             nextTransferID,
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
+					RecordLeaderboardTransferFunc:  recordLeaderboardTransferNoop,
 					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
 						return true, nil
 					},
-					GetBalanceByUserIDFunc: func(ctx context.Context, userID int64) (models.Balance, error) {
+					GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+						if username == "user2" {
+							return models.Balance{ID: 2}, nil
+						}
 						return models.Balance{ID: 1, Amount: 50}, nil
 					},
 				},
@@ -331,22 +500,87 @@ func Test_service_SendCoins(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "error_-_not_enough_coins_on_debit",
+			fields: fields{
+				repo: &MockRepository{
+					NextTransferIDFunc:             nextTransferID,
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
+					RecordLeaderboardTransferFunc:  recordLeaderboardTransferNoop,
+					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
+						return true, nil
+					},
+					GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+						if username == "user2" {
+							return models.Balance{ID: 2}, nil
+						}
+						return models.Balance{ID: 1, Amount: 1000}, nil
+					},
+					GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+						return models.AccountState{Status: models.UserStatusActive}, nil
+					},
+					LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+						return nil
+					},
+					DebitBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return errors.New(internalErrors.ErrNotEnoughCoins)
+					},
+					SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+						return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+					},
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				qp:  models.CoinsQuery{UserID: 1, Recipient: "user2", Amount: 500, Sender: "user1"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "error_-_repository_failure",
 			fields: fields{
 				repo: &MockRepository{
+					NextTransferIDFunc:             nextTransferID,
+					GetNotificationPreferencesFunc: getNoNotificationPreferences,
+					GetActiveQuestsFunc:            getNoActiveQuests,
+					GetPendingReferralFunc:         getNoPendingReferral,
+					GetDueAchievementsFunc:         getNoDueAchievements,
+					IncrementUserActivityFunc:      incrementUserActivityNoop,
+					RecordLeaderboardTransferFunc:  recordLeaderboardTransferNoop,
 					IsUserExistFunc: func(ctx context.Context, username string) (bool, error) {
 						return true, nil
 					},
-					GetBalanceByUserIDFunc: func(ctx context.Context, userID int64) (models.Balance, error) {
+					GetOrCreateWalletFunc: func(ctx context.Context, username, currency string) (models.Balance, error) {
+						if username == "user2" {
+							return models.Balance{ID: 2}, nil
+						}
 						return models.Balance{ID: 1, Amount: 1000}, nil
 					},
-					GetBalanceIDByUsernameFunc: func(ctx context.Context, username string) (int64, error) {
-						return 2, nil
+					GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+						return models.AccountState{Status: models.UserStatusActive}, nil
+					},
+					LockBalancesFunc: func(ctx context.Context, balanceIDs ...int64) error {
+						return nil
+					},
+					DebitBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
+						return nil
 					},
-					SendCoinsTXFunc: func(ctx context.Context, userID, senderBalanceID, recipientBalanceID, amount int64, sender, recipient string) error {
+					SpendBalanceLotsFunc: func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+						return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+					},
+					CreateBalanceHistoryFunc: func(ctx context.Context, entry models.BalanceHistory) error {
+						return nil
+					},
+					CreditBalanceFunc: func(ctx context.Context, balanceID, amount int64) error {
 						return errors.New("db error")
 					},
+					CreateBalanceLotFunc: func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+						return nil
+					},
 				},
 			},
 			args: args{
@@ -359,7 +593,8 @@ func Test_service_SendCoins(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &service{
-				repo: tt.fields.repo,
+				repo:      tt.fields.repo,
+				txManager: &MockTxManager{},
 			}
 			if err := s.SendCoins(tt.args.ctx, tt.args.qp); (err != nil) != tt.wantErr {
 				t.Errorf("service.SendCoins() error = %v, wantErr %v", err, tt.wantErr)
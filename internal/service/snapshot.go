@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/log"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/go-openapi/strfmt"
+)
+
+// Snapshot
+// SnapshotBalances сохраняет остатки всех балансов на конец каждых завершившихся суток (UTC),
+// начиная с суток после последнего снимка. Если снимков нет, начинает с Snapshot.BackfillDays суток назад.
+func (s *service) SnapshotBalances(ctx context.Context) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	return s.forEachOrganization(ctx, func(ctx context.Context) error {
+		return s.snapshotOrganizationBalances(ctx, today)
+	})
+}
+
+// snapshotOrganizationBalances досоздаёт снимки организации с последнего снятого дня до вчерашнего
+func (s *service) snapshotOrganizationBalances(ctx context.Context, today time.Time) error {
+	lastDay, err := s.repo.GetLastSnapshotDay(ctx)
+	if err != nil {
+		return err
+	}
+	start := today.AddDate(0, 0, -max(s.cfg.Snapshot.BackfillDays, 1))
+	if !lastDay.IsZero() {
+		start = time.Date(lastDay.Year(), lastDay.Month(), lastDay.Day()+1, 0, 0, 0, 0, time.UTC)
+	}
+
+	for d := start; d.Before(today); d = d.AddDate(0, 0, 1) {
+		var created int64
+		// остаток и история читаются из одного снимка БД, иначе конкурентный перевод исказит остаток
+		err := s.inTx(ctx, models.TxOptions{IsoLevel: models.RepeatableRead}, func(ctx context.Context, repo Repository) error {
+			var err error
+			created, err = repo.CreateBalanceSnapshots(ctx, d, d.AddDate(0, 0, 1))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		log.Logger.Info().Msgf("balance snapshots for %s: %d", d.Format(time.DateOnly), created)
+	}
+
+	return nil
+}
+
+// GetBalanceAt восстанавливает баланс пользователя на момент At: к ближайшему снимку не позже At
+// применяются операции после конца его суток. Без снимка операции после At вычитаются из текущего остатка.
+func (s *service) GetBalanceAt(ctx context.Context, qp models.BalanceAtQuery) (models.BalanceAtDTO, error) {
+	if qp.At.IsZero() {
+		return models.BalanceAtDTO{}, errors.New(internalErrors.ErrInvalidBalanceAtReqParams)
+	}
+
+	balanceAtDTO := models.BalanceAtDTO{Username: qp.Username, At: strfmt.DateTime(qp.At)}
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.RepeatableRead, ReadOnly: true}, func(ctx context.Context, repo Repository) error {
+		// сутки заканчиваются не позже At, только если начинаются не позже At - 24h
+		maxDay := qp.At.UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+		snapshot, err := repo.GetNearestSnapshot(ctx, qp.Username, maxDay)
+		if err != nil {
+			return err
+		}
+		if snapshot.BalanceID != 0 {
+			dayEnd := time.Date(snapshot.Day.Year(), snapshot.Day.Month(), snapshot.Day.Day()+1, 0, 0, 0, 0, time.UTC)
+			change, err := repo.GetBalanceChange(ctx, snapshot.BalanceID, qp.Username, dayEnd, qp.At)
+			if err != nil {
+				return err
+			}
+
+			balanceAtDTO.Amount = snapshot.Amount + change.Amount
+			balanceAtDTO.SnapshotDay = snapshot.Day.Format(time.DateOnly)
+			balanceAtDTO.ReplayedEntries = change.Count
+			return nil
+		}
+
+		opening, err := repo.GetStatementOpening(ctx, qp.Username, qp.At)
+		if err != nil {
+			return err
+		}
+		if opening.BalanceID == 0 {
+			return errors.New(internalErrors.ErrUserNotFound)
+		}
+		// до регистрации баланса не было
+		if qp.At.Before(opening.CreatedAt) {
+			return nil
+		}
+
+		balanceAtDTO.Amount = opening.Amount
+		return nil
+	})
+	if err != nil {
+		return models.BalanceAtDTO{}, err
+	}
+
+	return balanceAtDTO, nil
+}
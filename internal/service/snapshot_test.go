@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func Test_service_SnapshotBalances(t *testing.T) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		lastDay  time.Time
+		wantDays []time.Time
+	}{
+		{
+			name:     "success_-_missed_days_after_last_snapshot",
+			lastDay:  today.AddDate(0, 0, -3),
+			wantDays: []time.Time{today.AddDate(0, 0, -2), today.AddDate(0, 0, -1)},
+		},
+		{
+			name:     "success_-_nothing_to_do_after_yesterday_snapshot",
+			lastDay:  today.AddDate(0, 0, -1),
+			wantDays: []time.Time{},
+		},
+		{
+			name:     "success_-_backfill_without_snapshots",
+			wantDays: []time.Time{today.AddDate(0, 0, -2), today.AddDate(0, 0, -1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days := []time.Time{}
+			s := &service{
+				repo: &MockRepository{
+					GetOrganizationIDsFunc: getSingleOrganization,
+					GetLastSnapshotDayFunc: func(ctx context.Context) (time.Time, error) {
+						return tt.lastDay, nil
+					},
+					CreateBalanceSnapshotsFunc: func(ctx context.Context, day, dayEnd time.Time) (int64, error) {
+						if !dayEnd.Equal(day.AddDate(0, 0, 1)) {
+							t.Errorf("unexpected day end %v for %v", dayEnd, day)
+						}
+						days = append(days, day)
+						return 2, nil
+					},
+				},
+				txManager: &MockTxManager{},
+				cfg:       config.Config{Snapshot: config.Snapshot{BackfillDays: 2}},
+			}
+
+			if err := s.SnapshotBalances(context.Background()); err != nil {
+				t.Fatalf("service.SnapshotBalances() error = %v", err)
+			}
+			if !reflect.DeepEqual(days, tt.wantDays) {
+				t.Errorf("service.SnapshotBalances() days = %v, want %v", days, tt.wantDays)
+			}
+		})
+	}
+}
+
+func Test_service_GetBalanceAt(t *testing.T) {
+	at := time.Date(2026, 10, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		snapshot   models.BalanceSnapshot
+		opening    models.StatementOpening
+		want       models.BalanceAtDTO
+		wantMaxDay time.Time
+		wantChange time.Time
+		wantErr    string
+	}{
+		{
+			name:       "success_-_replayed_from_snapshot",
+			snapshot:   models.BalanceSnapshot{BalanceID: 1, Day: time.Date(2026, 10, 8, 0, 0, 0, 0, time.UTC), Amount: 700},
+			wantMaxDay: time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC),
+			wantChange: time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC),
+			want:       models.BalanceAtDTO{Username: "user1", Amount: 650, SnapshotDay: "2026-10-08", ReplayedEntries: 2},
+		},
+		{
+			name:       "success_-_replayed_back_from_current_balance",
+			opening:    models.StatementOpening{BalanceID: 1, Amount: 900, CreatedAt: at.AddDate(0, 0, -1)},
+			wantMaxDay: time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC),
+			want:       models.BalanceAtDTO{Username: "user1", Amount: 900},
+		},
+		{
+			name:       "success_-_before_registration",
+			opening:    models.StatementOpening{BalanceID: 1, Amount: 1000, CreatedAt: at.Add(time.Hour)},
+			wantMaxDay: time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC),
+			want:       models.BalanceAtDTO{Username: "user1"},
+		},
+		{
+			name:       "error_-_user_not_found",
+			wantMaxDay: time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC),
+			wantErr:    internalErrors.ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{
+				repo: &MockRepository{
+					GetNearestSnapshotFunc: func(ctx context.Context, username string, maxDay time.Time) (models.BalanceSnapshot, error) {
+						if !maxDay.Equal(tt.wantMaxDay) {
+							t.Errorf("GetNearestSnapshot() maxDay = %v, want %v", maxDay, tt.wantMaxDay)
+						}
+						return tt.snapshot, nil
+					},
+					GetBalanceChangeFunc: func(ctx context.Context, balanceID int64, username string, from, to time.Time) (models.BalanceChange, error) {
+						if !from.Equal(tt.wantChange) || !to.Equal(at) {
+							t.Errorf("GetBalanceChange() from = %v, to = %v", from, to)
+						}
+						return models.BalanceChange{Amount: -50, Count: 2}, nil
+					},
+					GetStatementOpeningFunc: func(ctx context.Context, username string, from time.Time) (models.StatementOpening, error) {
+						return tt.opening, nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.GetBalanceAt(context.Background(), models.BalanceAtQuery{Username: "user1", At: at})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.GetBalanceAt() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.GetBalanceAt() error = %v", err)
+			}
+			got.At = tt.want.At
+			if got != tt.want {
+				t.Errorf("service.GetBalanceAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/go-openapi/strfmt"
+)
+
+// Statement
+// WriteStatement передаёт emit выписку за [From, To): входящий остаток, операции с остатком после каждой
+// и исходящий остаток. Выписка читается из одного снимка БД, поэтому остатки сходятся с операциями.
+// emit вызывается по мере чтения истории, вся выписка в памяти не хранится.
+func (s *service) WriteStatement(ctx context.Context, qp models.StatementQuery, emit func(line models.StatementLineDTO) error) error {
+	if qp.From.IsZero() || !qp.From.Before(qp.To) {
+		return errors.New(internalErrors.ErrInvalidStatementReqParams)
+	}
+
+	// снимок только для чтения не получает ошибок сериализации, поэтому TxManager не повторит fn после emit
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.RepeatableRead, ReadOnly: true}, func(ctx context.Context, repo Repository) error {
+		opening, err := repo.GetStatementOpening(ctx, qp.Username, qp.From)
+		if err != nil {
+			return err
+		}
+		if opening.BalanceID == 0 {
+			return errors.New(internalErrors.ErrUserNotFound)
+		}
+
+		balance := opening.Amount
+		err = emit(models.StatementLineDTO{
+			Record:  models.StatementRecordOpening,
+			At:      strfmt.DateTime(qp.From),
+			Balance: balance,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = repo.StreamBalanceHistory(ctx, opening.BalanceID, qp.From, qp.To, func(entry models.BalanceHistory) error {
+			line := statementLine(entry, qp.Username)
+			if line.Direction == models.StatementDirectionCredit {
+				balance += entry.TransactionAmount
+			} else {
+				balance -= entry.TransactionAmount
+			}
+			line.Balance = balance
+
+			return emit(line)
+		})
+		if err != nil {
+			return err
+		}
+
+		return emit(models.StatementLineDTO{
+			Record:  models.StatementRecordClosing,
+			At:      strfmt.DateTime(qp.To),
+			Balance: balance,
+		})
+	})
+}
+
+// statementLine описывает запись истории со стороны владельца выписки
+func statementLine(entry models.BalanceHistory, username string) models.StatementLineDTO {
+	line := models.StatementLineDTO{
+		Record: models.StatementRecordEntry,
+		At:     strfmt.DateTime(entry.CreatedAt),
+		ID:     entry.ID,
+		Type:   entry.Type,
+		Amount: entry.TransactionAmount,
+		Item:   entry.MerchName,
+		Reason: entry.Reason,
+	}
+	if entry.Recipient == username {
+		line.Direction = models.StatementDirectionCredit
+		line.Counterparty = entry.Sender
+	} else {
+		line.Direction = models.StatementDirectionDebit
+		line.Counterparty = entry.Recipient
+	}
+
+	return line
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/go-openapi/strfmt"
+)
+
+func Test_service_WriteStatement(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	entries := []models.BalanceHistory{
+		{ID: 1, TransactionAmount: 200, Sender: "user2", Recipient: "user1", Type: models.HistoryTypeTransfer, Reason: "lunch", CreatedAt: from.Add(time.Hour)},
+		{ID: 2, TransactionAmount: 500, Sender: "user1", Recipient: shopUser, Type: models.HistoryTypePurchase, MerchName: "pink-hoody", CreatedAt: from.Add(2 * time.Hour)},
+		{ID: 3, TransactionAmount: 100, Sender: models.TreasuryAccount, Recipient: "user1", Type: models.HistoryTypeGrant, CreatedAt: from.Add(3 * time.Hour)},
+	}
+
+	tests := []struct {
+		name      string
+		qp        models.StatementQuery
+		opening   models.StatementOpening
+		wantLines []models.StatementLineDTO
+		wantErr   string
+	}{
+		{
+			name:    "success_-_opening_entries_and_closing_balance",
+			qp:      models.StatementQuery{Username: "user1", From: from, To: to},
+			opening: models.StatementOpening{BalanceID: 1, Amount: 1000},
+			wantLines: []models.StatementLineDTO{
+				{Record: models.StatementRecordOpening, At: strfmt.DateTime(from), Balance: 1000},
+				{Record: models.StatementRecordEntry, At: strfmt.DateTime(from.Add(time.Hour)), ID: 1, Type: models.HistoryTypeTransfer, Direction: models.StatementDirectionCredit, Amount: 200, Counterparty: "user2", Reason: "lunch", Balance: 1200},
+				{Record: models.StatementRecordEntry, At: strfmt.DateTime(from.Add(2 * time.Hour)), ID: 2, Type: models.HistoryTypePurchase, Direction: models.StatementDirectionDebit, Amount: 500, Counterparty: shopUser, Item: "pink-hoody", Balance: 700},
+				{Record: models.StatementRecordEntry, At: strfmt.DateTime(from.Add(3 * time.Hour)), ID: 3, Type: models.HistoryTypeGrant, Direction: models.StatementDirectionCredit, Amount: 100, Counterparty: models.TreasuryAccount, Balance: 800},
+				{Record: models.StatementRecordClosing, At: strfmt.DateTime(to), Balance: 800},
+			},
+		},
+		{
+			name:    "error_-_from_after_to",
+			qp:      models.StatementQuery{Username: "user1", From: to, To: from},
+			wantErr: internalErrors.ErrInvalidStatementReqParams,
+		},
+		{
+			name:    "error_-_user_not_found",
+			qp:      models.StatementQuery{Username: "user404", From: from, To: to},
+			wantErr: internalErrors.ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{
+				repo: &MockRepository{
+					GetStatementOpeningFunc: func(ctx context.Context, username string, from time.Time) (models.StatementOpening, error) {
+						return tt.opening, nil
+					},
+					StreamBalanceHistoryFunc: func(ctx context.Context, balanceID int64, from, to time.Time, fn func(entry models.BalanceHistory) error) error {
+						for _, entry := range entries {
+							if err := fn(entry); err != nil {
+								return err
+							}
+						}
+						return nil
+					},
+				},
+				txManager: &MockTxManager{},
+			}
+
+			lines := []models.StatementLineDTO{}
+			err := s.WriteStatement(context.Background(), tt.qp, func(line models.StatementLineDTO) error {
+				lines = append(lines, line)
+				return nil
+			})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.WriteStatement() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.WriteStatement() error = %v", err)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("service.WriteStatement() = %v, want %v", lines, tt.wantLines)
+			}
+		})
+	}
+}
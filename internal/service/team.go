@@ -0,0 +1,433 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/go-openapi/strfmt"
+)
+
+// maxTeamNameLength имя команды с префиксом team: должно помещаться в поля истории (64 символа)
+const maxTeamNameLength = 58
+
+// Team
+// CreateTeam создаёт команду с пустым кошельком монет, создатель становится её владельцем
+func (s *service) CreateTeam(ctx context.Context, qp models.TeamQuery) (models.TeamDTO, error) {
+	if !validTeamName(qp.Name) {
+		return models.TeamDTO{}, errors.New(internalErrors.ErrInvalidTeamReqParams)
+	}
+
+	teamDTO := models.TeamDTO{}
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		teamID, err := repo.CreateTeam(ctx, qp.Name, qp.UserID)
+		if err != nil {
+			return err
+		}
+		if teamID == 0 {
+			return errors.New(internalErrors.ErrTeamNameTaken)
+		}
+
+		team, err := repo.GetTeamByID(ctx, teamID)
+		if err != nil {
+			return err
+		}
+		teamDTO = toTeamDTO(team, models.TeamMember{Username: qp.Username, Role: models.TeamRoleOwner, CanSpend: true})
+
+		return nil
+	})
+	if err != nil {
+		return models.TeamDTO{}, err
+	}
+
+	return teamDTO, nil
+}
+
+// GetTeams возвращает команды пользователя вместе с его ролью в каждой
+func (s *service) GetTeams(ctx context.Context, qp models.TeamQuery) ([]models.TeamDTO, error) {
+	teamsDTO := []models.TeamDTO{}
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.RepeatableRead, ReadOnly: true}, func(ctx context.Context, repo Repository) error {
+		teams, err := repo.GetTeamsByUserID(ctx, qp.UserID)
+		if err != nil {
+			return err
+		}
+
+		for _, team := range teams {
+			member, err := repo.GetTeamMember(ctx, team.ID, qp.Username)
+			if err != nil {
+				return err
+			}
+			teamsDTO = append(teamsDTO, toTeamDTO(team, member))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return teamsDTO, nil
+}
+
+// GetTeam возвращает карточку команды с участниками, инвентарём и историей кошелька. Доступна только участникам.
+func (s *service) GetTeam(ctx context.Context, qp models.TeamQuery) (models.TeamDTO, error) {
+	teamDTO := models.TeamDTO{}
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.RepeatableRead, ReadOnly: true}, func(ctx context.Context, repo Repository) error {
+		team, member, err := getTeamMembership(ctx, repo, qp.ID, qp.Username)
+		if err != nil {
+			return err
+		}
+		teamDTO = toTeamDTO(team, member)
+
+		members, err := repo.GetTeamMembers(ctx, team.ID)
+		if err != nil {
+			return err
+		}
+		teamDTO.Members = make([]models.TeamMemberDTO, 0, len(members))
+		for _, m := range members {
+			teamDTO.Members = append(teamDTO.Members, m.ToModelTeamMemberDTO())
+		}
+
+		items, err := repo.GetInventoryMerchItemsByInventoryID(ctx, team.InventoryID)
+		if err != nil {
+			return err
+		}
+		teamDTO.Inventory = make([]models.MerchDTO, 0, len(items))
+		for _, item := range items {
+			teamDTO.Inventory = append(teamDTO.Inventory, models.MerchDTO{Type: item.Name, Quantity: item.Count})
+		}
+
+		teamDTO.History = []models.TransactionDTO{}
+		return repo.StreamBalanceHistory(ctx, team.BalanceID, time.Time{}, time.Now(), func(entry models.BalanceHistory) error {
+			teamDTO.History = append(teamDTO.History, entry.ToModelTransactionDTO())
+			return nil
+		})
+	})
+	if err != nil {
+		return models.TeamDTO{}, err
+	}
+
+	return teamDTO, nil
+}
+
+// SetTeamMember добавляет участника или меняет его роль и право тратить кошелёк. Доступно владельцам.
+func (s *service) SetTeamMember(ctx context.Context, qp models.TeamMemberQuery) (models.TeamMemberDTO, error) {
+	if qp.Role == "" {
+		qp.Role = models.TeamRoleMember
+	}
+	if qp.Username == "" || (qp.Role != models.TeamRoleOwner && qp.Role != models.TeamRoleMember) {
+		return models.TeamMemberDTO{}, errors.New(internalErrors.ErrInvalidTeamReqParams)
+	}
+
+	memberDTO := models.TeamMemberDTO{}
+	err := s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		if err := repo.LockTeam(ctx, qp.TeamID); err != nil {
+			return err
+		}
+		_, actor, err := getTeamMembership(ctx, repo, qp.TeamID, qp.Actor)
+		if err != nil {
+			return err
+		}
+		if actor.Role != models.TeamRoleOwner {
+			return errors.New(internalErrors.ErrTeamForbidden)
+		}
+
+		current, err := repo.GetTeamMember(ctx, qp.TeamID, qp.Username)
+		if err != nil {
+			return err
+		}
+		if current.Role == models.TeamRoleOwner && qp.Role != models.TeamRoleOwner {
+			if err := checkNotLastTeamOwner(ctx, repo, qp.TeamID); err != nil {
+				return err
+			}
+		}
+
+		member := models.TeamMember{TeamID: qp.TeamID, Username: qp.Username, Role: qp.Role, CanSpend: qp.CanSpend}
+		ok, err := repo.UpsertTeamMember(ctx, member)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New(internalErrors.ErrUserNotFound)
+		}
+		memberDTO = member.ToModelTeamMemberDTO()
+
+		return nil
+	})
+	if err != nil {
+		return models.TeamMemberDTO{}, err
+	}
+
+	return memberDTO, nil
+}
+
+// RemoveTeamMember исключает участника. Владельцы исключают любого, участник может выйти сам.
+// Последнего владельца исключить нельзя.
+func (s *service) RemoveTeamMember(ctx context.Context, qp models.TeamMemberQuery) error {
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		if err := repo.LockTeam(ctx, qp.TeamID); err != nil {
+			return err
+		}
+		_, actor, err := getTeamMembership(ctx, repo, qp.TeamID, qp.Actor)
+		if err != nil {
+			return err
+		}
+		if qp.Username != qp.Actor && actor.Role != models.TeamRoleOwner {
+			return errors.New(internalErrors.ErrTeamForbidden)
+		}
+
+		member, err := repo.GetTeamMember(ctx, qp.TeamID, qp.Username)
+		if err != nil {
+			return err
+		}
+		if member.UserID == 0 {
+			return errors.New(internalErrors.ErrTeamMemberNotFound)
+		}
+		if member.Role == models.TeamRoleOwner {
+			if err := checkNotLastTeamOwner(ctx, repo, qp.TeamID); err != nil {
+				return err
+			}
+		}
+
+		return repo.DeleteTeamMember(ctx, qp.TeamID, member.UserID)
+	})
+}
+
+// DepositToTeam переводит монеты пользователя в кошелёк команды. Пополнять кошелёк может любой пользователь,
+// перевод подчиняется тем же ограничениям и лимитам, что и перевод другому пользователю.
+func (s *service) DepositToTeam(ctx context.Context, qp models.TeamTransferQuery) error {
+	if qp.Amount < 1 {
+		return errors.New(internalErrors.ErrInvalidTeamReqParams)
+	}
+
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		team, err := repo.GetTeamByID(ctx, qp.TeamID)
+		if err != nil {
+			return err
+		}
+		if team.ID == 0 {
+			return errors.New(internalErrors.ErrTeamNotFound)
+		}
+
+		wallet, err := repo.GetOrCreateWallet(ctx, qp.Username, models.CurrencyCoins)
+		if err != nil {
+			return err
+		}
+		if wallet.Amount-qp.Amount < 0 {
+			return errors.New(internalErrors.ErrNotEnoughCoins)
+		}
+
+		if err := repo.LockBalances(ctx, wallet.ID, team.BalanceID); err != nil {
+			return err
+		}
+
+		entry := models.BalanceHistory{
+			TransactionAmount: qp.Amount,
+			Sender:            qp.Username,
+			Recipient:         team.AccountName(),
+			Type:              models.HistoryTypeTransfer,
+			Actor:             qp.Username,
+		}
+		if err := checkAccountCanSpend(ctx, repo, qp.Username); err != nil {
+			return err
+		}
+		if err := s.checkTransferLimits(ctx, repo, wallet.ID, team.BalanceID, entry, time.Now().UTC()); err != nil {
+			return err
+		}
+
+		// достижения, рефералы, квесты и рейтинг учитывают только переводы между пользователями
+		_, err = s.moveCoins(ctx, repo, wallet.ID, team.BalanceID, entry)
+		return err
+	})
+}
+
+// SendFromTeam переводит монеты из кошелька команды пользователю от имени участника с правом тратить кошелёк
+func (s *service) SendFromTeam(ctx context.Context, qp models.TeamTransferQuery) error {
+	if qp.Amount < 1 || qp.Recipient == "" {
+		return errors.New(internalErrors.ErrInvalidTeamReqParams)
+	}
+
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		team, err := getTeamSpender(ctx, repo, qp.TeamID, qp.Username)
+		if err != nil {
+			return err
+		}
+
+		validRecipient, err := repo.IsUserExist(ctx, qp.Recipient)
+		if err != nil {
+			return err
+		}
+		if !validRecipient {
+			return errors.New(internalErrors.ErrInvalidRecipient)
+		}
+		recipientWallet, err := repo.GetOrCreateWallet(ctx, qp.Recipient, models.CurrencyCoins)
+		if err != nil {
+			return err
+		}
+
+		if err := repo.LockBalances(ctx, team.BalanceID, recipientWallet.ID); err != nil {
+			return err
+		}
+
+		// кошелёк команды не обходит лимиты участника: отправка засчитывается ему вместе с личными переводами
+		memberWallet, err := repo.GetOrCreateWallet(ctx, qp.Username, models.CurrencyCoins)
+		if err != nil {
+			return err
+		}
+		if err := s.checkTransferLimits(ctx, repo, memberWallet.ID, recipientWallet.ID, models.BalanceHistory{
+			TransactionAmount: qp.Amount,
+			Sender:            qp.Username,
+			Recipient:         qp.Recipient,
+			Type:              models.HistoryTypeTransfer,
+		}, time.Now().UTC()); err != nil {
+			return err
+		}
+
+		_, err = s.moveCoins(ctx, repo, team.BalanceID, recipientWallet.ID, models.BalanceHistory{
+			TransactionAmount: qp.Amount,
+			Sender:            team.AccountName(),
+			Recipient:         qp.Recipient,
+			Type:              models.HistoryTypeTransfer,
+			Actor:             qp.Username,
+		})
+		return err
+	})
+}
+
+// BuyTeamItem покупает мерч за монеты команды в инвентарь команды или выбранного участника
+func (s *service) BuyTeamItem(ctx context.Context, qp models.TeamItemQuery) error {
+	return s.inTx(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context, repo Repository) error {
+		merch, err := repo.GetMerchByName(ctx, qp.Item)
+		if err != nil {
+			if merch.ID == 0 {
+				return errors.New(internalErrors.ErrItemDoesntExist)
+			}
+
+			return err
+		}
+		if merch.Currency != models.CurrencyCoins {
+			return errors.New(internalErrors.ErrTeamCurrencyUnsupported)
+		}
+
+		team, err := getTeamSpender(ctx, repo, qp.TeamID, qp.Username)
+		if err != nil {
+			return err
+		}
+
+		inventoryID := team.InventoryID
+		if qp.ForUser != "" {
+			member, err := repo.GetTeamMember(ctx, team.ID, qp.ForUser)
+			if err != nil {
+				return err
+			}
+			if member.UserID == 0 {
+				return errors.New(internalErrors.ErrTeamMemberNotFound)
+			}
+			inventoryID, err = repo.GetInventoryIDByUserID(ctx, member.UserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := repo.LockBalances(ctx, team.BalanceID); err != nil {
+			return err
+		}
+		if err := repo.DebitBalance(ctx, team.BalanceID, merch.Price); err != nil {
+			return err
+		}
+		if _, err := repo.SpendBalanceLots(ctx, team.BalanceID, merch.Price); err != nil {
+			return err
+		}
+		err = repo.CreateBalanceHistory(ctx, models.BalanceHistory{
+			BalanceID:         team.BalanceID,
+			TransactionAmount: merch.Price,
+			Sender:            team.AccountName(),
+			Recipient:         shopUser,
+			Type:              models.HistoryTypePurchase,
+			MerchName:         merch.Name,
+			Actor:             qp.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		return repo.AddInventoryMerch(ctx, inventoryID, merch.ID, merch.Name)
+	})
+}
+
+// getTeamMembership возвращает команду и участника. Для не участников команда считается не найденной.
+func getTeamMembership(ctx context.Context, repo Repository, teamID int64, username string) (models.Team, models.TeamMember, error) {
+	team, err := repo.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return models.Team{}, models.TeamMember{}, err
+	}
+	if team.ID == 0 {
+		return models.Team{}, models.TeamMember{}, errors.New(internalErrors.ErrTeamNotFound)
+	}
+
+	member, err := repo.GetTeamMember(ctx, teamID, username)
+	if err != nil {
+		return models.Team{}, models.TeamMember{}, err
+	}
+	if member.UserID == 0 {
+		return models.Team{}, models.TeamMember{}, errors.New(internalErrors.ErrTeamNotFound)
+	}
+
+	return team, member, nil
+}
+
+// getTeamSpender проверяет, что пользователь может тратить кошелёк команды и его аккаунт не ограничен
+func getTeamSpender(ctx context.Context, repo Repository, teamID int64, username string) (models.Team, error) {
+	team, member, err := getTeamMembership(ctx, repo, teamID, username)
+	if err != nil {
+		return models.Team{}, err
+	}
+	if !member.CanSpendFunds() {
+		return models.Team{}, errors.New(internalErrors.ErrTeamForbidden)
+	}
+	if err := checkAccountCanSpend(ctx, repo, username); err != nil {
+		return models.Team{}, err
+	}
+
+	return team, nil
+}
+
+func checkNotLastTeamOwner(ctx context.Context, repo Repository, teamID int64) error {
+	owners, err := repo.CountTeamOwners(ctx, teamID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return errors.New(internalErrors.ErrTeamLastOwner)
+	}
+
+	return nil
+}
+
+// validTeamName имя команды подчиняется тем же правилам, что и имя пользователя
+func validTeamName(name string) bool {
+	if name == "" || len(name) > maxTeamNameLength {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func toTeamDTO(team models.Team, member models.TeamMember) models.TeamDTO {
+	return models.TeamDTO{
+		ID:        team.ID,
+		Name:      team.Name,
+		Coins:     team.Amount,
+		Role:      member.Role,
+		CanSpend:  member.CanSpendFunds(),
+		CreatedAt: strfmt.DateTime(team.CreatedAt),
+	}
+}
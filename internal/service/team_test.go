@@ -0,0 +1,504 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+func Test_validTeamName(t *testing.T) {
+	tests := []struct {
+		name string
+		team string
+		want bool
+	}{
+		{name: "success_-_letters_and_digits", team: "backend42", want: true},
+		{name: "success_-_max_length", team: strings.Repeat("a", maxTeamNameLength), want: true},
+		{name: "error_-_empty", team: "", want: false},
+		{name: "error_-_too_long", team: strings.Repeat("a", maxTeamNameLength+1), want: false},
+		{name: "error_-_separator", team: "team:backend", want: false},
+		{name: "error_-_space", team: "back end", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validTeamName(tt.team); got != tt.want {
+				t.Errorf("validTeamName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// teamMembers участники команды 1 для моков
+var teamMembers = map[string]models.TeamMember{
+	"owner1":  {TeamID: 1, UserID: 1, Username: "owner1", Role: models.TeamRoleOwner},
+	"owner2":  {TeamID: 1, UserID: 2, Username: "owner2", Role: models.TeamRoleOwner},
+	"spender": {TeamID: 1, UserID: 3, Username: "spender", Role: models.TeamRoleMember, CanSpend: true},
+	"member":  {TeamID: 1, UserID: 4, Username: "member", Role: models.TeamRoleMember},
+}
+
+func newTeamMockRepository(owners int64) *MockRepository {
+	return &MockRepository{
+		GetNotificationPreferencesFunc: getNoNotificationPreferences,
+		NextTransferIDFunc:             nextTransferID,
+		GetTeamByIDFunc: func(ctx context.Context, teamID int64) (models.Team, error) {
+			if teamID != 1 {
+				return models.Team{}, nil
+			}
+			return models.Team{ID: 1, Name: "backend", BalanceID: 100, InventoryID: 200, Amount: 50}, nil
+		},
+		LockTeamFunc: func(ctx context.Context, teamID int64) error {
+			return nil
+		},
+		GetTeamMemberFunc: func(ctx context.Context, teamID int64, username string) (models.TeamMember, error) {
+			return teamMembers[username], nil
+		},
+		CountTeamOwnersFunc: func(ctx context.Context, teamID int64) (int64, error) {
+			return owners, nil
+		},
+		UpsertTeamMemberFunc: func(ctx context.Context, member models.TeamMember) (bool, error) {
+			return member.Username != "ghost", nil
+		},
+		DeleteTeamMemberFunc: func(ctx context.Context, teamID, userID int64) error {
+			return nil
+		},
+		GetAccountStateFunc: func(ctx context.Context, username string) (models.AccountState, error) {
+			return models.AccountState{Status: models.UserStatusActive}, nil
+		},
+	}
+}
+
+func Test_service_SetTeamMember(t *testing.T) {
+	tests := []struct {
+		name    string
+		owners  int64
+		qp      models.TeamMemberQuery
+		want    models.TeamMemberDTO
+		wantErr string
+	}{
+		{
+			name:   "success_-_member_added_by_owner",
+			owners: 1,
+			qp:     models.TeamMemberQuery{TeamID: 1, Actor: "owner1", Username: "newbie", CanSpend: true},
+			want:   models.TeamMemberDTO{Username: "newbie", Role: models.TeamRoleMember, CanSpend: true},
+		},
+		{
+			name:   "success_-_owner_demoted_when_another_owner_left",
+			owners: 2,
+			qp:     models.TeamMemberQuery{TeamID: 1, Actor: "owner1", Username: "owner2", Role: models.TeamRoleMember},
+			want:   models.TeamMemberDTO{Username: "owner2", Role: models.TeamRoleMember},
+		},
+		{
+			name:    "error_-_unknown_role",
+			qp:      models.TeamMemberQuery{TeamID: 1, Actor: "owner1", Username: "newbie", Role: "admin"},
+			wantErr: internalErrors.ErrInvalidTeamReqParams,
+		},
+		{
+			name:    "error_-_team_not_found",
+			qp:      models.TeamMemberQuery{TeamID: 2, Actor: "owner1", Username: "newbie"},
+			wantErr: internalErrors.ErrTeamNotFound,
+		},
+		{
+			name:    "error_-_actor_not_a_member",
+			qp:      models.TeamMemberQuery{TeamID: 1, Actor: "stranger", Username: "newbie"},
+			wantErr: internalErrors.ErrTeamNotFound,
+		},
+		{
+			name:    "error_-_actor_not_an_owner",
+			qp:      models.TeamMemberQuery{TeamID: 1, Actor: "spender", Username: "newbie"},
+			wantErr: internalErrors.ErrTeamForbidden,
+		},
+		{
+			name:    "error_-_last_owner_demoted",
+			owners:  1,
+			qp:      models.TeamMemberQuery{TeamID: 1, Actor: "owner1", Username: "owner1", Role: models.TeamRoleMember},
+			wantErr: internalErrors.ErrTeamLastOwner,
+		},
+		{
+			name:    "error_-_user_not_found",
+			owners:  1,
+			qp:      models.TeamMemberQuery{TeamID: 1, Actor: "owner1", Username: "ghost"},
+			wantErr: internalErrors.ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{
+				repo:      newTeamMockRepository(tt.owners),
+				txManager: &MockTxManager{},
+			}
+
+			got, err := s.SetTeamMember(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.SetTeamMember() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.SetTeamMember() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("service.SetTeamMember() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_service_RemoveTeamMember(t *testing.T) {
+	tests := []struct {
+		name    string
+		owners  int64
+		qp      models.TeamMemberQuery
+		wantErr string
+	}{
+		{
+			name: "success_-_owner_removes_member",
+			qp:   models.TeamMemberQuery{TeamID: 1, Actor: "owner1", Username: "member"},
+		},
+		{
+			name: "success_-_member_leaves",
+			qp:   models.TeamMemberQuery{TeamID: 1, Actor: "member", Username: "member"},
+		},
+		{
+			name:   "success_-_owner_leaves_when_another_owner_left",
+			owners: 2,
+			qp:     models.TeamMemberQuery{TeamID: 1, Actor: "owner1", Username: "owner1"},
+		},
+		{
+			name:    "error_-_member_removes_another_member",
+			qp:      models.TeamMemberQuery{TeamID: 1, Actor: "spender", Username: "member"},
+			wantErr: internalErrors.ErrTeamForbidden,
+		},
+		{
+			name:    "error_-_target_not_a_member",
+			qp:      models.TeamMemberQuery{TeamID: 1, Actor: "owner1", Username: "stranger"},
+			wantErr: internalErrors.ErrTeamMemberNotFound,
+		},
+		{
+			name:    "error_-_last_owner_leaves",
+			owners:  1,
+			qp:      models.TeamMemberQuery{TeamID: 1, Actor: "owner1", Username: "owner1"},
+			wantErr: internalErrors.ErrTeamLastOwner,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{
+				repo:      newTeamMockRepository(tt.owners),
+				txManager: &MockTxManager{},
+			}
+
+			err := s.RemoveTeamMember(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.RemoveTeamMember() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("service.RemoveTeamMember() error = %v", err)
+			}
+		})
+	}
+}
+
+func Test_service_DepositToTeam(t *testing.T) {
+	tests := []struct {
+		name    string
+		qp      models.TeamTransferQuery
+		state   models.AccountState
+		limit   config.TransferLimit
+		wantErr string
+	}{
+		{
+			name:  "success_-_user_deposits",
+			qp:    models.TeamTransferQuery{TeamID: 1, Username: "user1", Amount: 10},
+			state: models.AccountState{Status: models.UserStatusActive},
+		},
+		{
+			name:    "error_-_team_not_found",
+			qp:      models.TeamTransferQuery{TeamID: 2, Username: "user1", Amount: 10},
+			state:   models.AccountState{Status: models.UserStatusActive},
+			wantErr: internalErrors.ErrTeamNotFound,
+		},
+		{
+			name:    "error_-_account_frozen",
+			qp:      models.TeamTransferQuery{TeamID: 1, Username: "user1", Amount: 10},
+			state:   models.AccountState{Status: models.UserStatusFrozen},
+			wantErr: internalErrors.ErrAccountFrozen,
+		},
+		{
+			name:    "error_-_amount_over_limit",
+			qp:      models.TeamTransferQuery{TeamID: 1, Username: "user1", Amount: 100},
+			state:   models.AccountState{Status: models.UserStatusActive},
+			limit:   config.TransferLimit{Default: config.TransferLimits{MaxAmount: 50}},
+			wantErr: internalErrors.ErrTransferAmountLimit,
+		},
+		{
+			name:    "error_-_not_enough_coins",
+			qp:      models.TeamTransferQuery{TeamID: 1, Username: "user1", Amount: 1001},
+			state:   models.AccountState{Status: models.UserStatusActive},
+			wantErr: internalErrors.ErrNotEnoughCoins,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var history []models.BalanceHistory
+			// моки достижений, рефералов, квестов и рейтинга не заданы: пополнение команды их не затрагивает
+			repo := newTeamMockRepository(1)
+			repo.GetAccountStateFunc = func(ctx context.Context, username string) (models.AccountState, error) {
+				return tt.state, nil
+			}
+			repo.GetUserRoleByUsernameFunc = func(ctx context.Context, username string) (string, error) {
+				return models.RoleUser, nil
+			}
+			repo.GetOrCreateWalletFunc = func(ctx context.Context, username, currency string) (models.Balance, error) {
+				return models.Balance{ID: 2, Amount: 1000}, nil
+			}
+			repo.LockBalancesFunc = func(ctx context.Context, balanceIDs ...int64) error {
+				return nil
+			}
+			repo.DebitBalanceFunc = func(ctx context.Context, balanceID, amount int64) error {
+				return nil
+			}
+			repo.SpendBalanceLotsFunc = func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+				return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+			}
+			repo.CreditBalanceFunc = func(ctx context.Context, balanceID, amount int64) error {
+				return nil
+			}
+			repo.CreateBalanceLotFunc = func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+				return nil
+			}
+			repo.CreateBalanceHistoryFunc = func(ctx context.Context, entry models.BalanceHistory) error {
+				history = append(history, entry)
+				return nil
+			}
+			s := &service{repo: repo, txManager: &MockTxManager{}, cfg: config.Config{TransferLimit: tt.limit}}
+
+			err := s.DepositToTeam(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.DepositToTeam() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.DepositToTeam() error = %v", err)
+			}
+			if len(history) != 2 {
+				t.Fatalf("service.DepositToTeam() history entries = %v, want 2", len(history))
+			}
+			for _, entry := range history {
+				if entry.Sender != tt.qp.Username || entry.Recipient != "team:backend" {
+					t.Errorf("service.DepositToTeam() history sender = %v, recipient = %v", entry.Sender, entry.Recipient)
+				}
+			}
+		})
+	}
+}
+
+func Test_service_SendFromTeam(t *testing.T) {
+	tests := []struct {
+		name    string
+		qp      models.TeamTransferQuery
+		state   models.AccountState
+		limit   config.TransferLimit
+		wantErr string
+	}{
+		{
+			name: "success_-_owner_sends",
+			qp:   models.TeamTransferQuery{TeamID: 1, Username: "owner1", Recipient: "user2", Amount: 10},
+		},
+		{
+			name: "success_-_member_with_permission_sends",
+			qp:   models.TeamTransferQuery{TeamID: 1, Username: "spender", Recipient: "user2", Amount: 10},
+		},
+		{
+			name:    "error_-_member_without_permission",
+			qp:      models.TeamTransferQuery{TeamID: 1, Username: "member", Recipient: "user2", Amount: 10},
+			wantErr: internalErrors.ErrTeamForbidden,
+		},
+		{
+			name:    "error_-_invalid_amount",
+			qp:      models.TeamTransferQuery{TeamID: 1, Username: "owner1", Recipient: "user2", Amount: 0},
+			wantErr: internalErrors.ErrInvalidTeamReqParams,
+		},
+		{
+			name:    "error_-_invalid_recipient",
+			qp:      models.TeamTransferQuery{TeamID: 1, Username: "owner1", Recipient: "ghost", Amount: 10},
+			wantErr: internalErrors.ErrInvalidRecipient,
+		},
+		{
+			name:    "error_-_not_enough_coins",
+			qp:      models.TeamTransferQuery{TeamID: 1, Username: "owner1", Recipient: "user2", Amount: 51},
+			wantErr: internalErrors.ErrNotEnoughCoins,
+		},
+		{
+			name:    "error_-_member_account_frozen",
+			qp:      models.TeamTransferQuery{TeamID: 1, Username: "spender", Recipient: "user2", Amount: 10},
+			state:   models.AccountState{Status: models.UserStatusFrozen},
+			wantErr: internalErrors.ErrAccountFrozen,
+		},
+		{
+			name:    "error_-_member_amount_over_limit",
+			qp:      models.TeamTransferQuery{TeamID: 1, Username: "spender", Recipient: "user2", Amount: 10},
+			limit:   config.TransferLimit{Default: config.TransferLimits{MaxAmount: 5}},
+			wantErr: internalErrors.ErrTransferAmountLimit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var history []models.BalanceHistory
+			repo := newTeamMockRepository(1)
+			if tt.state.Status != "" {
+				repo.GetAccountStateFunc = func(ctx context.Context, username string) (models.AccountState, error) {
+					return tt.state, nil
+				}
+			}
+			repo.GetUserRoleByUsernameFunc = func(ctx context.Context, username string) (string, error) {
+				return models.RoleUser, nil
+			}
+			repo.IsUserExistFunc = func(ctx context.Context, username string) (bool, error) {
+				return username != "ghost", nil
+			}
+			repo.GetOrCreateWalletFunc = func(ctx context.Context, username, currency string) (models.Balance, error) {
+				return models.Balance{ID: 2}, nil
+			}
+			repo.LockBalancesFunc = func(ctx context.Context, balanceIDs ...int64) error {
+				return nil
+			}
+			repo.DebitBalanceFunc = func(ctx context.Context, balanceID, amount int64) error {
+				if amount > 50 {
+					return errors.New(internalErrors.ErrNotEnoughCoins)
+				}
+				return nil
+			}
+			repo.SpendBalanceLotsFunc = func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+				return []models.BalanceLot{{BalanceID: balanceID, Amount: amount}}, nil
+			}
+			repo.CreditBalanceFunc = func(ctx context.Context, balanceID, amount int64) error {
+				return nil
+			}
+			repo.CreateBalanceLotFunc = func(ctx context.Context, balanceID, amount int64, grantedAt time.Time) error {
+				return nil
+			}
+			repo.CreateBalanceHistoryFunc = func(ctx context.Context, entry models.BalanceHistory) error {
+				history = append(history, entry)
+				return nil
+			}
+			s := &service{repo: repo, txManager: &MockTxManager{}, cfg: config.Config{TransferLimit: tt.limit}}
+
+			err := s.SendFromTeam(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.SendFromTeam() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.SendFromTeam() error = %v", err)
+			}
+			for _, entry := range history {
+				if entry.Sender != "team:backend" || entry.Actor != tt.qp.Username {
+					t.Errorf("service.SendFromTeam() history sender = %v, actor = %v", entry.Sender, entry.Actor)
+				}
+			}
+		})
+	}
+}
+
+func Test_service_BuyTeamItem(t *testing.T) {
+	tests := []struct {
+		name          string
+		qp            models.TeamItemQuery
+		currency      string
+		wantInventory int64
+		wantErr       string
+	}{
+		{
+			name:          "success_-_team_inventory",
+			qp:            models.TeamItemQuery{TeamID: 1, Username: "spender", Item: "cup"},
+			wantInventory: 200,
+		},
+		{
+			name:          "success_-_member_inventory",
+			qp:            models.TeamItemQuery{TeamID: 1, Username: "owner1", Item: "cup", ForUser: "member"},
+			wantInventory: 304,
+		},
+		{
+			name:    "error_-_recipient_not_a_member",
+			qp:      models.TeamItemQuery{TeamID: 1, Username: "owner1", Item: "cup", ForUser: "stranger"},
+			wantErr: internalErrors.ErrTeamMemberNotFound,
+		},
+		{
+			name:    "error_-_member_without_permission",
+			qp:      models.TeamItemQuery{TeamID: 1, Username: "member", Item: "cup"},
+			wantErr: internalErrors.ErrTeamForbidden,
+		},
+		{
+			name:     "error_-_merch_priced_in_other_currency",
+			qp:       models.TeamItemQuery{TeamID: 1, Username: "owner1", Item: "cup"},
+			currency: "kudos",
+			wantErr:  internalErrors.ErrTeamCurrencyUnsupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inventoryID int64
+			var history models.BalanceHistory
+			currency := models.CurrencyCoins
+			if tt.currency != "" {
+				currency = tt.currency
+			}
+			repo := newTeamMockRepository(1)
+			repo.GetMerchByNameFunc = func(ctx context.Context, name string) (models.Merch, error) {
+				return models.Merch{ID: 5, Name: name, Price: 20, Currency: currency}, nil
+			}
+			repo.GetInventoryIDByUserIDFunc = func(ctx context.Context, userID int64) (int64, error) {
+				return 300 + userID, nil
+			}
+			repo.LockBalancesFunc = func(ctx context.Context, balanceIDs ...int64) error {
+				return nil
+			}
+			repo.DebitBalanceFunc = func(ctx context.Context, balanceID, amount int64) error {
+				return nil
+			}
+			repo.SpendBalanceLotsFunc = func(ctx context.Context, balanceID, amount int64) ([]models.BalanceLot, error) {
+				return nil, nil
+			}
+			repo.CreateBalanceHistoryFunc = func(ctx context.Context, entry models.BalanceHistory) error {
+				history = entry
+				return nil
+			}
+			repo.AddInventoryMerchFunc = func(ctx context.Context, invID, merchID int64, item string) error {
+				inventoryID = invID
+				return nil
+			}
+			s := &service{repo: repo, txManager: &MockTxManager{}}
+
+			err := s.BuyTeamItem(context.Background(), tt.qp)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("service.BuyTeamItem() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("service.BuyTeamItem() error = %v", err)
+			}
+			if inventoryID != tt.wantInventory {
+				t.Errorf("service.BuyTeamItem() inventory = %v, want %v", inventoryID, tt.wantInventory)
+			}
+			if history.BalanceID != 100 || history.Sender != "team:backend" || history.Actor != tt.qp.Username {
+				t.Errorf("service.BuyTeamItem() history = %+v", history)
+			}
+		})
+	}
+}
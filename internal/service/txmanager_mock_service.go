@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+)
+
+type MockTxManager struct {
+	RetryableFunc func(err error) bool
+}
+
+func (m *MockTxManager) Do(ctx context.Context, opts models.TxOptions, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (m *MockTxManager) Retryable(err error) bool {
+	if m.RetryableFunc == nil {
+		return false
+	}
+
+	return m.RetryableFunc(err)
+}
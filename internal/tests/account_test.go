@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestAccountStatus() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	userToken := login(t, &client, "accountUser")
+	login(t, &client, "accountPeer")
+
+	login(t, &client, "accountAdmin")
+	_, err := s.dbPool.Exec(ctx, `UPDATE shop."user" SET role = 'admin' WHERE username = 'accountAdmin'`)
+	require.NoError(t, err)
+	adminToken := login(t, &client, "accountAdmin")
+
+	change := func(t *testing.T, action string, body models.AccountActionReqBody) (*http.Response, []byte) {
+		reqBody, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		resp, respBody, err := client.SendJsonReq(adminToken, http.MethodPost, fmt.Sprintf("%s/api/admin/users/accountUser/%s", BaseURL, action), reqBody)
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+	send := func(t *testing.T) (*http.Response, []byte) {
+		reqBody, err := json.Marshal(models.SendCoinsReqBody{Recipient: "accountPeer", Amount: 10})
+		require.NoError(t, err)
+
+		resp, respBody, err := client.SendJsonReq(userToken, http.MethodPost, BaseURL+"/api/sendCoin", reqBody)
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+	authReq := func(t *testing.T) (*http.Response, []byte) {
+		reqBody, err := json.Marshal(models.AuthReqBody{Username: "accountUser", Password: "11111!Aa"})
+		require.NoError(t, err)
+
+		resp, respBody, err := client.SendJsonReq("", http.MethodPost, BaseURL+"/api/auth", reqBody)
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+
+	t.Run("error_reason_required", func(t *testing.T) {
+		resp, respBody := change(t, models.AccountActionFreeze, models.AccountActionReqBody{})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrInvalidAccountReqParams, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("success_frozen_account_can_read_but_not_send", func(t *testing.T) {
+		resp, respBody := change(t, models.AccountActionFreeze, models.AccountActionReqBody{Reason: "suspicious activity"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		account := models.AccountDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &account))
+		require.Equal(t, models.UserStatusFrozen, account.Status)
+
+		resp, respBody = send(t)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrAccountFrozen, strings.TrimSpace(string(respBody)))
+
+		resp, _, err := client.SendJsonReq(userToken, http.MethodGet, BaseURL+"/api/info", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("success_suspended_account_can't_login", func(t *testing.T) {
+		resp, _ := change(t, models.AccountActionSuspend, models.AccountActionReqBody{Reason: "policy violation"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _, err := client.SendJsonReq(userToken, http.MethodGet, BaseURL+"/api/info", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, respBody := authReq(t)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrAccountSuspended, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("success_activated_account_can_send", func(t *testing.T) {
+		resp, _ := change(t, models.AccountActionActivate, models.AccountActionReqBody{Reason: "reviewed"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _ = send(t)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("success_offboarded_coins_swept", func(t *testing.T) {
+		resp, respBody := change(t, models.AccountActionOffboard, models.AccountActionReqBody{Reason: "left the company", SweepCoins: true})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		account := models.AccountDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &account))
+		require.True(t, account.Offboarded)
+		require.Equal(t, int64(990), account.SweptAmount)
+
+		resp, respBody = authReq(t)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrAccountOffboarded, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("success_audit_lists_actions", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(adminToken, http.MethodGet, BaseURL+"/api/admin/users/accountUser/audit", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		audit := []models.AccountAuditDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &audit))
+
+		actions := make([]string, 0, len(audit))
+		for _, a := range audit {
+			require.Equal(t, "accountAdmin", a.Actor)
+			actions = append(actions, a.Action)
+		}
+		require.ElementsMatch(t, []string{
+			models.AccountActionFreeze,
+			models.AccountActionSuspend,
+			models.AccountActionActivate,
+			models.AccountActionOffboard,
+		}, actions)
+	})
+}
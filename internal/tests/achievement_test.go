@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestAchievements() {
+	t := s.T()
+	client := HttpClient{}
+
+	token := login(t, &client, "achiever")
+	recipients := make([]string, 0, 10)
+	for i := 0; i < 10; i++ {
+		username := fmt.Sprintf("achieverFriend%d", i)
+		login(t, &client, username)
+		recipients = append(recipients, username)
+	}
+
+	getInfo := func(t *testing.T) models.InfoDTO {
+		resp, respBody, err := client.SendJsonReq(token, http.MethodGet, BaseURL+"/api/info", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		infoDTO := models.InfoDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &infoDTO))
+
+		return infoDTO
+	}
+	codes := func(achievements []models.AchievementDTO) []string {
+		result := []string{}
+		for _, achievement := range achievements {
+			result = append(result, achievement.Code)
+		}
+		return result
+	}
+	sendCoins := func(t *testing.T, recipient string) {
+		reqBody, err := json.Marshal(models.SendCoinsReqBody{Recipient: recipient, Amount: 1})
+		require.NoError(t, err)
+		resp, _, err := client.SendJsonReq(token, http.MethodPost, BaseURL+"/api/sendCoin", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	t.Run("success_first_sign_in", func(t *testing.T) {
+		require.Equal(t, []string{"first-sign-in"}, codes(getInfo(t).Achievements))
+	})
+
+	t.Run("success_first_transfer_awarded_once", func(t *testing.T) {
+		sendCoins(t, recipients[0])
+		sendCoins(t, recipients[0])
+
+		require.Equal(t, []string{"first-sign-in", "first-transfer"}, codes(getInfo(t).Achievements))
+	})
+
+	t.Run("success_thanked_10_people_rewarded", func(t *testing.T) {
+		for _, recipient := range recipients[1:] {
+			sendCoins(t, recipient)
+		}
+
+		infoDTO := getInfo(t)
+		require.Equal(t, []string{"first-sign-in", "first-transfer", "thanked-10"}, codes(infoDTO.Achievements))
+
+		rewards := 0
+		for _, received := range infoDTO.CoinsHistory.Received {
+			if received.Type == models.HistoryTypeAchievement {
+				require.Equal(t, models.TreasuryAccount, received.FromUser)
+				require.Equal(t, int64(50), received.Amount)
+				rewards++
+			}
+		}
+		require.Equal(t, 1, rewards)
+		// 1000 - 11 переведённых + 50 награды
+		require.Equal(t, int64(1039), infoDTO.Coins)
+	})
+
+	t.Run("success_first_purchase", func(t *testing.T) {
+		resp, _, err := client.SendJsonReq(token, http.MethodGet, BaseURL+"/api/buy/pen", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		require.Contains(t, codes(getInfo(t).Achievements), "first-purchase")
+	})
+
+	t.Run("success_profile", func(t *testing.T) {
+		friendToken := login(t, &client, recipients[0])
+		resp, respBody, err := client.SendJsonReq(friendToken, http.MethodGet, BaseURL+"/api/users/achiever", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		profileDTO := models.ProfileDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &profileDTO))
+		require.Equal(t, "achiever", profileDTO.Username)
+		require.Equal(t, []string{"first-sign-in", "first-transfer", "thanked-10", "first-purchase"}, codes(profileDTO.Achievements))
+	})
+
+	t.Run("error_profile_unknown_user", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(token, http.MethodGet, BaseURL+"/api/users/nobodyHere", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrUserNotFound, strings.TrimSpace(string(respBody)))
+	})
+}
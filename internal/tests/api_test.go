@@ -24,9 +24,20 @@ import (
 	"github.com/devWaylander/coins_store/config"
 )
 
+// backgroundJobs фоновые задачи сервиса, которые тесты запускают вручную
+type backgroundJobs interface {
+	ExecuteDueSchedules(ctx context.Context) error
+	PayAllowance(ctx context.Context) error
+	ExpireCoins(ctx context.Context) error
+	ReleaseExpiredHolds(ctx context.Context) error
+	DetectFraud(ctx context.Context) error
+	SnapshotBalances(ctx context.Context) error
+}
+
 type E2eIntegrationTestSuite struct {
 	suite.Suite
 	dbPool *pgxpool.Pool
+	jobs   backgroundJobs
 }
 
 func TestE2eIntegrationTestSuite(t *testing.T) {
@@ -65,13 +76,15 @@ func (s *E2eIntegrationTestSuite) SetupSuite() {
 
 	// Repositories
 	usecaseRepo := repo.New(dbPool)
+	txManager := repo.NewTxManager(dbPool)
 	authMiddlewareRepo := auth.NewAuthRepo(dbPool)
 
-	// Auth Middleware
-	authMiddleware := auth.NewMiddleware(authMiddlewareRepo, cfg.Common.JWTSecret)
-
 	// Service
-	service := service.New(usecaseRepo)
+	service := service.New(usecaseRepo, txManager, cfg)
+
+	// Auth Middleware
+	authMiddleware := auth.NewMiddleware(authMiddlewareRepo, cfg.Common.JWTSecret, service)
+	s.jobs = service
 
 	// Handler
 	mux := http.NewServeMux()
@@ -101,6 +114,31 @@ func (s *E2eIntegrationTestSuite) TearDownSuite() {
 		"shop.user",
 		"shop.inventory",
 		"shop.inventory_merch",
+		"shop.transfer_schedule",
+		"shop.transfer_schedule_run",
+		"shop.system_account",
+		"shop.allowance_run",
+		"shop.balance_lot",
+		"shop.payment_request",
+		"shop.balance_hold",
+		"shop.fraud_case",
+		"shop.account_audit",
+		"shop.balance_snapshot",
+		"shop.team",
+		"shop.team_member",
+		"shop.leaderboard_stat",
+		"shop.leaderboard_pair",
+		"shop.user_achievement",
+		"shop.user_activity",
+		"shop.kudos",
+		"shop.kudos_reaction",
+		"shop.referral",
+		"shop.check_in",
+		"shop.quest_recipient",
+		"shop.quest_progress",
+		"shop.quest",
+		"shop.notification",
+		"shop.notification_preference",
 	}
 
 	for _, table := range tablesToClear {
@@ -386,7 +424,7 @@ func (s *E2eIntegrationTestSuite) TestSendCoins() {
 			Coins: 931,
 			CoinsHistory: models.BalanceHistoryDTO{
 				Received: []models.ReceivedDTO{},
-				Sent:     []models.SentDTO{{ToUser: "user3", Amount: 69}},
+				Sent:     []models.SentDTO{{ToUser: "user3", Amount: 69, Type: "transfer"}},
 			},
 		}
 		require.Equal(t, expectedInfoData.Coins, respInfoData.Coins)
@@ -405,7 +443,7 @@ func (s *E2eIntegrationTestSuite) TestSendCoins() {
 		expectedInfoData = models.InfoDTO{
 			Coins: 1069,
 			CoinsHistory: models.BalanceHistoryDTO{
-				Received: []models.ReceivedDTO{{FromUser: "user2", Amount: 69}},
+				Received: []models.ReceivedDTO{{FromUser: "user2", Amount: 69, Type: "transfer"}},
 				Sent:     []models.SentDTO{},
 			},
 		}
@@ -529,7 +567,7 @@ func (s *E2eIntegrationTestSuite) TestSendCoins() {
 			Coins: 0,
 			CoinsHistory: models.BalanceHistoryDTO{
 				Received: []models.ReceivedDTO{},
-				Sent:     []models.SentDTO{{ToUser: "user3", Amount: 69}, {ToUser: "user3", Amount: 931}},
+				Sent:     []models.SentDTO{{ToUser: "user3", Amount: 69, Type: "transfer"}, {ToUser: "user3", Amount: 931, Type: "transfer"}},
 			},
 		}
 		require.Equal(t, expectedInfoData.Coins, respInfoData.Coins)
@@ -548,7 +586,7 @@ func (s *E2eIntegrationTestSuite) TestSendCoins() {
 		expectedInfoData = models.InfoDTO{
 			Coins: 2000,
 			CoinsHistory: models.BalanceHistoryDTO{
-				Received: []models.ReceivedDTO{{FromUser: "user2", Amount: 69}, {FromUser: "user2", Amount: 931}},
+				Received: []models.ReceivedDTO{{FromUser: "user2", Amount: 69, Type: "transfer"}, {FromUser: "user2", Amount: 931, Type: "transfer"}},
 				Sent:     []models.SentDTO{},
 			},
 		}
@@ -622,7 +660,7 @@ func (s *E2eIntegrationTestSuite) TestGetUserInfo() {
 			Coins: 0,
 			CoinsHistory: models.BalanceHistoryDTO{
 				Received: []models.ReceivedDTO{},
-				Sent:     []models.SentDTO{{ToUser: "AvitoShop", Amount: 500}, {ToUser: "AvitoShop", Amount: 500}},
+				Sent:     []models.SentDTO{{ToUser: "AvitoShop", Amount: 500, Type: "purchase"}, {ToUser: "AvitoShop", Amount: 500, Type: "purchase"}},
 			},
 			Inventory: []models.MerchDTO{{Type: "pink-hoody", Quantity: 2}},
 		}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestBulkSendCoins() {
+	t := s.T()
+	client := HttpClient{}
+
+	senderToken := login(t, &client, "bulkUser1")
+	login(t, &client, "bulkUser2")
+	login(t, &client, "bulkUser3")
+
+	getCoins := func(t *testing.T) int64 {
+		resp, respBody, err := client.SendJsonReq(senderToken, http.MethodGet, BaseURL+"/api/info", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respInfoData := models.InfoDTO{}
+		err = json.Unmarshal(respBody, &respInfoData)
+		require.NoError(t, err)
+
+		return respInfoData.Coins
+	}
+
+	t.Run("error_invalid_recipient_rejects_whole_batch", func(t *testing.T) {
+		reqBody, err := json.Marshal(models.BulkSendCoinsReqBody{Transfers: []models.BulkTransferItem{
+			{Recipient: "bulkUser2", Amount: 100},
+			{Recipient: "bulkUserInvalid", Amount: 100},
+		}})
+		require.NoError(t, err)
+
+		resp, respBody, err := client.SendJsonReq(senderToken, http.MethodPost, BaseURL+"/api/sendCoin/bulk", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		result := models.BulkSendCoinsResultDTO{}
+		err = json.Unmarshal(respBody, &result)
+		require.NoError(t, err)
+		require.Equal(t, []models.BulkTransferInvalidDTO{{Index: 1, ToUser: "bulkUserInvalid", Error: internalErrors.ErrInvalidRecipient}}, result.Invalid)
+		require.Equal(t, int64(1000), getCoins(t))
+	})
+
+	t.Run("error_total_exceeds_balance", func(t *testing.T) {
+		reqBody, err := json.Marshal(models.BulkSendCoinsReqBody{Transfers: []models.BulkTransferItem{
+			{Recipient: "bulkUser2", Amount: 600},
+			{Recipient: "bulkUser3", Amount: 600},
+		}})
+		require.NoError(t, err)
+
+		resp, respBody, err := client.SendJsonReq(senderToken, http.MethodPost, BaseURL+"/api/sendCoin/bulk", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrNotEnoughCoins, strings.TrimSpace(string(respBody)))
+		require.Equal(t, int64(1000), getCoins(t))
+	})
+
+	t.Run("success_bulk_send_coins", func(t *testing.T) {
+		reqBody, err := json.Marshal(models.BulkSendCoinsReqBody{Transfers: []models.BulkTransferItem{
+			{Recipient: "bulkUser2", Amount: 300, Memo: "release"},
+			{Recipient: "bulkUser3", Amount: 200, Memo: "release"},
+		}})
+		require.NoError(t, err)
+
+		resp, respBody, err := client.SendJsonReq(senderToken, http.MethodPost, BaseURL+"/api/sendCoin/bulk", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		result := models.BulkSendCoinsResultDTO{}
+		err = json.Unmarshal(respBody, &result)
+		require.NoError(t, err)
+		require.Equal(t, int64(500), result.Total)
+		require.Equal(t, int64(500), getCoins(t))
+	})
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestCheckIn() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	tokens := map[string]string{}
+	for _, username := range []string{"checkInUser1", "checkInUser2", "checkInRacer"} {
+		tokens[username] = login(t, &client, username)
+	}
+
+	checkIn := func(username string) (*http.Response, []byte) {
+		resp, respBody, err := client.SendJsonReq(tokens[username], http.MethodPost, BaseURL+"/api/checkIn", []byte{})
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+	getInfo := func(username string) models.InfoDTO {
+		resp, respBody, err := client.SendJsonReq(tokens[username], http.MethodGet, BaseURL+"/api/info", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		infoDTO := models.InfoDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &infoDTO))
+
+		return infoDTO
+	}
+
+	first := models.CheckInDTO{}
+	t.Run("success_first_check_in", func(t *testing.T) {
+		resp, respBody := checkIn("checkInUser1")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(respBody, &first))
+		require.Equal(t, 1, first.Streak)
+		require.Greater(t, first.Reward, int64(0))
+
+		infoDTO := getInfo("checkInUser1")
+		require.Equal(t, 1000+first.Reward, infoDTO.Coins)
+		require.Contains(t, infoDTO.CoinsHistory.Received, models.ReceivedDTO{
+			FromUser: models.TreasuryAccount,
+			Amount:   first.Reward,
+			Type:     models.HistoryTypeCheckIn,
+		})
+	})
+
+	t.Run("error_second_check_in_same_day", func(t *testing.T) {
+		resp, respBody := checkIn("checkInUser1")
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrAlreadyCheckedIn, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("success_streak_continues_from_yesterday", func(t *testing.T) {
+		day, err := time.Parse(models.CheckInDayLayout, first.Day)
+		require.NoError(t, err)
+		_, err = s.dbPool.Exec(ctx, `
+			INSERT INTO shop."check_in" (user_id, day, streak, reward)
+			SELECT id, $1, 4, 0 FROM shop."user" WHERE username = 'checkInUser2'
+		`, day.AddDate(0, 0, -1))
+		require.NoError(t, err)
+
+		resp, respBody := checkIn("checkInUser2")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		checkInDTO := models.CheckInDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &checkInDTO))
+		require.Equal(t, 5, checkInDTO.Streak)
+		require.GreaterOrEqual(t, checkInDTO.Reward, first.Reward)
+	})
+
+	t.Run("success_one_check_in_when_requests_race", func(t *testing.T) {
+		const requests = 10
+
+		var wg sync.WaitGroup
+		statuses := make(chan int, requests)
+		for range requests {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, _, err := client.SendJsonReq(tokens["checkInRacer"], http.MethodPost, BaseURL+"/api/checkIn", []byte{})
+				if err == nil {
+					statuses <- resp.StatusCode
+				}
+			}()
+		}
+		wg.Wait()
+		close(statuses)
+
+		counts := map[int]int{}
+		for status := range statuses {
+			counts[status]++
+		}
+		require.Equal(t, map[int]int{http.StatusOK: 1, http.StatusConflict: requests - 1}, counts)
+
+		rewards := 0
+		for _, received := range getInfo("checkInRacer").CoinsHistory.Received {
+			if received.Type == models.HistoryTypeCheckIn {
+				rewards++
+			}
+		}
+		require.Equal(t, 1, rewards)
+	})
+}
@@ -0,0 +1,246 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	stressUsersCount    = 10
+	stressRequestsCount = 300
+	stressStartBalance  = 1000
+)
+
+func login(t *testing.T, client *HttpClient, username string) string {
+	return loginToOrganization(t, client, "", username)
+}
+
+// loginToOrganization входит в организацию, пустое имя - организация по умолчанию
+func loginToOrganization(t *testing.T, client *HttpClient, organization, username string) string {
+	reqBody, err := json.Marshal(models.AuthReqBody{
+		Organization: organization,
+		Username:     username,
+		Password:     "11111!Aa",
+	})
+	require.NoError(t, err)
+
+	resp, respBody, err := client.SendJsonReq("", http.MethodPost, BaseURL+"/api/auth", reqBody)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	respAuthData := models.AuthDTO{}
+	err = json.Unmarshal(respBody, &respAuthData)
+	require.NoError(t, err)
+	require.Greater(t, len(respAuthData.Token), 0)
+
+	return respAuthData.Token
+}
+
+func (s *E2eIntegrationTestSuite) TestConcurrentSendCoins() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	usernames := make([]string, 0, stressUsersCount)
+	tokens := make([]string, 0, stressUsersCount)
+	for i := 0; i < stressUsersCount; i++ {
+		username := fmt.Sprintf("stressUser%d", i)
+		usernames = append(usernames, username)
+		tokens = append(tokens, login(t, &client, username))
+	}
+
+	t.Run("success_total_supply_is_conserved", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errCh := make(chan error, stressRequestsCount)
+
+		for k := 0; k < stressRequestsCount; k++ {
+			from := k % stressUsersCount
+			to := (k*7 + 1) % stressUsersCount
+			if from == to {
+				to = (to + 1) % stressUsersCount
+			}
+			amount := int64(k%7+1) * 60
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				var (
+					resp     *http.Response
+					respBody []byte
+					err      error
+				)
+				// каждый пятый запрос - покупка, остальные - переводы
+				if k%5 == 0 {
+					resp, respBody, err = client.SendJsonReq(tokens[from], http.MethodGet, BaseURL+"/api/buy/hoody", []byte{})
+				} else {
+					reqBody, mErr := json.Marshal(models.SendCoinsReqBody{Recipient: usernames[to], Amount: amount})
+					if mErr != nil {
+						errCh <- mErr
+						return
+					}
+					resp, respBody, err = client.SendJsonReq(tokens[from], http.MethodPost, BaseURL+"/api/sendCoin", reqBody)
+				}
+				if err != nil {
+					errCh <- err
+					return
+				}
+
+				switch resp.StatusCode {
+				case http.StatusOK:
+				case http.StatusBadRequest:
+					if strings.TrimSpace(string(respBody)) != internalErrors.ErrNotEnoughCoins {
+						errCh <- fmt.Errorf("unexpected response: %s", respBody)
+					}
+				default:
+					errCh <- fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+				}
+			}()
+		}
+		wg.Wait()
+		close(errCh)
+
+		for err := range errCh {
+			require.NoError(t, err)
+		}
+
+		// сумма балансов и потраченного в магазине должна совпадать с начальной эмиссией
+		var balancesSum, spentSum int64
+		err := s.dbPool.QueryRow(ctx, `
+			SELECT
+				COALESCE(SUM(b.amount), 0)
+			FROM
+				shop."user" u
+			INNER JOIN
+				shop."balance" b
+			ON
+				u.balance_id = b.id
+			WHERE
+				u.username = ANY($1)
+		`, usernames).Scan(&balancesSum)
+		require.NoError(t, err)
+
+		err = s.dbPool.QueryRow(ctx, `
+			SELECT
+				COALESCE(SUM(bh.transaction_amount), 0)
+			FROM
+				shop."balance_history" bh
+			WHERE
+				bh.sender = ANY($1) AND bh.recipient = 'AvitoShop'
+		`, usernames).Scan(&spentSum)
+		require.NoError(t, err)
+
+		require.Equal(t, int64(stressUsersCount*stressStartBalance), balancesSum+spentSum)
+
+		// баланс каждого пользователя должен сходиться с его историей
+		for _, username := range usernames {
+			var amount, received, sent int64
+			err := s.dbPool.QueryRow(ctx, `
+				SELECT
+					b.amount,
+					COALESCE(SUM(bh.transaction_amount) FILTER (WHERE bh.recipient = u.username), 0),
+					COALESCE(SUM(bh.transaction_amount) FILTER (WHERE bh.sender = u.username), 0)
+				FROM
+					shop."user" u
+				INNER JOIN
+					shop."balance" b
+				ON
+					u.balance_id = b.id
+				LEFT JOIN
+					shop."balance_history" bh
+				ON
+					bh.balance_id = b.id
+				WHERE
+					u.username = $1
+				GROUP BY
+					b.amount
+			`, username).Scan(&amount, &received, &sent)
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, amount, int64(0))
+			require.Equal(t, int64(stressStartBalance)+received-sent, amount, username)
+
+			// остатки лотов должны совпадать с балансом
+			var lotsSum int64
+			err = s.dbPool.QueryRow(ctx, `
+				SELECT
+					COALESCE(SUM(bl.amount), 0)
+				FROM
+					shop."user" u
+				INNER JOIN
+					shop."balance_lot" bl
+				ON
+					bl.balance_id = u.balance_id
+				WHERE
+					u.username = $1
+			`, username).Scan(&lotsSum)
+			require.NoError(t, err)
+			require.Equal(t, amount, lotsSum, username)
+		}
+	})
+}
+
+func (s *E2eIntegrationTestSuite) TestCrossedSendCoins() {
+	t := s.T()
+	client := HttpClient{}
+
+	tokenA := login(t, &client, "crossUserA")
+	tokenB := login(t, &client, "crossUserB")
+
+	t.Run("success_crossed_transfers_without_failures", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errCh := make(chan error, stressRequestsCount)
+
+		for k := 0; k < stressRequestsCount; k++ {
+			token, recipient := tokenA, "crossUserB"
+			if k%2 == 1 {
+				token, recipient = tokenB, "crossUserA"
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				reqBody, err := json.Marshal(models.SendCoinsReqBody{Recipient: recipient, Amount: 1})
+				if err != nil {
+					errCh <- err
+					return
+				}
+				resp, respBody, err := client.SendJsonReq(token, http.MethodPost, BaseURL+"/api/sendCoin", reqBody)
+				if err != nil {
+					errCh <- err
+					return
+				}
+				if resp.StatusCode != http.StatusOK {
+					errCh <- fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+				}
+			}()
+		}
+		wg.Wait()
+		close(errCh)
+
+		for err := range errCh {
+			require.NoError(t, err)
+		}
+
+		// переводы встречные и равные, поэтому балансы не должны измениться
+		for _, token := range []string{tokenA, tokenB} {
+			resp, respBody, err := client.SendJsonReq(token, http.MethodGet, BaseURL+"/api/info", []byte{})
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respInfoData := models.InfoDTO{}
+			err = json.Unmarshal(respBody, &respInfoData)
+			require.NoError(t, err)
+			require.Equal(t, int64(stressStartBalance), respInfoData.Coins)
+		}
+	})
+}
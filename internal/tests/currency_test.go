@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestCurrencies() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	tokens := map[string]string{}
+	for _, username := range []string{"currencyUser1", "currencyUser2"} {
+		tokens[username] = login(t, &client, username)
+	}
+
+	login(t, &client, "currencyAdmin")
+	_, err := s.dbPool.Exec(ctx, `UPDATE shop."user" SET role = 'admin' WHERE username = 'currencyAdmin'`)
+	require.NoError(t, err)
+	adminToken := login(t, &client, "currencyAdmin")
+
+	// мерч с ценой в благодарностях заводится только через БД
+	_, err = s.dbPool.Exec(ctx, `
+		INSERT INTO shop."merch" (org_id, name, price, currency)
+		SELECT o.id, 'kudos-sticker', 5, 'kudos' FROM shop."organization" o WHERE o.name = 'default'
+		ON CONFLICT (org_id, name) DO NOTHING
+	`)
+	require.NoError(t, err)
+
+	grant := func(t *testing.T, currency string) (*http.Response, []byte) {
+		reqBody, err := json.Marshal(models.GrantReqBody{
+			Usernames: []string{"currencyUser1"},
+			Amount:    20,
+			Reason:    "thanks",
+			Currency:  currency,
+		})
+		require.NoError(t, err)
+
+		resp, respBody, err := client.SendJsonReq(adminToken, http.MethodPost, BaseURL+"/api/admin/grants", reqBody)
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+	send := func(t *testing.T, amount int64, currency string) (*http.Response, []byte) {
+		reqBody, err := json.Marshal(models.SendCoinsReqBody{Recipient: "currencyUser2", Amount: amount, Currency: currency})
+		require.NoError(t, err)
+
+		resp, respBody, err := client.SendJsonReq(tokens["currencyUser1"], http.MethodPost, BaseURL+"/api/sendCoin", reqBody)
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+	info := func(t *testing.T, username string) models.InfoDTO {
+		resp, respBody, err := client.SendJsonReq(tokens[username], http.MethodGet, BaseURL+"/api/info", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		infoDTO := models.InfoDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &infoDTO))
+
+		return infoDTO
+	}
+
+	t.Run("success_new_user_has_coins_wallet_only", func(t *testing.T) {
+		infoDTO := info(t, "currencyUser2")
+		require.Equal(t, int64(1000), infoDTO.Coins)
+		require.Equal(t, []models.WalletDTO{{Currency: models.CurrencyCoins, Amount: 1000, Available: 1000}}, infoDTO.Wallets)
+	})
+
+	t.Run("error_grant_unknown_currency", func(t *testing.T) {
+		resp, respBody := grant(t, "gold")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrUnknownCurrency, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("success_kudos_granted", func(t *testing.T) {
+		resp, _ := grant(t, "kudos")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		infoDTO := info(t, "currencyUser1")
+		require.Equal(t, int64(1000), infoDTO.Coins)
+		require.Equal(t, []models.WalletDTO{
+			{Currency: models.CurrencyCoins, Amount: 1000, Available: 1000},
+			{Currency: "kudos", Amount: 20, Available: 20},
+		}, infoDTO.Wallets)
+		require.ElementsMatch(t, []models.ReceivedDTO{
+			{FromUser: models.TreasuryAccount, Amount: 20, Type: models.HistoryTypeGrant, Currency: "kudos"},
+		}, infoDTO.CoinsHistory.Received)
+	})
+
+	t.Run("error_send_unknown_currency", func(t *testing.T) {
+		resp, respBody := send(t, 1, "gold")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrUnknownCurrency, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("error_send_more_kudos_than_wallet_has", func(t *testing.T) {
+		resp, respBody := send(t, 21, "kudos")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrNotEnoughCoins, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("success_send_kudos", func(t *testing.T) {
+		resp, _ := send(t, 8, "kudos")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		sender := info(t, "currencyUser1")
+		require.Equal(t, int64(1000), sender.Coins)
+		require.Equal(t, []models.WalletDTO{
+			{Currency: models.CurrencyCoins, Amount: 1000, Available: 1000},
+			{Currency: "kudos", Amount: 12, Available: 12},
+		}, sender.Wallets)
+		require.ElementsMatch(t, []models.SentDTO{
+			{ToUser: "currencyUser2", Amount: 8, Type: models.HistoryTypeTransfer, Currency: "kudos"},
+		}, sender.CoinsHistory.Sent)
+
+		recipient := info(t, "currencyUser2")
+		require.Equal(t, []models.WalletDTO{
+			{Currency: models.CurrencyCoins, Amount: 1000, Available: 1000},
+			{Currency: "kudos", Amount: 8, Available: 8},
+		}, recipient.Wallets)
+	})
+
+	t.Run("success_coins_by_default", func(t *testing.T) {
+		resp, _ := send(t, 100, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		recipient := info(t, "currencyUser2")
+		require.Equal(t, int64(1100), recipient.Coins)
+		require.Contains(t, recipient.CoinsHistory.Received, models.ReceivedDTO{FromUser: "currencyUser1", Amount: 100, Type: models.HistoryTypeTransfer})
+	})
+
+	t.Run("success_merch_bought_with_kudos", func(t *testing.T) {
+		resp, _, err := client.SendJsonReq(tokens["currencyUser2"], http.MethodGet, BaseURL+"/api/buy/kudos-sticker", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		infoDTO := info(t, "currencyUser2")
+		require.Equal(t, int64(1100), infoDTO.Coins)
+		require.Equal(t, []models.WalletDTO{
+			{Currency: models.CurrencyCoins, Amount: 1100, Available: 1100},
+			{Currency: "kudos", Amount: 3, Available: 3},
+		}, infoDTO.Wallets)
+		require.Equal(t, []models.MerchDTO{{Type: "kudos-sticker", Quantity: 1}}, infoDTO.Inventory)
+
+		// на оставшиеся 3 благодарности стикер не купить
+		resp, _, err = client.SendJsonReq(tokens["currencyUser2"], http.MethodGet, BaseURL+"/api/buy/kudos-sticker", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("success_admin_sees_transaction_currency", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(adminToken, http.MethodGet, fmt.Sprintf("%s/api/admin/users/%s/transactions", BaseURL, "currencyUser2"), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		transactions := []models.TransactionDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &transactions))
+		currencies := map[string]string{}
+		for _, tr := range transactions {
+			currencies[fmt.Sprintf("%s:%d", tr.Type, tr.Amount)] = tr.Currency
+		}
+		require.Equal(t, map[string]string{
+			"purchase:5":   "kudos",
+			"transfer:100": models.CurrencyCoins,
+			"transfer:8":   "kudos",
+		}, currencies)
+	})
+}
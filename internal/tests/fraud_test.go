@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestFraudDetection() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	tokens := map[string]string{}
+	for _, username := range []string{"fraudUser1", "fraudUser2", "fraudUser3"} {
+		tokens[username] = login(t, &client, username)
+	}
+
+	login(t, &client, "fraudAdmin")
+	_, err := s.dbPool.Exec(ctx, `UPDATE shop."user" SET role = 'admin' WHERE username = 'fraudAdmin'`)
+	require.NoError(t, err)
+	adminToken := login(t, &client, "fraudAdmin")
+
+	send := func(t *testing.T, sender, recipient string) *http.Response {
+		reqBody, err := json.Marshal(models.SendCoinsReqBody{Recipient: recipient, Amount: 10})
+		require.NoError(t, err)
+
+		resp, _, err := client.SendJsonReq(tokens[sender], http.MethodPost, BaseURL+"/api/sendCoin", reqBody)
+		require.NoError(t, err)
+
+		return resp
+	}
+
+	// монеты возвращаются к отправителю через двух посредников
+	require.Equal(t, http.StatusOK, send(t, "fraudUser1", "fraudUser2").StatusCode)
+	require.Equal(t, http.StatusOK, send(t, "fraudUser2", "fraudUser3").StatusCode)
+	require.Equal(t, http.StatusOK, send(t, "fraudUser3", "fraudUser1").StatusCode)
+
+	require.NoError(t, s.jobs.DetectFraud(ctx))
+	require.NoError(t, s.jobs.DetectFraud(ctx))
+
+	cycleCase := models.FraudCaseDTO{}
+	t.Run("success_cycle_case_created_once", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(adminToken, http.MethodGet, BaseURL+"/api/admin/fraud/cases", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		cases := []models.FraudCaseDTO{}
+		err = json.Unmarshal(respBody, &cases)
+		require.NoError(t, err)
+
+		found := 0
+		for _, fc := range cases {
+			if fc.Rule == models.FraudRuleCycle && strings.Join(fc.Usernames, ",") == "fraudUser1,fraudUser2,fraudUser3" {
+				cycleCase = fc
+				found++
+			}
+		}
+		require.Equal(t, 1, found)
+		require.Equal(t, models.FraudCaseStatusOpen, cycleCase.Status)
+	})
+
+	t.Run("error_cases_by_regular_user", func(t *testing.T) {
+		resp, _, err := client.SendJsonReq(tokens["fraudUser1"], http.MethodGet, BaseURL+"/api/admin/fraud/cases", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("success_confirmed_case_blocks_sending", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(adminToken, http.MethodPost, fmt.Sprintf("%s/api/admin/fraud/cases/%d/confirm", BaseURL, cycleCase.ID), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resolved := models.FraudCaseDTO{}
+		err = json.Unmarshal(respBody, &resolved)
+		require.NoError(t, err)
+		require.Equal(t, models.FraudCaseStatusConfirmed, resolved.Status)
+		require.Equal(t, "fraudAdmin", resolved.ResolvedBy)
+
+		require.Equal(t, http.StatusForbidden, send(t, "fraudUser1", "fraudAdmin").StatusCode)
+	})
+
+	t.Run("error_case_already_resolved", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(adminToken, http.MethodPost, fmt.Sprintf("%s/api/admin/fraud/cases/%d/dismiss", BaseURL, cycleCase.ID), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrFraudCaseResolved, strings.TrimSpace(string(respBody)))
+	})
+}
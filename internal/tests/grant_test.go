@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/devWaylander/coins_store/internal/repo"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestAdminGrants() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	userToken := login(t, &client, "grantUser1")
+	login(t, &client, "grantUser2")
+
+	// роль администратора выдаётся только через БД
+	login(t, &client, "grantAdmin")
+	_, err := s.dbPool.Exec(ctx, `UPDATE shop."user" SET role = 'admin' WHERE username = 'grantAdmin'`)
+	require.NoError(t, err)
+	adminToken := login(t, &client, "grantAdmin")
+
+	reqBody, err := json.Marshal(models.GrantReqBody{
+		Usernames: []string{"grantUser1", "grantUser2", "grantUserInvalid"},
+		Amount:    100,
+		Reason:    "hackathon",
+	})
+	require.NoError(t, err)
+
+	t.Run("error_grant_by_regular_user", func(t *testing.T) {
+		resp, _, err := client.SendJsonReq(userToken, http.MethodPost, BaseURL+"/api/admin/grants", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("success_grant_coins", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(adminToken, http.MethodPost, BaseURL+"/api/admin/grants", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		result := models.GrantResultDTO{}
+		err = json.Unmarshal(respBody, &result)
+		require.NoError(t, err)
+		require.Equal(t, int64(200), result.Total)
+		require.Len(t, result.Invalid, 1)
+
+		resp, respBody, err = client.SendJsonReq(userToken, http.MethodGet, BaseURL+"/api/info", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respInfoData := models.InfoDTO{}
+		err = json.Unmarshal(respBody, &respInfoData)
+		require.NoError(t, err)
+		require.Equal(t, int64(1100), respInfoData.Coins)
+		require.ElementsMatch(t, []models.ReceivedDTO{{FromUser: models.TreasuryAccount, Amount: 100, Type: models.HistoryTypeGrant}}, respInfoData.CoinsHistory.Received)
+	})
+
+	t.Run("success_allowance_paid_once_per_period", func(t *testing.T) {
+		require.NoError(t, s.jobs.PayAllowance(ctx))
+		require.NoError(t, s.jobs.PayAllowance(ctx))
+
+		var runs int64
+		err := s.dbPool.QueryRow(ctx, `SELECT COUNT(*) FROM shop."allowance_run"`).Scan(&runs)
+		require.NoError(t, err)
+		require.LessOrEqual(t, runs, int64(1))
+	})
+
+	t.Run("success_frozen_user_skipped_by_allowance", func(t *testing.T) {
+		var orgID int64
+		err := s.dbPool.QueryRow(ctx, `SELECT org_id FROM shop."user" WHERE username = 'grantUser2'`).Scan(&orgID)
+		require.NoError(t, err)
+		orgCtx := context.WithValue(ctx, models.OrgIDKey, orgID)
+
+		_, err = s.dbPool.Exec(ctx, `UPDATE shop."user" SET status = $1 WHERE username = 'grantUser2'`, models.UserStatusFrozen)
+		require.NoError(t, err)
+		defer func() {
+			_, err := s.dbPool.Exec(ctx, `UPDATE shop."user" SET status = $1 WHERE username = 'grantUser2'`, models.UserStatusActive)
+			require.NoError(t, err)
+		}()
+
+		usernames, err := repo.New(s.dbPool).GetActiveUsernames(orgCtx)
+		require.NoError(t, err)
+		require.Contains(t, usernames, "grantUser1")
+		require.NotContains(t, usernames, "grantUser2")
+	})
+
+	t.Run("success_system_account_created_once", func(t *testing.T) {
+		var orgID int64
+		err := s.dbPool.QueryRow(ctx, `SELECT org_id FROM shop."user" WHERE username = 'grantUser1'`).Scan(&orgID)
+		require.NoError(t, err)
+		orgCtx := context.WithValue(ctx, models.OrgIDKey, orgID)
+
+		countSystemBalances := func() int64 {
+			var count int64
+			err := s.dbPool.QueryRow(ctx, `SELECT COUNT(*) FROM shop."balance" WHERE is_system AND org_id = $1`, orgID).Scan(&count)
+			require.NoError(t, err)
+			return count
+		}
+
+		usecaseRepo := repo.New(s.dbPool)
+		first, err := usecaseRepo.GetSystemBalanceID(orgCtx, "grantTestAccount", models.CurrencyCoins)
+		require.NoError(t, err)
+		before := countSystemBalances()
+
+		second, err := usecaseRepo.GetSystemBalanceID(orgCtx, "grantTestAccount", models.CurrencyCoins)
+		require.NoError(t, err)
+		require.Equal(t, first, second)
+		require.Equal(t, before, countSystemBalances())
+	})
+}
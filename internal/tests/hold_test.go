@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestHolds() {
+	t := s.T()
+	client := HttpClient{}
+
+	ownerToken := login(t, &client, "holdUser1")
+	recipientToken := login(t, &client, "holdUser2")
+
+	getInfo := func(t *testing.T, token string) models.InfoDTO {
+		resp, respBody, err := client.SendJsonReq(token, http.MethodGet, BaseURL+"/api/info", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respInfoData := models.InfoDTO{}
+		err = json.Unmarshal(respBody, &respInfoData)
+		require.NoError(t, err)
+
+		return respInfoData
+	}
+
+	holdDTO := models.HoldDTO{}
+
+	t.Run("success_create_hold", func(t *testing.T) {
+		reqBody, err := json.Marshal(models.HoldReqBody{Recipient: "holdUser2", Amount: 800, Reference: "auction:1", TTL: "1h"})
+		require.NoError(t, err)
+
+		resp, respBody, err := client.SendJsonReq(ownerToken, http.MethodPost, BaseURL+"/api/holds", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		err = json.Unmarshal(respBody, &holdDTO)
+		require.NoError(t, err)
+		require.Equal(t, models.HoldStatusActive, holdDTO.Status)
+
+		info := getInfo(t, ownerToken)
+		require.Equal(t, int64(1000), info.Coins)
+		require.Equal(t, int64(200), info.Available)
+	})
+
+	t.Run("error_held_coins_cannot_be_spent", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(ownerToken, http.MethodGet, BaseURL+"/api/buy/hoody", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrNotEnoughCoins, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("success_capture_hold", func(t *testing.T) {
+		resp, _, err := client.SendJsonReq(ownerToken, http.MethodPost, fmt.Sprintf("%s/api/holds/%d/capture", BaseURL, holdDTO.ID), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		ownerInfo := getInfo(t, ownerToken)
+		require.Equal(t, int64(200), ownerInfo.Coins)
+		require.Equal(t, int64(200), ownerInfo.Available)
+
+		recipientInfo := getInfo(t, recipientToken)
+		require.Equal(t, int64(1800), recipientInfo.Coins)
+	})
+
+	t.Run("error_capture_hold_twice", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(ownerToken, http.MethodPost, fmt.Sprintf("%s/api/holds/%d/capture", BaseURL, holdDTO.ID), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrHoldNotActive, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("success_recipient_releases_hold", func(t *testing.T) {
+		reqBody, err := json.Marshal(models.HoldReqBody{Recipient: "holdUser1", Amount: 500})
+		require.NoError(t, err)
+
+		resp, respBody, err := client.SendJsonReq(recipientToken, http.MethodPost, BaseURL+"/api/holds", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		hold := models.HoldDTO{}
+		err = json.Unmarshal(respBody, &hold)
+		require.NoError(t, err)
+
+		resp, _, err = client.SendJsonReq(ownerToken, http.MethodPost, fmt.Sprintf("%s/api/holds/%d/release", BaseURL, hold.ID), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		info := getInfo(t, recipientToken)
+		require.Equal(t, info.Coins, info.Available)
+	})
+}
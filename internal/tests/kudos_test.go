@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestKudosFeed() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	tokens := map[string]string{}
+	for _, username := range []string{"kudosAlice", "kudosBob", "kudosCarol"} {
+		tokens[username] = login(t, &client, username)
+	}
+
+	login(t, &client, "kudosAdmin")
+	_, err := s.dbPool.Exec(ctx, `UPDATE shop."user" SET role = 'admin' WHERE username = 'kudosAdmin'`)
+	require.NoError(t, err)
+	tokens["kudosAdmin"] = login(t, &client, "kudosAdmin")
+
+	call := func(t *testing.T, username, method, path string, body any) (*http.Response, []byte) {
+		reqBody := []byte{}
+		if body != nil {
+			var err error
+			reqBody, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+
+		resp, respBody, err := client.SendJsonReq(tokens[username], method, BaseURL+path, reqBody)
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+	getFeed := func(t *testing.T, query string) models.KudosFeedDTO {
+		resp, respBody := call(t, "kudosCarol", http.MethodGet, "/api/kudos?"+query, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		feedDTO := models.KudosFeedDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &feedDTO))
+
+		return feedDTO
+	}
+	findKudos := func(t *testing.T, message string) (models.KudosDTO, bool) {
+		for _, kudos := range getFeed(t, "limit=100").Items {
+			if kudos.Message == message {
+				return kudos, true
+			}
+		}
+		return models.KudosDTO{}, false
+	}
+
+	sends := []models.SendCoinsReqBody{
+		{Recipient: "kudosBob", Amount: 10, Public: true, Message: "за помощь с релизом"},
+		{Recipient: "kudosBob", Amount: 20, Message: "приватная благодарность"},
+		{Recipient: "kudosCarol", Amount: 5, Public: true, Message: "за ревью"},
+		{Recipient: "kudosCarol", Amount: 7, Public: true, Message: "за демо"},
+	}
+	for _, body := range sends {
+		resp, _ := call(t, "kudosAlice", http.MethodPost, "/api/sendCoin", body)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	t.Run("success_only_public_transfers_in_feed", func(t *testing.T) {
+		feedDTO := getFeed(t, "")
+		messages := []string{}
+		for _, kudos := range feedDTO.Items {
+			messages = append(messages, kudos.Message)
+		}
+		require.Equal(t, []string{"за демо", "за ревью", "за помощь с релизом"}, messages)
+		require.Empty(t, feedDTO.NextCursor)
+		require.Equal(t, "kudosAlice", feedDTO.Items[2].FromUser)
+		require.Equal(t, "kudosBob", feedDTO.Items[2].ToUser)
+		require.Equal(t, int64(10), feedDTO.Items[2].Amount)
+	})
+
+	t.Run("success_cursor_pagination", func(t *testing.T) {
+		first := getFeed(t, "limit=2")
+		require.Len(t, first.Items, 2)
+		require.NotEmpty(t, first.NextCursor)
+
+		second := getFeed(t, "limit=2&cursor="+url.QueryEscape(first.NextCursor))
+		require.Len(t, second.Items, 1)
+		require.Equal(t, "за помощь с релизом", second.Items[0].Message)
+		require.Empty(t, second.NextCursor)
+	})
+
+	t.Run("success_reactions", func(t *testing.T) {
+		kudos, ok := findKudos(t, "за помощь с релизом")
+		require.True(t, ok)
+
+		path := fmt.Sprintf("/api/kudos/%d/reactions/%s", kudos.ID, url.PathEscape("🎉"))
+		for _, username := range []string{"kudosCarol", "kudosCarol", "kudosBob"} {
+			resp, _ := call(t, username, http.MethodPut, path, nil)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+
+		kudos, _ = findKudos(t, "за помощь с релизом")
+		require.Equal(t, []models.KudosReactionDTO{{Emoji: "🎉", Count: 2, Reacted: true}}, kudos.Reactions)
+
+		resp, _ := call(t, "kudosCarol", http.MethodDelete, path, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		kudos, _ = findKudos(t, "за помощь с релизом")
+		require.Equal(t, []models.KudosReactionDTO{{Emoji: "🎉", Count: 1, Reacted: false}}, kudos.Reactions)
+	})
+
+	t.Run("success_boost", func(t *testing.T) {
+		kudos, ok := findKudos(t, "за помощь с релизом")
+		require.True(t, ok)
+
+		resp, _ := call(t, "kudosCarol", http.MethodPost, fmt.Sprintf("/api/kudos/%d/boost", kudos.ID), models.KudosBoostReqBody{Amount: 15})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		kudos, _ = findKudos(t, "за помощь с релизом")
+		require.Equal(t, int64(15), kudos.Boosted)
+		require.Equal(t, int64(1), kudos.Boosts)
+
+		resp, respBody := call(t, "kudosBob", http.MethodGet, "/api/info", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		infoDTO := models.InfoDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &infoDTO))
+		require.Equal(t, int64(1045), infoDTO.Coins)
+	})
+
+	t.Run("error_boost_own_kudos", func(t *testing.T) {
+		kudos, _ := findKudos(t, "за помощь с релизом")
+		resp, respBody := call(t, "kudosAlice", http.MethodPost, fmt.Sprintf("/api/kudos/%d/boost", kudos.ID), models.KudosBoostReqBody{Amount: 1})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrKudosBoostOwn, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("error_invalid_reaction", func(t *testing.T) {
+		kudos, _ := findKudos(t, "за помощь с релизом")
+		resp, respBody := call(t, "kudosCarol", http.MethodPut, fmt.Sprintf("/api/kudos/%d/reactions/%s", kudos.ID, url.PathEscape("💩")), nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrInvalidKudosReaction, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("error_public_transfer_without_message", func(t *testing.T) {
+		resp, respBody := call(t, "kudosAlice", http.MethodPost, "/api/sendCoin", models.SendCoinsReqBody{Recipient: "kudosBob", Amount: 1, Public: true, Message: "  "})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrInvalidSendCoinsReqParams, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("error_invalid_cursor", func(t *testing.T) {
+		resp, respBody := call(t, "kudosCarol", http.MethodGet, "/api/kudos?cursor=broken", nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrInvalidKudosReqParams, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("success_reversed_transfer_hidden", func(t *testing.T) {
+		kudos, ok := findKudos(t, "за демо")
+		require.True(t, ok)
+
+		var entryID int64
+		err := s.dbPool.QueryRow(ctx, `SELECT MIN(id) FROM shop."balance_history" WHERE kudos_id = $1`, kudos.ID).Scan(&entryID)
+		require.NoError(t, err)
+
+		resp, _ := call(t, "kudosAdmin", http.MethodPost, fmt.Sprintf("/api/admin/transactions/%d/reverse", entryID), models.ReversalReqBody{Reason: "mistaken transfer"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, ok = findKudos(t, "за демо")
+		require.False(t, ok)
+
+		resp, respBody := call(t, "kudosBob", http.MethodPost, fmt.Sprintf("/api/kudos/%d/boost", kudos.ID), models.KudosBoostReqBody{Amount: 1})
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrKudosNotFound, strings.TrimSpace(string(respBody)))
+	})
+}
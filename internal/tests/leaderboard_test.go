@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestLeaderboard() {
+	t := s.T()
+	client := HttpClient{}
+
+	tokens := map[string]string{}
+	for _, username := range []string{"lbAlice", "lbBob", "lbCarol", "lbDave"} {
+		tokens[username] = login(t, &client, username)
+	}
+
+	call := func(t *testing.T, username, method, path string, body any) (*http.Response, []byte) {
+		reqBody := []byte{}
+		if body != nil {
+			var err error
+			reqBody, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+
+		resp, respBody, err := client.SendJsonReq(tokens[username], method, BaseURL+path, reqBody)
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+
+	// рейтинг по команде не зависит от переводов в других тестах
+	resp, respBody := call(t, "lbAlice", http.MethodPost, "/api/teams", models.CreateTeamReqBody{Name: "leaderboard"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	teamDTO := models.TeamDTO{}
+	require.NoError(t, json.Unmarshal(respBody, &teamDTO))
+	for _, username := range []string{"lbBob", "lbCarol"} {
+		resp, _ := call(t, "lbAlice", http.MethodPut, fmt.Sprintf("/api/teams/%d/members/%s", teamDTO.ID, username), models.TeamMemberReqBody{})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	transfers := []struct {
+		from, to string
+		amount   int64
+	}{
+		{"lbAlice", "lbBob", 50},
+		{"lbAlice", "lbCarol", 30},
+		{"lbAlice", "lbCarol", 20},
+		{"lbBob", "lbCarol", 100},
+		{"lbCarol", "lbBob", 10},
+	}
+	for _, tr := range transfers {
+		resp, _ := call(t, tr.from, http.MethodPost, "/api/sendCoin", models.SendCoinsReqBody{Recipient: tr.to, Amount: tr.amount})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	getLeaderboard := func(t *testing.T, username, query string) models.LeaderboardDTO {
+		resp, respBody := call(t, username, http.MethodGet, "/api/leaderboard?"+query, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		leaderboardDTO := models.LeaderboardDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &leaderboardDTO))
+
+		return leaderboardDTO
+	}
+
+	t.Run("success_received", func(t *testing.T) {
+		leaderboardDTO := getLeaderboard(t, "lbBob", fmt.Sprintf("team=%d", teamDTO.ID))
+		require.Equal(t, models.LeaderboardMetricReceived, leaderboardDTO.Metric)
+		require.Equal(t, models.LeaderboardPeriodWeek, leaderboardDTO.Period)
+		require.NotNil(t, leaderboardDTO.From)
+		require.Equal(t, []models.LeaderboardEntryDTO{
+			{Rank: 1, Username: "lbCarol", Value: 150},
+			{Rank: 2, Username: "lbBob", Value: 60},
+		}, leaderboardDTO.Entries)
+	})
+
+	t.Run("success_given", func(t *testing.T) {
+		leaderboardDTO := getLeaderboard(t, "lbBob", fmt.Sprintf("team=%d&metric=given&period=month", teamDTO.ID))
+		require.Equal(t, []models.LeaderboardEntryDTO{
+			{Rank: 1, Username: "lbAlice", Value: 100},
+			{Rank: 1, Username: "lbBob", Value: 100},
+			{Rank: 3, Username: "lbCarol", Value: 10},
+		}, leaderboardDTO.Entries)
+	})
+
+	t.Run("success_thanked", func(t *testing.T) {
+		leaderboardDTO := getLeaderboard(t, "lbBob", fmt.Sprintf("team=%d&metric=thanked&period=all&limit=2", teamDTO.ID))
+		require.Nil(t, leaderboardDTO.From)
+		require.Equal(t, []models.LeaderboardEntryDTO{
+			{Rank: 1, Username: "lbAlice", Value: 2},
+			{Rank: 2, Username: "lbBob", Value: 1},
+		}, leaderboardDTO.Entries)
+	})
+
+	t.Run("success_organization_leaderboard", func(t *testing.T) {
+		leaderboardDTO := getLeaderboard(t, "lbDave", "metric=thanked&limit=100")
+		usernames := []string{}
+		for _, entry := range leaderboardDTO.Entries {
+			usernames = append(usernames, entry.Username)
+		}
+		require.Contains(t, usernames, "lbAlice")
+		require.NotContains(t, usernames, "lbDave")
+	})
+
+	t.Run("success_opt_out", func(t *testing.T) {
+		resp, _ := call(t, "lbCarol", http.MethodPut, "/api/leaderboard/optOut", models.LeaderboardOptOutReqBody{OptOut: true})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		leaderboardDTO := getLeaderboard(t, "lbBob", fmt.Sprintf("team=%d", teamDTO.ID))
+		require.Equal(t, []models.LeaderboardEntryDTO{
+			{Rank: 1, Username: "lbBob", Value: 60},
+		}, leaderboardDTO.Entries)
+
+		// агрегаты копились и во время скрытия
+		resp, _ = call(t, "lbCarol", http.MethodPut, "/api/leaderboard/optOut", models.LeaderboardOptOutReqBody{OptOut: false})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		leaderboardDTO = getLeaderboard(t, "lbBob", fmt.Sprintf("team=%d", teamDTO.ID))
+		require.Equal(t, "lbCarol", leaderboardDTO.Entries[0].Username)
+	})
+
+	t.Run("error_stranger_team_leaderboard", func(t *testing.T) {
+		resp, respBody := call(t, "lbDave", http.MethodGet, fmt.Sprintf("/api/leaderboard?team=%d", teamDTO.ID), nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrTeamNotFound, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("error_invalid_params", func(t *testing.T) {
+		for _, query := range []string{"metric=spent", "period=year", "limit=0x", "limit=1000", "team=-1"} {
+			resp, respBody := call(t, "lbDave", http.MethodGet, "/api/leaderboard?"+query, nil)
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+			require.Equal(t, internalErrors.ErrInvalidLeaderboardReqParams, strings.TrimSpace(string(respBody)), query)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/config"
+	"github.com/devWaylander/coins_store/internal/repo"
+	"github.com/devWaylander/coins_store/internal/service"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestTransferLimits() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	login(t, &client, "limitUser1")
+	login(t, &client, "limitUser2")
+	login(t, &client, "limitUser3")
+
+	var senderID int64
+	err := s.dbPool.QueryRow(ctx, `SELECT id FROM shop."user" WHERE username = 'limitUser1'`).Scan(&senderID)
+	require.NoError(t, err)
+
+	// отдельный экземпляр сервиса с лимитами, чтобы не влиять на остальные тесты
+	limited := service.New(repo.New(s.dbPool), repo.NewTxManager(s.dbPool), config.Config{
+		TransferLimit: config.TransferLimit{
+			Default:        config.TransferLimits{DailyTotal: 300, ReceivedTotal: 250},
+			ReceivedPeriod: config.AllowancePeriodDaily,
+		},
+	})
+
+	send := func(recipient string, amount int64) error {
+		return limited.SendCoins(ctx, models.CoinsQuery{UserID: senderID, Amount: amount, Sender: "limitUser1", Recipient: recipient})
+	}
+
+	t.Run("success_within_limits", func(t *testing.T) {
+		require.NoError(t, send("limitUser2", 200))
+	})
+
+	t.Run("error_received_from_sender_limit", func(t *testing.T) {
+		err := send("limitUser2", 100)
+
+		limitErr := &internalErrors.LimitError{}
+		require.True(t, errors.As(err, &limitErr))
+		require.Equal(t, internalErrors.ErrReceivedFromSenderLimit, limitErr.Code)
+	})
+
+	t.Run("error_daily_limit_counts_all_recipients", func(t *testing.T) {
+		err := send("limitUser3", 150)
+
+		limitErr := &internalErrors.LimitError{}
+		require.True(t, errors.As(err, &limitErr))
+		require.Equal(t, internalErrors.ErrDailyTransferLimit, limitErr.Code)
+
+		now := time.Now().UTC()
+		tomorrow := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+		require.True(t, tomorrow.Equal(limitErr.ResetAt))
+	})
+
+	t.Run("success_remaining_daily_limit", func(t *testing.T) {
+		require.NoError(t, send("limitUser3", 100))
+	})
+}
+
+func (s *E2eIntegrationTestSuite) TestTeamTransferLimits() {
+	t := s.T()
+	client := HttpClient{}
+
+	login(t, &client, "limitTeamOwner")
+	login(t, &client, "limitTeamDonor")
+	login(t, &client, "limitTeamRecipient")
+
+	var orgID, ownerID int64
+	err := s.dbPool.QueryRow(context.Background(), `SELECT org_id, id FROM shop."user" WHERE username = 'limitTeamOwner'`).Scan(&orgID, &ownerID)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), models.OrgIDKey, orgID)
+
+	limited := service.New(repo.New(s.dbPool), repo.NewTxManager(s.dbPool), config.Config{
+		TransferLimit: config.TransferLimit{Default: config.TransferLimits{DailyTotal: 100}},
+	})
+
+	team, err := limited.CreateTeam(ctx, models.TeamQuery{UserID: ownerID, Username: "limitTeamOwner", Name: "limitqa"})
+	require.NoError(t, err)
+	require.NoError(t, limited.DepositToTeam(ctx, models.TeamTransferQuery{TeamID: team.ID, Username: "limitTeamDonor", Amount: 100}))
+
+	sendFromTeam := func(amount int64) error {
+		return limited.SendFromTeam(ctx, models.TeamTransferQuery{TeamID: team.ID, Username: "limitTeamOwner", Recipient: "limitTeamRecipient", Amount: amount})
+	}
+	requireDailyLimit := func(t *testing.T, err error) {
+		limitErr := &internalErrors.LimitError{}
+		require.True(t, errors.As(err, &limitErr))
+		require.Equal(t, internalErrors.ErrDailyTransferLimit, limitErr.Code)
+	}
+
+	t.Run("success_team_send_within_member_limit", func(t *testing.T) {
+		require.NoError(t, sendFromTeam(60))
+	})
+
+	t.Run("error_team_sends_count_toward_member_limit", func(t *testing.T) {
+		requireDailyLimit(t, sendFromTeam(50))
+	})
+
+	t.Run("error_personal_send_counts_team_sends", func(t *testing.T) {
+		err := limited.SendCoins(ctx, models.CoinsQuery{UserID: ownerID, Amount: 50, Sender: "limitTeamOwner", Recipient: "limitTeamRecipient"})
+		requireDailyLimit(t, err)
+	})
+}
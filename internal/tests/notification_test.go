@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestNotifications() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	tokens := map[string]string{}
+	for _, username := range []string{"notifAlice", "notifBob"} {
+		tokens[username] = login(t, &client, username)
+	}
+
+	login(t, &client, "notifAdmin")
+	_, err := s.dbPool.Exec(ctx, `UPDATE shop."user" SET role = 'admin' WHERE username = 'notifAdmin'`)
+	require.NoError(t, err)
+	tokens["notifAdmin"] = login(t, &client, "notifAdmin")
+
+	call := func(t *testing.T, username, method, path string, body any) (*http.Response, []byte) {
+		reqBody := []byte{}
+		if body != nil {
+			var err error
+			reqBody, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+
+		resp, respBody, err := client.SendJsonReq(tokens[username], method, BaseURL+path, reqBody)
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+	getNotifications := func(t *testing.T, username, query string) models.NotificationsDTO {
+		resp, respBody := call(t, username, http.MethodGet, "/api/notifications"+query, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		notificationsDTO := models.NotificationsDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &notificationsDTO))
+
+		return notificationsDTO
+	}
+	getUnread := func(t *testing.T, username string) int64 {
+		resp, respBody := call(t, username, http.MethodGet, "/api/notifications/unread", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		unreadDTO := models.UnreadNotificationsDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &unreadDTO))
+
+		return unreadDTO.Unread
+	}
+	types := func(notificationsDTO models.NotificationsDTO) []string {
+		result := []string{}
+		for _, item := range notificationsDTO.Items {
+			result = append(result, item.Type)
+		}
+		return result
+	}
+
+	t.Run("success_update_preferences", func(t *testing.T) {
+		threshold := int64(500)
+		resp, respBody := call(t, "notifAlice", http.MethodPut, "/api/notifications/preferences", models.NotificationPreferencesReqBody{LowBalanceThreshold: &threshold})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		preferencesDTO := models.NotificationPreferencesDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &preferencesDTO))
+		require.Equal(t, models.NotificationPreferencesDTO{CoinsReceived: true, Purchase: true, LowBalance: true, Grant: true, LowBalanceThreshold: 500}, preferencesDTO)
+
+		off := false
+		resp, _ = call(t, "notifBob", http.MethodPut, "/api/notifications/preferences", models.NotificationPreferencesReqBody{Purchase: &off})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, respBody = call(t, "notifBob", http.MethodGet, "/api/notifications/preferences", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(respBody, &preferencesDTO))
+		require.False(t, preferencesDTO.Purchase)
+		require.True(t, preferencesDTO.CoinsReceived)
+	})
+
+	t.Run("success_events_recorded", func(t *testing.T) {
+		for _, amount := range []int64{600, 10} {
+			resp, _ := call(t, "notifAlice", http.MethodPost, "/api/sendCoin", models.SendCoinsReqBody{Recipient: "notifBob", Amount: amount})
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+		for _, username := range []string{"notifAlice", "notifBob"} {
+			resp, _ := call(t, username, http.MethodGet, "/api/buy/pen", nil)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+		resp, _ := call(t, "notifAdmin", http.MethodPost, "/api/admin/grants", models.GrantReqBody{Usernames: []string{"notifBob"}, Amount: 5, Reason: "Бонус"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// низкий баланс уведомляется один раз при пересечении порога
+		aliceDTO := getNotifications(t, "notifAlice", "")
+		require.Equal(t, []string{models.NotificationTypePurchase, models.NotificationTypeLowBalance}, types(aliceDTO))
+		require.Equal(t, "pen", aliceDTO.Items[0].Item)
+		require.Equal(t, models.PurchaseStatusCompleted, aliceDTO.Items[0].Status)
+		require.Equal(t, int64(400), aliceDTO.Items[1].Amount)
+
+		// покупки у notifBob отключены
+		bobDTO := getNotifications(t, "notifBob", "")
+		require.Equal(t, []string{models.NotificationTypeGrant, models.NotificationTypeCoinsReceived, models.NotificationTypeCoinsReceived}, types(bobDTO))
+		require.Equal(t, "Бонус", bobDTO.Items[0].Message)
+		require.Equal(t, "notifAlice", bobDTO.Items[1].FromUser)
+		require.Equal(t, int64(10), bobDTO.Items[1].Amount)
+		require.Equal(t, int64(3), getUnread(t, "notifBob"))
+	})
+
+	t.Run("success_pagination", func(t *testing.T) {
+		first := getNotifications(t, "notifBob", "?limit=2")
+		require.Len(t, first.Items, 2)
+		require.NotEmpty(t, first.NextCursor)
+
+		second := getNotifications(t, "notifBob", "?limit=2&cursor="+first.NextCursor)
+		require.Len(t, second.Items, 1)
+		require.Empty(t, second.NextCursor)
+	})
+
+	t.Run("success_mark_read", func(t *testing.T) {
+		bobDTO := getNotifications(t, "notifBob", "")
+
+		resp, respBody := call(t, "notifBob", http.MethodPost, "/api/notifications/read", models.NotificationsReadReqBody{IDs: []int64{bobDTO.Items[0].ID}})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		unreadDTO := models.UnreadNotificationsDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &unreadDTO))
+		require.Equal(t, int64(2), unreadDTO.Unread)
+
+		unreadOnly := getNotifications(t, "notifBob", "?unread=true")
+		require.Len(t, unreadOnly.Items, 2)
+		require.NotEqual(t, bobDTO.Items[0].ID, unreadOnly.Items[0].ID)
+
+		// чужие уведомления не отмечаются
+		aliceDTO := getNotifications(t, "notifAlice", "")
+		resp, _ = call(t, "notifBob", http.MethodPost, "/api/notifications/read", models.NotificationsReadReqBody{IDs: []int64{aliceDTO.Items[0].ID}})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, int64(2), getUnread(t, "notifAlice"))
+
+		resp, _ = call(t, "notifBob", http.MethodPost, "/api/notifications/read", models.NotificationsReadReqBody{All: true})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Zero(t, getUnread(t, "notifBob"))
+
+		bobDTO = getNotifications(t, "notifBob", "")
+		require.True(t, bobDTO.Items[0].Read)
+		require.NotNil(t, bobDTO.Items[0].ReadAt)
+	})
+
+	t.Run("error_invalid_params", func(t *testing.T) {
+		resp, _ := call(t, "notifBob", http.MethodPost, "/api/notifications/read", models.NotificationsReadReqBody{})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = call(t, "notifBob", http.MethodGet, "/api/notifications?limit=1000", nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		negative := int64(-1)
+		resp, _ = call(t, "notifBob", http.MethodPut, "/api/notifications/preferences", models.NotificationPreferencesReqBody{LowBalanceThreshold: &negative})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
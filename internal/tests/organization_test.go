@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestOrganizationIsolation() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	// вторая организация не создаётся миграциями, её заводит демонстрационный сид
+	seed, err := os.ReadFile("../../db/seeds/acme.sql")
+	require.NoError(t, err)
+	_, err = s.dbPool.Exec(ctx, string(seed))
+	require.NoError(t, err)
+
+	// одно и то же имя в двух организациях - разные пользователи
+	defaultTwin := login(t, &client, "orgTwin")
+	acmeTwin := loginToOrganization(t, &client, "acme", "orgTwin")
+	acmeUser := loginToOrganization(t, &client, "acme", "orgAcmeUser")
+	defaultUser := login(t, &client, "orgDefaultUser")
+
+	login(t, &client, "orgAdmin")
+	_, err = s.dbPool.Exec(ctx, `UPDATE shop."user" SET role = 'admin' WHERE username = 'orgAdmin'`)
+	require.NoError(t, err)
+	adminToken := login(t, &client, "orgAdmin")
+
+	call := func(t *testing.T, token, method, path string, body any) (*http.Response, []byte) {
+		reqBody := []byte{}
+		if body != nil {
+			var err error
+			reqBody, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+
+		resp, respBody, err := client.SendJsonReq(token, method, BaseURL+path, reqBody)
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+	info := func(t *testing.T, token string) models.InfoDTO {
+		resp, respBody := call(t, token, http.MethodGet, "/api/info", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		infoDTO := models.InfoDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &infoDTO))
+
+		return infoDTO
+	}
+
+	t.Run("error_unknown_organization", func(t *testing.T) {
+		resp, respBody := call(t, "", http.MethodPost, "/api/auth", models.AuthReqBody{Organization: "unknown", Username: "orgTwin", Password: "11111!Aa"})
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrOrganizationNotFound, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("success_starting_balance_of_organization", func(t *testing.T) {
+		require.Equal(t, int64(1000), info(t, defaultTwin).Coins)
+		require.Equal(t, int64(500), info(t, acmeTwin).Coins)
+	})
+
+	t.Run("success_catalog_of_organization", func(t *testing.T) {
+		resp, respBody := call(t, acmeTwin, http.MethodGet, "/api/buy/pink-hoody", nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrItemDoesntExist, strings.TrimSpace(string(respBody)))
+
+		resp, _ = call(t, acmeTwin, http.MethodGet, "/api/buy/sticker", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, []models.MerchDTO{{Type: "sticker", Quantity: 1}}, info(t, acmeTwin).Inventory)
+
+		resp, _ = call(t, defaultTwin, http.MethodGet, "/api/buy/sticker", nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Empty(t, info(t, defaultTwin).Inventory)
+	})
+
+	t.Run("error_coins_cant_cross_organizations", func(t *testing.T) {
+		resp, respBody := call(t, defaultUser, http.MethodPost, "/api/sendCoin", models.SendCoinsReqBody{Recipient: "orgAcmeUser", Amount: 10})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrInvalidRecipient, strings.TrimSpace(string(respBody)))
+		require.Equal(t, int64(1000), info(t, defaultUser).Coins)
+		require.Equal(t, int64(500), info(t, acmeUser).Coins)
+	})
+
+	t.Run("success_same_username_resolves_inside_organization", func(t *testing.T) {
+		resp, _ := call(t, acmeUser, http.MethodPost, "/api/sendCoin", models.SendCoinsReqBody{Recipient: "orgTwin", Amount: 10})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		require.Equal(t, int64(490), info(t, acmeUser).Coins)
+		require.Equal(t, int64(500), info(t, acmeTwin).Coins)
+		require.Equal(t, int64(1000), info(t, defaultTwin).Coins)
+	})
+
+	t.Run("error_admin_cant_reach_other_organization", func(t *testing.T) {
+		resp, _ := call(t, adminToken, http.MethodPost, "/api/admin/users/orgAcmeUser/freeze", models.AccountActionReqBody{Reason: "cross tenant"})
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, respBody := call(t, adminToken, http.MethodGet, "/api/admin/users/orgAcmeUser/transactions", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		transactions := []models.TransactionDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &transactions))
+		require.Empty(t, transactions)
+
+		var entryID int64
+		err := s.dbPool.QueryRow(ctx, `
+			SELECT bh.id FROM shop."balance_history" bh
+			INNER JOIN shop."organization" o ON o.id = bh.org_id
+			WHERE o.name = 'acme' AND bh.type = 'transfer'
+			LIMIT 1
+		`).Scan(&entryID)
+		require.NoError(t, err)
+
+		resp, _ = call(t, adminToken, http.MethodPost, fmt.Sprintf("/api/admin/transactions/%d/reverse", entryID), models.ReversalReqBody{Reason: "cross tenant"})
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Equal(t, int64(490), info(t, acmeUser).Coins)
+	})
+
+	t.Run("error_hold_of_other_organization_not_found", func(t *testing.T) {
+		resp, respBody := call(t, acmeTwin, http.MethodPost, "/api/holds", models.HoldReqBody{Recipient: "orgAcmeUser", Amount: 100, TTL: "1h"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		hold := models.HoldDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &hold))
+
+		// владелец с тем же именем в другой организации не может распорядиться резервом
+		resp, _ = call(t, defaultTwin, http.MethodPost, fmt.Sprintf("/api/holds/%d/capture", hold.ID), nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp, _ = call(t, defaultTwin, http.MethodPost, fmt.Sprintf("/api/holds/%d/release", hold.ID), nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, _ = call(t, acmeTwin, http.MethodPost, fmt.Sprintf("/api/holds/%d/release", hold.ID), nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("success_same_team_name_in_each_organization", func(t *testing.T) {
+		resp, respBody := call(t, defaultTwin, http.MethodPost, "/api/teams", models.CreateTeamReqBody{Name: "org-team"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		team := models.TeamDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &team))
+
+		resp, _ = call(t, acmeTwin, http.MethodPost, "/api/teams", models.CreateTeamReqBody{Name: "org-team"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _ = call(t, acmeTwin, http.MethodGet, fmt.Sprintf("/api/teams/%d", team.ID), nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("success_jobs_run_in_every_organization", func(t *testing.T) {
+		resp, respBody := call(t, acmeUser, http.MethodPost, "/api/holds", models.HoldReqBody{Recipient: "orgTwin", Amount: 100, TTL: "1h"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		hold := models.HoldDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &hold))
+
+		_, err := s.dbPool.Exec(ctx, `UPDATE shop."balance_hold" SET expires_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, hold.ID)
+		require.NoError(t, err)
+		require.NoError(t, s.jobs.ReleaseExpiredHolds(ctx))
+
+		var status string
+		err = s.dbPool.QueryRow(ctx, `SELECT status FROM shop."balance_hold" WHERE id = $1`, hold.ID).Scan(&status)
+		require.NoError(t, err)
+		require.Equal(t, models.HoldStatusReleased, status)
+	})
+}
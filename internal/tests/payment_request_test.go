@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestPaymentRequests() {
+	t := s.T()
+	client := HttpClient{}
+
+	requesterToken := login(t, &client, "requestUser1")
+	payerToken := login(t, &client, "requestUser2")
+
+	reqBody, err := json.Marshal(models.PaymentRequestReqBody{Payer: "requestUser2", Amount: 150, Memo: "pizza"})
+	require.NoError(t, err)
+
+	requestDTO := models.PaymentRequestDTO{}
+
+	t.Run("success_create_payment_request", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(requesterToken, http.MethodPost, BaseURL+"/api/requests", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		err = json.Unmarshal(respBody, &requestDTO)
+		require.NoError(t, err)
+		require.Equal(t, models.PaymentRequestStatusPending, requestDTO.Status)
+	})
+
+	t.Run("error_duplicate_pending_request", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(requesterToken, http.MethodPost, BaseURL+"/api/requests", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrDuplicatePaymentRequest, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("success_payer_lists_incoming_requests", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(payerToken, http.MethodGet, BaseURL+"/api/requests/incoming", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		requestsDTO := []models.PaymentRequestDTO{}
+		err = json.Unmarshal(respBody, &requestsDTO)
+		require.NoError(t, err)
+		require.Len(t, requestsDTO, 1)
+		require.Equal(t, requestDTO.ID, requestsDTO[0].ID)
+	})
+
+	t.Run("success_accept_payment_request", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(payerToken, http.MethodPost, fmt.Sprintf("%s/api/requests/%d/accept", BaseURL, requestDTO.ID), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		paidDTO := models.PaymentRequestDTO{}
+		err = json.Unmarshal(respBody, &paidDTO)
+		require.NoError(t, err)
+		require.Equal(t, models.PaymentRequestStatusPaid, paidDTO.Status)
+
+		resp, respBody, err = client.SendJsonReq(requesterToken, http.MethodGet, BaseURL+"/api/info", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respInfoData := models.InfoDTO{}
+		err = json.Unmarshal(respBody, &respInfoData)
+		require.NoError(t, err)
+		require.Equal(t, int64(1150), respInfoData.Coins)
+	})
+
+	t.Run("error_accept_paid_request_again", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(payerToken, http.MethodPost, fmt.Sprintf("%s/api/requests/%d/accept", BaseURL, requestDTO.ID), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrPaymentRequestResolved, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("error_requester_cannot_accept_own_request", func(t *testing.T) {
+		resp, _, err := client.SendJsonReq(requesterToken, http.MethodPost, fmt.Sprintf("%s/api/requests/%d/decline", BaseURL, requestDTO.ID), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestQuests() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	tokens := map[string]string{}
+	for _, username := range []string{"questAlice", "questBob", "questCarol", "questDave"} {
+		tokens[username] = login(t, &client, username)
+	}
+
+	login(t, &client, "questAdmin")
+	_, err := s.dbPool.Exec(ctx, `UPDATE shop."user" SET role = 'admin' WHERE username = 'questAdmin'`)
+	require.NoError(t, err)
+	tokens["questAdmin"] = login(t, &client, "questAdmin")
+
+	call := func(t *testing.T, username, method, path string, body any) (*http.Response, []byte) {
+		reqBody := []byte{}
+		if body != nil {
+			var err error
+			reqBody, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+
+		resp, respBody, err := client.SendJsonReq(tokens[username], method, BaseURL+path, reqBody)
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+	getQuest := func(t *testing.T, username string, questID int64) (models.UserQuestDTO, bool) {
+		resp, respBody := call(t, username, http.MethodGet, "/api/quests", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		questsDTO := []models.UserQuestDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &questsDTO))
+		for _, questDTO := range questsDTO {
+			if questDTO.ID == questID {
+				return questDTO, true
+			}
+		}
+
+		return models.UserQuestDTO{}, false
+	}
+
+	now := time.Now()
+	body := models.QuestReqBody{
+		Title:     "Спасибо другим командам",
+		Metric:    models.QuestMetricDistinctRecipients,
+		OtherTeam: true,
+		Target:    2,
+		Reward:    50,
+		StartsAt:  strfmt.DateTime(now.Add(-time.Minute)),
+		EndsAt:    strfmt.DateTime(now.Add(time.Hour)),
+	}
+
+	t.Run("error_invalid_quest", func(t *testing.T) {
+		invalid := body
+		invalid.Metric = models.QuestMetricPurchases
+		resp, _ := call(t, "questAdmin", http.MethodPost, "/api/admin/quests", invalid)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("error_not_admin", func(t *testing.T) {
+		resp, _ := call(t, "questAlice", http.MethodPost, "/api/admin/quests", body)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	resp, respBody := call(t, "questAdmin", http.MethodPost, "/api/admin/quests", body)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	questDTO := models.QuestDTO{}
+	require.NoError(t, json.Unmarshal(respBody, &questDTO))
+	require.Equal(t, models.QuestEventTransfer, questDTO.Event)
+	// задание действует на всю организацию, поэтому не должно пережить тест
+	t.Cleanup(func() {
+		_, err := s.dbPool.Exec(ctx, `UPDATE shop."quest" SET deleted_at = NOW() WHERE id = $1`, questDTO.ID)
+		require.NoError(t, err)
+	})
+
+	resp, respBody = call(t, "questAlice", http.MethodPost, "/api/teams", models.CreateTeamReqBody{Name: "quest"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	teamDTO := models.TeamDTO{}
+	require.NoError(t, json.Unmarshal(respBody, &teamDTO))
+	resp, _ = call(t, "questAlice", http.MethodPut, fmt.Sprintf("/api/teams/%d/members/questBob", teamDTO.ID), models.TeamMemberReqBody{})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	sendCoins := func(t *testing.T, to string) {
+		resp, _ := call(t, "questAlice", http.MethodPost, "/api/sendCoin", models.SendCoinsReqBody{Recipient: to, Amount: 10})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	t.Run("success_teammate_and_repeated_recipient_not_counted", func(t *testing.T) {
+		sendCoins(t, "questBob")
+		sendCoins(t, "questCarol")
+		sendCoins(t, "questCarol")
+
+		userQuestDTO, ok := getQuest(t, "questAlice", questDTO.ID)
+		require.True(t, ok)
+		require.Equal(t, int64(1), userQuestDTO.Progress)
+		require.False(t, userQuestDTO.Completed)
+	})
+
+	t.Run("success_completion_pays_reward_once", func(t *testing.T) {
+		sendCoins(t, "questDave")
+		sendCoins(t, "questAdmin")
+
+		userQuestDTO, ok := getQuest(t, "questAlice", questDTO.ID)
+		require.True(t, ok)
+		require.True(t, userQuestDTO.Completed)
+
+		resp, respBody := call(t, "questAlice", http.MethodGet, "/api/info", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		infoDTO := models.InfoDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &infoDTO))
+		require.Equal(t, int64(1000-5*10+50), infoDTO.Coins)
+		require.Contains(t, infoDTO.CoinsHistory.Received, models.ReceivedDTO{
+			FromUser: models.TreasuryAccount,
+			Amount:   50,
+			Type:     models.HistoryTypeQuest,
+		})
+	})
+
+	t.Run("success_update_and_delete", func(t *testing.T) {
+		updated := body
+		updated.Target = 5
+		resp, _ := call(t, "questAdmin", http.MethodPut, fmt.Sprintf("/api/admin/quests/%d", questDTO.ID), updated)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		userQuestDTO, ok := getQuest(t, "questCarol", questDTO.ID)
+		require.True(t, ok)
+		require.Equal(t, int64(5), userQuestDTO.Target)
+		require.Zero(t, userQuestDTO.Progress)
+
+		resp, _ = call(t, "questAdmin", http.MethodDelete, fmt.Sprintf("/api/admin/quests/%d", questDTO.ID), nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		_, ok = getQuest(t, "questAlice", questDTO.ID)
+		require.False(t, ok)
+
+		resp, _ = call(t, "questAdmin", http.MethodDelete, fmt.Sprintf("/api/admin/quests/%d", questDTO.ID), nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp, _ = call(t, "questAdmin", http.MethodPut, fmt.Sprintf("/api/admin/quests/%d", questDTO.ID), updated)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
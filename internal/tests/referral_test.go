@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/devWaylander/coins_store/config"
+	"github.com/devWaylander/coins_store/internal/repo"
+	"github.com/devWaylander/coins_store/internal/service"
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestReferrals() {
+	t := s.T()
+	client := HttpClient{}
+
+	referrerToken := login(t, &client, "referrer")
+	// коллега зарегистрирован раньше приглашённого, поэтому его монеты тратятся приглашённым первыми
+	colleagueToken := login(t, &client, "referralColleague")
+
+	getReferrals := func(t *testing.T) models.ReferralsDTO {
+		resp, respBody, err := client.SendJsonReq(referrerToken, http.MethodGet, BaseURL+"/api/referrals", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		referralsDTO := models.ReferralsDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &referralsDTO))
+
+		return referralsDTO
+	}
+	register := func(t *testing.T, username, code string) (*http.Response, []byte) {
+		reqBody, err := json.Marshal(models.AuthReqBody{Username: username, Password: "11111!Aa", ReferralCode: code})
+		require.NoError(t, err)
+
+		resp, respBody, err := client.SendJsonReq("", http.MethodPost, BaseURL+"/api/auth", reqBody)
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+	getInfo := func(t *testing.T, token string) models.InfoDTO {
+		resp, respBody, err := client.SendJsonReq(token, http.MethodGet, BaseURL+"/api/info", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		infoDTO := models.InfoDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &infoDTO))
+
+		return infoDTO
+	}
+
+	code := getReferrals(t).Code
+	require.NotEmpty(t, code)
+
+	t.Run("error_unknown_referral_code", func(t *testing.T) {
+		resp, respBody := register(t, "referralStranger", "NOSUCHCODE")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrInvalidReferralCode, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("success_referral_pending_until_activity", func(t *testing.T) {
+		resp, _ := register(t, "referee1", strings.ToLower(code))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		referralsDTO := getReferrals(t)
+		require.Equal(t, int64(1), referralsDTO.Referrals)
+		require.Equal(t, int64(0), referralsDTO.Qualified)
+		require.Equal(t, "referee1", referralsDTO.Items[0].Username)
+		require.Equal(t, models.ReferralStatusPending, referralsDTO.Items[0].Status)
+	})
+
+	t.Run("success_transfer_to_referrer_does_not_qualify", func(t *testing.T) {
+		token := login(t, &client, "referee1")
+		reqBody, err := json.Marshal(models.SendCoinsReqBody{Recipient: "referrer", Amount: 1})
+		require.NoError(t, err)
+		resp, _, err := client.SendJsonReq(token, http.MethodPost, BaseURL+"/api/sendCoin", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		require.Equal(t, models.ReferralStatusPending, getReferrals(t).Items[0].Status)
+	})
+
+	// отдельный экземпляр сервиса с бонусом, чтобы не влиять на остальные тесты
+	withBonus := service.New(repo.New(s.dbPool), repo.NewTxManager(s.dbPool), config.Config{
+		Referral: config.Referral{Bonus: 100},
+	})
+	refereeCtx := func(t *testing.T) (context.Context, int64) {
+		ctx := context.Background()
+		var orgID, refereeID int64
+		err := s.dbPool.QueryRow(ctx, `SELECT org_id, id FROM shop."user" WHERE username = 'referee1'`).Scan(&orgID, &refereeID)
+		require.NoError(t, err)
+
+		return context.WithValue(ctx, models.OrgIDKey, orgID), refereeID
+	}
+
+	t.Run("success_purchase_from_starting_balance_does_not_qualify", func(t *testing.T) {
+		ctx, refereeID := refereeCtx(t)
+		require.NoError(t, withBonus.BuyItem(ctx, models.ItemQuery{UserID: refereeID, Username: "referee1", Item: "pen"}))
+
+		require.Equal(t, models.ReferralStatusPending, getReferrals(t).Items[0].Status)
+	})
+
+	t.Run("success_bonus_paid_after_purchase", func(t *testing.T) {
+		reqBody, err := json.Marshal(models.SendCoinsReqBody{Recipient: "referee1", Amount: 20})
+		require.NoError(t, err)
+		resp, _, err := client.SendJsonReq(colleagueToken, http.MethodPost, BaseURL+"/api/sendCoin", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		ctx, refereeID := refereeCtx(t)
+		require.NoError(t, withBonus.BuyItem(ctx, models.ItemQuery{UserID: refereeID, Username: "referee1", Item: "pen"}))
+		// бонус выплачивается один раз
+		require.NoError(t, withBonus.BuyItem(ctx, models.ItemQuery{UserID: refereeID, Username: "referee1", Item: "pen"}))
+
+		referralsDTO := getReferrals(t)
+		require.Equal(t, int64(1), referralsDTO.Qualified)
+		require.Equal(t, int64(100), referralsDTO.BonusEarned)
+		require.Equal(t, models.ReferralStatusQualified, referralsDTO.Items[0].Status)
+		require.NotNil(t, referralsDTO.Items[0].QualifiedAt)
+
+		bonuses := 0
+		for _, received := range getInfo(t, referrerToken).CoinsHistory.Received {
+			if received.Type == models.HistoryTypeReferral {
+				require.Equal(t, models.TreasuryAccount, received.FromUser)
+				require.Equal(t, int64(100), received.Amount)
+				bonuses++
+			}
+		}
+		require.Equal(t, 1, bonuses)
+
+		// 1000 - 1 переведённая пригласившему - 3 ручки по 10 + 20 от коллеги + 100 бонуса
+		require.Equal(t, int64(1089), getInfo(t, login(t, &client, "referee1")).Coins)
+	})
+
+	t.Run("success_referral_code_ignored_on_login", func(t *testing.T) {
+		resp, _ := register(t, "referee1", "NOSUCHCODE")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _ = register(t, "referrer", code)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, int64(1), getReferrals(t).Referrals)
+	})
+}
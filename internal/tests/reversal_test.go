@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestTransactionReversal() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	tokens := map[string]string{}
+	for _, username := range []string{"reversalUser1", "reversalUser2"} {
+		tokens[username] = login(t, &client, username)
+	}
+
+	login(t, &client, "reversalAdmin")
+	_, err := s.dbPool.Exec(ctx, `UPDATE shop."user" SET role = 'admin' WHERE username = 'reversalAdmin'`)
+	require.NoError(t, err)
+	adminToken := login(t, &client, "reversalAdmin")
+
+	send := func(t *testing.T, sender, recipient string, amount int64) {
+		reqBody, err := json.Marshal(models.SendCoinsReqBody{Recipient: recipient, Amount: amount})
+		require.NoError(t, err)
+
+		resp, _, err := client.SendJsonReq(tokens[sender], http.MethodPost, BaseURL+"/api/sendCoin", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	lastTransfer := func(t *testing.T, username string) models.TransactionDTO {
+		resp, respBody, err := client.SendJsonReq(adminToken, http.MethodGet, fmt.Sprintf("%s/api/admin/users/%s/transactions", BaseURL, username), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		transactions := []models.TransactionDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &transactions))
+		for _, tr := range transactions {
+			if tr.Type == models.HistoryTypeTransfer {
+				return tr
+			}
+		}
+		t.Fatalf("no transfers for %s", username)
+		return models.TransactionDTO{}
+	}
+	reverse := func(t *testing.T, entryID int64) (*http.Response, []byte) {
+		reqBody, err := json.Marshal(models.ReversalReqBody{Reason: "mistaken transfer"})
+		require.NoError(t, err)
+
+		resp, respBody, err := client.SendJsonReq(adminToken, http.MethodPost, fmt.Sprintf("%s/api/admin/transactions/%d/reverse", BaseURL, entryID), reqBody)
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+	info := func(t *testing.T, username string) models.InfoDTO {
+		resp, respBody, err := client.SendJsonReq(tokens[username], http.MethodGet, BaseURL+"/api/info", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		infoDTO := models.InfoDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &infoDTO))
+
+		return infoDTO
+	}
+
+	send(t, "reversalUser1", "reversalUser2", 500)
+	transfer := lastTransfer(t, "reversalUser2")
+
+	t.Run("success_transfer_reversed", func(t *testing.T) {
+		resp, respBody := reverse(t, transfer.ID)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		reversal := models.ReversalDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &reversal))
+		require.Equal(t, models.ReversalDTO{
+			FromUser:   "reversalUser2",
+			ToUser:     "reversalUser1",
+			Amount:     500,
+			Currency:   models.CurrencyCoins,
+			Reason:     "mistaken transfer",
+			ReversedBy: "reversalAdmin",
+		}, reversal)
+
+		senderInfo := info(t, "reversalUser1")
+		require.Equal(t, int64(1000), senderInfo.Coins)
+		require.ElementsMatch(t, []models.SentDTO{{ToUser: "reversalUser2", Amount: 500, Type: models.HistoryTypeTransfer, Reversed: true}}, senderInfo.CoinsHistory.Sent)
+		require.ElementsMatch(t, []models.ReceivedDTO{{FromUser: "reversalUser2", Amount: 500, Type: models.HistoryTypeReversal}}, senderInfo.CoinsHistory.Received)
+
+		recipientInfo := info(t, "reversalUser2")
+		require.Equal(t, int64(1000), recipientInfo.Coins)
+		require.ElementsMatch(t, []models.ReceivedDTO{{FromUser: "reversalUser1", Amount: 500, Type: models.HistoryTypeTransfer, Reversed: true}}, recipientInfo.CoinsHistory.Received)
+		require.ElementsMatch(t, []models.SentDTO{{ToUser: "reversalUser1", Amount: 500, Type: models.HistoryTypeReversal}}, recipientInfo.CoinsHistory.Sent)
+	})
+
+	t.Run("error_already_reversed_by_other_side", func(t *testing.T) {
+		senderTransfer := lastTransfer(t, "reversalUser1")
+		require.True(t, senderTransfer.Reversed)
+
+		resp, respBody := reverse(t, senderTransfer.ID)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrTransactionAlreadyReversed, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("error_recipient_spent_coins", func(t *testing.T) {
+		send(t, "reversalUser1", "reversalUser2", 300)
+		transfer := lastTransfer(t, "reversalUser2")
+		send(t, "reversalUser2", "reversalUser1", 1100)
+
+		resp, respBody := reverse(t, transfer.ID)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrReversalNotEnoughCoins, strings.TrimSpace(string(respBody)))
+		require.Equal(t, int64(200), info(t, "reversalUser2").Coins)
+	})
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestSchedules() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	senderToken := login(t, &client, "scheduleUser1")
+	recipientToken := login(t, &client, "scheduleUser2")
+
+	scheduleDTO := models.ScheduleDTO{}
+
+	t.Run("success_create_recurring_schedule", func(t *testing.T) {
+		runAt := strfmt.DateTime(time.Now().Add(-time.Minute))
+		reqBody, err := json.Marshal(models.ScheduleReqBody{
+			Recipient: "scheduleUser2",
+			Amount:    50,
+			RunAt:     &runAt,
+			Interval:  "168h",
+		})
+		require.NoError(t, err)
+
+		resp, respBody, err := client.SendJsonReq(senderToken, http.MethodPost, BaseURL+"/api/schedules", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		err = json.Unmarshal(respBody, &scheduleDTO)
+		require.NoError(t, err)
+		require.Equal(t, models.ScheduleStatusActive, scheduleDTO.Status)
+		require.Equal(t, "168h0m0s", scheduleDTO.Interval)
+	})
+
+	t.Run("success_due_schedule_executed_once_across_replicas", func(t *testing.T) {
+		// несколько реплик одновременно забирают расписания
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				require.NoError(t, s.jobs.ExecuteDueSchedules(ctx))
+			}()
+		}
+		wg.Wait()
+
+		resp, respBody, err := client.SendJsonReq(senderToken, http.MethodGet, fmt.Sprintf("%s/api/schedules/%d/runs", BaseURL, scheduleDTO.ID), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		runs := []models.ScheduleRunDTO{}
+		err = json.Unmarshal(respBody, &runs)
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		require.Equal(t, models.ScheduleRunStatusSuccess, runs[0].Status)
+
+		resp, respBody, err = client.SendJsonReq(recipientToken, http.MethodGet, BaseURL+"/api/info", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respInfoData := models.InfoDTO{}
+		err = json.Unmarshal(respBody, &respInfoData)
+		require.NoError(t, err)
+		require.Equal(t, int64(1050), respInfoData.Coins)
+		require.ElementsMatch(t, []models.ReceivedDTO{{FromUser: "scheduleUser1", Amount: 50, Type: "transfer"}}, respInfoData.CoinsHistory.Received)
+	})
+
+	t.Run("success_schedule_paused_after_repeated_failures", func(t *testing.T) {
+		amount := int64(100000)
+		runAt := strfmt.DateTime(time.Now().Add(-time.Minute))
+		reqBody, err := json.Marshal(models.ScheduleUpdateReqBody{Amount: &amount, RunAt: &runAt})
+		require.NoError(t, err)
+
+		resp, _, err := client.SendJsonReq(senderToken, http.MethodPut, fmt.Sprintf("%s/api/schedules/%d", BaseURL, scheduleDTO.ID), reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// переносим следующий запуск в прошлое, чтобы не ждать интервал
+		for i := 0; i < 3; i++ {
+			_, err = s.dbPool.Exec(ctx, `UPDATE shop."transfer_schedule" SET next_run_at = NOW() - ($1 * INTERVAL '1 minute') WHERE id = $2`, i+1, scheduleDTO.ID)
+			require.NoError(t, err)
+			require.NoError(t, s.jobs.ExecuteDueSchedules(ctx))
+		}
+
+		resp, respBody, err := client.SendJsonReq(senderToken, http.MethodGet, BaseURL+"/api/schedules", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		schedules := []models.ScheduleDTO{}
+		err = json.Unmarshal(respBody, &schedules)
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+		require.Equal(t, models.ScheduleStatusPaused, schedules[0].Status)
+		require.Equal(t, int64(3), schedules[0].ConsecutiveFailures)
+	})
+
+	t.Run("success_delete_schedule", func(t *testing.T) {
+		resp, _, err := client.SendJsonReq(senderToken, http.MethodDelete, fmt.Sprintf("%s/api/schedules/%d", BaseURL, scheduleDTO.ID), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _, err = client.SendJsonReq(senderToken, http.MethodDelete, fmt.Sprintf("%s/api/schedules/%d", BaseURL, scheduleDTO.ID), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
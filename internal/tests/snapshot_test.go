@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestBalanceAt() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	tokens := map[string]string{}
+	for _, username := range []string{"snapshotUser1", "snapshotUser2"} {
+		tokens[username] = login(t, &client, username)
+	}
+
+	login(t, &client, "snapshotAdmin")
+	_, err := s.dbPool.Exec(ctx, `UPDATE shop."user" SET role = 'admin' WHERE username = 'snapshotAdmin'`)
+	require.NoError(t, err)
+	adminToken := login(t, &client, "snapshotAdmin")
+
+	send := func(t *testing.T, amount int64) {
+		reqBody, err := json.Marshal(models.SendCoinsReqBody{Recipient: "snapshotUser2", Amount: amount})
+		require.NoError(t, err)
+
+		resp, _, err := client.SendJsonReq(tokens["snapshotUser1"], http.MethodPost, BaseURL+"/api/sendCoin", reqBody)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	balanceAt := func(t *testing.T, at time.Time) models.BalanceAtDTO {
+		resp, respBody, err := client.SendJsonReq(adminToken, http.MethodGet, fmt.Sprintf("%s/api/admin/users/snapshotUser1/balance?at=%s", BaseURL, url.QueryEscape(at.Format(time.RFC3339))), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		balanceAtDTO := models.BalanceAtDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &balanceAtDTO))
+
+		return balanceAtDTO
+	}
+
+	// аккаунты зарегистрированы 5 суток назад, первый перевод совершён 3 суток назад
+	now := time.Now()
+	_, err = s.dbPool.Exec(ctx, `UPDATE shop."user" SET created_at = $1 WHERE username LIKE 'snapshotUser%'`, now.AddDate(0, 0, -5))
+	require.NoError(t, err)
+	send(t, 100)
+	_, err = s.dbPool.Exec(ctx, `UPDATE shop."balance_history" SET created_at = $1 WHERE sender = 'snapshotUser1'`, now.AddDate(0, 0, -3))
+	require.NoError(t, err)
+
+	require.NoError(t, s.jobs.SnapshotBalances(ctx))
+	require.NoError(t, s.jobs.SnapshotBalances(ctx))
+	send(t, 50)
+
+	t.Run("success_current_balance_from_yesterday_snapshot", func(t *testing.T) {
+		balance := balanceAt(t, time.Now().Add(time.Minute))
+		require.Equal(t, int64(850), balance.Amount)
+		require.Equal(t, time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly), balance.SnapshotDay)
+		require.Equal(t, int64(1), balance.ReplayedEntries)
+	})
+
+	t.Run("success_balance_after_first_transfer", func(t *testing.T) {
+		balance := balanceAt(t, now.AddDate(0, 0, -2))
+		require.Equal(t, int64(900), balance.Amount)
+		require.NotEmpty(t, balance.SnapshotDay)
+	})
+
+	t.Run("success_balance_before_first_transfer", func(t *testing.T) {
+		require.Equal(t, int64(1000), balanceAt(t, now.AddDate(0, 0, -4)).Amount)
+	})
+
+	t.Run("success_balance_before_registration", func(t *testing.T) {
+		balance := balanceAt(t, now.AddDate(0, 0, -6))
+		require.Equal(t, int64(0), balance.Amount)
+		require.Empty(t, balance.SnapshotDay)
+	})
+
+	t.Run("error_invalid_time", func(t *testing.T) {
+		resp, _, err := client.SendJsonReq(adminToken, http.MethodGet, BaseURL+"/api/admin/users/snapshotUser1/balance?at=yesterday", []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
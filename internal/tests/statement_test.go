@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestStatement() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	tokens := map[string]string{}
+	for _, username := range []string{"statementUser1", "statementUser2"} {
+		tokens[username] = login(t, &client, username)
+	}
+
+	login(t, &client, "statementAdmin")
+	_, err := s.dbPool.Exec(ctx, `UPDATE shop."user" SET role = 'admin' WHERE username = 'statementAdmin'`)
+	require.NoError(t, err)
+	adminToken := login(t, &client, "statementAdmin")
+
+	from := time.Now().Add(-time.Minute)
+
+	reqBody, err := json.Marshal(models.SendCoinsReqBody{Recipient: "statementUser2", Amount: 150})
+	require.NoError(t, err)
+	resp, _, err := client.SendJsonReq(tokens["statementUser1"], http.MethodPost, BaseURL+"/api/sendCoin", reqBody)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _, err = client.SendJsonReq(tokens["statementUser1"], http.MethodGet, BaseURL+"/api/buy/cup", []byte{})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	statementURL := func(path, format string) string {
+		values := url.Values{}
+		values.Set("from", from.Format(time.RFC3339))
+		values.Set("format", format)
+		return BaseURL + path + "?" + values.Encode()
+	}
+
+	t.Run("success_jsonl_statement", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(tokens["statementUser1"], http.MethodGet, statementURL("/api/statement", models.StatementFormatJSONL), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+		lines := []models.StatementLineDTO{}
+		scanner := bufio.NewScanner(bytes.NewReader(respBody))
+		for scanner.Scan() {
+			line := models.StatementLineDTO{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+			lines = append(lines, line)
+		}
+		require.NoError(t, scanner.Err())
+
+		require.Len(t, lines, 4)
+		require.Equal(t, models.StatementRecordOpening, lines[0].Record)
+		require.Equal(t, int64(1000), lines[0].Balance)
+
+		require.Equal(t, models.StatementDirectionDebit, lines[1].Direction)
+		require.Equal(t, "statementUser2", lines[1].Counterparty)
+		require.Equal(t, int64(850), lines[1].Balance)
+
+		require.Equal(t, models.HistoryTypePurchase, lines[2].Type)
+		require.Equal(t, "cup", lines[2].Item)
+		require.Equal(t, int64(830), lines[2].Balance)
+
+		require.Equal(t, models.StatementRecordClosing, lines[3].Record)
+		require.Equal(t, int64(830), lines[3].Balance)
+	})
+
+	t.Run("success_csv_statement_by_admin", func(t *testing.T) {
+		resp, respBody, err := client.SendJsonReq(adminToken, http.MethodGet, statementURL("/api/admin/users/statementUser2/statement", models.StatementFormatCSV), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		records, err := csv.NewReader(bytes.NewReader(respBody)).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 4)
+		require.Equal(t, models.StatementCSVHeader, records[0])
+		require.Equal(t, []string{models.StatementRecordOpening, "1000"}, []string{records[1][0], records[1][9]})
+		require.Equal(t, []string{models.StatementDirectionCredit, "150", "statementUser1", "1150"}, []string{records[2][4], records[2][5], records[2][6], records[2][9]})
+		require.Equal(t, []string{models.StatementRecordClosing, "1150"}, []string{records[3][0], records[3][9]})
+	})
+
+	t.Run("error_invalid_format", func(t *testing.T) {
+		resp, _, err := client.SendJsonReq(tokens["statementUser1"], http.MethodGet, statementURL("/api/statement", "xml"), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("error_statement_by_regular_user", func(t *testing.T) {
+		resp, _, err := client.SendJsonReq(tokens["statementUser1"], http.MethodGet, statementURL("/api/admin/users/statementUser2/statement", models.StatementFormatCSV), []byte{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/devWaylander/coins_store/pkg/errors"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestTeamWallets() {
+	t := s.T()
+	client := HttpClient{}
+
+	tokens := map[string]string{}
+	for _, username := range []string{"teamOwner", "teamMember", "teamDonor", "teamStranger"} {
+		tokens[username] = login(t, &client, username)
+	}
+
+	call := func(t *testing.T, username, method, path string, body any) (*http.Response, []byte) {
+		reqBody := []byte{}
+		if body != nil {
+			var err error
+			reqBody, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+
+		resp, respBody, err := client.SendJsonReq(tokens[username], method, BaseURL+path, reqBody)
+		require.NoError(t, err)
+
+		return resp, respBody
+	}
+
+	resp, respBody := call(t, "teamOwner", http.MethodPost, "/api/teams", models.CreateTeamReqBody{Name: "qa"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	teamDTO := models.TeamDTO{}
+	require.NoError(t, json.Unmarshal(respBody, &teamDTO))
+	require.Equal(t, "qa", teamDTO.Name)
+	require.Equal(t, models.TeamRoleOwner, teamDTO.Role)
+	require.True(t, teamDTO.CanSpend)
+	teamPath := fmt.Sprintf("/api/teams/%d", teamDTO.ID)
+
+	getTeam := func(t *testing.T, username string) models.TeamDTO {
+		resp, respBody := call(t, username, http.MethodGet, teamPath, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		teamDTO := models.TeamDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &teamDTO))
+
+		return teamDTO
+	}
+
+	t.Run("error_team_name_taken", func(t *testing.T) {
+		resp, respBody := call(t, "teamDonor", http.MethodPost, "/api/teams", models.CreateTeamReqBody{Name: "qa"})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrTeamNameTaken, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("error_stranger_cant_see_team", func(t *testing.T) {
+		resp, _ := call(t, "teamStranger", http.MethodGet, teamPath, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("success_member_added", func(t *testing.T) {
+		resp, respBody := call(t, "teamOwner", http.MethodPut, teamPath+"/members/teamMember", models.TeamMemberReqBody{})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		memberDTO := models.TeamMemberDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &memberDTO))
+		require.Equal(t, models.TeamMemberDTO{Username: "teamMember", Role: models.TeamRoleMember}, memberDTO)
+
+		resp, _ = call(t, "teamMember", http.MethodGet, "/api/teams", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("success_anyone_deposits", func(t *testing.T) {
+		resp, _ := call(t, "teamDonor", http.MethodPost, teamPath+"/deposit", models.TeamDepositReqBody{Amount: 100})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, int64(100), getTeam(t, "teamOwner").Coins)
+	})
+
+	t.Run("error_member_without_permission_cant_spend", func(t *testing.T) {
+		resp, respBody := call(t, "teamMember", http.MethodPost, teamPath+"/sendCoin", models.TeamSendCoinsReqBody{Recipient: "teamDonor", Amount: 10})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrTeamForbidden, strings.TrimSpace(string(respBody)))
+
+		resp, _ = call(t, "teamMember", http.MethodGet, teamPath+"/buy/cup", nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("success_member_with_permission_sends", func(t *testing.T) {
+		resp, _ := call(t, "teamOwner", http.MethodPut, teamPath+"/members/teamMember", models.TeamMemberReqBody{CanSpend: true})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _ = call(t, "teamMember", http.MethodPost, teamPath+"/sendCoin", models.TeamSendCoinsReqBody{Recipient: "teamDonor", Amount: 10})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, respBody := call(t, "teamDonor", http.MethodGet, "/api/info", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		infoDTO := models.InfoDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &infoDTO))
+		require.Equal(t, int64(910), infoDTO.Coins)
+	})
+
+	t.Run("success_purchases_into_team_and_member_inventories", func(t *testing.T) {
+		resp, _ := call(t, "teamOwner", http.MethodGet, teamPath+"/buy/cup", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, _ = call(t, "teamOwner", http.MethodGet, teamPath+"/buy/cup?forUser=teamMember", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _ = call(t, "teamOwner", http.MethodGet, teamPath+"/buy/cup?forUser=teamStranger", nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, respBody := call(t, "teamMember", http.MethodGet, "/api/info", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		infoDTO := models.InfoDTO{}
+		require.NoError(t, json.Unmarshal(respBody, &infoDTO))
+		require.Equal(t, int64(1000), infoDTO.Coins)
+		require.Equal(t, []models.MerchDTO{{Type: "cup", Quantity: 1}}, infoDTO.Inventory)
+
+		team := getTeam(t, "teamMember")
+		require.Equal(t, int64(50), team.Coins)
+		require.Equal(t, []models.MerchDTO{{Type: "cup", Quantity: 1}}, team.Inventory)
+	})
+
+	t.Run("success_history_records_acting_member", func(t *testing.T) {
+		team := getTeam(t, "teamOwner")
+		actors := map[string]string{}
+		for _, tr := range team.History {
+			actors[fmt.Sprintf("%s:%s:%d", tr.Type, tr.FromUser, tr.Amount)] += tr.Actor + ";"
+		}
+		require.Equal(t, map[string]string{
+			"transfer:teamDonor:100": "teamDonor;",
+			"transfer:team:qa:10":    "teamMember;",
+			"purchase:team:qa:20":    "teamOwner;teamOwner;",
+		}, actors)
+		require.ElementsMatch(t, []models.TeamMemberDTO{
+			{Username: "teamOwner", Role: models.TeamRoleOwner, CanSpend: true},
+			{Username: "teamMember", Role: models.TeamRoleMember, CanSpend: true},
+		}, team.Members)
+	})
+
+	t.Run("error_last_owner_cant_leave", func(t *testing.T) {
+		resp, respBody := call(t, "teamOwner", http.MethodDelete, teamPath+"/members/teamOwner", nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, internalErrors.ErrTeamLastOwner, strings.TrimSpace(string(respBody)))
+	})
+
+	t.Run("success_member_leaves", func(t *testing.T) {
+		resp, _ := call(t, "teamMember", http.MethodDelete, teamPath+"/members/teamMember", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _ = call(t, "teamMember", http.MethodGet, teamPath, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
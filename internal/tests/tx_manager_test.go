@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/devWaylander/coins_store/internal/repo"
+	"github.com/devWaylander/coins_store/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *E2eIntegrationTestSuite) TestTxManager() {
+	t := s.T()
+	ctx := context.Background()
+	client := HttpClient{}
+
+	login(t, &client, "txUser1")
+
+	usecaseRepo := repo.New(s.dbPool)
+	txManager := repo.NewTxManager(s.dbPool)
+
+	var balance models.Balance
+	err := s.dbPool.QueryRow(ctx, `SELECT u.balance_id, b.amount FROM shop."user" u INNER JOIN shop."balance" b ON u.balance_id = b.id WHERE u.username = $1`, "txUser1").
+		Scan(&balance.ID, &balance.Amount)
+	require.NoError(t, err)
+
+	t.Run("fail_closure_error_rolls_back", func(t *testing.T) {
+		errClosure := errors.New("closure error")
+		err := txManager.Do(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context) error {
+			if err := usecaseRepo.DebitBalance(ctx, balance.ID, 100); err != nil {
+				return err
+			}
+
+			amount, err := usecaseRepo.GetBalanceAmountByUserID(ctx, mustUserID(t, s, "txUser1"))
+			require.NoError(t, err)
+			require.Equal(t, balance.Amount-100, amount)
+
+			return errClosure
+		})
+		require.ErrorIs(t, err, errClosure)
+
+		amount, err := usecaseRepo.GetBalanceAmountByUserID(ctx, mustUserID(t, s, "txUser1"))
+		require.NoError(t, err)
+		require.Equal(t, balance.Amount, amount)
+	})
+
+	t.Run("success_nested_do_error_rolls_back_savepoint_only", func(t *testing.T) {
+		errNested := errors.New("nested error")
+		err := txManager.Do(ctx, models.TxOptions{IsoLevel: models.ReadCommitted}, func(ctx context.Context) error {
+			nestedErr := txManager.Do(ctx, models.TxOptions{}, func(ctx context.Context) error {
+				if err := usecaseRepo.DebitBalance(ctx, balance.ID, 100); err != nil {
+					return err
+				}
+				return errNested
+			})
+			require.ErrorIs(t, nestedErr, errNested)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		amount, err := usecaseRepo.GetBalanceAmountByUserID(ctx, mustUserID(t, s, "txUser1"))
+		require.NoError(t, err)
+		require.Equal(t, balance.Amount, amount)
+	})
+
+	t.Run("success_nested_do_commits_with_outer_transaction", func(t *testing.T) {
+		err := txManager.Do(ctx, models.TxOptions{IsoLevel: models.Serializable}, func(ctx context.Context) error {
+			return txManager.Do(ctx, models.TxOptions{}, func(ctx context.Context) error {
+				return usecaseRepo.DebitBalance(ctx, balance.ID, 100)
+			})
+		})
+		require.NoError(t, err)
+
+		amount, err := usecaseRepo.GetBalanceAmountByUserID(ctx, mustUserID(t, s, "txUser1"))
+		require.NoError(t, err)
+		require.Equal(t, balance.Amount-100, amount)
+	})
+}
+
+func mustUserID(t *testing.T, s *E2eIntegrationTestSuite, username string) int64 {
+	var userID int64
+	err := s.dbPool.QueryRow(context.Background(), `SELECT id FROM shop."user" WHERE username = $1`, username).Scan(&userID)
+	require.NoError(t, err)
+
+	return userID
+}
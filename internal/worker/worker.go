@@ -0,0 +1,29 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/devWaylander/coins_store/pkg/log"
+)
+
+// Run периодически вызывает fn до отмены контекста.
+// Ошибка fn логируется и не останавливает воркер.
+func Run(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) error {
+	log.Logger.Info().Msgf("Worker %s is up with interval: %s", name, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Logger.Info().Msgf("Worker %s is shutting down", name)
+			return nil
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				log.Logger.Err(err).Msgf("worker %s failed: %s", name, err.Error())
+			}
+		}
+	}
+}
@@ -6,7 +6,15 @@ const (
 	ErrMarshalResponse   = "ERR_FAILED_TO_ENCODE_JSON_RESP"
 	ErrDecodeCtx         = "ERR_FAILED_TO_DECODE_CONTEXT_CLAIMS"
 	// ===================-  USER  -===================
-	ErrUserNotFound = "ERR_USER_NOT_FOUND"
+	ErrUserNotFound            = "ERR_USER_NOT_FOUND"
+	ErrAccountFrozen           = "ERR_ACCOUNT_FROZEN"
+	ErrAccountSuspended        = "ERR_ACCOUNT_SUSPENDED"
+	ErrAccountOffboarded       = "ERR_ACCOUNT_OFFBOARDED"
+	ErrAccountOnFraudHold      = "ERR_ACCOUNT_ON_FRAUD_HOLD"
+	ErrInvalidAccountReqParams = "ERR_INVALID_ACCOUNT_REQ_PARAMS"
+	ErrAccountStatusUnchanged  = "ERR_ACCOUNT_STATUS_UNCHANGED"
+	ErrChangeAccountStatus     = "ERR_CHANGE_ACCOUNT_STATUS"
+	ErrGetAccountAudit         = "ERR_GET_ACCOUNT_AUDIT"
 	// ===================-  AUTH  -===================
 	ErrInvalidAuthReqParams = "ERR_INVALID_AUTH_REQ_PARAMS"
 	ErrWrongPassword        = "ERR_WRONG_PASSWORD"
@@ -16,6 +24,8 @@ const (
 	ErrInvalidToken         = "ERR_INVALID_AUTH_TOKEN"
 	ErrInvalidClaims        = "ERR_CANNOT_PARSE_CLAIMS"
 	ErrLogin                = "ERR_FAILED_TO_LOGIN"
+	ErrForbidden            = "ERR_FORBIDDEN"
+	ErrOrganizationNotFound = "ERR_ORGANIZATION_NOT_FOUND"
 	// ===================-  INFO  -===================
 	ErrGetInfo = "ERR_GET_INFO"
 	// ===================-  BUY ITEM  -===================
@@ -27,4 +37,106 @@ const (
 	ErrInvalidRecipient          = "ERR_RECIPIENT_DOESNT_EXIST"
 	ErrInvalidRecipientYourself  = "ERR_RECIPIENT_IS_YOURSELF"
 	ErrNotEnoughCoins            = "ERR_NOT_ENOUGH_COINS"
+	ErrInvalidBulkTransfers      = "ERR_INVALID_BULK_TRANSFERS"
+	ErrTooManyBulkTransfers      = "ERR_TOO_MANY_BULK_TRANSFERS"
+	ErrSendCoins                 = "ERR_SEND_COINS"
+	ErrUnknownCurrency           = "ERR_UNKNOWN_CURRENCY"
+	// ===================-  TRANSFER LIMIT  -===================
+	ErrTransferAmountLimit      = "ERR_TRANSFER_AMOUNT_LIMIT_EXCEEDED"
+	ErrDailyTransferLimit       = "ERR_DAILY_TRANSFER_LIMIT_EXCEEDED"
+	ErrWeeklyTransferLimit      = "ERR_WEEKLY_TRANSFER_LIMIT_EXCEEDED"
+	ErrHourlyTransferCountLimit = "ERR_HOURLY_TRANSFER_COUNT_LIMIT_EXCEEDED"
+	ErrReceivedFromSenderLimit  = "ERR_RECEIVED_FROM_SENDER_LIMIT_EXCEEDED"
+	// ===================-  SCHEDULE  -===================
+	ErrInvalidScheduleReqParams = "ERR_INVALID_SCHEDULE_REQ_PARAMS"
+	ErrScheduleNotFound         = "ERR_SCHEDULE_NOT_FOUND"
+	ErrScheduleCompleted        = "ERR_SCHEDULE_COMPLETED"
+	ErrGetSchedules             = "ERR_GET_SCHEDULES"
+	ErrCreateSchedule           = "ERR_CREATE_SCHEDULE"
+	ErrUpdateSchedule           = "ERR_UPDATE_SCHEDULE"
+	ErrDeleteSchedule           = "ERR_DELETE_SCHEDULE"
+	// ===================-  GRANT  -===================
+	ErrInvalidGrantReqParams = "ERR_INVALID_GRANT_REQ_PARAMS"
+	ErrInvalidGrantCSV       = "ERR_INVALID_GRANT_CSV"
+	ErrInvalidGrantAmount    = "ERR_INVALID_GRANT_AMOUNT"
+	ErrGrantCoins            = "ERR_GRANT_COINS"
+	// ===================-  PAYMENT REQUEST  -===================
+	ErrInvalidPaymentRequestReqParams = "ERR_INVALID_PAYMENT_REQUEST_REQ_PARAMS"
+	ErrPaymentRequestNotFound         = "ERR_PAYMENT_REQUEST_NOT_FOUND"
+	ErrPaymentRequestResolved         = "ERR_PAYMENT_REQUEST_ALREADY_RESOLVED"
+	ErrPaymentRequestExpired          = "ERR_PAYMENT_REQUEST_EXPIRED"
+	ErrDuplicatePaymentRequest        = "ERR_DUPLICATE_PAYMENT_REQUEST"
+	ErrGetPaymentRequests             = "ERR_GET_PAYMENT_REQUESTS"
+	ErrCreatePaymentRequest           = "ERR_CREATE_PAYMENT_REQUEST"
+	ErrResolvePaymentRequest          = "ERR_RESOLVE_PAYMENT_REQUEST"
+	// ===================-  HOLD  -===================
+	ErrInvalidHoldReqParams = "ERR_INVALID_HOLD_REQ_PARAMS"
+	ErrHoldNotFound         = "ERR_HOLD_NOT_FOUND"
+	ErrHoldNotActive        = "ERR_HOLD_NOT_ACTIVE"
+	ErrGetHolds             = "ERR_GET_HOLDS"
+	ErrCreateHold           = "ERR_CREATE_HOLD"
+	ErrResolveHold          = "ERR_RESOLVE_HOLD"
+	// ===================-  FRAUD  -===================
+	ErrInvalidFraudCaseReqParams = "ERR_INVALID_FRAUD_CASE_REQ_PARAMS"
+	ErrFraudCaseNotFound         = "ERR_FRAUD_CASE_NOT_FOUND"
+	ErrFraudCaseResolved         = "ERR_FRAUD_CASE_ALREADY_RESOLVED"
+	ErrGetFraudCases             = "ERR_GET_FRAUD_CASES"
+	ErrResolveFraudCase          = "ERR_RESOLVE_FRAUD_CASE"
+	// ===================-  REVERSAL  -===================
+	ErrInvalidReversalReqParams   = "ERR_INVALID_REVERSAL_REQ_PARAMS"
+	ErrTransactionNotFound        = "ERR_TRANSACTION_NOT_FOUND"
+	ErrTransactionNotReversible   = "ERR_TRANSACTION_NOT_REVERSIBLE"
+	ErrTransactionAlreadyReversed = "ERR_TRANSACTION_ALREADY_REVERSED"
+	ErrReversalNotEnoughCoins     = "ERR_REVERSAL_NOT_ENOUGH_COINS"
+	ErrGetTransactions            = "ERR_GET_TRANSACTIONS"
+	ErrReverseTransaction         = "ERR_REVERSE_TRANSACTION"
+	// ===================-  STATEMENT  -===================
+	ErrInvalidStatementReqParams = "ERR_INVALID_STATEMENT_REQ_PARAMS"
+	ErrGetStatement              = "ERR_GET_STATEMENT"
+	// ===================-  SNAPSHOT  -===================
+	ErrInvalidBalanceAtReqParams = "ERR_INVALID_BALANCE_AT_REQ_PARAMS"
+	ErrGetBalanceAt              = "ERR_GET_BALANCE_AT"
+	// ===================-  TEAM  -===================
+	ErrInvalidTeamReqParams    = "ERR_INVALID_TEAM_REQ_PARAMS"
+	ErrTeamNameTaken           = "ERR_TEAM_NAME_TAKEN"
+	ErrTeamNotFound            = "ERR_TEAM_NOT_FOUND"
+	ErrTeamForbidden           = "ERR_TEAM_FORBIDDEN"
+	ErrTeamMemberNotFound      = "ERR_TEAM_MEMBER_NOT_FOUND"
+	ErrTeamLastOwner           = "ERR_TEAM_LAST_OWNER"
+	ErrTeamCurrencyUnsupported = "ERR_TEAM_CURRENCY_UNSUPPORTED"
+	ErrTeam                    = "ERR_TEAM"
+	// ===================-  LEADERBOARD  -===================
+	ErrInvalidLeaderboardReqParams = "ERR_INVALID_LEADERBOARD_REQ_PARAMS"
+	ErrGetLeaderboard              = "ERR_GET_LEADERBOARD"
+	ErrSetLeaderboardOptOut        = "ERR_SET_LEADERBOARD_OPT_OUT"
+	// ===================-  ACHIEVEMENT  -===================
+	ErrGetProfile = "ERR_GET_PROFILE"
+	// ===================-  KUDOS  -===================
+	ErrInvalidKudosReqParams = "ERR_INVALID_KUDOS_REQ_PARAMS"
+	ErrKudosNotFound         = "ERR_KUDOS_NOT_FOUND"
+	ErrKudosBoostOwn         = "ERR_KUDOS_BOOST_OWN"
+	ErrInvalidKudosReaction  = "ERR_INVALID_KUDOS_REACTION"
+	ErrGetKudosFeed          = "ERR_GET_KUDOS_FEED"
+	ErrBoostKudos            = "ERR_BOOST_KUDOS"
+	ErrReactKudos            = "ERR_REACT_KUDOS"
+	// ===================-  REFERRAL  -===================
+	ErrInvalidReferralCode = "ERR_INVALID_REFERRAL_CODE"
+	ErrGetReferrals        = "ERR_GET_REFERRALS"
+	// ===================-  CHECK IN  -===================
+	ErrAlreadyCheckedIn = "ERR_ALREADY_CHECKED_IN"
+	ErrCheckInDisabled  = "ERR_CHECK_IN_DISABLED"
+	ErrCheckIn          = "ERR_CHECK_IN"
+	// ===================-  QUEST  -===================
+	ErrInvalidQuestReqParams = "ERR_INVALID_QUEST_REQ_PARAMS"
+	ErrQuestNotFound         = "ERR_QUEST_NOT_FOUND"
+	ErrCreateQuest           = "ERR_CREATE_QUEST"
+	ErrGetQuests             = "ERR_GET_QUESTS"
+	ErrUpdateQuest           = "ERR_UPDATE_QUEST"
+	ErrDeleteQuest           = "ERR_DELETE_QUEST"
+	// ===================-  NOTIFICATION  -===================
+	ErrInvalidNotificationReqParams  = "ERR_INVALID_NOTIFICATION_REQ_PARAMS"
+	ErrGetNotifications              = "ERR_GET_NOTIFICATIONS"
+	ErrReadNotifications             = "ERR_READ_NOTIFICATIONS"
+	ErrGetNotificationPreferences    = "ERR_GET_NOTIFICATION_PREFERENCES"
+	ErrUpdateNotificationPreferences = "ERR_UPDATE_NOTIFICATION_PREFERENCES"
 )
@@ -0,0 +1,14 @@
+package errors
+
+import "time"
+
+// LimitError превышение лимита переводов. Error возвращает код ошибки,
+// ResetAt - момент сброса лимита, нулевой для лимита на сумму одного перевода.
+type LimitError struct {
+	Code    string
+	ResetAt time.Time
+}
+
+func (e *LimitError) Error() string {
+	return e.Code
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+)
+
+// Метрики не публикуются в глобальный набор expvar: он содержит cmdline и memstats процесса,
+// которые не должны видеть администраторы организаций
+var (
+	// TxRetries кол-во повторов транзакций по SQLSTATE ошибки (40001, 40P01)
+	TxRetries = new(expvar.Map)
+	// TxRetriesExhausted кол-во транзакций, упавших после исчерпания повторов
+	TxRetriesExhausted = new(expvar.Int)
+	// BalanceLocks кол-во захватов блокировок балансов
+	BalanceLocks = new(expvar.Int)
+	// BalanceLockWaitMicros суммарное время ожидания блокировок балансов
+	BalanceLockWaitMicros = new(expvar.Int)
+)
+
+var vars = func() *expvar.Map {
+	m := new(expvar.Map)
+	m.Set("tx_retries", TxRetries)
+	m.Set("tx_retries_exhausted", TxRetriesExhausted)
+	m.Set("balance_locks", BalanceLocks)
+	m.Set("balance_lock_wait_us", BalanceLockWaitMicros)
+	return m
+}()
+
+// Handler отдаёт метрики транзакций и блокировок в формате JSON
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprintln(w, vars.String())
+	})
+}
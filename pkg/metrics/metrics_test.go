@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	TxRetries.Add("40001", 1)
+	TxRetriesExhausted.Add(1)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/admin/debug/vars", nil))
+
+	got := map[string]json.RawMessage{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Handler() body = %s, error = %v", rec.Body.String(), err)
+	}
+	for _, name := range []string{"tx_retries", "tx_retries_exhausted", "balance_locks", "balance_lock_wait_us"} {
+		if _, ok := got[name]; !ok {
+			t.Errorf("Handler() missing %s", name)
+		}
+	}
+	// переменные процесса из глобального набора expvar не отдаются
+	for _, name := range []string{"cmdline", "memstats"} {
+		if _, ok := got[name]; ok {
+			t.Errorf("Handler() exposes %s", name)
+		}
+	}
+	if string(got["tx_retries_exhausted"]) != "1" {
+		t.Errorf("Handler() tx_retries_exhausted = %s, want 1", got["tx_retries_exhausted"])
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	AccountActionFreeze   = "freeze"
+	AccountActionSuspend  = "suspend"
+	AccountActionActivate = "activate"
+	AccountActionOffboard = "offboard"
+)
+
+// AccountState ограничения аккаунта, проверяемые перед списанием монет
+type AccountState struct {
+	Status    string
+	FraudHold bool
+	Deleted   bool
+}
+
+type AccountActionReqBody struct {
+	Reason string `json:"reason"`
+	// SweepCoins только для offboard: перевести остаток монет в казначейство
+	SweepCoins bool `json:"sweepCoins"`
+}
+
+type AccountQuery struct {
+	Admin      string `json:"admin"`
+	Username   string `json:"username"`
+	Action     string `json:"action"`
+	Reason     string `json:"reason"`
+	SweepCoins bool   `json:"sweep_coins"`
+}
+
+type AccountDTO struct {
+	Username    string `json:"username"`
+	Status      string `json:"status"`
+	Offboarded  bool   `json:"offboarded"`
+	SweptAmount int64  `json:"sweptAmount"`
+}
+
+type AccountAuditDB struct {
+	ID          int64           `db:"id"`
+	UserID      int64           `db:"user_id"`
+	Action      string          `db:"action"`
+	Reason      string          `db:"reason"`
+	Actor       string          `db:"actor"`
+	SweptAmount int64           `db:"swept_amount"`
+	CreatedAt   strfmt.DateTime `db:"created_at"`
+}
+
+func (aadb *AccountAuditDB) ToModelAccountAudit() AccountAudit {
+	return AccountAudit{
+		ID:          aadb.ID,
+		UserID:      aadb.UserID,
+		Action:      aadb.Action,
+		Reason:      aadb.Reason,
+		Actor:       aadb.Actor,
+		SweptAmount: aadb.SweptAmount,
+		CreatedAt:   time.Time(aadb.CreatedAt),
+	}
+}
+
+type AccountAudit struct {
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"user_id"`
+	Action      string    `json:"action"`
+	Reason      string    `json:"reason"`
+	Actor       string    `json:"actor"`
+	SweptAmount int64     `json:"swept_amount"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (aa *AccountAudit) ToModelAccountAuditDTO() AccountAuditDTO {
+	return AccountAuditDTO{
+		Action:      aa.Action,
+		Reason:      aa.Reason,
+		Actor:       aa.Actor,
+		SweptAmount: aa.SweptAmount,
+		CreatedAt:   strfmt.DateTime(aa.CreatedAt),
+	}
+}
+
+type AccountAuditDTO struct {
+	Action      string          `json:"action"`
+	Reason      string          `json:"reason"`
+	Actor       string          `json:"actor"`
+	SweptAmount int64           `json:"sweptAmount,omitempty"`
+	CreatedAt   strfmt.DateTime `json:"createdAt"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// События, на которые реагируют достижения
+const (
+	AchievementEventTransfer = "transfer"
+	AchievementEventPurchase = "purchase"
+	AchievementEventSignIn   = "sign_in"
+)
+
+// Счётчики активности пользователя в shop.user_activity
+const (
+	ActivitySignIns       = "sign_ins"
+	ActivityTransfersSent = "transfers_sent"
+	ActivityPurchases     = "purchases"
+)
+
+// Achievement определение достижения из shop.achievement.
+// Достижение выдаётся, когда после события Event значение метрики Metric достигает Threshold.
+type Achievement struct {
+	ID          int64  `json:"id"`
+	Code        string `json:"code"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Event       string `json:"event"`
+	Metric      string `json:"metric"`
+	Threshold   int64  `json:"threshold"`
+	Reward      int64  `json:"reward"`
+}
+
+type UserAchievement struct {
+	Code        string    `json:"code"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Reward      int64     `json:"reward"`
+	AwardedAt   time.Time `json:"awarded_at"`
+}
+
+func (ua UserAchievement) ToModelAchievementDTO() AchievementDTO {
+	return AchievementDTO{
+		Code:        ua.Code,
+		Title:       ua.Title,
+		Description: ua.Description,
+		Reward:      ua.Reward,
+		AwardedAt:   strfmt.DateTime(ua.AwardedAt),
+	}
+}
+
+type AchievementDTO struct {
+	Code        string          `json:"code"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Reward      int64           `json:"reward,omitempty"`
+	AwardedAt   strfmt.DateTime `json:"awardedAt"`
+}
+
+type ProfileDTO struct {
+	Username     string           `json:"username"`
+	Achievements []AchievementDTO `json:"achievements"`
+}
@@ -6,21 +6,35 @@ type contextKey string
 
 const UserIDKey contextKey = "userID"
 const UsernameKey contextKey = "username"
+const RoleKey contextKey = "role"
+const OrgIDKey contextKey = "orgID"
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
 
 type Claims struct {
 	UserID   int64  `json:"uid"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
+	OrgID    int64  `json:"org"`
 	jwt.RegisteredClaims
 }
 
 type AuthReqBody struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Organization string `json:"organization"`
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+	// ReferralCode код пригласившего пользователя, учитывается только при регистрации
+	ReferralCode string `json:"referralCode"`
 }
 
 type AuthQuery struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Organization string `json:"organization"`
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+	ReferralCode string `json:"referralCode"`
 }
 
 type AuthDTO struct {
@@ -1,6 +1,30 @@
 package models
 
-import "github.com/go-openapi/strfmt"
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	HistoryTypeTransfer   = "transfer"
+	HistoryTypePurchase   = "purchase"
+	HistoryTypeGrant      = "grant"
+	HistoryTypeAllowance  = "allowance"
+	HistoryTypeExpiration = "expiration"
+	// HistoryTypeSweep остаток монет удалённого аккаунта переведён в казначейство
+	HistoryTypeSweep = "sweep"
+	// HistoryTypeReversal компенсирующая запись отмены перевода администратором
+	HistoryTypeReversal = "reversal"
+	// HistoryTypeAchievement награда за достижение из казначейства
+	HistoryTypeAchievement = "achievement"
+	// HistoryTypeReferral бонус за приглашение из казначейства
+	HistoryTypeReferral = "referral"
+	// HistoryTypeCheckIn награда за ежедневную отметку из казначейства
+	HistoryTypeCheckIn = "check_in"
+	// HistoryTypeQuest награда за выполненное задание из казначейства
+	HistoryTypeQuest = "quest"
+)
 
 type BalanceHistoryDB struct {
 	ID                int64            `db:"id"`
@@ -8,32 +32,120 @@ type BalanceHistoryDB struct {
 	TransactionAmount int64            `db:"transaction_amount"`
 	Sender            string           `db:"sender"`
 	Recipient         string           `db:"recipient"`
+	Type              string           `db:"type"`
+	Reason            *string          `db:"reason"`
+	ReversalOf        *int64           `db:"reversal_of"`
+	Actor             *string          `db:"actor"`
+	MerchName         *string          `db:"merch_name"`
+	KudosID           *int64           `db:"kudos_id"`
+	TransferID        *int64           `db:"transfer_id"`
+	Currency          string           `db:"currency"`
+	Reversed          bool             `db:"reversed"`
 	DeletedAt         *strfmt.DateTime `db:"deleted_at"`
 	CreatedAt         strfmt.DateTime  `db:"created_at"`
 }
 
 func (bhdb *BalanceHistoryDB) ToModelBalanceHistory() BalanceHistory {
-	return BalanceHistory{
+	bh := BalanceHistory{
+		ID:                bhdb.ID,
+		BalanceID:         bhdb.BalanceID,
 		TransactionAmount: bhdb.TransactionAmount,
 		Sender:            bhdb.Sender,
 		Recipient:         bhdb.Recipient,
+		Type:              bhdb.Type,
+		Currency:          bhdb.Currency,
+		Reversed:          bhdb.Reversed,
+		CreatedAt:         time.Time(bhdb.CreatedAt),
+	}
+	if bhdb.Reason != nil {
+		bh.Reason = *bhdb.Reason
+	}
+	if bhdb.ReversalOf != nil {
+		bh.ReversalOf = *bhdb.ReversalOf
+	}
+	if bhdb.Actor != nil {
+		bh.Actor = *bhdb.Actor
+	}
+	if bhdb.MerchName != nil {
+		bh.MerchName = *bhdb.MerchName
+	}
+	if bhdb.KudosID != nil {
+		bh.KudosID = *bhdb.KudosID
+	}
+	if bhdb.TransferID != nil {
+		bh.TransferID = *bhdb.TransferID
 	}
+
+	return bh
 }
 
+// BalanceHistory запись истории баланса. ReversalOf ссылается на отменённую запись того же баланса,
+// Actor - администратор, отменивший перевод, Reversed отмечает отменённый перевод, MerchName - купленный предмет.
+// Currency заполняется из валюты баланса при записи. KudosID ссылается на публичную благодарность перевода.
+// TransferID общий у обеих записей одного перевода.
 type BalanceHistory struct {
-	TransactionAmount int64  `json:"transaction_amount"`
-	Sender            string `json:"sender"`
-	Recipient         string `json:"recipient"`
+	ID                int64     `json:"id"`
+	BalanceID         int64     `json:"balance_id"`
+	TransactionAmount int64     `json:"transaction_amount"`
+	Sender            string    `json:"sender"`
+	Recipient         string    `json:"recipient"`
+	Type              string    `json:"type"`
+	Reason            string    `json:"reason"`
+	ReversalOf        int64     `json:"reversal_of"`
+	Actor             string    `json:"actor"`
+	MerchName         string    `json:"merch_name"`
+	KudosID           int64     `json:"kudos_id"`
+	TransferID        int64     `json:"transfer_id"`
+	Currency          string    `json:"currency"`
+	Reversed          bool      `json:"reversed"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (bh *BalanceHistory) ToModelTransactionDTO() TransactionDTO {
+	return TransactionDTO{
+		ID:         bh.ID,
+		FromUser:   bh.Sender,
+		ToUser:     bh.Recipient,
+		Amount:     bh.TransactionAmount,
+		Type:       bh.Type,
+		Currency:   bh.Currency,
+		Reason:     bh.Reason,
+		Actor:      bh.Actor,
+		ReversalOf: bh.ReversalOf,
+		Reversed:   bh.Reversed,
+		CreatedAt:  strfmt.DateTime(bh.CreatedAt),
+	}
 }
 
 type ReceivedDTO struct {
 	FromUser string `json:"fromUser"`
 	Amount   int64  `json:"amount"`
+	Type     string `json:"type,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	Reversed bool   `json:"reversed,omitempty"`
 }
 
 type SentDTO struct {
-	ToUser string `json:"toUser"`
-	Amount int64  `json:"amount"`
+	ToUser   string `json:"toUser"`
+	Amount   int64  `json:"amount"`
+	Type     string `json:"type,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	Reversed bool   `json:"reversed,omitempty"`
+}
+
+// TransactionDTO запись истории баланса для администраторов
+type TransactionDTO struct {
+	ID         int64           `json:"id"`
+	FromUser   string          `json:"fromUser"`
+	ToUser     string          `json:"toUser"`
+	Amount     int64           `json:"amount"`
+	Type       string          `json:"type"`
+	Currency   string          `json:"currency"`
+	Reason     string          `json:"reason,omitempty"`
+	Actor      string          `json:"actor,omitempty"`
+	ReversalOf int64           `json:"reversalOf,omitempty"`
+	Reversed   bool            `json:"reversed,omitempty"`
+	CreatedAt  strfmt.DateTime `json:"createdAt"`
 }
 
 type BalanceHistoryDTO struct {
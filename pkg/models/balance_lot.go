@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+type BalanceLotDB struct {
+	ID        int64           `db:"id"`
+	BalanceID int64           `db:"balance_id"`
+	Amount    int64           `db:"amount"`
+	GrantedAt strfmt.DateTime `db:"granted_at"`
+	CreatedAt strfmt.DateTime `db:"created_at"`
+}
+
+func (bldb *BalanceLotDB) ToModelBalanceLot() BalanceLot {
+	return BalanceLot{
+		ID:        bldb.ID,
+		BalanceID: bldb.BalanceID,
+		Amount:    bldb.Amount,
+		GrantedAt: time.Time(bldb.GrantedAt),
+	}
+}
+
+// BalanceLot часть баланса, начисленная в один момент времени.
+// Currency - валюта баланса, заполняется только при выборке лотов всех кошельков пользователя.
+type BalanceLot struct {
+	ID        int64     `json:"id"`
+	BalanceID int64     `json:"balance_id"`
+	Amount    int64     `json:"amount"`
+	GrantedAt time.Time `json:"granted_at"`
+	Currency  string    `json:"currency"`
+}
+
+// BalanceOwner баланс с именем владельца в истории: пользователь или команда, и его валюта
+type BalanceOwner struct {
+	BalanceID int64  `json:"balance_id"`
+	Username  string `json:"username"`
+	Currency  string `json:"currency"`
+}
+
+type ExpiringCoinsDTO struct {
+	Amount    int64           `json:"amount"`
+	Currency  string          `json:"currency"`
+	ExpiresAt strfmt.DateTime `json:"expiresAt"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// CheckInDayLayout формат суток отметки в ответах
+const CheckInDayLayout = "2006-01-02"
+
+// CheckIn ежедневная отметка. Day - сутки в часовом поясе компании, полночь в UTC.
+type CheckIn struct {
+	Day    time.Time `json:"day"`
+	Streak int       `json:"streak"`
+	Reward int64     `json:"reward"`
+}
+
+type CheckInDTO struct {
+	Day    string `json:"day"`
+	Streak int    `json:"streak"`
+	Reward int64  `json:"reward"`
+	// NextReward награда за отметку завтра, если серия не прервётся
+	NextReward int64 `json:"nextReward"`
+}
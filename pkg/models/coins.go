@@ -1,8 +1,15 @@
 package models
 
+import "time"
+
 type SendCoinsReqBody struct {
 	Recipient string `json:"toUser"`
 	Amount    int64  `json:"amount"`
+	// Currency необязательна, по умолчанию монеты
+	Currency string `json:"currency"`
+	// Public публикует перевод в ленте благодарностей, для публичного перевода Message обязателен
+	Public  bool   `json:"public"`
+	Message string `json:"message"`
 }
 
 type CoinsQuery struct {
@@ -10,4 +17,50 @@ type CoinsQuery struct {
 	Amount    int64  `json:"amount"`
 	Sender    string `json:"sender"`
 	Recipient string `json:"recipient"`
+	Currency  string `json:"currency"`
+	// Memo сохраняется в истории как причина перевода
+	Memo string `json:"memo"`
+	// Public создаёт публичную благодарность с Memo в качестве сообщения
+	Public bool `json:"public"`
+}
+
+type BulkTransferItem struct {
+	Recipient string `json:"toUser"`
+	Amount    int64  `json:"amount"`
+	Memo      string `json:"memo"`
+}
+
+type BulkSendCoinsReqBody struct {
+	Transfers []BulkTransferItem `json:"transfers"`
+}
+
+type BulkCoinsQuery struct {
+	UserID    int64              `json:"user_id"`
+	Sender    string             `json:"sender"`
+	Transfers []BulkTransferItem `json:"transfers"`
+}
+
+type BulkTransferInvalidDTO struct {
+	Index  int    `json:"index"`
+	ToUser string `json:"toUser"`
+	Error  string `json:"error"`
+}
+
+type BulkSendCoinsResultDTO struct {
+	Total     int64                    `json:"total"`
+	Transfers []BulkTransferItem       `json:"transfers"`
+	Invalid   []BulkTransferInvalidDTO `json:"invalid"`
+}
+
+// TransferUsage сумма и количество переводов за период.
+// OldestAt - время самого раннего перевода периода, без переводов - начало периода.
+type TransferUsage struct {
+	Amount   int64
+	Count    int64
+	OldestAt time.Time
+}
+
+type TransferLimitErrorDTO struct {
+	Error   string     `json:"error"`
+	ResetAt *time.Time `json:"resetAt,omitempty"`
 }
@@ -0,0 +1,26 @@
+package models
+
+// CurrencyCoins валюта по умолчанию: её кошелёк создаётся при регистрации
+const CurrencyCoins = "coins"
+
+// Wallet баланс пользователя в одной валюте вместе с зарезервированной суммой
+type Wallet struct {
+	BalanceID int64  `json:"balance_id"`
+	Currency  string `json:"currency"`
+	Amount    int64  `json:"amount"`
+	Held      int64  `json:"held"`
+}
+
+func (w *Wallet) ToModelWalletDTO() WalletDTO {
+	return WalletDTO{
+		Currency:  w.Currency,
+		Amount:    w.Amount,
+		Available: w.Amount - w.Held,
+	}
+}
+
+type WalletDTO struct {
+	Currency  string `json:"currency"`
+	Amount    int64  `json:"amount"`
+	Available int64  `json:"available"`
+}
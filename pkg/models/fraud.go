@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	// FraudRuleCycle монеты вернулись к отправителю через одного или двух посредников
+	FraudRuleCycle = "cycle"
+	// FraudRuleFunnel много новых аккаунтов переводят монеты одному получателю
+	FraudRuleFunnel = "funnel"
+	// FraudRuleBurst крупные переводы сразу после регистрации
+	FraudRuleBurst = "burst"
+
+	FraudCaseStatusOpen      = "open"
+	FraudCaseStatusDismissed = "dismissed"
+	FraudCaseStatusConfirmed = "confirmed"
+)
+
+type FraudCaseDB struct {
+	ID         int64            `db:"id"`
+	Rule       string           `db:"rule"`
+	SubjectKey string           `db:"subject_key"`
+	Usernames  []string         `db:"usernames"`
+	Details    string           `db:"details"`
+	Status     string           `db:"status"`
+	ResolvedBy *string          `db:"resolved_by"`
+	ResolvedAt *strfmt.DateTime `db:"resolved_at"`
+	CreatedAt  strfmt.DateTime  `db:"created_at"`
+}
+
+func (fcdb *FraudCaseDB) ToModelFraudCase() FraudCase {
+	fc := FraudCase{
+		ID:         fcdb.ID,
+		Rule:       fcdb.Rule,
+		SubjectKey: fcdb.SubjectKey,
+		Usernames:  fcdb.Usernames,
+		Details:    fcdb.Details,
+		Status:     fcdb.Status,
+		CreatedAt:  time.Time(fcdb.CreatedAt),
+	}
+	if fcdb.ResolvedBy != nil {
+		fc.ResolvedBy = *fcdb.ResolvedBy
+	}
+	if fcdb.ResolvedAt != nil {
+		resolvedAt := time.Time(*fcdb.ResolvedAt)
+		fc.ResolvedAt = &resolvedAt
+	}
+
+	return fc
+}
+
+// FraudCase срабатывание правила обнаружения мошенничества для разбора администратором
+type FraudCase struct {
+	ID         int64      `json:"id"`
+	Rule       string     `json:"rule"`
+	SubjectKey string     `json:"subject_key"`
+	Usernames  []string   `json:"usernames"`
+	Details    string     `json:"details"`
+	Status     string     `json:"status"`
+	ResolvedBy string     `json:"resolved_by"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (fc *FraudCase) ToModelFraudCaseDTO() FraudCaseDTO {
+	dto := FraudCaseDTO{
+		ID:         fc.ID,
+		Rule:       fc.Rule,
+		Usernames:  fc.Usernames,
+		Details:    fc.Details,
+		Status:     fc.Status,
+		ResolvedBy: fc.ResolvedBy,
+		CreatedAt:  strfmt.DateTime(fc.CreatedAt),
+	}
+	if fc.ResolvedAt != nil {
+		resolvedAt := strfmt.DateTime(*fc.ResolvedAt)
+		dto.ResolvedAt = &resolvedAt
+	}
+
+	return dto
+}
+
+type FraudCaseDTO struct {
+	ID         int64            `json:"id"`
+	Rule       string           `json:"rule"`
+	Usernames  []string         `json:"usernames"`
+	Details    string           `json:"details"`
+	Status     string           `json:"status"`
+	ResolvedBy string           `json:"resolvedBy,omitempty"`
+	ResolvedAt *strfmt.DateTime `json:"resolvedAt,omitempty"`
+	CreatedAt  strfmt.DateTime  `json:"createdAt"`
+}
+
+type FraudCaseQuery struct {
+	ID     int64  `json:"id"`
+	Admin  string `json:"admin"`
+	Status string `json:"status"`
+}
+
+// FraudHit срабатывание правила до записи в кейс
+type FraudHit struct {
+	Rule      string
+	Usernames []string
+	Amount    int64
+}
@@ -0,0 +1,48 @@
+package models
+
+const TreasuryAccount = "Treasury"
+
+type GrantReqBody struct {
+	Usernames []string `json:"usernames"`
+	All       bool     `json:"all"`
+	Amount    int64    `json:"amount"`
+	Reason    string   `json:"reason"`
+	Currency  string   `json:"currency"`
+	DryRun    bool     `json:"dryRun"`
+}
+
+type GrantRecipient struct {
+	Line     int    `json:"line"`
+	Username string `json:"username"`
+	Amount   int64  `json:"amount"`
+}
+
+type GrantQuery struct {
+	Admin      string           `json:"admin"`
+	Recipients []GrantRecipient `json:"recipients"`
+	All        bool             `json:"all"`
+	Amount     int64            `json:"amount"`
+	Reason     string           `json:"reason"`
+	Currency   string           `json:"currency"`
+	DryRun     bool             `json:"dry_run"`
+	// Invalid строки, отклонённые ещё при разборе запроса (например, CSV)
+	Invalid []GrantInvalidDTO `json:"invalid"`
+}
+
+type GrantDTO struct {
+	ToUser string `json:"toUser"`
+	Amount int64  `json:"amount"`
+}
+
+type GrantInvalidDTO struct {
+	Line   int    `json:"line,omitempty"`
+	ToUser string `json:"toUser"`
+	Error  string `json:"error"`
+}
+
+type GrantResultDTO struct {
+	DryRun  bool              `json:"dryRun"`
+	Total   int64             `json:"total"`
+	Granted []GrantDTO        `json:"granted"`
+	Invalid []GrantInvalidDTO `json:"invalid"`
+}
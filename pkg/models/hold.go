@@ -0,0 +1,111 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+)
+
+type HoldDB struct {
+	ID         int64            `db:"id"`
+	BalanceID  int64            `db:"balance_id"`
+	OwnerID    int64            `db:"owner_id"`
+	Owner      string           `db:"owner"`
+	Recipient  string           `db:"recipient"`
+	Amount     int64            `db:"amount"`
+	Reference  *string          `db:"reference"`
+	Status     string           `db:"status"`
+	ExpiresAt  strfmt.DateTime  `db:"expires_at"`
+	ResolvedAt *strfmt.DateTime `db:"resolved_at"`
+	CreatedAt  strfmt.DateTime  `db:"created_at"`
+}
+
+func (hdb *HoldDB) ToModelHold() Hold {
+	hold := Hold{
+		ID:        hdb.ID,
+		BalanceID: hdb.BalanceID,
+		OwnerID:   hdb.OwnerID,
+		Owner:     hdb.Owner,
+		Recipient: hdb.Recipient,
+		Amount:    hdb.Amount,
+		Status:    hdb.Status,
+		ExpiresAt: time.Time(hdb.ExpiresAt),
+		CreatedAt: time.Time(hdb.CreatedAt),
+	}
+	if hdb.Reference != nil {
+		hold.Reference = *hdb.Reference
+	}
+
+	return hold
+}
+
+// Hold резерв монет владельца в пользу получателя до захвата, освобождения или истечения срока
+type Hold struct {
+	ID        int64     `json:"id"`
+	BalanceID int64     `json:"balance_id"`
+	OwnerID   int64     `json:"owner_id"`
+	Owner     string    `json:"owner"`
+	Recipient string    `json:"recipient"`
+	Amount    int64     `json:"amount"`
+	Reference string    `json:"reference"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsActive истёкший резерв не уменьшает доступный баланс, даже если его ещё не освободили
+func (h *Hold) IsActive(now time.Time) bool {
+	return h.Status == HoldStatusActive && now.Before(h.ExpiresAt)
+}
+
+func (h *Hold) ToModelHoldDTO(now time.Time) HoldDTO {
+	dto := HoldDTO{
+		ID:        h.ID,
+		FromUser:  h.Owner,
+		ToUser:    h.Recipient,
+		Amount:    h.Amount,
+		Reference: h.Reference,
+		Status:    h.Status,
+		ExpiresAt: strfmt.DateTime(h.ExpiresAt),
+		CreatedAt: strfmt.DateTime(h.CreatedAt),
+	}
+	if h.Status == HoldStatusActive && !h.IsActive(now) {
+		dto.Status = HoldStatusReleased
+	}
+
+	return dto
+}
+
+type HoldDTO struct {
+	ID        int64           `json:"id"`
+	FromUser  string          `json:"fromUser"`
+	ToUser    string          `json:"toUser"`
+	Amount    int64           `json:"amount"`
+	Reference string          `json:"reference,omitempty"`
+	Status    string          `json:"status"`
+	ExpiresAt strfmt.DateTime `json:"expiresAt"`
+	CreatedAt strfmt.DateTime `json:"createdAt"`
+}
+
+type HoldReqBody struct {
+	Recipient string `json:"toUser"`
+	Amount    int64  `json:"amount"`
+	Reference string `json:"reference"`
+	TTL       string `json:"ttl"`
+}
+
+type HoldQuery struct {
+	ID        int64         `json:"id"`
+	UserID    int64         `json:"user_id"`
+	Username  string        `json:"username"`
+	Recipient string        `json:"recipient"`
+	Amount    int64         `json:"amount"`
+	Reference string        `json:"reference"`
+	TTL       time.Duration `json:"ttl"`
+}
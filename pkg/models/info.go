@@ -6,7 +6,12 @@ type InfoQuery struct {
 }
 
 type InfoDTO struct {
-	Coins        int64             `json:"coins"`
-	Inventory    []MerchDTO        `json:"inventory"`
-	CoinsHistory BalanceHistoryDTO `json:"coinHistory"`
+	Coins        int64              `json:"coins"`
+	Available    int64              `json:"available"`
+	Inventory    []MerchDTO         `json:"inventory"`
+	CoinsHistory BalanceHistoryDTO  `json:"coinHistory"`
+	ExpiringSoon []ExpiringCoinsDTO `json:"expiringSoon"`
+	// Wallets балансы во всех валютах, Coins и Available дублируют кошелёк монет
+	Wallets      []WalletDTO      `json:"wallets"`
+	Achievements []AchievementDTO `json:"achievements"`
 }
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// KudosMessageMaxLength ограничение сообщения перевода, сообщение сохраняется и в причине записи истории
+const KudosMessageMaxLength = 255
+
+// KudosReactions реакции, которыми можно отметить благодарность
+var KudosReactions = map[string]*struct{}{
+	"👍":  {},
+	"❤️": {},
+	"🎉":  {},
+	"🙏":  {},
+	"🔥":  {},
+	"👏":  {},
+	"😂":  {},
+}
+
+// Kudos публичная благодарность. Amount - сумма исходного перевода,
+// Boosted и Boosts - сумма и количество переводов, добавленных сверху другими пользователями.
+type Kudos struct {
+	ID        int64     `json:"id"`
+	Sender    string    `json:"sender"`
+	Recipient string    `json:"recipient"`
+	Message   string    `json:"message"`
+	Currency  string    `json:"currency"`
+	Amount    int64     `json:"amount"`
+	Boosted   int64     `json:"boosted"`
+	Boosts    int64     `json:"boosts"`
+	Hidden    bool      `json:"hidden"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// KudosReaction количество реакций на благодарность, Reacted - реакция поставлена запросившим пользователем
+type KudosReaction struct {
+	KudosID int64  `json:"kudos_id"`
+	Emoji   string `json:"emoji"`
+	Count   int64  `json:"count"`
+	Reacted bool   `json:"reacted"`
+}
+
+// KudosFeedFilter Before - id, с которого начинается страница (не включительно), 0 - с последних благодарностей
+type KudosFeedFilter struct {
+	Before int64 `json:"before"`
+	Limit  int   `json:"limit"`
+}
+
+type KudosFeedQuery struct {
+	UserID int64  `json:"user_id"`
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+type KudosBoostReqBody struct {
+	Amount int64 `json:"amount"`
+}
+
+type KudosBoostQuery struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+	KudosID  int64  `json:"kudos_id"`
+	Amount   int64  `json:"amount"`
+}
+
+type KudosReactionQuery struct {
+	UserID  int64  `json:"user_id"`
+	KudosID int64  `json:"kudos_id"`
+	Emoji   string `json:"emoji"`
+}
+
+type KudosFeedDTO struct {
+	Items []KudosDTO `json:"items"`
+	// NextCursor передаётся в cursor для следующей страницы, пустой на последней странице
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+type KudosDTO struct {
+	ID        int64              `json:"id"`
+	FromUser  string             `json:"fromUser"`
+	ToUser    string             `json:"toUser"`
+	Message   string             `json:"message"`
+	Currency  string             `json:"currency,omitempty"`
+	Amount    int64              `json:"amount"`
+	Boosted   int64              `json:"boosted"`
+	Boosts    int64              `json:"boosts"`
+	Reactions []KudosReactionDTO `json:"reactions"`
+	CreatedAt strfmt.DateTime    `json:"createdAt"`
+}
+
+type KudosReactionDTO struct {
+	Emoji   string `json:"emoji"`
+	Count   int64  `json:"count"`
+	Reacted bool   `json:"reacted"`
+}
+
+func (k Kudos) ToModelKudosDTO() KudosDTO {
+	// валюта указывается только для благодарностей не в монетах, как в истории
+	currency := k.Currency
+	if currency == CurrencyCoins {
+		currency = ""
+	}
+
+	return KudosDTO{
+		ID:        k.ID,
+		FromUser:  k.Sender,
+		ToUser:    k.Recipient,
+		Message:   k.Message,
+		Currency:  currency,
+		Amount:    k.Amount,
+		Boosted:   k.Boosted,
+		Boosts:    k.Boosts,
+		Reactions: []KudosReactionDTO{},
+		CreatedAt: strfmt.DateTime(k.CreatedAt),
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	LeaderboardMetricReceived = "received"
+	LeaderboardMetricGiven    = "given"
+	// LeaderboardMetricThanked число разных пользователей, которым отправлены монеты
+	LeaderboardMetricThanked = "thanked"
+
+	LeaderboardPeriodWeek  = "week"
+	LeaderboardPeriodMonth = "month"
+	LeaderboardPeriodAll   = "all"
+)
+
+// LeaderboardPeriod календарный период, в котором копятся агрегаты рейтинга
+type LeaderboardPeriod struct {
+	Period string    `json:"period"`
+	Start  time.Time `json:"start"`
+}
+
+// LeaderboardFilter TeamID ограничивает рейтинг участниками команды, 0 - вся организация
+type LeaderboardFilter struct {
+	Metric      string    `json:"metric"`
+	Period      string    `json:"period"`
+	PeriodStart time.Time `json:"period_start"`
+	TeamID      int64     `json:"team_id"`
+	Limit       int       `json:"limit"`
+}
+
+type LeaderboardEntry struct {
+	Rank     int64  `json:"rank"`
+	Username string `json:"username"`
+	Value    int64  `json:"value"`
+}
+
+type LeaderboardQuery struct {
+	Username string `json:"username"`
+	Metric   string `json:"metric"`
+	Period   string `json:"period"`
+	TeamID   int64  `json:"team_id"`
+	Limit    int    `json:"limit"`
+}
+
+type LeaderboardOptOutReqBody struct {
+	OptOut bool `json:"optOut"`
+}
+
+type LeaderboardDTO struct {
+	Metric  string                `json:"metric"`
+	Period  string                `json:"period"`
+	From    *strfmt.DateTime      `json:"from,omitempty"`
+	Entries []LeaderboardEntryDTO `json:"entries"`
+}
+
+type LeaderboardEntryDTO struct {
+	Rank     int64  `json:"rank"`
+	Username string `json:"username"`
+	Value    int64  `json:"value"`
+}
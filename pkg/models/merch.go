@@ -6,23 +6,26 @@ type MerchDB struct {
 	ID        int64            `db:"id"`
 	Name      string           `db:"name"`
 	Price     int64            `db:"price"`
+	Currency  string           `db:"currency"`
 	DeletedAt *strfmt.DateTime `db:"deleted_at"`
 	CreatedAt strfmt.DateTime  `db:"created_at"`
 }
 
 func (mdb *MerchDB) ToModelMerch() Merch {
 	return Merch{
-		ID:    mdb.ID,
-		Name:  mdb.Name,
-		Price: mdb.Price,
+		ID:       mdb.ID,
+		Name:     mdb.Name,
+		Price:    mdb.Price,
+		Currency: mdb.Currency,
 	}
 }
 
 type Merch struct {
-	ID    int64  `json:"id"`
-	Name  string `json:"name"`
-	Price int64  `json:"price"`
-	Count int64  `json:"count"`
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Price    int64  `json:"price"`
+	Currency string `json:"currency"`
+	Count    int64  `json:"count"`
 }
 
 func (m *Merch) ToModelMerchDTO() MerchDTO {
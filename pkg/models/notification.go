@@ -0,0 +1,151 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	NotificationTypeCoinsReceived = "coins_received"
+	NotificationTypePurchase      = "purchase"
+	NotificationTypeLowBalance    = "low_balance"
+	NotificationTypeGrant         = "grant"
+
+	// PurchaseStatusCompleted покупка оплачена и мерч добавлен в инвентарь
+	PurchaseStatusCompleted = "completed"
+)
+
+// Notification уведомление во входящих пользователя. Для low_balance Amount - баланс после списания.
+type Notification struct {
+	ID        int64      `json:"id"`
+	Type      string     `json:"type"`
+	FromUser  string     `json:"from_user"`
+	Item      string     `json:"item"`
+	Status    string     `json:"status"`
+	Amount    int64      `json:"amount"`
+	Currency  string     `json:"currency"`
+	Message   string     `json:"message"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at"`
+}
+
+// NotificationFilter Before - id, с которого начинается страница (не включительно), 0 - с последних уведомлений
+type NotificationFilter struct {
+	UserID     int64 `json:"user_id"`
+	UnreadOnly bool  `json:"unread_only"`
+	Before     int64 `json:"before"`
+	Limit      int   `json:"limit"`
+}
+
+type NotificationsQuery struct {
+	UserID     int64  `json:"user_id"`
+	UnreadOnly bool   `json:"unread_only"`
+	Cursor     string `json:"cursor"`
+	Limit      int    `json:"limit"`
+}
+
+// NotificationsReadReqBody All отмечает прочитанными все уведомления, иначе только перечисленные в IDs
+type NotificationsReadReqBody struct {
+	IDs []int64 `json:"ids"`
+	All bool    `json:"all"`
+}
+
+type NotificationsReadQuery struct {
+	UserID int64   `json:"user_id"`
+	IDs    []int64 `json:"ids"`
+	All    bool    `json:"all"`
+}
+
+// NotificationPreferences LowBalanceThreshold nil - порог из конфигурации
+type NotificationPreferences struct {
+	CoinsReceived       bool   `json:"coins_received"`
+	Purchase            bool   `json:"purchase"`
+	LowBalance          bool   `json:"low_balance"`
+	Grant               bool   `json:"grant"`
+	LowBalanceThreshold *int64 `json:"low_balance_threshold"`
+}
+
+// DefaultNotificationPreferences настройки пользователя, который их не менял
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{CoinsReceived: true, Purchase: true, LowBalance: true, Grant: true}
+}
+
+// Enabled включены ли уведомления типа notificationType
+func (p NotificationPreferences) Enabled(notificationType string) bool {
+	switch notificationType {
+	case NotificationTypeCoinsReceived:
+		return p.CoinsReceived
+	case NotificationTypePurchase:
+		return p.Purchase
+	case NotificationTypeLowBalance:
+		return p.LowBalance
+	case NotificationTypeGrant:
+		return p.Grant
+	default:
+		return false
+	}
+}
+
+// NotificationPreferencesReqBody меняет только переданные поля
+type NotificationPreferencesReqBody struct {
+	CoinsReceived       *bool  `json:"coinsReceived"`
+	Purchase            *bool  `json:"purchase"`
+	LowBalance          *bool  `json:"lowBalance"`
+	Grant               *bool  `json:"grant"`
+	LowBalanceThreshold *int64 `json:"lowBalanceThreshold"`
+}
+
+type NotificationPreferencesDTO struct {
+	CoinsReceived bool `json:"coinsReceived"`
+	Purchase      bool `json:"purchase"`
+	LowBalance    bool `json:"lowBalance"`
+	Grant         bool `json:"grant"`
+	// LowBalanceThreshold действующий порог с учётом значения по умолчанию
+	LowBalanceThreshold int64 `json:"lowBalanceThreshold"`
+}
+
+type NotificationsDTO struct {
+	Items []NotificationDTO `json:"items"`
+	// NextCursor передаётся в cursor для следующей страницы, пустой на последней странице
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+type NotificationDTO struct {
+	ID        int64            `json:"id"`
+	Type      string           `json:"type"`
+	FromUser  string           `json:"fromUser,omitempty"`
+	Item      string           `json:"item,omitempty"`
+	Status    string           `json:"status,omitempty"`
+	Amount    int64            `json:"amount"`
+	Currency  string           `json:"currency"`
+	Message   string           `json:"message,omitempty"`
+	Read      bool             `json:"read"`
+	CreatedAt strfmt.DateTime  `json:"createdAt"`
+	ReadAt    *strfmt.DateTime `json:"readAt,omitempty"`
+}
+
+type UnreadNotificationsDTO struct {
+	Unread int64 `json:"unread"`
+}
+
+func (n Notification) ToModelNotificationDTO() NotificationDTO {
+	dto := NotificationDTO{
+		ID:        n.ID,
+		Type:      n.Type,
+		FromUser:  n.FromUser,
+		Item:      n.Item,
+		Status:    n.Status,
+		Amount:    n.Amount,
+		Currency:  n.Currency,
+		Message:   n.Message,
+		CreatedAt: strfmt.DateTime(n.CreatedAt),
+	}
+	if n.ReadAt != nil {
+		readAt := strfmt.DateTime(*n.ReadAt)
+		dto.Read = true
+		dto.ReadAt = &readAt
+	}
+
+	return dto
+}
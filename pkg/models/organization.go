@@ -0,0 +1,11 @@
+package models
+
+// OrganizationDefault организация, в которую входят пользователи без явно указанной организации
+const OrganizationDefault = "default"
+
+// Organization тенант: свой каталог мерча, стартовый баланс и пользователи
+type Organization struct {
+	ID              int64  `json:"id"`
+	Name            string `json:"name"`
+	StartingBalance int64  `json:"starting_balance"`
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	PaymentRequestStatusPending  = "pending"
+	PaymentRequestStatusPaid     = "paid"
+	PaymentRequestStatusDeclined = "declined"
+	PaymentRequestStatusExpired  = "expired"
+)
+
+type PaymentRequestDB struct {
+	ID          int64            `db:"id"`
+	RequesterID int64            `db:"requester_id"`
+	Requester   string           `db:"requester"`
+	Payer       string           `db:"payer"`
+	Amount      int64            `db:"amount"`
+	Memo        *string          `db:"memo"`
+	Status      string           `db:"status"`
+	ExpiresAt   strfmt.DateTime  `db:"expires_at"`
+	ResolvedAt  *strfmt.DateTime `db:"resolved_at"`
+	CreatedAt   strfmt.DateTime  `db:"created_at"`
+}
+
+func (prdb *PaymentRequestDB) ToModelPaymentRequest() PaymentRequest {
+	pr := PaymentRequest{
+		ID:          prdb.ID,
+		RequesterID: prdb.RequesterID,
+		Requester:   prdb.Requester,
+		Payer:       prdb.Payer,
+		Amount:      prdb.Amount,
+		Status:      prdb.Status,
+		ExpiresAt:   time.Time(prdb.ExpiresAt),
+		CreatedAt:   time.Time(prdb.CreatedAt),
+	}
+	if prdb.Memo != nil {
+		pr.Memo = *prdb.Memo
+	}
+
+	return pr
+}
+
+type PaymentRequest struct {
+	ID          int64     `json:"id"`
+	RequesterID int64     `json:"requester_id"`
+	Requester   string    `json:"requester"`
+	Payer       string    `json:"payer"`
+	Amount      int64     `json:"amount"`
+	Memo        string    `json:"memo"`
+	Status      string    `json:"status"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// IsExpired ожидающий запрос с истёкшим сроком считается просроченным
+func (pr *PaymentRequest) IsExpired(now time.Time) bool {
+	return pr.Status == PaymentRequestStatusPending && !now.Before(pr.ExpiresAt)
+}
+
+func (pr *PaymentRequest) ToModelPaymentRequestDTO(now time.Time) PaymentRequestDTO {
+	dto := PaymentRequestDTO{
+		ID:        pr.ID,
+		FromUser:  pr.Requester,
+		ToUser:    pr.Payer,
+		Amount:    pr.Amount,
+		Memo:      pr.Memo,
+		Status:    pr.Status,
+		ExpiresAt: strfmt.DateTime(pr.ExpiresAt),
+		CreatedAt: strfmt.DateTime(pr.CreatedAt),
+	}
+	if pr.IsExpired(now) {
+		dto.Status = PaymentRequestStatusExpired
+	}
+
+	return dto
+}
+
+// PaymentRequestDTO fromUser запрашивает монеты, toUser - плательщик
+type PaymentRequestDTO struct {
+	ID        int64           `json:"id"`
+	FromUser  string          `json:"fromUser"`
+	ToUser    string          `json:"toUser"`
+	Amount    int64           `json:"amount"`
+	Memo      string          `json:"memo,omitempty"`
+	Status    string          `json:"status"`
+	ExpiresAt strfmt.DateTime `json:"expiresAt"`
+	CreatedAt strfmt.DateTime `json:"createdAt"`
+}
+
+type PaymentRequestReqBody struct {
+	Payer  string `json:"toUser"`
+	Amount int64  `json:"amount"`
+	Memo   string `json:"memo"`
+}
+
+type PaymentRequestQuery struct {
+	ID       int64  `json:"id"`
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+	Payer    string `json:"payer"`
+	Amount   int64  `json:"amount"`
+	Memo     string `json:"memo"`
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	QuestEventTransfer = "transfer"
+	QuestEventPurchase = "purchase"
+
+	// QuestMetricTransfers количество переводов монет
+	QuestMetricTransfers = "transfers"
+	// QuestMetricCoinsSent сумма переведённых монет
+	QuestMetricCoinsSent = "coins_sent"
+	// QuestMetricDistinctRecipients количество разных получателей монет
+	QuestMetricDistinctRecipients = "distinct_recipients"
+	// QuestMetricPurchases количество покупок
+	QuestMetricPurchases = "purchases"
+	// QuestMetricCoinsSpent сумма монет, потраченных на покупки
+	QuestMetricCoinsSpent = "coins_spent"
+)
+
+// QuestMetrics событие, по которому считается метрика задания
+var QuestMetrics = map[string]string{
+	QuestMetricTransfers:          QuestEventTransfer,
+	QuestMetricCoinsSent:          QuestEventTransfer,
+	QuestMetricDistinctRecipients: QuestEventTransfer,
+	QuestMetricPurchases:          QuestEventPurchase,
+	QuestMetricCoinsSpent:         QuestEventPurchase,
+}
+
+const (
+	QuestTitleMaxLength       = 128
+	QuestDescriptionMaxLength = 512
+)
+
+// Quest задание организации. Метрика считается по событиям в периоде [StartsAt, EndsAt),
+// OtherTeam - учитываются только переводы пользователям, не состоящим в командах отправителя.
+type Quest struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Event       string    `json:"event"`
+	Metric      string    `json:"metric"`
+	OtherTeam   bool      `json:"other_team"`
+	Target      int64     `json:"target"`
+	Reward      int64     `json:"reward"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+}
+
+// UserQuest задание с прогрессом пользователя
+type UserQuest struct {
+	Quest
+	Progress    int64      `json:"progress"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+type QuestReqBody struct {
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Metric      string          `json:"metric"`
+	OtherTeam   bool            `json:"otherTeam"`
+	Target      int64           `json:"target"`
+	Reward      int64           `json:"reward"`
+	StartsAt    strfmt.DateTime `json:"startsAt"`
+	EndsAt      strfmt.DateTime `json:"endsAt"`
+}
+
+func (b QuestReqBody) ToModelQuest(questID int64) Quest {
+	return Quest{
+		ID:          questID,
+		Title:       b.Title,
+		Description: b.Description,
+		Metric:      b.Metric,
+		OtherTeam:   b.OtherTeam,
+		Target:      b.Target,
+		Reward:      b.Reward,
+		StartsAt:    time.Time(b.StartsAt),
+		EndsAt:      time.Time(b.EndsAt),
+	}
+}
+
+type QuestDTO struct {
+	ID          int64           `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	Event       string          `json:"event"`
+	Metric      string          `json:"metric"`
+	OtherTeam   bool            `json:"otherTeam"`
+	Target      int64           `json:"target"`
+	Reward      int64           `json:"reward"`
+	StartsAt    strfmt.DateTime `json:"startsAt"`
+	EndsAt      strfmt.DateTime `json:"endsAt"`
+}
+
+type UserQuestDTO struct {
+	QuestDTO
+	Progress    int64            `json:"progress"`
+	Completed   bool             `json:"completed"`
+	CompletedAt *strfmt.DateTime `json:"completedAt,omitempty"`
+}
+
+func (q Quest) ToModelQuestDTO() QuestDTO {
+	return QuestDTO{
+		ID:          q.ID,
+		Title:       q.Title,
+		Description: q.Description,
+		Event:       q.Event,
+		Metric:      q.Metric,
+		OtherTeam:   q.OtherTeam,
+		Target:      q.Target,
+		Reward:      q.Reward,
+		StartsAt:    strfmt.DateTime(q.StartsAt),
+		EndsAt:      strfmt.DateTime(q.EndsAt),
+	}
+}
+
+func (uq UserQuest) ToModelUserQuestDTO() UserQuestDTO {
+	dto := UserQuestDTO{
+		QuestDTO: uq.Quest.ToModelQuestDTO(),
+		Progress: uq.Progress,
+	}
+	if uq.CompletedAt != nil {
+		completedAt := strfmt.DateTime(*uq.CompletedAt)
+		dto.Completed = true
+		dto.CompletedAt = &completedAt
+	}
+
+	return dto
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	// ReferralStatusPending приглашённый ещё не совершил первую покупку или перевод
+	ReferralStatusPending = "pending"
+	// ReferralStatusQualified бонус выплачен обеим сторонам
+	ReferralStatusQualified = "qualified"
+)
+
+// Referral приглашение пользователя. Bonus - сумма, выплаченная каждой из сторон.
+type Referral struct {
+	ID          int64      `json:"id"`
+	Referrer    string     `json:"referrer"`
+	Referee     string     `json:"referee"`
+	Bonus       int64      `json:"bonus"`
+	CreatedAt   time.Time  `json:"created_at"`
+	QualifiedAt *time.Time `json:"qualified_at"`
+}
+
+type ReferralsDTO struct {
+	Code        string        `json:"code"`
+	Referrals   int64         `json:"referrals"`
+	Qualified   int64         `json:"qualified"`
+	BonusEarned int64         `json:"bonusEarned"`
+	Items       []ReferralDTO `json:"items"`
+}
+
+type ReferralDTO struct {
+	Username    string           `json:"username"`
+	Status      string           `json:"status"`
+	Bonus       int64            `json:"bonus"`
+	CreatedAt   strfmt.DateTime  `json:"createdAt"`
+	QualifiedAt *strfmt.DateTime `json:"qualifiedAt,omitempty"`
+}
+
+func (r Referral) ToModelReferralDTO() ReferralDTO {
+	dto := ReferralDTO{
+		Username:  r.Referee,
+		Status:    ReferralStatusPending,
+		Bonus:     r.Bonus,
+		CreatedAt: strfmt.DateTime(r.CreatedAt),
+	}
+	if r.QualifiedAt != nil {
+		qualifiedAt := strfmt.DateTime(*r.QualifiedAt)
+		dto.Status = ReferralStatusQualified
+		dto.QualifiedAt = &qualifiedAt
+	}
+
+	return dto
+}
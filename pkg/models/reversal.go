@@ -0,0 +1,22 @@
+package models
+
+type ReversalReqBody struct {
+	Reason string `json:"reason"`
+}
+
+type ReversalQuery struct {
+	Admin string `json:"admin"`
+	// EntryID запись истории перевода у отправителя или получателя
+	EntryID int64  `json:"entry_id"`
+	Reason  string `json:"reason"`
+}
+
+type ReversalDTO struct {
+	// FromUser получатель исходного перевода, у которого списаны монеты
+	FromUser   string `json:"fromUser"`
+	ToUser     string `json:"toUser"`
+	Amount     int64  `json:"amount"`
+	Currency   string `json:"currency"`
+	Reason     string `json:"reason"`
+	ReversedBy string `json:"reversedBy"`
+}
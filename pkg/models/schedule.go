@@ -0,0 +1,169 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusPaused    = "paused"
+	ScheduleStatusCompleted = "completed"
+
+	ScheduleRunStatusSuccess = "success"
+	ScheduleRunStatusFailed  = "failed"
+)
+
+type ScheduleDB struct {
+	ID                  int64            `db:"id"`
+	UserID              int64            `db:"user_id"`
+	Sender              string           `db:"sender"`
+	Recipient           string           `db:"recipient"`
+	Amount              int64            `db:"amount"`
+	IntervalSeconds     *int64           `db:"interval_seconds"`
+	NextRunAt           strfmt.DateTime  `db:"next_run_at"`
+	ConsecutiveFailures int64            `db:"consecutive_failures"`
+	PausedAt            *strfmt.DateTime `db:"paused_at"`
+	CompletedAt         *strfmt.DateTime `db:"completed_at"`
+	DeletedAt           *strfmt.DateTime `db:"deleted_at"`
+	CreatedAt           strfmt.DateTime  `db:"created_at"`
+}
+
+func (sdb *ScheduleDB) ToModelSchedule() Schedule {
+	schedule := Schedule{
+		ID:                  sdb.ID,
+		UserID:              sdb.UserID,
+		Sender:              sdb.Sender,
+		Recipient:           sdb.Recipient,
+		Amount:              sdb.Amount,
+		NextRunAt:           time.Time(sdb.NextRunAt),
+		ConsecutiveFailures: sdb.ConsecutiveFailures,
+		Paused:              sdb.PausedAt != nil,
+		Completed:           sdb.CompletedAt != nil,
+	}
+	if sdb.IntervalSeconds != nil {
+		schedule.Interval = time.Duration(*sdb.IntervalSeconds) * time.Second
+	}
+
+	return schedule
+}
+
+type Schedule struct {
+	ID                  int64         `json:"id"`
+	UserID              int64         `json:"user_id"`
+	Sender              string        `json:"sender"`
+	Recipient           string        `json:"recipient"`
+	Amount              int64         `json:"amount"`
+	Interval            time.Duration `json:"interval"`
+	NextRunAt           time.Time     `json:"next_run_at"`
+	ConsecutiveFailures int64         `json:"consecutive_failures"`
+	Paused              bool          `json:"paused"`
+	Completed           bool          `json:"completed"`
+}
+
+func (s *Schedule) ToModelScheduleDTO() ScheduleDTO {
+	dto := ScheduleDTO{
+		ID:                  s.ID,
+		ToUser:              s.Recipient,
+		Amount:              s.Amount,
+		NextRunAt:           strfmt.DateTime(s.NextRunAt),
+		ConsecutiveFailures: s.ConsecutiveFailures,
+		Status:              ScheduleStatusActive,
+	}
+	if s.Interval > 0 {
+		dto.Interval = s.Interval.String()
+	}
+	switch {
+	case s.Completed:
+		dto.Status = ScheduleStatusCompleted
+	case s.Paused:
+		dto.Status = ScheduleStatusPaused
+	}
+
+	return dto
+}
+
+type ScheduleDTO struct {
+	ID                  int64           `json:"id"`
+	ToUser              string          `json:"toUser"`
+	Amount              int64           `json:"amount"`
+	Interval            string          `json:"interval,omitempty"`
+	NextRunAt           strfmt.DateTime `json:"nextRunAt"`
+	ConsecutiveFailures int64           `json:"consecutiveFailures"`
+	Status              string          `json:"status"`
+}
+
+type ScheduleReqBody struct {
+	Recipient string           `json:"toUser"`
+	Amount    int64            `json:"amount"`
+	RunAt     *strfmt.DateTime `json:"runAt"`
+	Interval  string           `json:"interval"`
+}
+
+type ScheduleUpdateReqBody struct {
+	Amount   *int64           `json:"amount"`
+	RunAt    *strfmt.DateTime `json:"runAt"`
+	Interval *string          `json:"interval"`
+	Paused   *bool            `json:"paused"`
+}
+
+type ScheduleQuery struct {
+	ID        int64          `json:"id"`
+	UserID    int64          `json:"user_id"`
+	Username  string         `json:"username"`
+	Recipient string         `json:"recipient"`
+	Amount    *int64         `json:"amount"`
+	RunAt     *time.Time     `json:"run_at"`
+	Interval  *time.Duration `json:"interval"`
+	Paused    *bool          `json:"paused"`
+}
+
+type ScheduleRunDB struct {
+	ID          int64           `db:"id"`
+	ScheduleID  int64           `db:"schedule_id"`
+	ScheduledAt strfmt.DateTime `db:"scheduled_at"`
+	Status      string          `db:"status"`
+	Error       *string         `db:"error"`
+	CreatedAt   strfmt.DateTime `db:"created_at"`
+}
+
+func (srdb *ScheduleRunDB) ToModelScheduleRun() ScheduleRun {
+	run := ScheduleRun{
+		ID:          srdb.ID,
+		ScheduleID:  srdb.ScheduleID,
+		ScheduledAt: time.Time(srdb.ScheduledAt),
+		Status:      srdb.Status,
+		ExecutedAt:  time.Time(srdb.CreatedAt),
+	}
+	if srdb.Error != nil {
+		run.Error = *srdb.Error
+	}
+
+	return run
+}
+
+type ScheduleRun struct {
+	ID          int64     `json:"id"`
+	ScheduleID  int64     `json:"schedule_id"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error"`
+	ExecutedAt  time.Time `json:"executed_at"`
+}
+
+func (sr *ScheduleRun) ToModelScheduleRunDTO() ScheduleRunDTO {
+	return ScheduleRunDTO{
+		ScheduledAt: strfmt.DateTime(sr.ScheduledAt),
+		ExecutedAt:  strfmt.DateTime(sr.ExecutedAt),
+		Status:      sr.Status,
+		Error:       sr.Error,
+	}
+}
+
+type ScheduleRunDTO struct {
+	ScheduledAt strfmt.DateTime `json:"scheduledAt"`
+	ExecutedAt  strfmt.DateTime `json:"executedAt"`
+	Status      string          `json:"status"`
+	Error       string          `json:"error,omitempty"`
+}
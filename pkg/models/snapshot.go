@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// BalanceSnapshot остаток баланса на конец суток Day (UTC)
+type BalanceSnapshot struct {
+	BalanceID int64     `json:"balance_id"`
+	Day       time.Time `json:"day"`
+	Amount    int64     `json:"amount"`
+}
+
+// BalanceChange изменение баланса за период и количество операций
+type BalanceChange struct {
+	Amount int64 `json:"amount"`
+	Count  int64 `json:"count"`
+}
+
+type BalanceAtQuery struct {
+	Username string    `json:"username"`
+	At       time.Time `json:"at"`
+}
+
+type BalanceAtDTO struct {
+	Username string          `json:"username"`
+	At       strfmt.DateTime `json:"at"`
+	Amount   int64           `json:"amount"`
+	// SnapshotDay снимок, от которого восстановлен баланс, пусто - восстановлен от текущего остатка
+	SnapshotDay string `json:"snapshotDay,omitempty"`
+	// ReplayedEntries сколько операций истории применено к снимку
+	ReplayedEntries int64 `json:"replayedEntries"`
+}
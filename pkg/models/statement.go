@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	StatementFormatCSV   = "csv"
+	StatementFormatJSONL = "jsonl"
+
+	StatementRecordOpening = "opening"
+	StatementRecordEntry   = "entry"
+	StatementRecordClosing = "closing"
+
+	StatementDirectionCredit = "credit"
+	StatementDirectionDebit  = "debit"
+)
+
+// StatementCSVHeader порядок колонок CSV-выписки, совпадает с StatementLineDTO.CSVRecord
+var StatementCSVHeader = []string{"record", "at", "id", "type", "direction", "amount", "counterparty", "item", "reason", "balance"}
+
+type StatementQuery struct {
+	Username string    `json:"username"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+}
+
+// StatementOpening баланс и его значение на начало периода выписки, CreatedAt - регистрация владельца
+type StatementOpening struct {
+	BalanceID int64
+	Amount    int64
+	CreatedAt time.Time
+}
+
+// StatementLineDTO строка выписки: входящий остаток, операция или исходящий остаток.
+// Для операций Balance - остаток после операции.
+type StatementLineDTO struct {
+	Record       string          `json:"record"`
+	At           strfmt.DateTime `json:"at"`
+	ID           int64           `json:"id,omitempty"`
+	Type         string          `json:"type,omitempty"`
+	Direction    string          `json:"direction,omitempty"`
+	Amount       int64           `json:"amount,omitempty"`
+	Counterparty string          `json:"counterparty,omitempty"`
+	Item         string          `json:"item,omitempty"`
+	Reason       string          `json:"reason,omitempty"`
+	Balance      int64           `json:"balance"`
+}
+
+func (sl *StatementLineDTO) CSVRecord() []string {
+	record := []string{sl.Record, sl.At.String(), "", sl.Type, sl.Direction, "", csvText(sl.Counterparty), csvText(sl.Item), csvText(sl.Reason), strconv.FormatInt(sl.Balance, 10)}
+	if sl.Record == StatementRecordEntry {
+		record[2] = strconv.FormatInt(sl.ID, 10)
+		record[5] = strconv.FormatInt(sl.Amount, 10)
+	}
+
+	return record
+}
+
+// csvText экранирует текст пользователя, который табличные редакторы иначе выполнили бы как формулу
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
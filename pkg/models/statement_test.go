@@ -0,0 +1,42 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStatementLineDTO_CSVRecord(t *testing.T) {
+	tests := []struct {
+		name string
+		line StatementLineDTO
+		want []string
+	}{
+		{
+			name: "success_-_entry_record",
+			line: StatementLineDTO{Record: StatementRecordEntry, ID: 7, Type: "transfer", Direction: StatementDirectionDebit, Amount: 50, Counterparty: "user2", Reason: "lunch", Balance: 950},
+			want: []string{"entry", "0001-01-01T00:00:00.000Z", "7", "transfer", "debit", "50", "user2", "", "lunch", "950"},
+		},
+		{
+			name: "success_-_opening_record_without_entry_fields",
+			line: StatementLineDTO{Record: StatementRecordOpening, Balance: 1000},
+			want: []string{"opening", "0001-01-01T00:00:00.000Z", "", "", "", "", "", "", "", "1000"},
+		},
+		{
+			name: "success_-_formulas_escaped",
+			line: StatementLineDTO{Record: StatementRecordEntry, ID: 8, Type: "transfer", Direction: StatementDirectionCredit, Amount: 10, Counterparty: "@user", Item: "+cup", Reason: "=HYPERLINK(\"x\")", Balance: 10},
+			want: []string{"entry", "0001-01-01T00:00:00.000Z", "8", "transfer", "credit", "10", "'@user", "'+cup", "'=HYPERLINK(\"x\")", "10"},
+		},
+		{
+			name: "success_-_control_characters_escaped",
+			line: StatementLineDTO{Record: StatementRecordEntry, Reason: "\t-1", Item: "\rcup"},
+			want: []string{"entry", "0001-01-01T00:00:00.000Z", "0", "", "", "0", "", "'\rcup", "'\t-1", "0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.line.CSVRecord(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StatementLineDTO.CSVRecord() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
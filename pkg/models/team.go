@@ -0,0 +1,149 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+const (
+	TeamRoleOwner  = "owner"
+	TeamRoleMember = "member"
+	// teamAccountPrefix отличает команду от пользователя в истории: имена пользователей состоят только из букв и цифр
+	teamAccountPrefix = "team:"
+)
+
+// TeamAccountName имя команды в истории баланса
+func TeamAccountName(name string) string {
+	return teamAccountPrefix + name
+}
+
+// IsTeamAccount true, если имя в истории баланса принадлежит команде, а не пользователю
+func IsTeamAccount(name string) bool {
+	return strings.HasPrefix(name, teamAccountPrefix)
+}
+
+type CreateTeamReqBody struct {
+	Name string `json:"name"`
+}
+
+type TeamMemberReqBody struct {
+	Role     string `json:"role"`
+	CanSpend bool   `json:"canSpend"`
+}
+
+type TeamDepositReqBody struct {
+	Amount int64 `json:"amount"`
+}
+
+type TeamSendCoinsReqBody struct {
+	Recipient string `json:"toUser"`
+	Amount    int64  `json:"amount"`
+}
+
+type TeamQuery struct {
+	ID       int64  `json:"id"`
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// TeamMemberQuery Actor - участник, меняющий состав команды, Username - добавляемый или удаляемый участник
+type TeamMemberQuery struct {
+	TeamID   int64  `json:"team_id"`
+	Actor    string `json:"actor"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	CanSpend bool   `json:"can_spend"`
+}
+
+// TeamTransferQuery Username - пользователь, пополняющий кошелёк или тратящий его от имени команды
+type TeamTransferQuery struct {
+	TeamID    int64  `json:"team_id"`
+	Username  string `json:"username"`
+	Recipient string `json:"recipient"`
+	Amount    int64  `json:"amount"`
+}
+
+// TeamItemQuery ForUser - участник, в инвентарь которого попадёт покупка, пустой для инвентаря команды
+type TeamItemQuery struct {
+	TeamID   int64  `json:"team_id"`
+	Username string `json:"username"`
+	Item     string `json:"item"`
+	ForUser  string `json:"for_user"`
+}
+
+type TeamDB struct {
+	ID          int64           `db:"id"`
+	Name        string          `db:"name"`
+	BalanceID   int64           `db:"balance_id"`
+	InventoryID int64           `db:"inventory_id"`
+	Amount      int64           `db:"amount"`
+	CreatedAt   strfmt.DateTime `db:"created_at"`
+}
+
+func (tdb *TeamDB) ToModelTeam() Team {
+	return Team{
+		ID:          tdb.ID,
+		Name:        tdb.Name,
+		BalanceID:   tdb.BalanceID,
+		InventoryID: tdb.InventoryID,
+		Amount:      tdb.Amount,
+		CreatedAt:   time.Time(tdb.CreatedAt),
+	}
+}
+
+type Team struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	BalanceID   int64     `json:"balance_id"`
+	InventoryID int64     `json:"inventory_id"`
+	Amount      int64     `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (t *Team) AccountName() string {
+	return TeamAccountName(t.Name)
+}
+
+type TeamMember struct {
+	TeamID   int64  `json:"team_id"`
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	CanSpend bool   `json:"can_spend"`
+}
+
+// CanSpendFunds владельцы всегда могут тратить кошелёк команды, участники - только с разрешением
+func (m *TeamMember) CanSpendFunds() bool {
+	return m.Role == TeamRoleOwner || m.CanSpend
+}
+
+func (m *TeamMember) ToModelTeamMemberDTO() TeamMemberDTO {
+	return TeamMemberDTO{
+		Username: m.Username,
+		Role:     m.Role,
+		CanSpend: m.CanSpendFunds(),
+	}
+}
+
+type TeamMemberDTO struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	CanSpend bool   `json:"canSpend"`
+}
+
+// TeamDTO Role и CanSpend относятся к запросившему пользователю.
+// Участники, инвентарь и история возвращаются только в карточке команды.
+type TeamDTO struct {
+	ID        int64            `json:"id"`
+	Name      string           `json:"name"`
+	Coins     int64            `json:"coins"`
+	Role      string           `json:"role"`
+	CanSpend  bool             `json:"canSpend"`
+	Members   []TeamMemberDTO  `json:"members,omitempty"`
+	Inventory []MerchDTO       `json:"inventory,omitempty"`
+	History   []TransactionDTO `json:"history,omitempty"`
+	CreatedAt strfmt.DateTime  `json:"createdAt"`
+}
@@ -0,0 +1,14 @@
+package models
+
+type TxIsoLevel string
+
+const (
+	ReadCommitted  TxIsoLevel = "read committed"
+	RepeatableRead TxIsoLevel = "repeatable read"
+	Serializable   TxIsoLevel = "serializable"
+)
+
+type TxOptions struct {
+	IsoLevel TxIsoLevel `json:"iso_level"`
+	ReadOnly bool       `json:"read_only"`
+}
@@ -2,11 +2,21 @@ package models
 
 import "github.com/go-openapi/strfmt"
 
+const (
+	UserStatusActive = "active"
+	// UserStatusFrozen вход и просмотр разрешены, отправка монет и покупки запрещены
+	UserStatusFrozen = "frozen"
+	// UserStatusSuspended вход запрещён
+	UserStatusSuspended = "suspended"
+)
+
 type UserDB struct {
 	ID           int64            `db:"id"`
 	BalanceID    int64            `db:"balance_id"`
 	Username     string           `db:"username"`
 	PasswordHash string           `db:"password_hash"`
+	Role         string           `db:"role"`
+	Status       string           `db:"status"`
 	DeletedAt    *strfmt.DateTime `db:"deleted_at"`
 	CreatedAt    strfmt.DateTime  `db:"created_at"`
 }
@@ -17,6 +27,9 @@ func (udb *UserDB) ToModelUser() *User {
 		BalanceID:    udb.BalanceID,
 		Username:     udb.Username,
 		PasswordHash: udb.PasswordHash,
+		Role:         udb.Role,
+		Status:       udb.Status,
+		Deleted:      udb.DeletedAt != nil,
 	}
 }
 
@@ -25,4 +38,8 @@ type User struct {
 	BalanceID    int64  `json:"balance_id"`
 	Username     string `json:"username"`
 	PasswordHash string `json:"password_hash"`
+	Role         string `json:"role"`
+	Status       string `json:"status"`
+	// Deleted аккаунт удалён (offboarded)
+	Deleted bool `json:"deleted"`
 }